@@ -22,6 +22,7 @@ import (
 	"sync/atomic"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	"github.com/jaegertracing/jaeger/cmd/agent/app/processors"
 )
@@ -33,6 +34,14 @@ type Agent struct {
 	httpAddr   atomic.Value // string, set once agent starts listening
 	logger     *zap.Logger
 	closer     io.Closer
+
+	// samplingCache persists the cached sampling strategies when the agent stops, nil if not cached
+	samplingCache io.Closer
+
+	// grpcServer receives spans from local clients, nil if disabled
+	grpcServer   *grpc.Server
+	grpcHostPort string
+	grpcAddr     atomic.Value // string, set once agent starts listening
 }
 
 // NewAgent creates the new Agent.
@@ -47,6 +56,7 @@ func NewAgent(
 		logger:     logger,
 	}
 	a.httpAddr.Store("")
+	a.grpcAddr.Store("")
 	return a
 }
 
@@ -72,6 +82,21 @@ func (a *Agent) Run() error {
 		}
 		a.logger.Info("agent's http server exiting")
 	}()
+	if a.grpcServer != nil {
+		grpcListener, err := net.Listen("tcp", a.grpcHostPort)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		a.grpcAddr.Store(grpcListener.Addr().String())
+		go func() {
+			a.logger.Info("Starting jaeger-agent gRPC server", zap.Int("grpc-port", grpcListener.Addr().(*net.TCPAddr).Port))
+			if err := a.grpcServer.Serve(grpcListener); err != nil {
+				a.logger.Error("gRPC server failure", zap.Error(err))
+			}
+			a.logger.Info("agent's gRPC server exiting")
+		}()
+	}
 	for _, processor := range a.processors {
 		go processor.Serve()
 	}
@@ -83,10 +108,23 @@ func (a *Agent) HTTPAddr() string {
 	return a.httpAddr.Load().(string)
 }
 
+// GRPCAddr returns the address that the gRPC server is listening on, empty if it is disabled
+func (a *Agent) GRPCAddr() string {
+	return a.grpcAddr.Load().(string)
+}
+
 // Stop forces all agent go routines to exit.
 func (a *Agent) Stop() {
 	for _, processor := range a.processors {
 		go processor.Stop()
 	}
 	a.closer.Close()
+	if a.grpcServer != nil {
+		a.grpcServer.Stop()
+	}
+	if a.samplingCache != nil {
+		if err := a.samplingCache.Close(); err != nil {
+			a.logger.Error("Failed to persist sampling strategies cache", zap.Error(err))
+		}
+	}
 }
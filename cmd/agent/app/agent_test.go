@@ -16,6 +16,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -28,9 +29,13 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
+	agentTestutils "github.com/jaegertracing/jaeger/cmd/agent/app/testutils"
+	"github.com/jaegertracing/jaeger/model"
 	jmetrics "github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
 func TestAgentStartError(t *testing.T) {
@@ -41,6 +46,44 @@ func TestAgentStartError(t *testing.T) {
 	assert.Error(t, agent.Run())
 }
 
+func TestAgentGRPCServerStartError(t *testing.T) {
+	cfg := &Builder{
+		HTTPServer: HTTPServerConfiguration{HostPort: ":0"},
+		GRPCServer: GRPCServerConfiguration{HostPort: "bad-address"},
+	}
+	agent, err := cfg.CreateAgent(fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	assert.Error(t, agent.Run())
+}
+
+func TestAgentGRPCServer(t *testing.T) {
+	rep := agentTestutils.NewInMemoryReporter()
+	cfg := &Builder{
+		HTTPServer: HTTPServerConfiguration{HostPort: ":0"},
+		GRPCServer: GRPCServerConfiguration{HostPort: "127.0.0.1:0"},
+	}
+	cfg.WithReporter(rep)
+	agent, err := cfg.CreateAgent(fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	assert.Equal(t, "", agent.GRPCAddr())
+	require.NoError(t, agent.Run())
+	defer agent.Stop()
+	require.NotEqual(t, "", agent.GRPCAddr())
+
+	conn, err := grpc.Dial(agent.GRPCAddr(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(context.Background(), &api_v2.PostSpansRequest{
+		Batch: model.Batch{
+			Process: &model.Process{ServiceName: "svc"},
+			Spans:   []*model.Span{{OperationName: "op"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, rep.Spans(), 1)
+	assert.Equal(t, "op", rep.Spans()[0].OperationName)
+}
+
 func TestAgentSamplingEndpoint(t *testing.T) {
 	withRunningAgent(t, func(httpAddr string, errorch chan error) {
 		url := fmt.Sprintf("http://%s/sampling?service=abc", httpAddr)
@@ -17,8 +17,10 @@ package app
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/pkg/errors"
@@ -26,7 +28,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/agent/app/configmanager"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/grpcserver"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/httpserver"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/pipeline"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/processors"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter/grpc"
@@ -43,6 +47,9 @@ const (
 	defaultMaxPacketSize = 65000
 	defaultServerWorkers = 10
 
+	// defaultMaxTracesBodySize allows span batches over HTTP well beyond the maximum UDP packet size
+	defaultMaxTracesBodySize = 10 * 1024 * 1024
+
 	jaegerModel Model = "jaeger"
 	zipkinModel Model = "zipkin"
 
@@ -75,6 +82,10 @@ type CollectorProxy interface {
 type Builder struct {
 	Processors []ProcessorConfiguration `yaml:"processors"`
 	HTTPServer HTTPServerConfiguration  `yaml:"httpServer"`
+	GRPCServer GRPCServerConfiguration  `yaml:"grpcServer"`
+	Pipeline   PipelineConfiguration    `yaml:"pipeline"`
+
+	SamplingCache SamplingCacheConfiguration `yaml:"samplingCache"`
 
 	reporters []reporter.Reporter
 }
@@ -97,6 +108,31 @@ type ServerConfiguration struct {
 // HTTPServerConfiguration holds config for a server providing sampling strategies and baggage restrictions to clients
 type HTTPServerConfiguration struct {
 	HostPort string `yaml:"hostPort" validate:"nonzero"`
+	// MaxTracesBodySize is the maximum size in bytes of a span batch submitted to /api/traces
+	MaxTracesBodySize int `yaml:"maxTracesBodySize"`
+}
+
+// GRPCServerConfiguration holds config for a server receiving spans from local clients over gRPC
+type GRPCServerConfiguration struct {
+	// HostPort of the server, the server is disabled if empty
+	HostPort string `yaml:"hostPort"`
+}
+
+// PipelineConfiguration holds config for the rules applied to the spans before they are reported
+type PipelineConfiguration struct {
+	// RulesFile is the path of the JSON file with the rules, the pipeline is disabled if empty
+	RulesFile string `yaml:"rulesFile"`
+}
+
+// SamplingCacheConfiguration holds config for the cache of the sampling strategies fetched from the collectors
+type SamplingCacheConfiguration struct {
+	// RefreshInterval of the cached strategies, the expired ones are served while they are fetched again
+	// in the background. If zero, they are fetched again after every request
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+	// File where the cache is persisted across restarts, the cache is only kept in memory if empty
+	File string `yaml:"file"`
+	// MaxEntries is the maximum number of services cached, the least recently used are evicted beyond it
+	MaxEntries int `yaml:"maxEntries"`
 }
 
 // WithReporter adds auxiliary reporters.
@@ -107,13 +143,23 @@ func (b *Builder) WithReporter(r ...reporter.Reporter) *Builder {
 
 // CreateAgent creates the Agent
 func (b *Builder) CreateAgent(primaryProxy CollectorProxy, logger *zap.Logger, mFactory metrics.Factory) (*Agent, error) {
-	r := b.getReporter(primaryProxy)
+	r, err := b.Pipeline.getReporter(b.getReporter(primaryProxy), mFactory)
+	if err != nil {
+		return nil, err
+	}
 	processors, err := b.getProcessors(r, mFactory, logger)
 	if err != nil {
 		return nil, err
 	}
-	server := b.HTTPServer.getHTTPServer(primaryProxy.GetManager(), mFactory)
-	return NewAgent(processors, server, logger), nil
+	manager := b.SamplingCache.getManager(primaryProxy.GetManager(), mFactory, logger)
+	server := b.HTTPServer.getHTTPServer(manager, r, mFactory)
+	agent := NewAgent(processors, server, logger)
+	agent.samplingCache = manager
+	if b.GRPCServer.HostPort != "" {
+		agent.grpcServer = grpcserver.NewServer(r, logger)
+		agent.grpcHostPort = b.GRPCServer.HostPort
+	}
+	return agent, nil
 }
 
 func (b *Builder) getReporter(primaryProxy CollectorProxy) reporter.Reporter {
@@ -128,6 +174,31 @@ func (b *Builder) getReporter(primaryProxy CollectorProxy) reporter.Reporter {
 	return reporter.NewMultiReporter(rep...)
 }
 
+// getReporter wraps the reporter with the span processing pipeline, if it is configured.
+func (c PipelineConfiguration) getReporter(rep reporter.Reporter, mFactory metrics.Factory) (reporter.Reporter, error) {
+	if c.RulesFile == "" {
+		return rep, nil
+	}
+	rules, err := pipeline.LoadRules(c.RulesFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load span processing rules")
+	}
+	p, err := pipeline.New(rules, mFactory)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create span processing pipeline")
+	}
+	return pipeline.NewReporter(rep, p), nil
+}
+
+// getManager wraps the manager with the cache of the sampling strategies.
+func (c SamplingCacheConfiguration) getManager(manager configmanager.ClientConfigManager, mFactory metrics.Factory, logger *zap.Logger) *configmanager.CachingManager {
+	return configmanager.NewCachingManager(manager, configmanager.CacheOptions{
+		RefreshInterval: c.RefreshInterval,
+		File:            c.File,
+		MaxEntries:      c.MaxEntries,
+	}, mFactory, logger)
+}
+
 func (b *Builder) getProcessors(rep reporter.Reporter, mFactory metrics.Factory, logger *zap.Logger) ([]processors.Processor, error) {
 	retMe := make([]processors.Processor, len(b.Processors))
 	for idx, cfg := range b.Processors {
@@ -157,12 +228,14 @@ func (b *Builder) getProcessors(rep reporter.Reporter, mFactory metrics.Factory,
 	return retMe, nil
 }
 
-// GetHTTPServer creates an HTTP server that provides sampling strategies and baggage restrictions to client libraries.
-func (c HTTPServerConfiguration) getHTTPServer(manager configmanager.ClientConfigManager, mFactory metrics.Factory) *http.Server {
+// GetHTTPServer creates an HTTP server that provides sampling strategies and baggage restrictions to client libraries,
+// and accepts spans from them.
+func (c HTTPServerConfiguration) getHTTPServer(manager configmanager.ClientConfigManager, rep reporter.Reporter, mFactory metrics.Factory) *http.Server {
 	if c.HostPort == "" {
 		c.HostPort = defaultHTTPServerHostPort
 	}
-	return httpserver.NewHTTPServer(c.HostPort, manager, mFactory)
+	maxTracesBodySize := defaultInt(c.MaxTracesBodySize, defaultMaxTracesBodySize)
+	return httpserver.NewHTTPServer(c.HostPort, manager, rep, mFactory, int64(maxTracesBodySize))
 }
 
 // GetThriftProcessor gets a TBufferedServer backed Processor using the collector configuration
@@ -220,6 +293,30 @@ func CreateCollectorProxy(
 	grpcBuilder *grpc.ConnBuilder,
 	logger *zap.Logger,
 	mFactory metrics.Factory,
+) (CollectorProxy, error) {
+	proxy, err := createCollectorProxy(opts, tchanBuilder, grpcBuilder, logger, mFactory)
+	if err != nil || opts.Spool.Directory == "" {
+		return proxy, err
+	}
+	spoolingReporter, err := reporter.NewSpoolingReporter(proxy.GetReporter(), opts.Spool, mFactory, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create reporter spool")
+	}
+	if p, ok := proxy.(failedBatchSource); ok {
+		// batches coalesced by the reporter fail to send after they were accepted, and
+		// the spooled batches are only removed from the spool once they are sent
+		p.OnFailedBatch(spoolingReporter.SpoolBatch)
+		spoolingReporter.ReplayWith(p.GetUnbatchedReporter())
+	}
+	return &spoolingProxy{CollectorProxy: proxy, reporter: spoolingReporter}, nil
+}
+
+func createCollectorProxy(
+	opts *reporter.Options,
+	tchanBuilder *tchannel.Builder,
+	grpcBuilder *grpc.ConnBuilder,
+	logger *zap.Logger,
+	mFactory metrics.Factory,
 ) (CollectorProxy, error) {
 	// GRPC type is set as default in viper, but we check for legacy flags
 	// to keep backward compatibility
@@ -238,3 +335,31 @@ func CreateCollectorProxy(
 		return nil, errors.New(fmt.Sprintf("unknown reporter type %s", string(opts.ReporterType)))
 	}
 }
+
+// failedBatchSource is implemented by the proxies whose reporter can fail to send batches it accepted.
+type failedBatchSource interface {
+	OnFailedBatch(handler func(*jaegerThrift.Batch) error)
+	GetUnbatchedReporter() reporter.Reporter
+}
+
+// spoolingProxy is a CollectorProxy whose reporter spools the batches it fails to send.
+type spoolingProxy struct {
+	CollectorProxy
+	reporter *reporter.SpoolingReporter
+}
+
+// GetReporter returns the spooling reporter
+func (p *spoolingProxy) GetReporter() reporter.Reporter {
+	return p.reporter
+}
+
+// Close stops the spool and closes the wrapped proxy.
+func (p *spoolingProxy) Close() error {
+	err := p.reporter.Close()
+	if closer, ok := p.CollectorProxy.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
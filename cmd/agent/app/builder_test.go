@@ -19,6 +19,9 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -33,6 +36,7 @@ import (
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/jaegertracing/jaeger/cmd/agent/app/configmanager"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/pipeline"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter/grpc"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter/tchannel"
@@ -151,6 +155,45 @@ func TestBuilderWithProcessorErrors(t *testing.T) {
 	}
 }
 
+func TestBuilderWithPipeline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipeline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	rulesFile := dir + "/rules.json"
+	require.NoError(t, ioutil.WriteFile(rulesFile, []byte(`{"rules": [{"action": "drop-span", "operationPattern": "^health"}]}`), 0600))
+
+	r, err := (&PipelineConfiguration{RulesFile: rulesFile}).getReporter(fakeCollectorProxy{}, metrics.NullFactory)
+	require.NoError(t, err)
+	_, ok := r.(*pipeline.Reporter)
+	assert.True(t, ok)
+
+	r, err = (&PipelineConfiguration{}).getReporter(fakeCollectorProxy{}, metrics.NullFactory)
+	require.NoError(t, err)
+	assert.Equal(t, fakeCollectorProxy{}, r)
+
+	cfg := &Builder{Pipeline: PipelineConfiguration{RulesFile: rulesFile}}
+	agent, err := cfg.CreateAgent(fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	require.NoError(t, err)
+	assert.NotNil(t, agent)
+
+	cfg = &Builder{Pipeline: PipelineConfiguration{RulesFile: dir + "/missing.json"}}
+	_, err = cfg.CreateAgent(fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	assert.Contains(t, err.Error(), "cannot load span processing rules")
+
+	require.NoError(t, ioutil.WriteFile(rulesFile, []byte(`{"rules": [{"action": "drop-span"}]}`), 0600))
+	cfg = &Builder{Pipeline: PipelineConfiguration{RulesFile: rulesFile}}
+	_, err = cfg.CreateAgent(fakeCollectorProxy{}, zap.NewNop(), metrics.NullFactory)
+	assert.Contains(t, err.Error(), "cannot create span processing pipeline")
+}
+
+func TestBuilderWithSamplingCache(t *testing.T) {
+	cfg := SamplingCacheConfiguration{RefreshInterval: time.Minute}
+	manager := cfg.getManager(fakeCollectorProxy{}, metrics.NullFactory, zap.NewNop())
+	_, err := manager.GetSamplingStrategy("svc")
+	assert.EqualError(t, err, "no peers available")
+	assert.NoError(t, manager.Close())
+}
+
 func TestMultipleCollectorProxies(t *testing.T) {
 	b := Builder{}
 	ra := fakeCollectorProxy{}
@@ -258,6 +301,58 @@ func TestCreateCollectorProxy(t *testing.T) {
 	}
 }
 
+func TestCreateCollectorProxy_Spool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rOpts := new(reporter.Options)
+	rOpts.ReporterType = reporter.TCHANNEL
+	rOpts.Spool = reporter.SpoolOptions{Directory: dir, MaxSizeBytes: 1024, RetryInterval: time.Hour}
+	tchan := tchannel.NewBuilder()
+	tchan.CollectorHostPorts = []string{"foo"}
+	grpcBuilder := grpc.NewConnBuilder()
+
+	metricsFactory := metricstest.NewFactory(time.Microsecond)
+	proxy, err := CreateCollectorProxy(rOpts, tchan, grpcBuilder, zap.NewNop(), metricsFactory)
+	require.NoError(t, err)
+	assert.NoError(t, proxy.GetReporter().EmitBatch(&jaeger.Batch{Process: &jaeger.Process{ServiceName: "svc"}}))
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "reporter.batches.failures", Tags: map[string]string{"protocol": "tchannel", "format": "jaeger"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "reporter.spool.batches.spooled", Value: 1},
+	)
+	require.NoError(t, proxy.(io.Closer).Close())
+
+	rOpts.Spool.Directory = "/dev/null/spool"
+	_, err = CreateCollectorProxy(rOpts, tchan, grpcBuilder, zap.NewNop(), metrics.NullFactory)
+	assert.Error(t, err)
+}
+
+func TestCreateCollectorProxy_SpoolCoalescedBatches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rOpts := new(reporter.Options)
+	rOpts.ReporterType = reporter.GRPC
+	rOpts.Spool = reporter.SpoolOptions{Directory: dir, MaxSizeBytes: 1024, RetryInterval: time.Hour}
+	grpcBuilder := grpc.NewConnBuilder()
+	grpcBuilder.CollectorHostPorts = []string{"localhost:1"}
+	// every batch is sent as soon as it is accepted
+	grpcBuilder.BatchMaxBytes = 1
+	grpcBuilder.BatchMaxDelay = time.Hour
+
+	metricsFactory := metricstest.NewFactory(0)
+	proxy, err := CreateCollectorProxy(rOpts, tchannel.NewBuilder(), grpcBuilder, zap.NewNop(), metricsFactory)
+	require.NoError(t, err)
+	batch := &jaeger.Batch{Process: &jaeger.Process{ServiceName: "svc"}, Spans: []*jaeger.Span{{OperationName: "op"}}}
+	assert.NoError(t, proxy.GetReporter().EmitBatch(batch))
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "reporter.spool.batches.spooled", Value: 1},
+	)
+	require.NoError(t, proxy.(io.Closer).Close())
+}
+
 func TestCreateCollectorProxy_UnknownReporter(t *testing.T) {
 	rOpts := new(reporter.Options)
 	tchan := tchannel.NewBuilder()
@@ -0,0 +1,360 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmanager
+
+import (
+	"container/list"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/thrift-gen/baggage"
+	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
+)
+
+// CacheOptions holds the configuration of CachingManager.
+type CacheOptions struct {
+	// RefreshInterval is how long a sampling strategy is served from the cache before it is fetched again.
+	// Expired strategies are still served while they are fetched in the background. If zero, a strategy
+	// is fetched again after every request.
+	RefreshInterval time.Duration
+	// File is where the cache is persisted across restarts, the cache is only kept in memory if empty.
+	File string
+	// MaxEntries is the maximum number of services cached, the least recently used are evicted beyond it.
+	MaxEntries int
+	// PersistInterval is how often the cache is written to the file when it changed.
+	PersistInterval time.Duration
+}
+
+const (
+	defaultCacheMaxEntries      = 10000
+	defaultCachePersistInterval = 10 * time.Second
+)
+
+var (
+	// minRefreshBackoff and maxRefreshBackoff bound the delay before fetching again a strategy that
+	// could not be refreshed, it doubles on every failure. Vars to allow overriding in unit tests.
+	minRefreshBackoff = time.Second
+	maxRefreshBackoff = time.Minute
+)
+
+// cacheMetrics holds metrics related to CachingManager
+type cacheMetrics struct {
+	// Number of sampling strategies served from the cache
+	Hits metrics.Counter `metric:"sampling-cache.requests" tags:"result=hit"`
+
+	// Number of sampling strategies fetched from the collector
+	Misses metrics.Counter `metric:"sampling-cache.requests" tags:"result=miss"`
+
+	// Number of expired sampling strategies served from the cache while they are refreshed
+	StaleHits metrics.Counter `metric:"sampling-cache.requests" tags:"result=stale"`
+
+	// Number of failures to refresh expired sampling strategies in the background
+	RefreshFailures metrics.Counter `metric:"sampling-cache.refresh-failures"`
+
+	// Number of services in the cache
+	Entries metrics.Gauge `metric:"sampling-cache.entries"`
+
+	// Number of least recently used services evicted from the full cache
+	Evictions metrics.Counter `metric:"sampling-cache.evictions"`
+
+	// Number of failures to write the cache file
+	PersistFailures metrics.Counter `metric:"sampling-cache.persist-failures"`
+}
+
+type cacheEntry struct {
+	serviceName string
+	strategy    *sampling.SamplingStrategyResponse
+	// fetched is zero for the entries loaded from the file, so that they are refreshed on first use
+	fetched time.Time
+	// failures is the number of consecutive failed refreshes, the next one is not started before retryAt
+	failures int
+	retryAt  time.Time
+}
+
+// cacheFile is the format of the file where the cache is persisted.
+type cacheFile struct {
+	Strategies map[string]*sampling.SamplingStrategyResponse `json:"strategies"`
+}
+
+// CachingManager is a ClientConfigManager that caches the sampling strategies per service,
+// and serves the last known strategy of a service when it cannot be fetched from the collector.
+// Only the strategies of the services not cached yet are fetched while serving a request, the
+// expired ones are refreshed in the background so that requests never wait for a collector that is down.
+type CachingManager struct {
+	wrapped ClientConfigManager
+	options CacheOptions
+	logger  *zap.Logger
+	metrics cacheMetrics
+	timeNow func() time.Time
+
+	mux     sync.Mutex
+	entries map[string]*list.Element
+	// recent holds the entries, most recently used first
+	recent *list.List
+	// dirty is set when the entries changed since they were last persisted
+	dirty bool
+	// refreshing holds the services whose strategy is being refreshed in the background
+	refreshing map[string]struct{}
+	closed     bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewCachingManager creates a CachingManager, loading the cache from the file if it exists.
+// A file that cannot be read is logged and ignored, since the cache is rebuilt from the collector.
+// If the cache is persisted, the changes are written to the file in the background until Close is called.
+func NewCachingManager(manager ClientConfigManager, options CacheOptions, mFactory metrics.Factory, logger *zap.Logger) *CachingManager {
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = defaultCacheMaxEntries
+	}
+	if options.PersistInterval <= 0 {
+		options.PersistInterval = defaultCachePersistInterval
+	}
+	m := &CachingManager{
+		wrapped:    manager,
+		options:    options,
+		logger:     logger,
+		timeNow:    time.Now,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+		refreshing: make(map[string]struct{}),
+		stop:       make(chan struct{}),
+	}
+	metrics.Init(&m.metrics, mFactory, nil)
+	if options.File != "" {
+		if err := m.load(); err != nil {
+			logger.Warn("Cannot load sampling strategies cache", zap.String("file", options.File), zap.Error(err))
+		}
+		m.wg.Add(1)
+		go m.runPersistLoop()
+	}
+	m.metrics.Entries.Update(int64(len(m.entries)))
+	return m
+}
+
+// GetSamplingStrategy returns the cached sampling strategy of the service, starting to refresh it in the
+// background if it expired. The strategy is only fetched from the wrapped manager if it is not cached.
+func (m *CachingManager) GetSamplingStrategy(serviceName string) (*sampling.SamplingStrategyResponse, error) {
+	entry, cached := m.get(serviceName)
+	if !cached {
+		m.metrics.Misses.Inc(1)
+		strategy, err := m.wrapped.GetSamplingStrategy(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		m.put(cacheEntry{serviceName: serviceName, strategy: strategy, fetched: m.timeNow()})
+		return strategy, nil
+	}
+	if !entry.fetched.IsZero() && m.timeNow().Sub(entry.fetched) < m.options.RefreshInterval {
+		m.metrics.Hits.Inc(1)
+		return entry.strategy, nil
+	}
+	m.metrics.StaleHits.Inc(1)
+	m.startRefresh(serviceName)
+	return entry.strategy, nil
+}
+
+// startRefresh fetches the strategy of the service in the background, unless it is already being
+// fetched or its previous refresh failed less than the backoff ago.
+func (m *CachingManager) startRefresh(serviceName string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	elt, ok := m.entries[serviceName]
+	if !ok || m.closed {
+		return
+	}
+	if _, ok := m.refreshing[serviceName]; ok {
+		return
+	}
+	if m.timeNow().Before(elt.Value.(*cacheEntry).retryAt) {
+		return
+	}
+	m.refreshing[serviceName] = struct{}{}
+	m.wg.Add(1)
+	go m.refresh(serviceName)
+}
+
+func (m *CachingManager) refresh(serviceName string) {
+	defer m.wg.Done()
+	strategy, err := m.wrapped.GetSamplingStrategy(serviceName)
+	if err == nil {
+		m.put(cacheEntry{serviceName: serviceName, strategy: strategy, fetched: m.timeNow()})
+	}
+	m.mux.Lock()
+	delete(m.refreshing, serviceName)
+	if elt, ok := m.entries[serviceName]; ok && err != nil {
+		entry := elt.Value.(*cacheEntry)
+		entry.failures++
+		entry.retryAt = m.timeNow().Add(refreshBackoff(entry.failures))
+	}
+	m.mux.Unlock()
+	if err != nil {
+		m.metrics.RefreshFailures.Inc(1)
+		m.logger.Debug("Cannot refresh sampling strategy", zap.String("service", serviceName), zap.Error(err))
+	}
+}
+
+// refreshBackoff returns the delay before the next refresh after the given number of consecutive failures.
+func refreshBackoff(failures int) time.Duration {
+	backoff := minRefreshBackoff
+	for i := 1; i < failures && backoff < maxRefreshBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRefreshBackoff {
+		return maxRefreshBackoff
+	}
+	return backoff
+}
+
+// GetBaggageRestrictions returns baggage restrictions from the wrapped manager, they are not cached.
+func (m *CachingManager) GetBaggageRestrictions(serviceName string) ([]*baggage.BaggageRestriction, error) {
+	return m.wrapped.GetBaggageRestrictions(serviceName)
+}
+
+// Close waits for the refreshes in progress, stops persisting the cache in the background and
+// writes the pending changes to the file.
+func (m *CachingManager) Close() error {
+	m.mux.Lock()
+	m.closed = true
+	m.mux.Unlock()
+	close(m.stop)
+	m.wg.Wait()
+	if m.options.File == "" {
+		return nil
+	}
+	return m.persist()
+}
+
+// get returns the cached entry of the service and marks it as the most recently used.
+func (m *CachingManager) get(serviceName string) (cacheEntry, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	elt, ok := m.entries[serviceName]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	m.recent.MoveToFront(elt)
+	return *elt.Value.(*cacheEntry), true
+}
+
+// put caches the entry as the most recently used, evicting the least recently used entries beyond
+// the maximum. The cache is marked dirty if the strategy of the service changed.
+func (m *CachingManager) put(entry cacheEntry) {
+	m.mux.Lock()
+	if elt, ok := m.entries[entry.serviceName]; ok {
+		previous := elt.Value.(*cacheEntry)
+		if !reflect.DeepEqual(previous.strategy, entry.strategy) {
+			m.dirty = true
+		}
+		*previous = entry
+		m.recent.MoveToFront(elt)
+	} else {
+		m.entries[entry.serviceName] = m.recent.PushFront(&entry)
+		m.dirty = true
+	}
+	var evicted int64
+	for len(m.entries) > m.options.MaxEntries {
+		oldest := m.recent.Back()
+		m.recent.Remove(oldest)
+		delete(m.entries, oldest.Value.(*cacheEntry).serviceName)
+		evicted++
+	}
+	size := len(m.entries)
+	m.mux.Unlock()
+	m.metrics.Evictions.Inc(evicted)
+	m.metrics.Entries.Update(int64(size))
+}
+
+func (m *CachingManager) load() error {
+	content, err := ioutil.ReadFile(m.options.File)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file cacheFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return errors.Wrap(err, "cannot unmarshal sampling strategies")
+	}
+	for serviceName, strategy := range file.Strategies {
+		if strategy == nil || len(m.entries) >= m.options.MaxEntries {
+			continue
+		}
+		m.entries[serviceName] = m.recent.PushBack(&cacheEntry{serviceName: serviceName, strategy: strategy})
+	}
+	return nil
+}
+
+func (m *CachingManager) runPersistLoop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.options.PersistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.persist()
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// persist writes all the cached strategies to the file, replacing it atomically, if they changed
+// since the last write. A failed write is retried on the next call.
+func (m *CachingManager) persist() error {
+	m.mux.Lock()
+	if !m.dirty {
+		m.mux.Unlock()
+		return nil
+	}
+	file := cacheFile{Strategies: make(map[string]*sampling.SamplingStrategyResponse, len(m.entries))}
+	for serviceName, elt := range m.entries {
+		file.Strategies[serviceName] = elt.Value.(*cacheEntry).strategy
+	}
+	m.dirty = false
+	m.mux.Unlock()
+
+	if err := writeCacheFile(m.options.File, file); err != nil {
+		m.mux.Lock()
+		m.dirty = true
+		m.mux.Unlock()
+		m.metrics.PersistFailures.Inc(1)
+		m.logger.Error("Cannot persist sampling strategies cache", zap.String("file", m.options.File), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func writeCacheFile(path string, file cacheFile) error {
+	content, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,319 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmanager
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/thrift-gen/baggage"
+	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
+)
+
+// fakeCollector returns a probabilistic strategy with the configured rate, unless it is down.
+type fakeCollector struct {
+	sync.Mutex
+	rate  float64
+	down  bool
+	calls int
+}
+
+func (c *fakeCollector) set(rate float64, down bool) {
+	c.Lock()
+	defer c.Unlock()
+	c.rate = rate
+	c.down = down
+}
+
+func (c *fakeCollector) GetSamplingStrategy(serviceName string) (*sampling.SamplingStrategyResponse, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.calls++
+	if c.down {
+		return nil, errors.New("collector is down")
+	}
+	return probabilistic(c.rate), nil
+}
+
+func (c *fakeCollector) GetBaggageRestrictions(serviceName string) ([]*baggage.BaggageRestriction, error) {
+	return []*baggage.BaggageRestriction{{BaggageKey: "foo"}}, nil
+}
+
+func probabilistic(rate float64) *sampling.SamplingStrategyResponse {
+	return &sampling.SamplingStrategyResponse{
+		StrategyType:          sampling.SamplingStrategyType_PROBABILISTIC,
+		ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{SamplingRate: rate},
+	}
+}
+
+func withCacheDir(t *testing.T, test func(dir string)) {
+	dir, err := ioutil.TempDir("", "sampling-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	test(dir)
+}
+
+func assertRequests(t *testing.T, mFactory *metricstest.Factory, hit, miss, stale int) {
+	mFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "sampling-cache.requests", Tags: map[string]string{"result": "hit"}, Value: hit},
+		metricstest.ExpectedMetric{Name: "sampling-cache.requests", Tags: map[string]string{"result": "miss"}, Value: miss},
+		metricstest.ExpectedMetric{Name: "sampling-cache.requests", Tags: map[string]string{"result": "stale"}, Value: stale},
+	)
+}
+
+// waitForRefreshes waits until the strategies being refreshed in the background are fetched.
+func waitForRefreshes(t *testing.T, m *CachingManager) {
+	for i := 0; i < 1000; i++ {
+		m.mux.Lock()
+		refreshing := len(m.refreshing)
+		m.mux.Unlock()
+		if refreshing == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("the strategies were not refreshed")
+}
+
+func TestCachingManagerRefresh(t *testing.T) {
+	collector := &fakeCollector{rate: 0.1}
+	mFactory := metricstest.NewFactory(0)
+	m := NewCachingManager(collector, CacheOptions{RefreshInterval: time.Minute}, mFactory, zap.NewNop())
+	now := time.Unix(1000, 0)
+	m.timeNow = func() time.Time { return now }
+
+	s, err := m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.1), s)
+
+	collector.set(0.2, false)
+	now = now.Add(30 * time.Second)
+	s, err = m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.1), s)
+	assert.Equal(t, 1, collector.calls)
+
+	// the expired strategy is served while it is refreshed in the background
+	now = now.Add(30 * time.Second)
+	s, err = m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.1), s)
+	waitForRefreshes(t, m)
+	assert.Equal(t, 2, collector.calls)
+	s, err = m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.2), s)
+
+	_, err = m.GetSamplingStrategy("other")
+	require.NoError(t, err)
+	assertRequests(t, mFactory, 2, 2, 1)
+	mFactory.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "sampling-cache.entries", Value: 2})
+
+	b, err := m.GetBaggageRestrictions("svc")
+	require.NoError(t, err)
+	assert.Equal(t, []*baggage.BaggageRestriction{{BaggageKey: "foo"}}, b)
+	require.NoError(t, m.Close())
+}
+
+func TestCachingManagerStaleFallback(t *testing.T) {
+	collector := &fakeCollector{rate: 0.1}
+	mFactory := metricstest.NewFactory(0)
+	m := NewCachingManager(collector, CacheOptions{}, mFactory, zap.NewNop())
+	defer m.Close()
+
+	s, err := m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.1), s)
+
+	collector.set(0.2, true)
+	s, err = m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.1), s)
+	waitForRefreshes(t, m)
+
+	_, err = m.GetSamplingStrategy("other")
+	assert.EqualError(t, err, "collector is down")
+
+	collector.set(0.2, false)
+	now := time.Now().Add(minRefreshBackoff)
+	m.timeNow = func() time.Time { return now }
+	s, err = m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.1), s)
+	waitForRefreshes(t, m)
+	s, err = m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	assert.Equal(t, probabilistic(0.2), s)
+	// without refresh interval, every request refreshes the strategy
+	waitForRefreshes(t, m)
+	assert.Equal(t, 5, collector.calls)
+	assertRequests(t, mFactory, 0, 2, 3)
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "sampling-cache.refresh-failures", Value: 1})
+}
+
+func TestCachingManagerRefreshBackoff(t *testing.T) {
+	collector := &fakeCollector{rate: 0.1}
+	m := NewCachingManager(collector, CacheOptions{RefreshInterval: time.Minute}, metrics.NullFactory, zap.NewNop())
+	defer m.Close()
+	now := time.Unix(1000, 0)
+	m.timeNow = func() time.Time { return now }
+
+	_, err := m.GetSamplingStrategy("svc")
+	require.NoError(t, err)
+	collector.set(0.2, true)
+	now = now.Add(time.Minute)
+	for i := 1; i <= 3; i++ {
+		// the strategy is fetched again only once the backoff elapsed
+		_, err = m.GetSamplingStrategy("svc")
+		require.NoError(t, err)
+		waitForRefreshes(t, m)
+		_, err = m.GetSamplingStrategy("svc")
+		require.NoError(t, err)
+		waitForRefreshes(t, m)
+		assert.Equal(t, 1+i, collector.calls)
+		now = now.Add(refreshBackoff(i))
+	}
+
+	assert.Equal(t, time.Second, refreshBackoff(1))
+	assert.Equal(t, 4*time.Second, refreshBackoff(3))
+	assert.Equal(t, time.Minute, refreshBackoff(10))
+	assert.Equal(t, time.Minute, refreshBackoff(1000))
+}
+
+func TestCachingManagerPersistence(t *testing.T) {
+	withCacheDir(t, func(dir string) {
+		file := filepath.Join(dir, "sampling.json")
+		collector := &fakeCollector{rate: 0.1}
+		m := NewCachingManager(collector, CacheOptions{File: file}, metrics.NullFactory, zap.NewNop())
+		_, err := m.GetSamplingStrategy("svc")
+		require.NoError(t, err)
+		_, err = m.GetSamplingStrategy("other")
+		require.NoError(t, err)
+		// the changes are only written in the background
+		_, err = os.Stat(file)
+		assert.True(t, os.IsNotExist(err))
+		require.NoError(t, m.persist())
+
+		info, err := os.Stat(file)
+		require.NoError(t, err)
+		// the file is not rewritten when the strategies do not change
+		require.NoError(t, os.Chtimes(file, time.Unix(0, 0), time.Unix(0, 0)))
+		_, err = m.GetSamplingStrategy("svc")
+		require.NoError(t, err)
+		require.NoError(t, m.Close())
+		unchanged, err := os.Stat(file)
+		require.NoError(t, err)
+		assert.Equal(t, time.Unix(0, 0), unchanged.ModTime())
+		assert.Equal(t, info.Size(), unchanged.Size())
+
+		// after a restart, the persisted strategies are served while the collector is down
+		collector.set(0.2, true)
+		mFactory := metricstest.NewFactory(0)
+		m = NewCachingManager(collector, CacheOptions{RefreshInterval: time.Hour, File: file}, mFactory, zap.NewNop())
+		mFactory.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "sampling-cache.entries", Value: 2})
+		s, err := m.GetSamplingStrategy("svc")
+		require.NoError(t, err)
+		assert.Equal(t, probabilistic(0.1), s)
+		assertRequests(t, mFactory, 0, 0, 1)
+
+		// and refreshed on first use once the collector is back
+		collector.set(0.2, false)
+		s, err = m.GetSamplingStrategy("other")
+		require.NoError(t, err)
+		assert.Equal(t, probabilistic(0.1), s)
+		waitForRefreshes(t, m)
+		s, err = m.GetSamplingStrategy("other")
+		require.NoError(t, err)
+		assert.Equal(t, probabilistic(0.2), s)
+		assertRequests(t, mFactory, 1, 0, 2)
+		require.NoError(t, m.Close())
+
+		m = NewCachingManager(collector, CacheOptions{File: file}, metrics.NullFactory, zap.NewNop())
+		defer m.Close()
+		collector.set(0.3, true)
+		s, err = m.GetSamplingStrategy("other")
+		require.NoError(t, err)
+		assert.Equal(t, probabilistic(0.2), s)
+	})
+}
+
+func TestCachingManagerPersistsInBackground(t *testing.T) {
+	withCacheDir(t, func(dir string) {
+		file := filepath.Join(dir, "sampling.json")
+		m := NewCachingManager(&fakeCollector{rate: 0.1}, CacheOptions{File: file, PersistInterval: time.Millisecond}, metrics.NullFactory, zap.NewNop())
+		defer m.Close()
+		_, err := m.GetSamplingStrategy("svc")
+		require.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			if _, err = os.Stat(file); err == nil {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		assert.NoError(t, err)
+	})
+}
+
+func TestCachingManagerEviction(t *testing.T) {
+	collector := &fakeCollector{rate: 0.1}
+	mFactory := metricstest.NewFactory(0)
+	m := NewCachingManager(collector, CacheOptions{RefreshInterval: time.Hour, MaxEntries: 2}, mFactory, zap.NewNop())
+	for _, service := range []string{"a", "b", "a", "c"} {
+		_, err := m.GetSamplingStrategy(service)
+		require.NoError(t, err)
+	}
+	// b is the least recently used service when c is added
+	_, cached := m.get("b")
+	assert.False(t, cached)
+	_, cached = m.get("a")
+	assert.True(t, cached)
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "sampling-cache.evictions", Value: 1})
+	mFactory.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "sampling-cache.entries", Value: 2})
+}
+
+func TestCachingManagerBadFile(t *testing.T) {
+	withCacheDir(t, func(dir string) {
+		file := filepath.Join(dir, "sampling.json")
+		require.NoError(t, ioutil.WriteFile(file, []byte("bad"), 0600))
+		collector := &fakeCollector{rate: 0.1}
+		m := NewCachingManager(collector, CacheOptions{File: file}, metrics.NullFactory, zap.NewNop())
+		assert.Empty(t, m.entries)
+
+		_, err := m.GetSamplingStrategy("svc")
+		require.NoError(t, err)
+		require.NoError(t, m.Close())
+		m = NewCachingManager(collector, CacheOptions{File: file}, metrics.NullFactory, zap.NewNop())
+		assert.Len(t, m.entries, 1)
+		require.NoError(t, m.Close())
+
+		mFactory := metricstest.NewFactory(0)
+		m = NewCachingManager(collector, CacheOptions{File: filepath.Join(dir, "missing", "sampling.json")}, mFactory, zap.NewNop())
+		_, err = m.GetSamplingStrategy("svc")
+		require.NoError(t, err)
+		assert.Error(t, m.Close())
+		mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "sampling-cache.persist-failures", Value: 1})
+	})
+}
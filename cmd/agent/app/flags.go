@@ -19,6 +19,7 @@ import (
 	"flag"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -31,6 +32,15 @@ const (
 	suffixServerMaxPacketSize = "server-max-packet-size"
 	suffixServerHostPort      = "server-host-port"
 	httpServerHostPort        = "http-server.host-port"
+	httpServerMaxBodySize     = "http-server.max-traces-body-size"
+	grpcServerHostPort        = "grpc-server.host-port"
+	pipelineRulesFile         = "pipeline.rules-file"
+	samplingCacheRefresh      = "sampling-cache.refresh-interval"
+	samplingCacheFile         = "sampling-cache.file"
+	samplingCacheMaxEntries   = "sampling-cache.max-entries"
+
+	defaultSamplingCacheRefresh    = time.Minute
+	defaultSamplingCacheMaxEntries = 10000
 )
 
 var defaultProcessors = []struct {
@@ -55,7 +65,31 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.String(
 		httpServerHostPort,
 		defaultHTTPServerHostPort,
-		"host:port of the http server (e.g. for /sampling point and /baggageRestrictions endpoint)")
+		"host:port of the http server (e.g. for /sampling point, /baggageRestrictions and /api/traces endpoint)")
+	flags.Int(
+		httpServerMaxBodySize,
+		defaultMaxTracesBodySize,
+		"Maximum size in bytes of a span batch submitted to the /api/traces endpoint of the http server")
+	flags.String(
+		grpcServerHostPort,
+		"",
+		"host:port of the gRPC server receiving spans from local clients with the CollectorService API (disabled if empty)")
+	flags.String(
+		pipelineRulesFile,
+		"",
+		"Path of the JSON file with the rules to add, delete, hash or truncate tags and drop spans before they are reported (disabled if empty)")
+	flags.Duration(
+		samplingCacheRefresh,
+		defaultSamplingCacheRefresh,
+		"How long the sampling strategies fetched from the collectors are cached. The cached strategies are also served when the collectors are unreachable.")
+	flags.String(
+		samplingCacheFile,
+		"",
+		"Path of the file where the cached sampling strategies are persisted across restarts (kept only in memory if empty)")
+	flags.Int(
+		samplingCacheMaxEntries,
+		defaultSamplingCacheMaxEntries,
+		"Maximum number of services whose sampling strategies are cached, the least recently used are evicted beyond it")
 }
 
 // InitFromViper initializes Builder with properties retrieved from Viper.
@@ -71,5 +105,11 @@ func (b *Builder) InitFromViper(v *viper.Viper) *Builder {
 	}
 
 	b.HTTPServer.HostPort = v.GetString(httpServerHostPort)
+	b.HTTPServer.MaxTracesBodySize = v.GetInt(httpServerMaxBodySize)
+	b.GRPCServer.HostPort = v.GetString(grpcServerHostPort)
+	b.Pipeline.RulesFile = v.GetString(pipelineRulesFile)
+	b.SamplingCache.RefreshInterval = v.GetDuration(samplingCacheRefresh)
+	b.SamplingCache.File = v.GetString(samplingCacheFile)
+	b.SamplingCache.MaxEntries = v.GetInt(samplingCacheMaxEntries)
 	return b
 }
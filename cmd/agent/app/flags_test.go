@@ -18,6 +18,7 @@ package app
 import (
 	"flag"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -36,6 +37,12 @@ func TestBindFlags(t *testing.T) {
 
 	err := command.ParseFlags([]string{
 		"--http-server.host-port=:8080",
+		"--http-server.max-traces-body-size=1048576",
+		"--grpc-server.host-port=:14260",
+		"--pipeline.rules-file=rules.json",
+		"--sampling-cache.refresh-interval=5m",
+		"--sampling-cache.file=sampling.json",
+		"--sampling-cache.max-entries=100",
 		"--processor.jaeger-binary.server-host-port=:1111",
 		"--processor.jaeger-binary.server-max-packet-size=4242",
 		"--processor.jaeger-binary.server-queue-size=42",
@@ -46,6 +53,12 @@ func TestBindFlags(t *testing.T) {
 	b.InitFromViper(v)
 	assert.Equal(t, 3, len(b.Processors))
 	assert.Equal(t, ":8080", b.HTTPServer.HostPort)
+	assert.Equal(t, 1048576, b.HTTPServer.MaxTracesBodySize)
+	assert.Equal(t, ":14260", b.GRPCServer.HostPort)
+	assert.Equal(t, "rules.json", b.Pipeline.RulesFile)
+	assert.Equal(t, 5*time.Minute, b.SamplingCache.RefreshInterval)
+	assert.Equal(t, "sampling.json", b.SamplingCache.File)
+	assert.Equal(t, 100, b.SamplingCache.MaxEntries)
 	assert.Equal(t, ":1111", b.Processors[2].Server.HostPort)
 	assert.Equal(t, 4242, b.Processors[2].Server.MaxPacketSize)
 	assert.Equal(t, 42, b.Processors[2].Server.QueueSize)
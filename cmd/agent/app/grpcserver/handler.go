@@ -0,0 +1,57 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcserver
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
+	// registers the compressors that clients may use
+	_ "github.com/jaegertracing/jaeger/pkg/compression"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+// Handler implements api_v2.CollectorServiceServer on the agent, so that local clients can submit
+// spans over gRPC. The spans are emitted with the reporter, like the spans received over UDP.
+type Handler struct {
+	reporter reporter.Reporter
+	logger   *zap.Logger
+}
+
+// NewHandler creates a Handler that emits the received spans with the reporter.
+func NewHandler(reporter reporter.Reporter, logger *zap.Logger) *Handler {
+	return &Handler{reporter: reporter, logger: logger}
+}
+
+// PostSpans implements api_v2.CollectorServiceServer.
+func (h *Handler) PostSpans(ctx context.Context, r *api_v2.PostSpansRequest) (*api_v2.PostSpansResponse, error) {
+	if err := reporter.EmitModelBatch(h.reporter, r.Batch); err != nil {
+		h.logger.Error("cannot emit spans", zap.Error(err))
+		return nil, status.Errorf(codes.Unavailable, "cannot emit spans: %v", err)
+	}
+	return &api_v2.PostSpansResponse{}, nil
+}
+
+// NewServer creates a gRPC server with the CollectorService of the Handler.
+func NewServer(reporter reporter.Reporter, logger *zap.Logger) *grpc.Server {
+	server := grpc.NewServer()
+	api_v2.RegisterCollectorServiceServer(server, NewHandler(reporter, logger))
+	return server
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/testutils"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+)
+
+type failingReporter struct {
+	*testutils.InMemoryReporter
+}
+
+func (r failingReporter) EmitBatch(batch *jaeger.Batch) error {
+	return errors.New("emit failed")
+}
+
+func TestPostSpans(t *testing.T) {
+	rep := testutils.NewInMemoryReporter()
+	handler := NewHandler(rep, zap.NewNop())
+	_, err := handler.PostSpans(context.Background(), &api_v2.PostSpansRequest{
+		Batch: model.Batch{
+			Process: &model.Process{ServiceName: "svc"},
+			Spans:   []*model.Span{{OperationName: "op1"}, {OperationName: "op2"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, rep.Spans(), 2)
+	assert.Equal(t, "op1", rep.Spans()[0].OperationName)
+
+	handler = NewHandler(failingReporter{testutils.NewInMemoryReporter()}, zap.NewNop())
+	_, err = handler.PostSpans(context.Background(), &api_v2.PostSpansRequest{
+		Batch: model.Batch{Spans: []*model.Span{{OperationName: "op"}}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer(t *testing.T) {
+	rep := testutils.NewInMemoryReporter()
+	server := NewServer(rep, zap.NewNop())
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	for _, compressor := range []string{"", gzip.Name, "snappy"} {
+		t.Run("compressor "+compressor, func(t *testing.T) {
+			opts := []grpc.DialOption{grpc.WithInsecure()}
+			if compressor != "" {
+				opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(compressor)))
+			}
+			conn, err := grpc.Dial(listener.Addr().String(), opts...)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			before := len(rep.Spans())
+			client := api_v2.NewCollectorServiceClient(conn)
+			_, err = client.PostSpans(context.Background(), &api_v2.PostSpansRequest{
+				Batch: model.Batch{
+					Process: &model.Process{ServiceName: "svc"},
+					Spans:   []*model.Span{{OperationName: "op"}},
+				},
+			})
+			require.NoError(t, err)
+			assert.Len(t, rep.Spans(), before+1)
+		})
+	}
+}
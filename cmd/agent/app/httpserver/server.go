@@ -19,12 +19,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"strings"
 
+	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/uber/jaeger-lib/metrics"
 
 	"github.com/jaegertracing/jaeger/cmd/agent/app/configmanager"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 	tSampling "github.com/jaegertracing/jaeger/thrift-gen/sampling"
 )
 
@@ -32,12 +38,29 @@ const mimeTypeApplicationJSON = "application/json"
 
 var (
 	errBadRequest = errors.New("bad request")
+
+	thriftFormats = map[string]struct{}{
+		"application/x-thrift":                 {},
+		"application/vnd.apache.thrift.binary": {},
+	}
+	protobufFormats = map[string]struct{}{
+		"application/x-protobuf": {},
+		"application/protobuf":   {},
+	}
 )
 
 // NewHTTPServer creates a new server that hosts an HTTP/JSON endpoint for clients
-// to query for sampling strategies and baggage restrictions.
-func NewHTTPServer(hostPort string, manager configmanager.ClientConfigManager, mFactory metrics.Factory) *http.Server {
-	handler := newHTTPHandler(manager, mFactory)
+// to query for sampling strategies and baggage restrictions, and an endpoint
+// to submit spans, in Jaeger Thrift or api_v2 protobuf, to the reporter.
+func NewHTTPServer(
+	hostPort string,
+	manager configmanager.ClientConfigManager,
+	reporter reporter.Reporter,
+	mFactory metrics.Factory,
+	maxTracesBodySize int64,
+) *http.Server {
+	handler := newHTTPHandler(manager, reporter, mFactory)
+	handler.maxTracesBodySize = maxTracesBodySize
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		handler.serveSamplingHTTP(w, r, true /* thriftEnums092 */)
@@ -48,18 +71,24 @@ func NewHTTPServer(hostPort string, manager configmanager.ClientConfigManager, m
 	mux.HandleFunc("/baggageRestrictions", func(w http.ResponseWriter, r *http.Request) {
 		handler.serveBaggageHTTP(w, r)
 	})
+	mux.HandleFunc("/api/traces", func(w http.ResponseWriter, r *http.Request) {
+		handler.serveTracesHTTP(w, r)
+	})
 	return &http.Server{Addr: hostPort, Handler: mux}
 }
 
-func newHTTPHandler(manager configmanager.ClientConfigManager, mFactory metrics.Factory) *httpHandler {
-	handler := &httpHandler{manager: manager}
+func newHTTPHandler(manager configmanager.ClientConfigManager, reporter reporter.Reporter, mFactory metrics.Factory) *httpHandler {
+	handler := &httpHandler{manager: manager, reporter: reporter}
 	metrics.Init(&handler.metrics, mFactory, nil)
 	return handler
 }
 
 type httpHandler struct {
-	manager configmanager.ClientConfigManager
-	metrics struct {
+	manager  configmanager.ClientConfigManager
+	reporter reporter.Reporter
+	// maxTracesBodySize is the maximum size in bytes of span submissions
+	maxTracesBodySize int64
+	metrics           struct {
 		// Number of good sampling requests
 		SamplingRequestSuccess metrics.Counter `metric:"http-server.requests" tags:"type=sampling"`
 
@@ -69,6 +98,12 @@ type httpHandler struct {
 		// Number of good baggage requests
 		BaggageRequestSuccess metrics.Counter `metric:"http-server.requests" tags:"type=baggage"`
 
+		// Number of good span submissions in Jaeger Thrift
+		ThriftTracesRequestSuccess metrics.Counter `metric:"http-server.requests" tags:"type=traces-thrift"`
+
+		// Number of good span submissions in api_v2 protobuf
+		ProtobufTracesRequestSuccess metrics.Counter `metric:"http-server.requests" tags:"type=traces-protobuf"`
+
 		// Number of bad requests (400s)
 		BadRequest metrics.Counter `metric:"http-server.errors" tags:"status=4xx,source=all"`
 
@@ -80,6 +115,9 @@ type httpHandler struct {
 
 		// Number of failed response writes from http server
 		WriteFailures metrics.Counter `metric:"http-server.errors" tags:"status=5xx,source=write"`
+
+		// Number of span submissions the reporter failed to emit
+		ReporterFailures metrics.Counter `metric:"http-server.errors" tags:"status=5xx,source=reporter"`
 	}
 }
 
@@ -151,6 +189,73 @@ func (h *httpHandler) serveBaggageHTTP(w http.ResponseWriter, r *http.Request) {
 	h.metrics.BaggageRequestSuccess.Inc(1)
 }
 
+func (h *httpHandler) serveTracesHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.metrics.BadRequest.Inc(1)
+		http.Error(w, "spans must be submitted with POST", http.StatusMethodNotAllowed)
+		return
+	}
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		h.metrics.BadRequest.Inc(1)
+		http.Error(w, fmt.Sprintf("Cannot parse content type: %v", err), http.StatusBadRequest)
+		return
+	}
+	_, isThrift := thriftFormats[contentType]
+	_, isProtobuf := protobufFormats[contentType]
+	if !isThrift && !isProtobuf {
+		h.metrics.BadRequest.Inc(1)
+		http.Error(w, fmt.Sprintf("Unsupported content type: %v", contentType), http.StatusBadRequest)
+		return
+	}
+	if r.ContentLength > h.maxTracesBodySize {
+		h.metrics.BadRequest.Inc(1)
+		http.Error(w, fmt.Sprintf("Request body exceeds %d bytes", h.maxTracesBodySize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, h.maxTracesBodySize))
+	if err != nil {
+		h.metrics.BadRequest.Inc(1)
+		// MaxBytesReader fails once the limit is read, bodies without a length are only caught here
+		if int64(len(body)) == h.maxTracesBodySize {
+			http.Error(w, fmt.Sprintf("Request body exceeds %d bytes", h.maxTracesBodySize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Unable to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var emit func() error
+	if isThrift {
+		batch := &jaeger.Batch{}
+		if err := thrift.NewTDeserializer().Read(batch, body); err != nil {
+			h.metrics.BadRequest.Inc(1)
+			http.Error(w, fmt.Sprintf("Unable to parse Jaeger Thrift batch: %v", err), http.StatusBadRequest)
+			return
+		}
+		emit = func() error { return h.reporter.EmitBatch(batch) }
+	} else {
+		req := &api_v2.PostSpansRequest{}
+		if err := req.Unmarshal(body); err != nil {
+			h.metrics.BadRequest.Inc(1)
+			http.Error(w, fmt.Sprintf("Unable to parse protobuf request: %v", err), http.StatusBadRequest)
+			return
+		}
+		emit = func() error { return reporter.EmitModelBatch(h.reporter, req.Batch) }
+	}
+	if err := emit(); err != nil {
+		h.metrics.ReporterFailures.Inc(1)
+		http.Error(w, fmt.Sprintf("Cannot submit spans: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if isThrift {
+		h.metrics.ThriftTracesRequestSuccess.Inc(1)
+	} else {
+		h.metrics.ProtobufTracesRequestSuccess.Inc(1)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
 var samplingStrategyTypes = []tSampling.SamplingStrategyType{
 	tSampling.SamplingStrategyType_PROBABILISTIC,
 	tSampling.SamplingStrategyType_RATE_LIMITING,
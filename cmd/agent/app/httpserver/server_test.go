@@ -16,29 +16,40 @@
 package httpserver
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"io"
 	"io/ioutil"
 	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-lib/metrics/metricstest"
 
 	tSampling092 "github.com/jaegertracing/jaeger/cmd/agent/app/httpserver/thrift-0.9.2"
+	"github.com/jaegertracing/jaeger/cmd/agent/app/testutils"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/thrift-gen/baggage"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
 )
 
 type testServer struct {
 	metricsFactory *metricstest.Factory
 	mgr            *mockManager
+	reporter       *mockReporter
 	server         *httptest.Server
 }
 
+// testMaxTracesBodySize keeps the span submissions that are too large small in tests
+const testMaxTracesBodySize = 1024
+
 func withServer(
 	mockSamplingResponse *sampling.SamplingStrategyResponse,
 	mockBaggageResponse []*baggage.BaggageRestriction,
@@ -49,12 +60,14 @@ func withServer(
 		samplingResponse: mockSamplingResponse,
 		baggageResponse:  mockBaggageResponse,
 	}
-	realServer := NewHTTPServer(":1", mgr, metricsFactory)
+	rep := &mockReporter{InMemoryReporter: testutils.NewInMemoryReporter()}
+	realServer := NewHTTPServer(":1", mgr, rep, metricsFactory, testMaxTracesBodySize)
 	server := httptest.NewServer(realServer.Handler)
 	defer server.Close()
 	runTest(&testServer{
 		metricsFactory: metricsFactory,
 		mgr:            mgr,
+		reporter:       rep,
 		server:         server,
 	})
 }
@@ -195,7 +208,7 @@ func TestHTTPHandlerErrors(t *testing.T) {
 
 	t.Run("failure to write a response", func(t *testing.T) {
 		withServer(probabilistic(0.001), restrictions("luggage", 10), func(ts *testServer) {
-			handler := newHTTPHandler(ts.mgr, ts.metricsFactory)
+			handler := newHTTPHandler(ts.mgr, ts.reporter, ts.metricsFactory)
 
 			req := httptest.NewRequest("GET", "http://localhost:80/?service=X", nil)
 			w := &mockWriter{header: make(http.Header)}
@@ -213,6 +226,103 @@ func TestHTTPHandlerErrors(t *testing.T) {
 	})
 }
 
+func TestHTTPHandlerTraces(t *testing.T) {
+	thriftBatch := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc"},
+		Spans:   []*jaeger.Span{{TraceIdLow: 1, SpanId: 2, OperationName: "op"}},
+	}
+	thriftBody, err := thrift.NewTSerializer().Write(thriftBatch)
+	require.NoError(t, err)
+
+	protoRequest := &api_v2.PostSpansRequest{
+		Batch: model.Batch{
+			Process: &model.Process{ServiceName: "svc"},
+			Spans: []*model.Span{
+				{TraceID: model.NewTraceID(0, 1), SpanID: 2, OperationName: "op1"},
+				{TraceID: model.NewTraceID(0, 1), SpanID: 3, OperationName: "op2", Process: &model.Process{ServiceName: "other"}},
+			},
+		},
+	}
+	protoBody, err := protoRequest.Marshal()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		contentType string
+		body        []byte
+		metricType  string
+		spans       int
+	}{
+		{contentType: "application/x-thrift", body: thriftBody, metricType: "traces-thrift", spans: 1},
+		{contentType: "application/vnd.apache.thrift.binary", body: thriftBody, metricType: "traces-thrift", spans: 1},
+		{contentType: "application/x-protobuf", body: protoBody, metricType: "traces-protobuf", spans: 2},
+		{contentType: "application/protobuf; charset=binary", body: protoBody, metricType: "traces-protobuf", spans: 2},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.contentType, func(t *testing.T) {
+			withServer(nil, nil, func(ts *testServer) {
+				resp, err := http.Post(ts.server.URL+"/api/traces", testCase.contentType, bytes.NewReader(testCase.body))
+				require.NoError(t, err)
+				resp.Body.Close()
+				assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+				assert.Len(t, ts.reporter.Spans(), testCase.spans)
+				ts.metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+					Name: "http-server.requests", Tags: map[string]string{"type": testCase.metricType}, Value: 1,
+				})
+			})
+		})
+	}
+
+	withServer(nil, nil, func(ts *testServer) {
+		errorCases := []struct {
+			description string
+			method      string
+			contentType string
+			body        []byte
+			chunked     bool
+			statusCode  int
+		}{
+			{description: "GET", method: http.MethodGet, contentType: "application/x-thrift", body: thriftBody, statusCode: http.StatusMethodNotAllowed},
+			{description: "missing content type", method: http.MethodPost, body: thriftBody, statusCode: http.StatusBadRequest},
+			{description: "unsupported content type", method: http.MethodPost, contentType: "application/json", body: thriftBody, statusCode: http.StatusBadRequest},
+			{description: "bad thrift", method: http.MethodPost, contentType: "application/x-thrift", body: []byte("bad"), statusCode: http.StatusBadRequest},
+			{description: "bad protobuf", method: http.MethodPost, contentType: "application/x-protobuf", body: []byte("bad"), statusCode: http.StatusBadRequest},
+			{description: "too large", method: http.MethodPost, contentType: "application/x-thrift", body: make([]byte, testMaxTracesBodySize+1), statusCode: http.StatusRequestEntityTooLarge},
+			{description: "too large chunked", method: http.MethodPost, contentType: "application/x-thrift", body: make([]byte, testMaxTracesBodySize+1), chunked: true, statusCode: http.StatusRequestEntityTooLarge},
+		}
+		for _, testCase := range errorCases {
+			t.Run(testCase.description, func(t *testing.T) {
+				var body io.Reader = bytes.NewReader(testCase.body)
+				if testCase.chunked {
+					// Hides the length of the body, so that it is sent without a Content-Length
+					body = ioutil.NopCloser(body)
+				}
+				req, err := http.NewRequest(testCase.method, ts.server.URL+"/api/traces", body)
+				require.NoError(t, err)
+				if testCase.contentType != "" {
+					req.Header.Set("Content-Type", testCase.contentType)
+				}
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+				assert.Equal(t, testCase.statusCode, resp.StatusCode)
+			})
+		}
+		assert.Empty(t, ts.reporter.Spans())
+		ts.metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+			Name: "http-server.errors", Tags: map[string]string{"source": "all", "status": "4xx"}, Value: len(errorCases),
+		})
+
+		ts.reporter.err = errors.New("reporter error")
+		resp, err := http.Post(ts.server.URL+"/api/traces", "application/x-thrift", bytes.NewReader(thriftBody))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		ts.metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+			Name: "http-server.errors", Tags: map[string]string{"source": "reporter", "status": "5xx"}, Value: 1,
+		})
+	})
+}
+
 func probabilistic(probability float64) *sampling.SamplingStrategyResponse {
 	return &sampling.SamplingStrategyResponse{
 		StrategyType: sampling.SamplingStrategyType_PROBABILISTIC,
@@ -242,6 +352,18 @@ func (w *mockWriter) Write([]byte) (int, error) {
 
 func (w *mockWriter) WriteHeader(int) {}
 
+type mockReporter struct {
+	*testutils.InMemoryReporter
+	err error
+}
+
+func (r *mockReporter) EmitBatch(batch *jaeger.Batch) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.InMemoryReporter.EmitBatch(batch)
+}
+
 type mockManager struct {
 	samplingResponse *sampling.SamplingStrategyResponse
 	baggageResponse  []*baggage.BaggageRestriction
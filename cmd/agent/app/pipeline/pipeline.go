@@ -0,0 +1,334 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/uber/jaeger-lib/metrics"
+
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+// Pipeline applies the rules, in order, to the spans received by the agent before they are reported.
+// Spans in Jaeger batches are processed together with their process; Zipkin spans have no process,
+// so the tags added by AddTag rules are added to every span as binary annotations.
+type Pipeline struct {
+	rules   []rule
+	metrics pipelineMetrics
+}
+
+type pipelineMetrics struct {
+	// Number of spans dropped by DropSpan rules
+	SpansDropped metrics.Counter `metric:"pipeline.spans.dropped"`
+
+	// Number of tags, or parts of their values, deleted by DeleteTag rules
+	TagsDeleted metrics.Counter `metric:"pipeline.tags.deleted"`
+
+	// Number of tags, or parts of their values, hashed by HashTag rules
+	TagsHashed metrics.Counter `metric:"pipeline.tags.hashed"`
+
+	// Number of log fields truncated by TruncateLogFields rules
+	LogFieldsTruncated metrics.Counter `metric:"pipeline.log-fields.truncated"`
+}
+
+// counts accumulates the changes done to one batch, so that metrics are updated once per batch.
+type counts struct {
+	spansDropped       int64
+	tagsDeleted        int64
+	tagsHashed         int64
+	logFieldsTruncated int64
+}
+
+type rule interface {
+	processBatch(batch *jaeger.Batch, c *counts)
+	processZipkinSpans(spans []*zipkincore.Span, c *counts) []*zipkincore.Span
+}
+
+// New creates a Pipeline from the rules.
+func New(rules []Rule, mFactory metrics.Factory) (*Pipeline, error) {
+	p := &Pipeline{}
+	for i, r := range rules {
+		compiled, err := r.compile()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid rule #%d (%s)", i, r.Action)
+		}
+		p.rules = append(p.rules, compiled)
+	}
+	metrics.Init(&p.metrics, mFactory, nil)
+	return p, nil
+}
+
+// ProcessBatch applies the rules to the batch in place.
+func (p *Pipeline) ProcessBatch(batch *jaeger.Batch) {
+	var c counts
+	for _, r := range p.rules {
+		r.processBatch(batch, &c)
+	}
+	p.updateMetrics(c)
+}
+
+// ProcessZipkinSpans applies the rules to the spans in place and returns the spans that are not dropped.
+func (p *Pipeline) ProcessZipkinSpans(spans []*zipkincore.Span) []*zipkincore.Span {
+	var c counts
+	for _, r := range p.rules {
+		spans = r.processZipkinSpans(spans, &c)
+	}
+	p.updateMetrics(c)
+	return spans
+}
+
+func (p *Pipeline) updateMetrics(c counts) {
+	p.metrics.SpansDropped.Inc(c.spansDropped)
+	p.metrics.TagsDeleted.Inc(c.tagsDeleted)
+	p.metrics.TagsHashed.Inc(c.tagsHashed)
+	p.metrics.LogFieldsTruncated.Inc(c.logFieldsTruncated)
+}
+
+type addTagRule struct {
+	key       string
+	value     string
+	overwrite bool
+}
+
+func (r *addTagRule) processBatch(batch *jaeger.Batch, c *counts) {
+	if batch.Process == nil {
+		return
+	}
+	for _, tag := range batch.Process.Tags {
+		if tag.Key == r.key {
+			if r.overwrite {
+				value := r.value
+				*tag = jaeger.Tag{Key: r.key, VType: jaeger.TagType_STRING, VStr: &value}
+			}
+			return
+		}
+	}
+	value := r.value
+	batch.Process.Tags = append(batch.Process.Tags, &jaeger.Tag{Key: r.key, VType: jaeger.TagType_STRING, VStr: &value})
+}
+
+func (r *addTagRule) processZipkinSpans(spans []*zipkincore.Span, c *counts) []*zipkincore.Span {
+	for _, span := range spans {
+		r.processZipkinSpan(span)
+	}
+	return spans
+}
+
+func (r *addTagRule) processZipkinSpan(span *zipkincore.Span) {
+	for _, annotation := range span.BinaryAnnotations {
+		if annotation.Key == r.key {
+			if r.overwrite {
+				annotation.AnnotationType = zipkincore.AnnotationType_STRING
+				annotation.Value = []byte(r.value)
+			}
+			return
+		}
+	}
+	span.BinaryAnnotations = append(span.BinaryAnnotations, &zipkincore.BinaryAnnotation{
+		Key:            r.key,
+		Value:          []byte(r.value),
+		AnnotationType: zipkincore.AnnotationType_STRING,
+	})
+}
+
+// tagRule deletes or hashes the tags, in the process, the spans and their logs, whose key matches.
+type tagRule struct {
+	key   *regexp.Regexp
+	value *regexp.Regexp // nil if the whole tag is deleted or hashed
+	hash  bool
+	salt  string
+}
+
+func (r *tagRule) processBatch(batch *jaeger.Batch, c *counts) {
+	if batch.Process != nil {
+		batch.Process.Tags = r.processTags(batch.Process.Tags, c)
+	}
+	for _, span := range batch.Spans {
+		span.Tags = r.processTags(span.Tags, c)
+		for _, log := range span.Logs {
+			log.Fields = r.processTags(log.Fields, c)
+		}
+	}
+}
+
+func (r *tagRule) processTags(tags []*jaeger.Tag, c *counts) []*jaeger.Tag {
+	kept := tags[:0]
+	for _, tag := range tags {
+		if !r.key.MatchString(tag.Key) {
+			kept = append(kept, tag)
+			continue
+		}
+		if r.value != nil {
+			if tag.VType == jaeger.TagType_STRING {
+				if value, ok := r.redact(tag.GetVStr(), c); ok {
+					tag.VStr = &value
+				}
+			}
+		} else if r.hash {
+			value := r.hashValue(tagValue(tag))
+			*tag = jaeger.Tag{Key: tag.Key, VType: jaeger.TagType_STRING, VStr: &value}
+			c.tagsHashed++
+		} else {
+			c.tagsDeleted++
+			continue
+		}
+		kept = append(kept, tag)
+	}
+	return kept
+}
+
+func (r *tagRule) processZipkinSpans(spans []*zipkincore.Span, c *counts) []*zipkincore.Span {
+	for _, span := range spans {
+		kept := span.BinaryAnnotations[:0]
+		for _, annotation := range span.BinaryAnnotations {
+			if !r.key.MatchString(annotation.Key) {
+				kept = append(kept, annotation)
+				continue
+			}
+			if r.value != nil {
+				if annotation.AnnotationType == zipkincore.AnnotationType_STRING {
+					if value, ok := r.redact(string(annotation.Value), c); ok {
+						annotation.Value = []byte(value)
+					}
+				}
+			} else if r.hash {
+				annotation.Value = []byte(r.hashValue(annotation.Value))
+				annotation.AnnotationType = zipkincore.AnnotationType_STRING
+				c.tagsHashed++
+			} else {
+				c.tagsDeleted++
+				continue
+			}
+			kept = append(kept, annotation)
+		}
+		span.BinaryAnnotations = kept
+	}
+	return spans
+}
+
+// redact deletes or hashes the parts of the value matching the value pattern.
+func (r *tagRule) redact(value string, c *counts) (string, bool) {
+	if !r.value.MatchString(value) {
+		return value, false
+	}
+	if r.hash {
+		c.tagsHashed++
+		return r.value.ReplaceAllStringFunc(value, func(match string) string {
+			return r.hashValue([]byte(match))
+		}), true
+	}
+	c.tagsDeleted++
+	return r.value.ReplaceAllLiteralString(value, ""), true
+}
+
+func (r *tagRule) hashValue(value []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.salt))
+	h.Write(value)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func tagValue(tag *jaeger.Tag) []byte {
+	switch tag.VType {
+	case jaeger.TagType_STRING:
+		return []byte(tag.GetVStr())
+	case jaeger.TagType_BOOL:
+		return []byte(strconv.FormatBool(tag.GetVBool()))
+	case jaeger.TagType_LONG:
+		return []byte(strconv.FormatInt(tag.GetVLong(), 10))
+	case jaeger.TagType_DOUBLE:
+		return []byte(strconv.FormatFloat(tag.GetVDouble(), 'g', -1, 64))
+	default:
+		return tag.VBinary
+	}
+}
+
+// truncateRule truncates the string and binary values of the log fields of the spans.
+type truncateRule struct {
+	maxLength int
+}
+
+func (r *truncateRule) processBatch(batch *jaeger.Batch, c *counts) {
+	for _, span := range batch.Spans {
+		for _, log := range span.Logs {
+			for _, field := range log.Fields {
+				if field.VType == jaeger.TagType_STRING && len(field.GetVStr()) > r.maxLength {
+					value := truncate(field.GetVStr(), r.maxLength)
+					field.VStr = &value
+					c.logFieldsTruncated++
+				} else if field.VType == jaeger.TagType_BINARY && len(field.VBinary) > r.maxLength {
+					field.VBinary = field.VBinary[:r.maxLength]
+					c.logFieldsTruncated++
+				}
+			}
+		}
+	}
+}
+
+func (r *truncateRule) processZipkinSpans(spans []*zipkincore.Span, c *counts) []*zipkincore.Span {
+	for _, span := range spans {
+		for _, annotation := range span.Annotations {
+			if len(annotation.Value) > r.maxLength {
+				annotation.Value = truncate(annotation.Value, r.maxLength)
+				c.logFieldsTruncated++
+			}
+		}
+	}
+	return spans
+}
+
+// truncate cuts the string to at most maxLength bytes without splitting a UTF-8 character.
+func truncate(s string, maxLength int) string {
+	i := maxLength
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i]
+}
+
+type dropRule struct {
+	operation *regexp.Regexp
+}
+
+func (r *dropRule) processBatch(batch *jaeger.Batch, c *counts) {
+	kept := batch.Spans[:0]
+	for _, span := range batch.Spans {
+		if r.operation.MatchString(span.OperationName) {
+			c.spansDropped++
+			continue
+		}
+		kept = append(kept, span)
+	}
+	batch.Spans = kept
+}
+
+func (r *dropRule) processZipkinSpans(spans []*zipkincore.Span, c *counts) []*zipkincore.Span {
+	kept := spans[:0]
+	for _, span := range spans {
+		if r.operation.MatchString(span.Name) {
+			c.spansDropped++
+			continue
+		}
+		kept = append(kept, span)
+	}
+	return kept
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+func stringTag(key string, value string) *jaeger.Tag {
+	return &jaeger.Tag{Key: key, VType: jaeger.TagType_STRING, VStr: &value}
+}
+
+func hashOf(value string) string {
+	h := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(h[:])
+}
+
+func newPipeline(t *testing.T, rules ...Rule) (*Pipeline, *metricstest.Factory) {
+	mFactory := metricstest.NewFactory(0)
+	p, err := New(rules, mFactory)
+	require.NoError(t, err)
+	return p, mFactory
+}
+
+func TestAddTag(t *testing.T) {
+	p, _ := newPipeline(t,
+		Rule{Action: AddTag, Key: "env", Value: "prod"},
+		Rule{Action: AddTag, Key: "dc", Value: "east"},
+		Rule{Action: AddTag, Key: "host", Value: "agent-host", Overwrite: true},
+	)
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{
+			ServiceName: "svc",
+			Tags:        []*jaeger.Tag{stringTag("env", "dev"), stringTag("host", "client-host")},
+		},
+		Spans: []*jaeger.Span{{OperationName: "op"}},
+	}
+	p.ProcessBatch(batch)
+	assert.Equal(t, []*jaeger.Tag{
+		stringTag("env", "dev"),
+		stringTag("host", "agent-host"),
+		stringTag("dc", "east"),
+	}, batch.Process.Tags)
+
+	spans := p.ProcessZipkinSpans([]*zipkincore.Span{{
+		Name: "op",
+		BinaryAnnotations: []*zipkincore.BinaryAnnotation{
+			{Key: "host", Value: []byte{1}, AnnotationType: zipkincore.AnnotationType_BOOL},
+		},
+	}})
+	require.Len(t, spans, 1)
+	assert.Equal(t, []*zipkincore.BinaryAnnotation{
+		{Key: "host", Value: []byte("agent-host"), AnnotationType: zipkincore.AnnotationType_STRING},
+		{Key: "env", Value: []byte("prod"), AnnotationType: zipkincore.AnnotationType_STRING},
+		{Key: "dc", Value: []byte("east"), AnnotationType: zipkincore.AnnotationType_STRING},
+	}, spans[0].BinaryAnnotations)
+}
+
+func TestDeleteAndHashTags(t *testing.T) {
+	p, mFactory := newPipeline(t,
+		Rule{Action: DeleteTag, KeyPattern: `^user\.email$`},
+		Rule{Action: HashTag, KeyPattern: `^user\.id$`},
+		Rule{Action: HashTag, KeyPattern: `^http\.url$`, ValuePattern: `token=[^&]*`},
+		Rule{Action: DeleteTag, KeyPattern: `^db\.statement$`, ValuePattern: `'[^']*'`},
+	)
+	userID := int64(42)
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc", Tags: []*jaeger.Tag{stringTag("user.email", "a@b.c")}},
+		Spans: []*jaeger.Span{{
+			OperationName: "op",
+			Tags: []*jaeger.Tag{
+				stringTag("user.email", "a@b.c"),
+				{Key: "user.id", VType: jaeger.TagType_LONG, VLong: &userID},
+				stringTag("http.url", "http://x/y?token=secret&page=2"),
+				stringTag("db.statement", "SELECT * FROM t WHERE name = 'bob'"),
+				stringTag("other", "a@b.c"),
+			},
+			Logs: []*jaeger.Log{{Fields: []*jaeger.Tag{stringTag("event", "login"), stringTag("user.email", "a@b.c")}}},
+		}},
+	}
+	p.ProcessBatch(batch)
+	assert.Empty(t, batch.Process.Tags)
+	assert.Equal(t, []*jaeger.Tag{
+		stringTag("user.id", hashOf("42")),
+		stringTag("http.url", "http://x/y?"+hashOf("token=secret")+"&page=2"),
+		stringTag("db.statement", "SELECT * FROM t WHERE name = "),
+		stringTag("other", "a@b.c"),
+	}, batch.Spans[0].Tags)
+	assert.Equal(t, []*jaeger.Tag{stringTag("event", "login")}, batch.Spans[0].Logs[0].Fields)
+	mFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "pipeline.tags.deleted", Value: 4},
+		metricstest.ExpectedMetric{Name: "pipeline.tags.hashed", Value: 2},
+	)
+
+	spans := p.ProcessZipkinSpans([]*zipkincore.Span{{
+		Name: "op",
+		BinaryAnnotations: []*zipkincore.BinaryAnnotation{
+			{Key: "user.email", Value: []byte("a@b.c"), AnnotationType: zipkincore.AnnotationType_STRING},
+			{Key: "user.id", Value: []byte{0, 0, 0, 42}, AnnotationType: zipkincore.AnnotationType_I32},
+			{Key: "http.url", Value: []byte("http://x/y?token=secret"), AnnotationType: zipkincore.AnnotationType_STRING},
+		},
+	}})
+	assert.Equal(t, []*zipkincore.BinaryAnnotation{
+		{Key: "user.id", Value: []byte(hashOf(string([]byte{0, 0, 0, 42}))), AnnotationType: zipkincore.AnnotationType_STRING},
+		{Key: "http.url", Value: []byte("http://x/y?" + hashOf("token=secret")), AnnotationType: zipkincore.AnnotationType_STRING},
+	}, spans[0].BinaryAnnotations)
+	mFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "pipeline.tags.deleted", Value: 5},
+		metricstest.ExpectedMetric{Name: "pipeline.tags.hashed", Value: 4},
+	)
+}
+
+func TestHashTagWithSalt(t *testing.T) {
+	p, _ := newPipeline(t, Rule{Action: HashTag, KeyPattern: "email", Salt: "pepper"})
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc"},
+		Spans:   []*jaeger.Span{{Tags: []*jaeger.Tag{stringTag("email", "a@b.c")}}},
+	}
+	p.ProcessBatch(batch)
+	assert.Equal(t, hashOf("peppera@b.c"), batch.Spans[0].Tags[0].GetVStr())
+}
+
+func TestTruncateLogFields(t *testing.T) {
+	p, mFactory := newPipeline(t, Rule{Action: TruncateLogFields, MaxLength: 5})
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc"},
+		Spans: []*jaeger.Span{{
+			Tags: []*jaeger.Tag{stringTag("tag", "not a log field")},
+			Logs: []*jaeger.Log{{Fields: []*jaeger.Tag{
+				stringTag("short", "abc"),
+				stringTag("long", "abcdefgh"),
+				stringTag("utf8", "abcdé"),
+				{Key: "binary", VType: jaeger.TagType_BINARY, VBinary: []byte("abcdefgh")},
+			}}},
+		}},
+	}
+	p.ProcessBatch(batch)
+	assert.Equal(t, []*jaeger.Tag{stringTag("tag", "not a log field")}, batch.Spans[0].Tags)
+	assert.Equal(t, []*jaeger.Tag{
+		stringTag("short", "abc"),
+		stringTag("long", "abcde"),
+		stringTag("utf8", "abcd"),
+		{Key: "binary", VType: jaeger.TagType_BINARY, VBinary: []byte("abcde")},
+	}, batch.Spans[0].Logs[0].Fields)
+
+	spans := p.ProcessZipkinSpans([]*zipkincore.Span{{
+		Annotations: []*zipkincore.Annotation{{Value: "cs"}, {Value: "abcdefgh"}},
+	}})
+	assert.Equal(t, "cs", spans[0].Annotations[0].Value)
+	assert.Equal(t, "abcde", spans[0].Annotations[1].Value)
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "pipeline.log-fields.truncated", Value: 4})
+}
+
+func TestDropSpan(t *testing.T) {
+	p, mFactory := newPipeline(t, Rule{Action: DropSpan, OperationPattern: "^health"})
+	batch := &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc"},
+		Spans:   []*jaeger.Span{{OperationName: "healthcheck"}, {OperationName: "op"}, {OperationName: "health"}},
+	}
+	p.ProcessBatch(batch)
+	require.Len(t, batch.Spans, 1)
+	assert.Equal(t, "op", batch.Spans[0].OperationName)
+
+	spans := p.ProcessZipkinSpans([]*zipkincore.Span{{Name: "op"}, {Name: "healthcheck"}})
+	require.Len(t, spans, 1)
+	assert.Equal(t, "op", spans[0].Name)
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "pipeline.spans.dropped", Value: 3})
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+// Reporter is a reporter.Reporter that applies the pipeline to the spans before emitting them
+// with the wrapped reporter.
+type Reporter struct {
+	wrapped  reporter.Reporter
+	pipeline *Pipeline
+}
+
+// NewReporter creates a Reporter that processes the spans with the pipeline.
+func NewReporter(wrapped reporter.Reporter, pipeline *Pipeline) *Reporter {
+	return &Reporter{wrapped: wrapped, pipeline: pipeline}
+}
+
+// EmitZipkinBatch implements EmitZipkinBatch() of Reporter. Nothing is emitted if all the spans are dropped.
+func (r *Reporter) EmitZipkinBatch(spans []*zipkincore.Span) error {
+	if len(spans) == 0 {
+		return r.wrapped.EmitZipkinBatch(spans)
+	}
+	if spans = r.pipeline.ProcessZipkinSpans(spans); len(spans) == 0 {
+		return nil
+	}
+	return r.wrapped.EmitZipkinBatch(spans)
+}
+
+// EmitBatch implements EmitBatch() of Reporter. Nothing is emitted if all the spans are dropped.
+func (r *Reporter) EmitBatch(batch *jaeger.Batch) error {
+	if len(batch.Spans) == 0 {
+		return r.wrapped.EmitBatch(batch)
+	}
+	r.pipeline.ProcessBatch(batch)
+	if len(batch.Spans) == 0 {
+		return nil
+	}
+	return r.wrapped.EmitBatch(batch)
+}
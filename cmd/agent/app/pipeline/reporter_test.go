@@ -0,0 +1,75 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/testutils"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+func TestReporter(t *testing.T) {
+	p, _ := newPipeline(t,
+		Rule{Action: DropSpan, OperationPattern: "^health"},
+		Rule{Action: DeleteTag, KeyPattern: "secret"},
+	)
+	wrapped := testutils.NewInMemoryReporter()
+	r := NewReporter(wrapped, p)
+
+	require.NoError(t, r.EmitBatch(&jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc"},
+		Spans:   []*jaeger.Span{{OperationName: "op", Tags: []*jaeger.Tag{stringTag("secret", "x")}}, {OperationName: "health"}},
+	}))
+	require.NoError(t, r.EmitBatch(&jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc"},
+		Spans:   []*jaeger.Span{{OperationName: "health"}},
+	}))
+	require.Len(t, wrapped.Spans(), 1)
+	assert.Equal(t, "op", wrapped.Spans()[0].OperationName)
+	assert.Empty(t, wrapped.Spans()[0].Tags)
+
+	require.NoError(t, r.EmitZipkinBatch([]*zipkincore.Span{{Name: "op"}, {Name: "health"}}))
+	require.NoError(t, r.EmitZipkinBatch([]*zipkincore.Span{{Name: "health"}}))
+	require.Len(t, wrapped.ZipkinSpans(), 1)
+	assert.Equal(t, "op", wrapped.ZipkinSpans()[0].Name)
+}
+
+type countingReporter struct {
+	batches int
+}
+
+func (r *countingReporter) EmitZipkinBatch(spans []*zipkincore.Span) error {
+	r.batches++
+	return nil
+}
+
+func (r *countingReporter) EmitBatch(batch *jaeger.Batch) error {
+	r.batches++
+	return nil
+}
+
+func TestReporterEmptyBatch(t *testing.T) {
+	p, _ := newPipeline(t, Rule{Action: DropSpan, OperationPattern: ".*"})
+	wrapped := &countingReporter{}
+	r := NewReporter(wrapped, p)
+	require.NoError(t, r.EmitBatch(&jaeger.Batch{Process: &jaeger.Process{ServiceName: "svc"}}))
+	require.NoError(t, r.EmitZipkinBatch(nil))
+	assert.Equal(t, 2, wrapped.batches)
+}
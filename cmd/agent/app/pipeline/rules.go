@@ -0,0 +1,143 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Action is the kind of processing done by a Rule.
+type Action string
+
+const (
+	// AddTag adds a tag to the process of the spans. An existing tag with the same key is kept,
+	// unless Overwrite is set.
+	AddTag Action = "add-tag"
+	// DeleteTag deletes the tags whose key matches KeyPattern. If ValuePattern is set, only the
+	// parts of the string values matching it are deleted.
+	DeleteTag Action = "delete-tag"
+	// HashTag replaces the value of the tags whose key matches KeyPattern with its SHA-256 hash.
+	// If ValuePattern is set, only the parts of the string values matching it are hashed.
+	HashTag Action = "hash-tag"
+	// TruncateLogFields truncates the values of the log fields longer than MaxLength bytes.
+	TruncateLogFields Action = "truncate-log-fields"
+	// DropSpan drops the spans whose operation name matches OperationPattern.
+	DropSpan Action = "drop-span"
+)
+
+// Rule describes one step of the pipeline, as read from the rules file.
+type Rule struct {
+	Action Action `json:"action"`
+
+	// Key, Value, Env, File and Overwrite are used by AddTag. The value of the tag is read
+	// from File if set, otherwise from the environment variable Env if set and not empty,
+	// otherwise it is Value.
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Env       string `json:"env"`
+	File      string `json:"file"`
+	Overwrite bool   `json:"overwrite"`
+
+	// KeyPattern, ValuePattern and Salt are used by DeleteTag and HashTag.
+	KeyPattern   string `json:"keyPattern"`
+	ValuePattern string `json:"valuePattern"`
+	Salt         string `json:"salt"`
+
+	// MaxLength is used by TruncateLogFields.
+	MaxLength int `json:"maxLength"`
+
+	// OperationPattern is used by DropSpan.
+	OperationPattern string `json:"operationPattern"`
+}
+
+type rulesFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadRules reads the rules from a JSON file of the form {"rules": [...]}.
+func LoadRules(path string) ([]Rule, error) {
+	content, err := ioutil.ReadFile(path) /* nolint #nosec , this comes from an admin, not user */
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open rules file")
+	}
+	var file rulesFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal rules")
+	}
+	return file.Rules, nil
+}
+
+func (r Rule) compile() (rule, error) {
+	switch r.Action {
+	case AddTag:
+		if r.Key == "" {
+			return nil, errors.New("key is required")
+		}
+		value := r.Value
+		if r.File != "" {
+			content, err := ioutil.ReadFile(r.File) /* nolint #nosec , this comes from an admin, not user */
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot read tag value")
+			}
+			value = strings.TrimSpace(string(content))
+		} else if v := os.Getenv(r.Env); r.Env != "" && v != "" {
+			value = v
+		}
+		return &addTagRule{key: r.Key, value: value, overwrite: r.Overwrite}, nil
+	case DeleteTag, HashTag:
+		key, err := compilePattern("keyPattern", r.KeyPattern)
+		if err != nil {
+			return nil, err
+		}
+		tr := &tagRule{key: key, hash: r.Action == HashTag, salt: r.Salt}
+		if r.ValuePattern != "" {
+			if tr.value, err = compilePattern("valuePattern", r.ValuePattern); err != nil {
+				return nil, err
+			}
+		}
+		return tr, nil
+	case TruncateLogFields:
+		if r.MaxLength <= 0 {
+			return nil, errors.New("maxLength must be positive")
+		}
+		return &truncateRule{maxLength: r.MaxLength}, nil
+	case DropSpan:
+		operation, err := compilePattern("operationPattern", r.OperationPattern)
+		if err != nil {
+			return nil, err
+		}
+		return &dropRule{operation: operation}, nil
+	default:
+		return nil, fmt.Errorf("unknown action %q", r.Action)
+	}
+}
+
+func compilePattern(name string, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("%s is required", name)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", name)
+	}
+	return re, nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+)
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipeline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "rules.json", `{"rules": [
+		{"action": "add-tag", "key": "env", "value": "prod", "overwrite": true},
+		{"action": "hash-tag", "keyPattern": "^http\\.url$", "valuePattern": "\\?.*$", "salt": "s"},
+		{"action": "truncate-log-fields", "maxLength": 10},
+		{"action": "drop-span", "operationPattern": "^health"}
+	]}`)
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{Action: AddTag, Key: "env", Value: "prod", Overwrite: true},
+		{Action: HashTag, KeyPattern: `^http\.url$`, ValuePattern: `\?.*$`, Salt: "s"},
+		{Action: TruncateLogFields, MaxLength: 10},
+		{Action: DropSpan, OperationPattern: "^health"},
+	}, rules)
+
+	_, err = LoadRules(filepath.Join(dir, "missing.json"))
+	assert.Contains(t, err.Error(), "failed to open rules file")
+
+	_, err = LoadRules(writeFile(t, dir, "bad.json", "bad"))
+	assert.Contains(t, err.Error(), "failed to unmarshal rules")
+}
+
+func TestAddTagValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipeline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := writeFile(t, dir, "build", "abc123\n")
+
+	os.Setenv("PIPELINE_TEST_ENV", "from-env")
+	defer os.Unsetenv("PIPELINE_TEST_ENV")
+
+	testCases := []struct {
+		rule  Rule
+		value string
+	}{
+		{rule: Rule{Action: AddTag, Key: "k", Value: "v"}, value: "v"},
+		{rule: Rule{Action: AddTag, Key: "k", Value: "v", Env: "PIPELINE_TEST_ENV"}, value: "from-env"},
+		{rule: Rule{Action: AddTag, Key: "k", Value: "v", Env: "PIPELINE_TEST_UNSET"}, value: "v"},
+		{rule: Rule{Action: AddTag, Key: "k", Value: "v", File: path}, value: "abc123"},
+	}
+	for _, testCase := range testCases {
+		compiled, err := testCase.rule.compile()
+		require.NoError(t, err)
+		assert.Equal(t, testCase.value, compiled.(*addTagRule).value)
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	testCases := []struct {
+		rule Rule
+		err  string
+	}{
+		{rule: Rule{Action: "rename-tag"}, err: `invalid rule #0 (rename-tag): unknown action "rename-tag"`},
+		{rule: Rule{Action: AddTag}, err: "invalid rule #0 (add-tag): key is required"},
+		{rule: Rule{Action: AddTag, Key: "k", File: "/does/not/exist"}, err: "invalid rule #0 (add-tag): cannot read tag value"},
+		{rule: Rule{Action: DeleteTag}, err: "invalid rule #0 (delete-tag): keyPattern is required"},
+		{rule: Rule{Action: HashTag, KeyPattern: "("}, err: "invalid rule #0 (hash-tag): invalid keyPattern"},
+		{rule: Rule{Action: HashTag, KeyPattern: "k", ValuePattern: "("}, err: "invalid rule #0 (hash-tag): invalid valuePattern"},
+		{rule: Rule{Action: TruncateLogFields}, err: "invalid rule #0 (truncate-log-fields): maxLength must be positive"},
+		{rule: Rule{Action: DropSpan}, err: "invalid rule #0 (drop-span): operationPattern is required"},
+	}
+	for _, testCase := range testCases {
+		_, err := New([]Rule{testCase.rule}, metrics.NullFactory)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), testCase.err)
+	}
+}
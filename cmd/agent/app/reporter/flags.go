@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -28,6 +29,13 @@ const (
 	reporterType = "reporter.type"
 	// Agent tags
 	agentTags = "jaeger.tags"
+	// Spool of the batches that could not be sent
+	spoolDirectory     = "reporter.spool.directory"
+	spoolMaxSizeBytes  = "reporter.spool.max-size-bytes"
+	spoolRetryInterval = "reporter.spool.retry-interval"
+
+	defaultSpoolMaxSizeBytes  = 100 * 1024 * 1024
+	defaultSpoolRetryInterval = 5 * time.Second
 	// TCHANNEL is name of tchannel reporter.
 	TCHANNEL Type = "tchannel"
 	// GRPC is name of gRPC reporter.
@@ -41,18 +49,25 @@ type Type string
 type Options struct {
 	ReporterType Type
 	AgentTags    map[string]string
+	Spool        SpoolOptions
 }
 
 // AddFlags adds flags for Options.
 func AddFlags(flags *flag.FlagSet) {
 	flags.String(reporterType, string(GRPC), fmt.Sprintf("Reporter type to use e.g. %s, %s", string(GRPC), string(TCHANNEL)))
 	flags.String(agentTags, "", "One or more tags to be added to the Process tags of all spans passing through this agent. Ex: key1=value1,key2=${envVar:defaultValue}")
+	flags.String(spoolDirectory, "", "Directory where the batches that could not be sent to the collectors are persisted until they can be replayed. Spooling is disabled if empty.")
+	flags.Int64(spoolMaxSizeBytes, defaultSpoolMaxSizeBytes, "Maximum size of the spool directory in bytes, the oldest batches are evicted beyond it.")
+	flags.Duration(spoolRetryInterval, defaultSpoolRetryInterval, "How often the spooled batches are replayed to the collectors.")
 }
 
 // InitFromViper initializes Options with properties retrieved from Viper.
 func (b *Options) InitFromViper(v *viper.Viper) *Options {
 	b.ReporterType = Type(v.GetString(reporterType))
 	b.AgentTags = parseAgentTags(v.GetString(agentTags))
+	b.Spool.Directory = v.GetString(spoolDirectory)
+	b.Spool.MaxSizeBytes = v.GetInt64(spoolMaxSizeBytes)
+	b.Spool.RetryInterval = v.GetDuration(spoolRetryInterval)
 	return b
 }
 
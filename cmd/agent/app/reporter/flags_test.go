@@ -18,6 +18,7 @@ import (
 	"flag"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -42,6 +43,27 @@ func TestBindFlags_NoJaegerTags(t *testing.T) {
 	b.InitFromViper(v)
 	assert.Equal(t, Type("grpc"), b.ReporterType)
 	assert.Len(t, b.AgentTags, 0)
+	assert.Equal(t, SpoolOptions{MaxSizeBytes: defaultSpoolMaxSizeBytes, RetryInterval: defaultSpoolRetryInterval}, b.Spool)
+}
+
+func TestBindFlags_Spool(t *testing.T) {
+	v := viper.New()
+	command := cobra.Command{}
+	flags := &flag.FlagSet{}
+	AddFlags(flags)
+	command.PersistentFlags().AddGoFlagSet(flags)
+	v.BindPFlags(command.PersistentFlags())
+
+	err := command.ParseFlags([]string{
+		"--reporter.spool.directory=/var/spool/jaeger",
+		"--reporter.spool.max-size-bytes=1024",
+		"--reporter.spool.retry-interval=1m",
+	})
+	require.NoError(t, err)
+
+	b := &Options{}
+	b.InitFromViper(v)
+	assert.Equal(t, SpoolOptions{Directory: "/var/spool/jaeger", MaxSizeBytes: 1024, RetryInterval: time.Minute}, b.Spool)
 }
 
 func TestBindFlags(t *testing.T) {
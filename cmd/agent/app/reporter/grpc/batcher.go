@@ -0,0 +1,207 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/jaeger-lib/metrics"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type batcherMetrics struct {
+	// Number of coalesced batches sent because they reached the maximum size
+	FlushesOnSize metrics.Counter `metric:"flushes" tags:"reason=size"`
+
+	// Number of coalesced batches sent because they reached the maximum delay
+	FlushesOnTimer metrics.Counter `metric:"flushes" tags:"reason=timer"`
+
+	// Number of coalesced batches sent because the reporter was closed
+	FlushesOnClose metrics.Counter `metric:"flushes" tags:"reason=close"`
+
+	// Number of coalesced batches that failed to send
+	FlushFailures metrics.Counter `metric:"flush_failures"`
+
+	// Number of spans in the coalesced batches
+	BatchSpans metrics.Histogram `metric:"batch_spans" buckets:"1,10,100,1000,10000"`
+
+	// Size in bytes of the coalesced batches
+	BatchBytes metrics.Histogram `metric:"batch_bytes" buckets:"1000,10000,100000,1000000,10000000"`
+}
+
+// pendingBatch holds the spans of one process waiting to be sent.
+type pendingBatch struct {
+	process *model.Process
+	spans   []*model.Span
+	bytes   int
+}
+
+// batcher coalesces the spans of the batches received from clients by process, so that the
+// spans of many small batches are sent in few requests. A coalesced batch is sent once it
+// reaches maxBytes, and at the latest after maxDelay.
+type batcher struct {
+	maxBytes int
+	maxDelay time.Duration
+	send     func(spans []*model.Span, process *model.Process) ([]*model.Span, error)
+	metrics  batcherMetrics
+
+	mux sync.Mutex
+	// onFailure receives the coalesced batches that failed to send, if set
+	onFailure func(spans []*model.Span, process *model.Process)
+	// pending batches by hash code of their process, processes with the same hash code share a slot
+	pending map[uint64][]*pendingBatch
+
+	done    chan struct{}
+	stopped sync.WaitGroup
+}
+
+func newBatcher(
+	maxBytes int,
+	maxDelay time.Duration,
+	send func(spans []*model.Span, process *model.Process) ([]*model.Span, error),
+	mFactory metrics.Factory,
+) *batcher {
+	b := &batcher{
+		maxBytes: maxBytes,
+		maxDelay: maxDelay,
+		send:     send,
+		pending:  make(map[uint64][]*pendingBatch),
+		done:     make(chan struct{}),
+	}
+	metrics.Init(&b.metrics, mFactory.Namespace(metrics.NSOptions{Name: "reporter"}).Namespace(metrics.NSOptions{Name: "batcher"}), nil)
+	b.stopped.Add(1)
+	go b.flushLoop()
+	return b
+}
+
+// add appends spans to the pending batch of their process, and sends it if it is full.
+func (b *batcher) add(spans []*model.Span, process *model.Process) {
+	var key uint64
+	if process != nil {
+		key, _ = model.HashCode(process) // err is always nil when hashing into memory
+	}
+	bytes := 0
+	for _, span := range spans {
+		bytes += span.Size()
+	}
+
+	b.mux.Lock()
+	slot := b.pending[key]
+	var batch *pendingBatch
+	for _, p := range slot {
+		if sameProcess(p.process, process) {
+			batch = p
+			break
+		}
+	}
+	if batch == nil {
+		batch = &pendingBatch{process: process}
+		slot = append(slot, batch)
+		b.pending[key] = slot
+	}
+	batch.spans = append(batch.spans, spans...)
+	batch.bytes += bytes
+	full := batch.bytes >= b.maxBytes
+	if full {
+		b.removePending(key, batch)
+	}
+	b.mux.Unlock()
+
+	if full {
+		b.flush(batch, b.metrics.FlushesOnSize)
+	}
+}
+
+// removePending removes a batch from its slot, the lock must be held.
+func (b *batcher) removePending(key uint64, batch *pendingBatch) {
+	slot := b.pending[key]
+	for i, p := range slot {
+		if p == batch {
+			slot = append(slot[:i], slot[i+1:]...)
+			break
+		}
+	}
+	if len(slot) == 0 {
+		delete(b.pending, key)
+	} else {
+		b.pending[key] = slot
+	}
+}
+
+func sameProcess(p1, p2 *model.Process) bool {
+	if p1 == nil || p2 == nil {
+		return p1 == p2
+	}
+	return p1.Equal(p2)
+}
+
+func (b *batcher) flush(batch *pendingBatch, reason metrics.Counter) {
+	reason.Inc(1)
+	b.metrics.BatchSpans.Record(float64(len(batch.spans)))
+	b.metrics.BatchBytes.Record(float64(batch.bytes))
+	if failed, err := b.send(batch.spans, batch.process); err != nil {
+		b.metrics.FlushFailures.Inc(1)
+		b.mux.Lock()
+		onFailure := b.onFailure
+		b.mux.Unlock()
+		if onFailure != nil {
+			onFailure(failed, batch.process)
+		}
+	}
+}
+
+// setOnFailure sets the function receiving the coalesced batches that failed to send.
+func (b *batcher) setOnFailure(onFailure func(spans []*model.Span, process *model.Process)) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.onFailure = onFailure
+}
+
+// flushAll sends all pending batches.
+func (b *batcher) flushAll(reason metrics.Counter) {
+	b.mux.Lock()
+	pending := b.pending
+	b.pending = make(map[uint64][]*pendingBatch)
+	b.mux.Unlock()
+
+	for _, slot := range pending {
+		for _, batch := range slot {
+			b.flush(batch, reason)
+		}
+	}
+}
+
+func (b *batcher) flushLoop() {
+	defer b.stopped.Done()
+	ticker := time.NewTicker(b.maxDelay)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			b.flushAll(b.metrics.FlushesOnTimer)
+		}
+	}
+}
+
+// close stops the timer and sends the pending batches.
+func (b *batcher) close() {
+	close(b.done)
+	b.stopped.Wait()
+	b.flushAll(b.metrics.FlushesOnClose)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type sentBatches struct {
+	mux     sync.Mutex
+	batches []model.Batch
+	err     error
+}
+
+func (s *sentBatches) send(spans []*model.Span, process *model.Process) ([]*model.Span, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.batches = append(s.batches, model.Batch{Spans: spans, Process: process})
+	if s.err != nil {
+		return spans, s.err
+	}
+	return nil, nil
+}
+
+func (s *sentBatches) get() []model.Batch {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return append([]model.Batch(nil), s.batches...)
+}
+
+func makeSpans(operations ...string) []*model.Span {
+	var spans []*model.Span
+	for _, op := range operations {
+		spans = append(spans, &model.Span{OperationName: op})
+	}
+	return spans
+}
+
+func TestBatcherCoalescesByProcess(t *testing.T) {
+	sent := &sentBatches{}
+	mFactory := metricstest.NewFactory(0)
+	b := newBatcher(1024*1024, time.Hour, sent.send, mFactory)
+
+	b.add(makeSpans("a1"), model.NewProcess("a", nil))
+	b.add(makeSpans("b1"), model.NewProcess("b", nil))
+	b.add(makeSpans("a2", "a3"), model.NewProcess("a", nil))
+	b.add(makeSpans("z1"), nil)
+	b.add(makeSpans("z2"), nil)
+	assert.Empty(t, sent.get())
+
+	b.close()
+	byService := map[string][]*model.Span{}
+	for _, batch := range sent.get() {
+		service := ""
+		if batch.Process != nil {
+			service = batch.Process.ServiceName
+		}
+		byService[service] = batch.Spans
+	}
+	assert.Equal(t, map[string][]*model.Span{
+		"a": makeSpans("a1", "a2", "a3"),
+		"b": makeSpans("b1"),
+		"":  makeSpans("z1", "z2"),
+	}, byService)
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "reporter.batcher.flushes", Tags: map[string]string{"reason": "close"}, Value: 3,
+	})
+}
+
+func TestBatcherFlushesOnSize(t *testing.T) {
+	sent := &sentBatches{}
+	mFactory := metricstest.NewFactory(0)
+	spanSize := makeSpans("op")[0].Size()
+	b := newBatcher(2*spanSize, time.Hour, sent.send, mFactory)
+	defer b.close()
+
+	process := model.NewProcess("svc", nil)
+	b.add(makeSpans("op"), process)
+	assert.Empty(t, sent.get())
+	b.add(makeSpans("op"), process)
+	require.Len(t, sent.get(), 1)
+	assert.Len(t, sent.get()[0].Spans, 2)
+	assert.Empty(t, b.pending)
+
+	var failed []model.Batch
+	b.setOnFailure(func(spans []*model.Span, process *model.Process) {
+		failed = append(failed, model.Batch{Spans: spans, Process: process})
+	})
+	sent.err = errors.New("collector is down")
+	b.add(makeSpans("op", "op", "op"), process)
+	assert.Equal(t, []model.Batch{{Spans: makeSpans("op", "op", "op"), Process: process}}, failed)
+	mFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "reporter.batcher.flushes", Tags: map[string]string{"reason": "size"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "reporter.batcher.flush_failures", Value: 1},
+	)
+}
+
+func TestBatcherFlushesOnTimer(t *testing.T) {
+	sent := &sentBatches{}
+	b := newBatcher(1024*1024, time.Millisecond, sent.send, metricstest.NewFactory(0))
+	defer b.close()
+
+	b.add(makeSpans("op"), model.NewProcess("svc", nil))
+	for i := 0; i < 100 && len(sent.get()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Len(t, sent.get(), 1)
+}
+
+func TestBatcherHashCollision(t *testing.T) {
+	sent := &sentBatches{}
+	b := newBatcher(1024*1024, time.Hour, sent.send, metricstest.NewFactory(0))
+	p1, p2 := model.NewProcess("a", nil), model.NewProcess("b", nil)
+	// pretend that both processes have the same hash code
+	key, err := model.HashCode(p2)
+	require.NoError(t, err)
+	b.pending[key] = []*pendingBatch{{process: p1}, {process: p2}}
+	b.add(makeSpans("b1"), p2)
+	assert.Len(t, b.pending[key][1].spans, 1)
+	b.removePending(key, b.pending[key][0])
+	assert.Equal(t, p2, b.pending[key][0].process)
+	b.close()
+	assert.Len(t, sent.get(), 1)
+
+	assert.True(t, sameProcess(nil, nil))
+	assert.False(t, sameProcess(p1, nil))
+	assert.False(t, sameProcess(p1, p2))
+}
@@ -20,7 +20,9 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"strings"
+	"time"
 
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	"go.uber.org/zap"
@@ -30,6 +32,7 @@ import (
 	"google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/resolver/manual"
 
+	"github.com/jaegertracing/jaeger/pkg/compression"
 	"github.com/jaegertracing/jaeger/pkg/discovery"
 	"github.com/jaegertracing/jaeger/pkg/discovery/grpcresolver"
 )
@@ -49,6 +52,19 @@ type ConnBuilder struct {
 	DiscoveryMinPeers int
 	Notifier          discovery.Notifier
 	Discoverer        discovery.Discoverer
+
+	// Routing is either RoutingRoundRobin (default) or RoutingTraceID
+	Routing string
+
+	// Compression is one of compression.Names
+	Compression string
+	// BatchMaxBytes is the maximum size of the batches that spans are coalesced into, batching is disabled if 0
+	BatchMaxBytes int
+	// BatchMaxDelay is the maximum time spans wait to be coalesced into a batch
+	BatchMaxDelay time.Duration
+
+	// router tells the collectors that the connection routes traces to, set by CreateConnection with RoutingTraceID
+	router *traceRouter
 }
 
 // NewConnBuilder creates a new grpc connection builder.
@@ -109,9 +125,26 @@ func (b *ConnBuilder) CreateConnection(logger *zap.Logger) (*grpc.ClientConn, er
 		dialOptions = append(dialOptions, grpc.WithInsecure())
 	}
 
+	balancerName := roundrobin.Name
+	discoveryMinPeers := b.DiscoveryMinPeers
+	switch b.Routing {
+	case "", RoutingRoundRobin:
+	case RoutingTraceID:
+		b.router = &traceRouter{}
+		balancerName = registerTraceIDBalancer(b.router)
+		// every collector owns a part of the trace IDs, so the agent must connect to all of them
+		discoveryMinPeers = math.MaxInt32
+		if b.Notifier != nil && b.Discoverer != nil {
+			logger.Info("Ignoring discovery min-peers, trace-id routing connects to all collectors",
+				zap.Int("min-peers", b.DiscoveryMinPeers))
+		}
+	default:
+		return nil, fmt.Errorf("unknown routing %q, must be %s or %s", b.Routing, RoutingRoundRobin, RoutingTraceID)
+	}
+
 	if b.Notifier != nil && b.Discoverer != nil {
-		logger.Info("Using external discovery service with roundrobin load balancer")
-		grpcResolver := grpcresolver.New(b.Notifier, b.Discoverer, logger, b.DiscoveryMinPeers)
+		logger.Info("Using external discovery service", zap.String("balancer", balancerName))
+		grpcResolver := grpcresolver.New(b.Notifier, b.Discoverer, logger, discoveryMinPeers)
 		dialTarget = grpcResolver.Scheme() + ":///round_robin"
 	} else {
 		if b.CollectorHostPorts == nil {
@@ -130,7 +163,15 @@ func (b *ConnBuilder) CreateConnection(logger *zap.Logger) (*grpc.ClientConn, er
 			dialTarget = b.CollectorHostPorts[0]
 		}
 	}
-	dialOptions = append(dialOptions, grpc.WithBalancerName(roundrobin.Name))
+	switch b.Compression {
+	case "", compression.None:
+	default:
+		if err := compression.Validate(b.Compression); err != nil {
+			return nil, err
+		}
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(grpc.UseCompressor(b.Compression)))
+	}
+	dialOptions = append(dialOptions, grpc.WithBalancerName(balancerName))
 	dialOptions = append(dialOptions, grpc.WithUnaryInterceptor(grpc_retry.UnaryClientInterceptor(grpc_retry.WithMax(b.MaxRetry))))
 	return grpc.Dial(dialTarget, dialOptions...)
 }
@@ -70,6 +70,7 @@ func TestBuilderWithCollectors(t *testing.T) {
 		checkSuffixOnly bool
 		notifier        discovery.Notifier
 		discoverer      discovery.Discoverer
+		routing         string
 		err             error
 	}{
 		{
@@ -96,6 +97,27 @@ func TestBuilderWithCollectors(t *testing.T) {
 			notifier:        noopNotifier{},
 			discoverer:      discovery.FixedDiscoverer{},
 		},
+		{
+			target:          "///round_robin",
+			name:            "with trace ID routing",
+			hostPorts:       []string{"127.0.0.1:9876", "127.0.0.1:9877"},
+			checkSuffixOnly: true,
+			routing:         RoutingTraceID,
+		},
+		{
+			target:          "///round_robin",
+			name:            "with trace ID routing and fixed discoverer",
+			checkSuffixOnly: true,
+			notifier:        noopNotifier{},
+			discoverer:      discovery.FixedDiscoverer{},
+			routing:         RoutingTraceID,
+		},
+		{
+			name:      "with unknown routing",
+			hostPorts: []string{"127.0.0.1:9876"},
+			routing:   "random",
+			err:       errors.New(`unknown routing "random", must be round-robin or trace-id`),
+		},
 		{
 			target:          "",
 			name:            "without collectorPorts and resolver",
@@ -114,6 +136,7 @@ func TestBuilderWithCollectors(t *testing.T) {
 			cfg.CollectorHostPorts = test.hostPorts
 			cfg.Notifier = test.notifier
 			cfg.Discoverer = test.discoverer
+			cfg.Routing = test.routing
 
 			conn, err := cfg.CreateConnection(zap.NewNop())
 			if err != nil {
@@ -16,6 +16,7 @@ package grpc
 
 import (
 	"crypto/x509"
+	"errors"
 
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
@@ -25,31 +26,56 @@ import (
 	grpcManager "github.com/jaegertracing/jaeger/cmd/agent/app/configmanager/grpc"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
 	aReporter "github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 )
 
 // ProxyBuilder holds objects communicating with collector
 type ProxyBuilder struct {
-	reporter aReporter.Reporter
-	manager  configmanager.ClientConfigManager
-	conn     *grpc.ClientConn
+	reporter     aReporter.Reporter
+	unbatched    aReporter.Reporter
+	grpcReporter *Reporter
+	manager      configmanager.ClientConfigManager
+	conn         *grpc.ClientConn
 }
 
 var systemCertPool = x509.SystemCertPool // to allow overriding in unit test
 
 // NewCollectorProxy creates ProxyBuilder
 func NewCollectorProxy(builder *ConnBuilder, agentTags map[string]string, mFactory metrics.Factory, logger *zap.Logger) (*ProxyBuilder, error) {
+	if builder.BatchMaxBytes > 0 && builder.BatchMaxDelay <= 0 {
+		return nil, errors.New("the maximum delay of batches must be positive")
+	}
 	conn, err := builder.CreateConnection(logger)
 	if err != nil {
 		return nil, err
 	}
 	grpcMetrics := mFactory.Namespace(metrics.NSOptions{Name: "", Tags: map[string]string{"protocol": "grpc"}})
+	r := NewReporter(conn, agentTags, logger)
+	r.router = builder.router
+	if builder.BatchMaxBytes > 0 {
+		r.batcher = newBatcher(builder.BatchMaxBytes, builder.BatchMaxDelay, r.send, grpcMetrics)
+	}
 	return &ProxyBuilder{
-		conn:     conn,
-		reporter: reporter.WrapWithMetrics(NewReporter(conn, agentTags, logger), grpcMetrics),
-		manager:  configmanager.WrapWithMetrics(grpcManager.NewConfigManager(conn), grpcMetrics),
+		conn:         conn,
+		reporter:     reporter.WrapWithMetrics(r, grpcMetrics),
+		unbatched:    reporter.WrapWithMetrics(r.unbatched(), grpcMetrics),
+		grpcReporter: r,
+		manager:      configmanager.WrapWithMetrics(grpcManager.NewConfigManager(conn), grpcMetrics),
 	}, nil
 }
 
+// OnFailedBatch sets the function receiving the batches that the reporter accepted but failed to send
+// later, see Reporter.OnFailedBatch.
+func (b ProxyBuilder) OnFailedBatch(handler func(*jaeger.Batch) error) {
+	b.grpcReporter.OnFailedBatch(handler)
+}
+
+// GetUnbatchedReporter returns a Reporter that sends every batch right away instead of coalescing
+// it, so that its errors are returned. It is the same as GetReporter if batching is disabled.
+func (b ProxyBuilder) GetUnbatchedReporter() aReporter.Reporter {
+	return b.unbatched
+}
+
 // GetConn returns grpc conn
 func (b ProxyBuilder) GetConn() *grpc.ClientConn {
 	return b.conn
@@ -65,7 +91,8 @@ func (b ProxyBuilder) GetManager() configmanager.ClientConfigManager {
 	return b.manager
 }
 
-// Close closes connections used by proxy.
+// Close sends the pending spans and closes connections used by proxy.
 func (b ProxyBuilder) Close() error {
+	b.grpcReporter.Close()
 	return b.conn.Close()
 }
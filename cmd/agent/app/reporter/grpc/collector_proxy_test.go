@@ -15,9 +15,11 @@
 package grpc
 
 import (
+	"context"
 	"crypto/x509"
 	"errors"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
@@ -26,7 +28,9 @@ import (
 	"github.com/uber/jaeger-lib/metrics/metricstest"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
 
+	"github.com/jaegertracing/jaeger/pkg/compression"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 )
@@ -82,6 +86,83 @@ func TestMultipleCollectors(t *testing.T) {
 	require.Nil(t, proxy.Close())
 }
 
+func TestBatchingCompressedCollectorProxy(t *testing.T) {
+	spanHandler := &mockSpanHandler{}
+	encodings := &compressionRecorder{}
+	s, addr := initializeGRPCTestServer(t, func(s *grpc.Server) {
+		api_v2.RegisterCollectorServiceServer(s, spanHandler)
+	}, grpc.StatsHandler(encodings))
+	defer s.Stop()
+
+	mFactory := metricstest.NewFactory(time.Microsecond)
+	proxy, err := NewCollectorProxy(&ConnBuilder{
+		CollectorHostPorts: []string{addr.String()},
+		Compression:        compression.Snappy,
+		BatchMaxBytes:      1024 * 1024,
+		BatchMaxDelay:      time.Hour,
+	}, nil, mFactory, zap.NewNop())
+	require.NoError(t, err)
+
+	r := proxy.GetReporter()
+	for _, service := range []string{"a", "b", "a"} {
+		err := r.EmitBatch(&jaeger.Batch{Spans: []*jaeger.Span{{OperationName: "op"}}, Process: &jaeger.Process{ServiceName: service}})
+		require.NoError(t, err)
+	}
+	assert.Empty(t, spanHandler.getRequests())
+	// the unbatched reporter sends right away
+	err = proxy.GetUnbatchedReporter().EmitBatch(&jaeger.Batch{Spans: []*jaeger.Span{{OperationName: "op"}}, Process: &jaeger.Process{ServiceName: "c"}})
+	require.NoError(t, err)
+	assert.Len(t, spanHandler.getRequests(), 1)
+	require.NoError(t, proxy.Close())
+
+	spans := map[string]int{}
+	for _, req := range spanHandler.getRequests() {
+		spans[req.Batch.Process.ServiceName] += len(req.Batch.Spans)
+	}
+	assert.Equal(t, map[string]int{"a": 2, "b": 1, "c": 1}, spans)
+	assert.Equal(t, []string{"snappy", "snappy", "snappy"}, encodings.get())
+	mFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "reporter.batcher.flushes", Tags: map[string]string{"protocol": "grpc", "reason": "close"}, Value: 2,
+	})
+}
+
+// compressionRecorder is a stats.Handler that records the compression of the received requests.
+type compressionRecorder struct {
+	mux         sync.Mutex
+	compression []string
+}
+
+func (r *compressionRecorder) get() []string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.compression
+}
+
+func (r *compressionRecorder) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok {
+		r.mux.Lock()
+		r.compression = append(r.compression, header.Compression)
+		r.mux.Unlock()
+	}
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(ctx context.Context, s stats.ConnStats) {}
+
+func TestCollectorProxyBatchingCompressionErrors(t *testing.T) {
+	_, err := NewCollectorProxy(&ConnBuilder{CollectorHostPorts: []string{"foo"}, BatchMaxBytes: 1}, nil, nil, zap.NewNop())
+	assert.EqualError(t, err, "the maximum delay of batches must be positive")
+	_, err = NewCollectorProxy(&ConnBuilder{CollectorHostPorts: []string{"foo"}, Compression: "lz4"}, nil, nil, zap.NewNop())
+	assert.EqualError(t, err, `unknown compression "lz4", must be one of [none gzip snappy]`)
+}
+
 func initializeGRPCTestServer(t *testing.T, beforeServe func(server *grpc.Server), opts ...grpc.ServerOption) (*grpc.Server, net.Addr) {
 	server := grpc.NewServer(opts...)
 	lis, err := net.Listen("tcp", "localhost:0")
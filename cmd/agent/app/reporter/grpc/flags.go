@@ -16,9 +16,13 @@ package grpc
 
 import (
 	"flag"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/pkg/compression"
 )
 
 const (
@@ -32,6 +36,16 @@ const (
 	agentKey               = gRPCPrefix + "tls.key"
 	collectorTLSServerName = gRPCPrefix + "tls.server-name"
 	discoveryMinPeers      = gRPCPrefix + "discovery.min-peers"
+	routing                = gRPCPrefix + "routing"
+	compressionFlag        = gRPCPrefix + "compression"
+	batchMaxSize           = gRPCPrefix + "batch.max-size-bytes"
+	batchMaxDelay          = gRPCPrefix + "batch.max-delay"
+	defaultBatchMaxDelay   = 200 * time.Millisecond
+
+	// RoutingRoundRobin balances span batches over the collectors by round robin
+	RoutingRoundRobin = "round-robin"
+	// RoutingTraceID sends all spans of a trace to the same collector, using consistent hashing of the trace ID
+	RoutingTraceID = "trace-id"
 )
 
 // AddFlags adds flags for Options.
@@ -43,7 +57,11 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.String(collectorTLSServerName, "", "Override the TLS server name we expected in the remote certificate")
 	flags.String(agentCert, "", "Path to a TLS client certificate file, used to identify this agent to the collector")
 	flags.String(agentKey, "", "Path to the TLS client key for the client certificate")
-	flags.Int(discoveryMinPeers, 3, "Max number of collectors to which the agent will try to connect at any given time")
+	flags.Int(discoveryMinPeers, 3, "Max number of collectors to which the agent will try to connect at any given time. Ignored with trace-id routing, which connects to all collectors")
+	flags.String(compressionFlag, compression.None, fmt.Sprintf("Compression of the requests to the collectors, one of %v", compression.Names))
+	flags.Int(batchMaxSize, 0, "Coalesce the spans received from clients by process into batches of up to this size in bytes before sending them to the collectors. Batching is disabled if 0")
+	flags.Duration(batchMaxDelay, defaultBatchMaxDelay, "Maximum time spans wait to be coalesced into a batch")
+	flags.String(routing, RoutingRoundRobin, "How spans are routed to the collectors, either round-robin or trace-id. With trace-id all spans of a trace are sent to the same collector, e.g. for tail sampling, and the agent connects to all discovered collectors")
 }
 
 // InitFromViper initializes Options with properties retrieved from Viper.
@@ -59,5 +77,9 @@ func (b *ConnBuilder) InitFromViper(v *viper.Viper) *ConnBuilder {
 	b.TLSCert = v.GetString(agentCert)
 	b.TLSKey = v.GetString(agentKey)
 	b.DiscoveryMinPeers = v.GetInt(discoveryMinPeers)
+	b.Routing = v.GetString(routing)
+	b.Compression = v.GetString(compressionFlag)
+	b.BatchMaxBytes = v.GetInt(batchMaxSize)
+	b.BatchMaxDelay = v.GetDuration(batchMaxDelay)
 	return b
 }
@@ -17,11 +17,14 @@ package grpc
 import (
 	"flag"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/compression"
 )
 
 func TestBindFlags(t *testing.T) {
@@ -30,11 +33,15 @@ func TestBindFlags(t *testing.T) {
 		expected *ConnBuilder
 	}{
 		{cOpts: []string{"--reporter.grpc.host-port=localhost:1111", "--reporter.grpc.retry.max=15"},
-			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111"}, MaxRetry: 15, DiscoveryMinPeers: 3}},
+			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111"}, MaxRetry: 15, DiscoveryMinPeers: 3, Routing: RoutingRoundRobin, Compression: compression.None, BatchMaxDelay: defaultBatchMaxDelay}},
 		{cOpts: []string{"--reporter.grpc.host-port=localhost:1111,localhost:2222"},
-			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111", "localhost:2222"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 3}},
+			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111", "localhost:2222"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 3, Routing: RoutingRoundRobin, Compression: compression.None, BatchMaxDelay: defaultBatchMaxDelay}},
 		{cOpts: []string{"--reporter.grpc.host-port=localhost:1111,localhost:2222", "--reporter.grpc.discovery.min-peers=5"},
-			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111", "localhost:2222"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 5}},
+			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111", "localhost:2222"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 5, Routing: RoutingRoundRobin, Compression: compression.None, BatchMaxDelay: defaultBatchMaxDelay}},
+		{cOpts: []string{"--reporter.grpc.host-port=localhost:1111", "--reporter.grpc.routing=trace-id"},
+			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 3, Routing: RoutingTraceID, Compression: compression.None, BatchMaxDelay: defaultBatchMaxDelay}},
+		{cOpts: []string{"--reporter.grpc.host-port=localhost:1111", "--reporter.grpc.compression=snappy", "--reporter.grpc.batch.max-size-bytes=65536", "--reporter.grpc.batch.max-delay=1s"},
+			expected: &ConnBuilder{CollectorHostPorts: []string{"localhost:1111"}, MaxRetry: defaultMaxRetry, DiscoveryMinPeers: 3, Routing: RoutingRoundRobin, Compression: compression.Snappy, BatchMaxBytes: 65536, BatchMaxDelay: time.Second}},
 	}
 	for _, test := range tests {
 		v := viper.New()
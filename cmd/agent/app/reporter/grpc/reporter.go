@@ -25,6 +25,8 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	jConverter "github.com/jaegertracing/jaeger/model/converter/thrift/jaeger"
 	"github.com/jaegertracing/jaeger/model/converter/thrift/zipkin"
+	"github.com/jaegertracing/jaeger/pkg/discovery/hashring"
+	"github.com/jaegertracing/jaeger/pkg/multierror"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	thrift "github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
@@ -36,6 +38,11 @@ type Reporter struct {
 	agentTags []model.KeyValue
 	logger    *zap.Logger
 	sanitizer zipkin2.Sanitizer
+	// router groups the spans into one request per collector owning their traces, nil if spans are
+	// not routed by trace ID
+	router *traceRouter
+	// batcher coalesces the spans before they are sent, nil if batching is disabled
+	batcher *batcher
 }
 
 // NewReporter creates gRPC reporter.
@@ -50,7 +57,7 @@ func NewReporter(conn *grpc.ClientConn, agentTags map[string]string, logger *zap
 
 // EmitBatch implements EmitBatch() of Reporter
 func (r *Reporter) EmitBatch(b *thrift.Batch) error {
-	return r.send(jConverter.ToDomain(b.Spans, nil), jConverter.ToDomainProcess(b.Process))
+	return r.emit(jConverter.ToDomain(b.Spans, nil), jConverter.ToDomainProcess(b.Process))
 }
 
 // EmitZipkinBatch implements EmitZipkinBatch() of Reporter
@@ -62,34 +69,157 @@ func (r *Reporter) EmitZipkinBatch(zSpans []*zipkincore.Span) error {
 	if err != nil {
 		return err
 	}
-	return r.send(trace.Spans, nil)
+	return r.emit(trace.Spans, nil)
 }
 
-func (r *Reporter) send(spans []*model.Span, process *model.Process) error {
+// Close sends the spans waiting to be coalesced.
+func (r *Reporter) Close() error {
+	if r.batcher != nil {
+		r.batcher.close()
+	}
+	return nil
+}
+
+// OnFailedBatch sets the function receiving the coalesced batches that failed to send. The batches
+// their spans were received in were accepted, so the error cannot be returned to the clients anymore;
+// the handler can keep them for later instead, e.g. in the spool. It has no effect without batching.
+func (r *Reporter) OnFailedBatch(handler func(*thrift.Batch) error) {
+	if r.batcher == nil {
+		return
+	}
+	r.batcher.setOnFailure(func(spans []*model.Span, process *model.Process) {
+		for _, batch := range toThriftBatches(spans, process) {
+			if err := handler(batch); err != nil {
+				r.logger.Error("Failed to hand over coalesced batch", zap.Int("spans", len(batch.Spans)), zap.Error(err))
+			}
+		}
+	})
+}
+
+// unbatched returns a Reporter sharing the connection of r that sends every batch right away,
+// so that the caller learns whether it was sent.
+func (r *Reporter) unbatched() *Reporter {
+	u := *r
+	u.batcher = nil
+	return &u
+}
+
+// emit sends the spans, or hands them to the batcher. Errors to send coalesced batches are
+// only reported by logs and metrics, and to the handler of failed batches if set.
+func (r *Reporter) emit(spans []*model.Span, process *model.Process) error {
+	if r.batcher == nil {
+		_, err := r.send(spans, process)
+		return err
+	}
+	r.batcher.add(spans, process)
+	return nil
+}
+
+// send posts the spans, in one request per collector with trace ID routing. It returns the spans of the
+// requests that failed, as they were received.
+func (r *Reporter) send(spans []*model.Span, process *model.Process) ([]*model.Span, error) {
+	if r.router == nil {
+		if err := r.post(context.Background(), spans, process); err != nil {
+			return spans, err
+		}
+		return nil, nil
+	}
+	var failed []*model.Span
+	var errs []error
+	for _, group := range groupByCollector(spans, r.router) {
+		ctx := withTraceKey(context.Background(), hashring.TraceKey(group[0].TraceID))
+		if err := r.post(ctx, group, process); err != nil {
+			failed = append(failed, group...)
+			errs = append(errs, err)
+		}
+	}
+	return failed, multierror.Wrap(errs)
+}
+
+func (r *Reporter) post(ctx context.Context, spans []*model.Span, process *model.Process) error {
 	spans, process = addProcessTags(spans, process, r.agentTags)
 	batch := model.Batch{Spans: spans, Process: process}
 	req := &api_v2.PostSpansRequest{Batch: batch}
-	_, err := r.collector.PostSpans(context.Background(), req)
+	_, err := r.collector.PostSpans(ctx, req)
 	if err != nil {
 		r.logger.Error("Could not send spans over gRPC", zap.Error(err))
 	}
 	return err
 }
 
-// addTags appends jaeger tags for the agent to every span it sends to the collector.
+// groupByCollector splits spans by the collector that their traces are routed to, keeping the order of the spans
+func groupByCollector(spans []*model.Span, router *traceRouter) [][]*model.Span {
+	var groups [][]*model.Span
+	index := make(map[string]int)
+	routes := make(map[model.TraceID]string)
+	for _, span := range spans {
+		collector, ok := routes[span.TraceID]
+		if !ok {
+			collector = router.route(hashring.TraceKey(span.TraceID))
+			routes[span.TraceID] = collector
+		}
+		i, ok := index[collector]
+		if !ok {
+			i = len(groups)
+			index[collector] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], span)
+	}
+	return groups
+}
+
+// toThriftBatches converts coalesced spans back to Jaeger batches. The spans of Zipkin batches have no
+// common process but carry their own, they are grouped by process.
+func toThriftBatches(spans []*model.Span, process *model.Process) []*thrift.Batch {
+	if process != nil {
+		return []*thrift.Batch{{Process: jConverter.FromDomainProcess(process), Spans: jConverter.FromDomain(spans)}}
+	}
+	var processes []*model.Process
+	var batches []*thrift.Batch
+	for _, span := range spans {
+		i := 0
+		for i < len(processes) && !sameProcess(processes[i], span.Process) {
+			i++
+		}
+		if i == len(processes) {
+			batch := &thrift.Batch{}
+			if span.Process != nil {
+				batch.Process = jConverter.FromDomainProcess(span.Process)
+			}
+			processes = append(processes, span.Process)
+			batches = append(batches, batch)
+		}
+		batches[i].Spans = append(batches[i].Spans, jConverter.FromDomainSpan(span))
+	}
+	return batches
+}
+
+// addTags appends jaeger tags for the agent to every span it sends to the collector. The processes
+// are copied rather than modified, so that failed batches can be handed over as they were received.
 func addProcessTags(spans []*model.Span, process *model.Process, agentTags []model.KeyValue) ([]*model.Span, *model.Process) {
 	if len(agentTags) == 0 {
 		return spans, process
 	}
 	if process != nil {
-		process.Tags = append(process.Tags, agentTags...)
+		process = withTags(process, agentTags)
 	}
-	for _, span := range spans {
+	tagged := make([]*model.Span, len(spans))
+	for i, span := range spans {
 		if span.Process != nil {
-			span.Process.Tags = append(span.Process.Tags, agentTags...)
+			spanCopy := *span
+			spanCopy.Process = withTags(span.Process, agentTags)
+			span = &spanCopy
 		}
+		tagged[i] = span
 	}
-	return spans, process
+	return tagged, process
+}
+
+func withTags(process *model.Process, tags []model.KeyValue) *model.Process {
+	processTags := make([]model.KeyValue, 0, len(process.Tags)+len(tags))
+	processTags = append(append(processTags, process.Tags...), tags...)
+	return &model.Process{ServiceName: process.ServiceName, Tags: processTags}
 }
 
 func makeModelKeyValue(agentTags map[string]string) []model.KeyValue {
@@ -16,12 +16,14 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
@@ -123,7 +125,7 @@ func TestReporter_SendFailure(t *testing.T) {
 	conn, err := grpc.Dial("", grpc.WithInsecure())
 	require.NoError(t, err)
 	rep := NewReporter(conn, nil, zap.NewNop())
-	err = rep.send(nil, nil)
+	_, err = rep.send(nil, nil)
 	assert.EqualError(t, err, "rpc error: code = Unavailable desc = all SubConns are in TransientFailure, latest connection error: connection error: desc = \"transport: Error while dialing dial tcp: missing address\"")
 }
 
@@ -160,6 +162,52 @@ func TestReporter_AddProcessTags_JaegerBatch(t *testing.T) {
 	_, actualProcess := addProcessTags(spans, process, makeModelKeyValue(tags))
 
 	assert.Equal(t, expectedProcess, actualProcess)
+	assert.Empty(t, process.Tags, "the process of the batch is not modified")
+}
+
+func TestReporter_OnFailedBatch(t *testing.T) {
+	conn, err := grpc.Dial("", grpc.WithInsecure())
+	require.NoError(t, err)
+	//lint:ignore SA5001 don't care about errors
+	defer conn.Close()
+	rep := NewReporter(conn, map[string]string{"agent": "a1"}, zap.NewNop())
+	// without batching, errors are returned to the caller
+	rep.OnFailedBatch(func(*jThrift.Batch) error { return nil })
+
+	rep.batcher = newBatcher(1, time.Hour, rep.send, metricstest.NewFactory(0))
+	var failed []*jThrift.Batch
+	rep.OnFailedBatch(func(batch *jThrift.Batch) error {
+		failed = append(failed, batch)
+		return errors.New("spool is full")
+	})
+	batch := &jThrift.Batch{Process: &jThrift.Process{ServiceName: "node"}, Spans: []*jThrift.Span{{OperationName: "foo"}}}
+	require.NoError(t, rep.EmitBatch(batch))
+	require.NoError(t, rep.Close())
+	require.Len(t, failed, 1)
+	assert.Equal(t, "node", failed[0].Process.ServiceName)
+	assert.Empty(t, failed[0].Process.Tags, "failed batches are handed over without the agent tags")
+	require.Len(t, failed[0].Spans, 1)
+	assert.Equal(t, "foo", failed[0].Spans[0].OperationName)
+}
+
+func TestToThriftBatches(t *testing.T) {
+	spans := []*model.Span{
+		{OperationName: "a1", Process: model.NewProcess("a", nil)},
+		{OperationName: "b1", Process: model.NewProcess("b", nil)},
+		{OperationName: "a2", Process: model.NewProcess("a", nil)},
+		{OperationName: "none"},
+	}
+	batches := toThriftBatches(spans, nil)
+	require.Len(t, batches, 3)
+	assert.Equal(t, "a", batches[0].Process.ServiceName)
+	assert.Equal(t, []string{"a1", "a2"}, []string{batches[0].Spans[0].OperationName, batches[0].Spans[1].OperationName})
+	assert.Equal(t, "b", batches[1].Process.ServiceName)
+	assert.Nil(t, batches[2].Process)
+
+	batches = toThriftBatches(spans[:2], model.NewProcess("c", nil))
+	require.Len(t, batches, 1)
+	assert.Equal(t, "c", batches[0].Process.ServiceName)
+	assert.Len(t, batches[0].Spans, 2)
 }
 
 func TestReporter_MakeModelKeyValue(t *testing.T) {
@@ -0,0 +1,180 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/jaegertracing/jaeger/pkg/discovery/hashring"
+)
+
+// traceIDBalancerName is the name of the gRPC balancer which routes requests by trace ID
+const traceIDBalancerName = "jaeger_trace_id"
+
+type traceKeyContextKey struct{}
+
+// traceIDBalancers numbers the balancers registered for every connection with trace ID routing
+var traceIDBalancers uint32
+
+// registerTraceIDBalancer registers a balancer routing requests by trace ID which reports its routes
+// to router, and returns its name. The balancer of every connection is registered under its own name,
+// since gRPC gives no other way to share state between the connection and its balancer.
+func registerTraceIDBalancer(router *traceRouter) string {
+	name := fmt.Sprintf("%s_%d", traceIDBalancerName, atomic.AddUint32(&traceIDBalancers, 1))
+	balancer.Register(traceIDBalancerBuilder{name: name, router: router})
+	return name
+}
+
+// traceRouter tells the collector that the requests for a trace key are routed to, so that the spans
+// of all the traces owned by a collector can be sent to it in a single request.
+type traceRouter struct {
+	picker atomic.Value // *traceIDPicker
+}
+
+// route returns the address of the collector that the trace key is routed to, or an empty string
+// if no collector is ready.
+func (r *traceRouter) route(key uint64) string {
+	if p, ok := r.picker.Load().(*traceIDPicker); ok && p != nil {
+		return p.route(key)
+	}
+	return ""
+}
+
+func (r *traceRouter) setPicker(p *traceIDPicker) {
+	r.picker.Store(p)
+}
+
+// withTraceKey returns a context which routes the request to the collector owning the trace key
+func withTraceKey(ctx context.Context, key uint64) context.Context {
+	return context.WithValue(ctx, traceKeyContextKey{}, key)
+}
+
+// traceIDBalancerBuilder builds a base balancer whose pickers know all the resolved collectors,
+// not only the ready ones, so that the owner of a trace does not change when a connection blips.
+type traceIDBalancerBuilder struct {
+	name   string
+	router *traceRouter
+}
+
+func (bb traceIDBalancerBuilder) Name() string {
+	return bb.name
+}
+
+func (bb traceIDBalancerBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	pickerBuilder := &traceIDPickerBuilder{router: bb.router}
+	b := base.NewBalancerBuilderWithConfig(bb.name, pickerBuilder, base.Config{HealthCheck: true}).Build(cc, opts)
+	return &traceIDBalancer{Balancer: b, v2: b.(balancer.V2Balancer), pickerBuilder: pickerBuilder}
+}
+
+// traceIDBalancer records the resolved addresses for the picker builder before passing them to the base balancer.
+type traceIDBalancer struct {
+	balancer.Balancer
+	v2            balancer.V2Balancer
+	pickerBuilder *traceIDPickerBuilder
+}
+
+func (b *traceIDBalancer) UpdateResolverState(s resolver.State) {
+	b.pickerBuilder.setAddresses(s.Addresses)
+	b.v2.UpdateResolverState(s)
+}
+
+func (b *traceIDBalancer) UpdateSubConnState(sc balancer.SubConn, state balancer.SubConnState) {
+	b.v2.UpdateSubConnState(sc, state)
+}
+
+type traceIDPickerBuilder struct {
+	mux       sync.Mutex
+	addresses []string
+	// router is told the routes of the pickers, if set
+	router *traceRouter
+}
+
+func (pb *traceIDPickerBuilder) setAddresses(resolved []resolver.Address) {
+	addresses := make([]string, 0, len(resolved))
+	for _, addr := range resolved {
+		addresses = append(addresses, addr.Addr)
+	}
+	pb.mux.Lock()
+	defer pb.mux.Unlock()
+	pb.addresses = addresses
+}
+
+// Build creates a picker with a consistent hash ring of all the resolved collectors, and one of the
+// ready collectors which takes over the traces of the collectors that are not ready.
+func (pb *traceIDPickerBuilder) Build(readySCs map[resolver.Address]balancer.SubConn) balancer.Picker {
+	if len(readySCs) == 0 {
+		pb.setRoutes(nil)
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	subConns := make(map[string]balancer.SubConn, len(readySCs))
+	ready := make([]string, 0, len(readySCs))
+	for addr, sc := range readySCs {
+		subConns[addr.Addr] = sc
+		ready = append(ready, addr.Addr)
+	}
+	pb.mux.Lock()
+	all := append(append([]string(nil), pb.addresses...), ready...)
+	pb.mux.Unlock()
+	readyRing := hashring.New(ready)
+	picker := &traceIDPicker{
+		ring:      hashring.New(all),
+		readyRing: readyRing,
+		subConns:  subConns,
+		addresses: readyRing.Peers(),
+	}
+	pb.setRoutes(picker)
+	return picker
+}
+
+func (pb *traceIDPickerBuilder) setRoutes(picker *traceIDPicker) {
+	if pb.router != nil {
+		pb.router.setPicker(picker)
+	}
+}
+
+type traceIDPicker struct {
+	ring      *hashring.Ring
+	readyRing *hashring.Ring
+	subConns  map[string]balancer.SubConn
+	addresses []string
+	next      uint32
+}
+
+// Pick returns the collector owning the trace key of the request, or if it is not ready the ready
+// collector owning it among the ready ones. Requests without a trace key, e.g. sampling strategy
+// requests, are balanced by round robin.
+func (p *traceIDPicker) Pick(ctx context.Context, opts balancer.PickOptions) (balancer.SubConn, func(balancer.DoneInfo), error) {
+	if key, ok := ctx.Value(traceKeyContextKey{}).(uint64); ok {
+		return p.subConns[p.route(key)], nil, nil
+	}
+	next := atomic.AddUint32(&p.next, 1)
+	return p.subConns[p.addresses[next%uint32(len(p.addresses))]], nil, nil
+}
+
+// route returns the address of the collector owning the trace key, or if it is not ready of the ready
+// collector owning it among the ready ones.
+func (p *traceIDPicker) route(key uint64) string {
+	if owner := p.ring.Get(key); p.subConns[owner] != nil {
+		return owner
+	}
+	return p.readyRing.Get(key)
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/discovery/hashring"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+)
+
+type fakeSubConn struct {
+	addr string
+}
+
+func (*fakeSubConn) UpdateAddresses([]resolver.Address) {}
+
+func (*fakeSubConn) Connect() {}
+
+func TestTraceIDPicker(t *testing.T) {
+	_, _, err := new(traceIDPickerBuilder).Build(nil).Pick(context.Background(), balancer.PickOptions{})
+	assert.Equal(t, balancer.ErrNoSubConnAvailable, err)
+
+	readySCs := map[resolver.Address]balancer.SubConn{
+		{Addr: "a:1"}: &fakeSubConn{addr: "a:1"},
+		{Addr: "b:1"}: &fakeSubConn{addr: "b:1"},
+		{Addr: "c:1"}: &fakeSubConn{addr: "c:1"},
+	}
+	picker := new(traceIDPickerBuilder).Build(readySCs)
+	ring := hashring.New([]string{"a:1", "b:1", "c:1"})
+	for i := uint64(0); i < 100; i++ {
+		key := hashring.TraceKey(model.NewTraceID(0, i))
+		sc, _, err := picker.Pick(withTraceKey(context.Background(), key), balancer.PickOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, ring.Get(key), sc.(*fakeSubConn).addr)
+	}
+
+	// requests without a trace key are balanced by round robin
+	picked := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		sc, _, err := picker.Pick(context.Background(), balancer.PickOptions{})
+		require.NoError(t, err)
+		picked[sc.(*fakeSubConn).addr]++
+	}
+	assert.Equal(t, map[string]int{"a:1": 10, "b:1": 10, "c:1": 10}, picked)
+}
+
+func TestTraceIDPickerFailover(t *testing.T) {
+	pb := &traceIDPickerBuilder{}
+	pb.setAddresses([]resolver.Address{{Addr: "a:1"}, {Addr: "b:1"}, {Addr: "c:1"}})
+	picker := pb.Build(map[resolver.Address]balancer.SubConn{
+		{Addr: "a:1"}: &fakeSubConn{addr: "a:1"},
+		{Addr: "b:1"}: &fakeSubConn{addr: "b:1"},
+	})
+	ring := hashring.New([]string{"a:1", "b:1", "c:1"})
+	readyRing := hashring.New([]string{"a:1", "b:1"})
+	failedOver := 0
+	for i := uint64(0); i < 100; i++ {
+		key := hashring.TraceKey(model.NewTraceID(0, i))
+		sc, _, err := picker.Pick(withTraceKey(context.Background(), key), balancer.PickOptions{})
+		require.NoError(t, err)
+		// the traces of the ready collectors stay with them, only those of c:1 move
+		if owner := ring.Get(key); owner != "c:1" {
+			assert.Equal(t, owner, sc.(*fakeSubConn).addr)
+		} else {
+			assert.Equal(t, readyRing.Get(key), sc.(*fakeSubConn).addr)
+			failedOver++
+		}
+	}
+	assert.True(t, failedOver > 0)
+}
+
+func TestTraceRouter(t *testing.T) {
+	router := &traceRouter{}
+	assert.Equal(t, "", router.route(1), "no collector is ready before the first picker is built")
+
+	pb := &traceIDPickerBuilder{router: router}
+	pb.Build(map[resolver.Address]balancer.SubConn{{Addr: "a:1"}: &fakeSubConn{addr: "a:1"}})
+	assert.Equal(t, "a:1", router.route(1))
+
+	pb.Build(nil)
+	assert.Equal(t, "", router.route(1))
+}
+
+// routedCollector fails the requests routed to the collectors in failures
+type routedCollector struct {
+	api_v2.CollectorServiceClient
+	router   *traceRouter
+	failures map[string]bool
+	requests map[string][]*api_v2.PostSpansRequest
+}
+
+func (c *routedCollector) PostSpans(ctx context.Context, r *api_v2.PostSpansRequest, _ ...grpc.CallOption) (*api_v2.PostSpansResponse, error) {
+	collector := c.router.route(ctx.Value(traceKeyContextKey{}).(uint64))
+	c.requests[collector] = append(c.requests[collector], r)
+	if c.failures[collector] {
+		return nil, errors.New("collector is down")
+	}
+	return &api_v2.PostSpansResponse{}, nil
+}
+
+func TestReporterSendsOneRequestPerCollector(t *testing.T) {
+	router := &traceRouter{}
+	pb := &traceIDPickerBuilder{router: router}
+	pb.Build(map[resolver.Address]balancer.SubConn{
+		{Addr: "a:1"}: &fakeSubConn{addr: "a:1"},
+		{Addr: "b:1"}: &fakeSubConn{addr: "b:1"},
+	})
+	collector := &routedCollector{
+		router:   router,
+		failures: map[string]bool{"b:1": true},
+		requests: make(map[string][]*api_v2.PostSpansRequest),
+	}
+	r := &Reporter{collector: collector, logger: zap.NewNop(), router: router}
+
+	var spans []*model.Span
+	for i := uint64(1); i <= 20; i++ {
+		spans = append(spans,
+			&model.Span{TraceID: model.NewTraceID(0, i), SpanID: 1},
+			&model.Span{TraceID: model.NewTraceID(0, i), SpanID: 2})
+	}
+	failed, err := r.send(spans, model.NewProcess("svc", nil))
+	assert.EqualError(t, err, "collector is down")
+
+	require.Len(t, collector.requests["a:1"], 1)
+	require.Len(t, collector.requests["b:1"], 1)
+	assert.Equal(t, collector.requests["b:1"][0].Batch.Spans, failed, "only the spans of the failed request are returned")
+	for addr, requests := range collector.requests {
+		for _, span := range requests[0].Batch.Spans {
+			assert.Equal(t, addr, router.route(hashring.TraceKey(span.TraceID)))
+		}
+	}
+	assert.Len(t, collector.requests["a:1"][0].Batch.Spans, len(spans)-len(failed))
+}
+
+func TestTraceIDRouting(t *testing.T) {
+	spanHandler1 := &mockSpanHandler{}
+	s1, addr1 := initializeGRPCTestServer(t, func(s *grpc.Server) {
+		api_v2.RegisterCollectorServiceServer(s, spanHandler1)
+	})
+	defer s1.Stop()
+	spanHandler2 := &mockSpanHandler{}
+	s2, addr2 := initializeGRPCTestServer(t, func(s *grpc.Server) {
+		api_v2.RegisterCollectorServiceServer(s, spanHandler2)
+	})
+	defer s2.Stop()
+
+	hostPorts := []string{addr1.String(), addr2.String()}
+	proxy, err := NewCollectorProxy(&ConnBuilder{CollectorHostPorts: hostPorts, Routing: RoutingTraceID}, nil, metrics.NullFactory, zap.NewNop())
+	require.NoError(t, err)
+	defer proxy.Close()
+	r := proxy.GetReporter()
+
+	batch := &jaeger.Batch{Process: &jaeger.Process{ServiceName: "service"}}
+	for i := int64(1); i <= 20; i++ {
+		batch.Spans = append(batch.Spans,
+			&jaeger.Span{TraceIdLow: i, SpanId: 1, OperationName: "op"},
+			&jaeger.Span{TraceIdLow: i, SpanId: 2, OperationName: "op"})
+	}
+	// wait until both collectors are connected, the traces of a collector that is not ready go to another one
+	for i := 0; i < 100 && (len(spanHandler1.getRequests()) == 0 || len(spanHandler2.getRequests()) == 0); i++ {
+		require.NoError(t, r.EmitBatch(batch))
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NotEmpty(t, spanHandler1.getRequests())
+	require.NotEmpty(t, spanHandler2.getRequests())
+
+	handler1Count := len(spanHandler1.getRequests())
+	handler2Count := len(spanHandler2.getRequests())
+	require.NoError(t, r.EmitBatch(batch))
+
+	ring := hashring.New(hostPorts)
+	for addr, requests := range map[string][]*api_v2.PostSpansRequest{
+		addr1.String(): spanHandler1.getRequests()[handler1Count:],
+		addr2.String(): spanHandler2.getRequests()[handler2Count:],
+	} {
+		require.Len(t, requests, 1, "the traces owned by a collector are sent in a single request")
+		for _, span := range requests[0].GetBatch().Spans {
+			assert.Equal(t, addr, ring.Get(hashring.TraceKey(span.TraceID)))
+		}
+	}
+}
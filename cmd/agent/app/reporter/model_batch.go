@@ -0,0 +1,56 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+	jConverter "github.com/jaegertracing/jaeger/model/converter/thrift/jaeger"
+	"github.com/jaegertracing/jaeger/pkg/multierror"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+)
+
+// EmitModelBatch converts a batch of the domain model, such as received in a api_v2.PostSpansRequest,
+// to Jaeger Thrift and emits it with the reporter. The spans without process inherit the process
+// of the batch; Thrift batches have a single process, so spans with different processes are
+// emitted in separate batches.
+func EmitModelBatch(r Reporter, batch model.Batch) error {
+	var batches []*jaeger.Batch
+	var processes []*model.Process
+	for _, span := range batch.Spans {
+		process := span.Process
+		if process == nil {
+			process = batch.Process
+		}
+		if process == nil {
+			process = &model.Process{}
+		}
+		i := 0
+		for i < len(processes) && !processes[i].Equal(process) {
+			i++
+		}
+		if i == len(processes) {
+			processes = append(processes, process)
+			batches = append(batches, &jaeger.Batch{Process: jConverter.FromDomainProcess(process)})
+		}
+		batches[i].Spans = append(batches[i].Spans, jConverter.FromDomainSpan(span))
+	}
+	var errors []error
+	for _, b := range batches {
+		if err := r.EmitBatch(b); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return multierror.Wrap(errors)
+}
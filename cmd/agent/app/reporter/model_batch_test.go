@@ -0,0 +1,91 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+type batchRecorder struct {
+	batches []*jaeger.Batch
+}
+
+func (r *batchRecorder) EmitZipkinBatch(spans []*zipkincore.Span) error {
+	return nil
+}
+
+func (r *batchRecorder) EmitBatch(batch *jaeger.Batch) error {
+	r.batches = append(r.batches, batch)
+	return nil
+}
+
+func TestEmitModelBatch(t *testing.T) {
+	other := &model.Process{ServiceName: "other", Tags: []model.KeyValue{model.String("k", "v")}}
+	batch := model.Batch{
+		Process: &model.Process{ServiceName: "svc"},
+		Spans: []*model.Span{
+			{TraceID: model.NewTraceID(0, 1), SpanID: 1, OperationName: "op1"},
+			{TraceID: model.NewTraceID(0, 1), SpanID: 2, OperationName: "op2", Process: other},
+			{TraceID: model.NewTraceID(0, 1), SpanID: 3, OperationName: "op3", Process: &model.Process{ServiceName: "svc"}},
+			{TraceID: model.NewTraceID(0, 1), SpanID: 4, OperationName: "op4", Process: &model.Process{ServiceName: "other", Tags: []model.KeyValue{model.String("k", "v")}}},
+		},
+	}
+	r := &batchRecorder{}
+	require.NoError(t, EmitModelBatch(r, batch))
+	require.Len(t, r.batches, 2)
+
+	assert.Equal(t, "svc", r.batches[0].Process.ServiceName)
+	require.Len(t, r.batches[0].Spans, 2)
+	assert.Equal(t, "op1", r.batches[0].Spans[0].OperationName)
+	assert.Equal(t, "op3", r.batches[0].Spans[1].OperationName)
+
+	assert.Equal(t, "other", r.batches[1].Process.ServiceName)
+	require.Len(t, r.batches[1].Process.Tags, 1)
+	assert.Equal(t, "k", r.batches[1].Process.Tags[0].Key)
+	require.Len(t, r.batches[1].Spans, 2)
+	assert.Equal(t, "op2", r.batches[1].Spans[0].OperationName)
+	assert.Equal(t, "op4", r.batches[1].Spans[1].OperationName)
+}
+
+func TestEmitModelBatchWithoutProcess(t *testing.T) {
+	r := &batchRecorder{}
+	require.NoError(t, EmitModelBatch(r, model.Batch{Spans: []*model.Span{{OperationName: "op"}}}))
+	require.Len(t, r.batches, 1)
+	assert.NotNil(t, r.batches[0].Process)
+	assert.Len(t, r.batches[0].Spans, 1)
+
+	r = &batchRecorder{}
+	require.NoError(t, EmitModelBatch(r, model.Batch{}))
+	assert.Empty(t, r.batches)
+}
+
+func TestEmitModelBatchError(t *testing.T) {
+	batch := model.Batch{
+		Spans: []*model.Span{
+			{OperationName: "op1", Process: &model.Process{ServiceName: "svc1"}},
+			{OperationName: "op2", Process: &model.Process{ServiceName: "svc2"}},
+		},
+	}
+	err := EmitModelBatch(alwaysFailReporter{err: errors.New("emit failed")}, batch)
+	assert.EqualError(t, err, "[emit failed, emit failed]")
+}
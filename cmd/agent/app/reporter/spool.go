@@ -0,0 +1,357 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model/converter/thrift/zipkin"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+const (
+	spoolFileNameLength = 16
+	spoolTmpSuffix      = ".tmp"
+)
+
+// SpoolOptions configures the on-disk spool of the batches that could not be sent to the collectors.
+type SpoolOptions struct {
+	// Directory of the spool, the spool is disabled if empty
+	Directory string
+	// MaxSizeBytes is the maximum size of the spool, the oldest batches are evicted beyond it
+	MaxSizeBytes int64
+	// RetryInterval is how often the spooled batches are replayed
+	RetryInterval time.Duration
+}
+
+type spoolMetrics struct {
+	// Number of batches written to the spool
+	BatchesSpooled metrics.Counter `metric:"batches.spooled"`
+
+	// Number of spooled batches sent to collector
+	BatchesReplayed metrics.Counter `metric:"batches.replayed"`
+
+	// Number of spooled batches removed to keep the spool under its maximum size
+	BatchesEvicted metrics.Counter `metric:"batches.evicted"`
+
+	// Number of batches that could neither be sent nor spooled
+	BatchesDropped metrics.Counter `metric:"batches.dropped"`
+
+	// Number of batches in the spool
+	Batches metrics.Gauge `metric:"batches"`
+
+	// Size of the spool in bytes
+	SizeBytes metrics.Gauge `metric:"size_bytes"`
+}
+
+// spooledBatch is a batch file in the spool directory. Files are named after a fixed width
+// sequence number, so that their lexical order is the order in which they were spooled, and
+// their extension is the format of the batch.
+type spooledBatch struct {
+	name string
+	size int64
+}
+
+// SpoolingReporter wraps a Reporter and persists the batches it fails to send in a directory.
+// The spooled batches are replayed in order once the wrapped Reporter succeeds again; while
+// the spool is not empty new batches are appended to it, so that they are not sent before the
+// older ones. The spool survives restarts of the agent.
+type SpoolingReporter struct {
+	wrapped Reporter
+	// replayer sends the spooled batches, the wrapped Reporter unless set
+	replayer Reporter
+	options  SpoolOptions
+	logger   *zap.Logger
+	metrics  spoolMetrics
+
+	mux     sync.Mutex
+	batches []spooledBatch
+	size    int64
+	nextSeq uint64
+
+	done    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// NewSpoolingReporter creates a SpoolingReporter and loads the batches already in the spool directory.
+func NewSpoolingReporter(wrapped Reporter, options SpoolOptions, mFactory metrics.Factory, logger *zap.Logger) (*SpoolingReporter, error) {
+	if options.MaxSizeBytes <= 0 {
+		return nil, fmt.Errorf("spool maximum size must be positive, got %d", options.MaxSizeBytes)
+	}
+	if options.RetryInterval <= 0 {
+		return nil, fmt.Errorf("spool retry interval must be positive, got %v", options.RetryInterval)
+	}
+	if err := os.MkdirAll(options.Directory, 0700); err != nil {
+		return nil, err
+	}
+	r := &SpoolingReporter{
+		wrapped: wrapped,
+		options: options,
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+	metrics.Init(&r.metrics, mFactory.Namespace(metrics.NSOptions{Name: "reporter"}).Namespace(metrics.NSOptions{Name: "spool"}), nil)
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	if len(r.batches) > 0 {
+		logger.Info("Found spooled batches", zap.Int("batches", len(r.batches)), zap.Int64("bytes", r.size))
+	}
+	r.stopped.Add(1)
+	go r.replayLoop()
+	return r, nil
+}
+
+// load reads the spool directory, leftovers of interrupted writes are removed.
+func (r *SpoolingReporter) load() error {
+	files, err := ioutil.ReadDir(r.options.Directory)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		name := file.Name()
+		if strings.HasSuffix(name, spoolTmpSuffix) {
+			os.Remove(filepath.Join(r.options.Directory, name))
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, filepath.Ext(name)), 10, 64)
+		if err != nil || file.IsDir() {
+			r.logger.Warn("Ignoring unexpected file in spool directory", zap.String("file", name))
+			continue
+		}
+		r.batches = append(r.batches, spooledBatch{name: name, size: file.Size()})
+		r.size += file.Size()
+		if seq >= r.nextSeq {
+			r.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(r.batches, func(i, j int) bool { return r.batches[i].name < r.batches[j].name })
+	r.updateGauges()
+	return nil
+}
+
+// EmitZipkinBatch implements Reporter.
+func (r *SpoolingReporter) EmitZipkinBatch(spans []*zipkincore.Span) error {
+	return r.emit(zipkinBatches, func() error { return r.wrapped.EmitZipkinBatch(spans) }, func() ([]byte, error) {
+		return zipkin.SerializeThrift(spans), nil
+	})
+}
+
+// EmitBatch implements Reporter.
+func (r *SpoolingReporter) EmitBatch(batch *jaeger.Batch) error {
+	return r.emit(jaegerBatches, func() error { return r.wrapped.EmitBatch(batch) }, func() ([]byte, error) {
+		return serializeJaegerBatch(batch)
+	})
+}
+
+// ReplayWith sets the Reporter sending the spooled batches. A batch is only removed from the spool once
+// the Reporter returns, so it must not accept batches it sends later, e.g. to coalesce them.
+func (r *SpoolingReporter) ReplayWith(replayer Reporter) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.replayer = replayer
+}
+
+// SpoolBatch writes a batch to the spool without sending it first. It receives the batches that the
+// wrapped Reporter accepted but failed to send later, e.g. once coalesced with other batches.
+func (r *SpoolingReporter) SpoolBatch(batch *jaeger.Batch) error {
+	return r.store(jaegerBatches, func() ([]byte, error) { return serializeJaegerBatch(batch) })
+}
+
+func serializeJaegerBatch(batch *jaeger.Batch) ([]byte, error) {
+	if batch.Process == nil {
+		// Process is a required field, the batch could not be serialized
+		return nil, errors.New("cannot spool a batch without process")
+	}
+	return thrift.NewTSerializer().Write(batch)
+}
+
+func (r *SpoolingReporter) emit(format string, send func() error, serialize func() ([]byte, error)) error {
+	r.mux.Lock()
+	spooling := len(r.batches) > 0
+	r.mux.Unlock()
+	if !spooling {
+		err := send()
+		if err == nil {
+			return nil
+		}
+		r.logger.Debug("Failed to send batch, spooling it", zap.Error(err))
+	}
+	return r.store(format, serialize)
+}
+
+// store serializes a batch and spools it, batches which cannot be spooled are dropped.
+func (r *SpoolingReporter) store(format string, serialize func() ([]byte, error)) error {
+	data, err := serialize()
+	if err == nil {
+		err = r.spool(format, data)
+	}
+	if err != nil {
+		r.metrics.BatchesDropped.Inc(1)
+		return err
+	}
+	return nil
+}
+
+// spool writes a batch to the spool, and evicts the oldest batches beyond the maximum size.
+func (r *SpoolingReporter) spool(format string, data []byte) error {
+	size := int64(len(data))
+	if size > r.options.MaxSizeBytes {
+		return fmt.Errorf("batch of %d bytes exceeds the maximum spool size", size)
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	name := fmt.Sprintf("%0*d.%s", spoolFileNameLength, r.nextSeq, format)
+	path := filepath.Join(r.options.Directory, name)
+	if err := ioutil.WriteFile(path+spoolTmpSuffix, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(path+spoolTmpSuffix, path); err != nil {
+		return err
+	}
+	r.nextSeq++
+	r.batches = append(r.batches, spooledBatch{name: name, size: size})
+	r.size += size
+	r.metrics.BatchesSpooled.Inc(1)
+	for r.size > r.options.MaxSizeBytes {
+		r.logger.Warn("Spool is full, evicting the oldest batch", zap.String("file", r.batches[0].name))
+		r.removeOldest()
+		r.metrics.BatchesEvicted.Inc(1)
+	}
+	r.updateGauges()
+	return nil
+}
+
+// removeOldest removes the first batch of the spool, the lock must be held.
+func (r *SpoolingReporter) removeOldest() {
+	if err := os.Remove(filepath.Join(r.options.Directory, r.batches[0].name)); err != nil && !os.IsNotExist(err) {
+		r.logger.Error("Failed to remove spooled batch", zap.String("file", r.batches[0].name), zap.Error(err))
+	}
+	r.size -= r.batches[0].size
+	r.batches = r.batches[1:]
+}
+
+func (r *SpoolingReporter) updateGauges() {
+	r.metrics.Batches.Update(int64(len(r.batches)))
+	r.metrics.SizeBytes.Update(r.size)
+}
+
+func (r *SpoolingReporter) replayLoop() {
+	defer r.stopped.Done()
+	ticker := time.NewTicker(r.options.RetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.replay()
+		}
+	}
+}
+
+// replay sends the spooled batches in order, until the spool is empty or a batch fails to send.
+func (r *SpoolingReporter) replay() {
+	for {
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+		r.mux.Lock()
+		if len(r.batches) == 0 {
+			r.mux.Unlock()
+			return
+		}
+		oldest := r.batches[0]
+		r.mux.Unlock()
+
+		err := r.send(oldest.name)
+		if err != nil && !os.IsNotExist(err) {
+			r.logger.Debug("Failed to replay spooled batch", zap.String("file", oldest.name), zap.Error(err))
+			if _, corrupted := err.(spoolCorruptionError); !corrupted {
+				return
+			}
+			r.logger.Error("Discarding corrupted spooled batch", zap.String("file", oldest.name), zap.Error(err))
+			r.metrics.BatchesDropped.Inc(1)
+		} else if err == nil {
+			r.metrics.BatchesReplayed.Inc(1)
+		}
+
+		r.mux.Lock()
+		// The batch may have been evicted while it was sent
+		if len(r.batches) > 0 && r.batches[0].name == oldest.name {
+			r.removeOldest()
+			r.updateGauges()
+		}
+		r.mux.Unlock()
+	}
+}
+
+// spoolCorruptionError is returned for spooled batches that cannot be decoded, and would never be sent.
+type spoolCorruptionError struct {
+	error
+}
+
+func (r *SpoolingReporter) send(name string) error {
+	data, err := ioutil.ReadFile(filepath.Join(r.options.Directory, name))
+	if err != nil {
+		return err
+	}
+	r.mux.Lock()
+	replayer := r.replayer
+	r.mux.Unlock()
+	if replayer == nil {
+		replayer = r.wrapped
+	}
+	switch filepath.Ext(name) {
+	case "." + jaegerBatches:
+		batch := &jaeger.Batch{}
+		if err := thrift.NewTDeserializer().Read(batch, data); err != nil {
+			return spoolCorruptionError{err}
+		}
+		return replayer.EmitBatch(batch)
+	case "." + zipkinBatches:
+		spans, err := zipkin.DeserializeThrift(data)
+		if err != nil {
+			return spoolCorruptionError{err}
+		}
+		return replayer.EmitZipkinBatch(spans)
+	default:
+		return spoolCorruptionError{fmt.Errorf("unknown batch format %q", filepath.Ext(name))}
+	}
+}
+
+// Close stops replaying the spooled batches, they are kept on disk for the next start.
+func (r *SpoolingReporter) Close() error {
+	close(r.done)
+	r.stopped.Wait()
+	return nil
+}
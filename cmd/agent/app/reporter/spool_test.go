@@ -0,0 +1,292 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/testutils"
+	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+// unreliableReporter fails to emit batches while the collector is down.
+type unreliableReporter struct {
+	*testutils.InMemoryReporter
+	mux  sync.Mutex
+	down bool
+}
+
+func (r *unreliableReporter) setDown(down bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.down = down
+}
+
+func (r *unreliableReporter) err() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.down {
+		return errors.New("collector is down")
+	}
+	return nil
+}
+
+func (r *unreliableReporter) EmitZipkinBatch(spans []*zipkincore.Span) error {
+	if err := r.err(); err != nil {
+		return err
+	}
+	return r.InMemoryReporter.EmitZipkinBatch(spans)
+}
+
+func (r *unreliableReporter) EmitBatch(batch *jaeger.Batch) error {
+	if err := r.err(); err != nil {
+		return err
+	}
+	return r.InMemoryReporter.EmitBatch(batch)
+}
+
+func newBatch(operation string) *jaeger.Batch {
+	return &jaeger.Batch{
+		Process: &jaeger.Process{ServiceName: "svc"},
+		Spans:   []*jaeger.Span{{OperationName: operation}},
+	}
+}
+
+func withSpool(t *testing.T, test func(dir string)) {
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	test(dir)
+}
+
+func newSpoolingReporter(t *testing.T, wrapped Reporter, dir string, maxSizeBytes int64, mFactory metrics.Factory) *SpoolingReporter {
+	options := SpoolOptions{Directory: dir, MaxSizeBytes: maxSizeBytes, RetryInterval: time.Hour}
+	r, err := NewSpoolingReporter(wrapped, options, mFactory, zap.NewNop())
+	require.NoError(t, err)
+	return r
+}
+
+func operations(spans []*jaeger.Span) []string {
+	var ops []string
+	for _, span := range spans {
+		ops = append(ops, span.OperationName)
+	}
+	return ops
+}
+
+func TestSpoolingReporterReplaysInOrder(t *testing.T) {
+	withSpool(t, func(dir string) {
+		wrapped := &unreliableReporter{InMemoryReporter: testutils.NewInMemoryReporter()}
+		mFactory := metricstest.NewFactory(0)
+		r := newSpoolingReporter(t, wrapped, dir, 1024*1024, mFactory)
+		defer r.Close()
+
+		require.NoError(t, r.EmitBatch(newBatch("a")))
+		wrapped.setDown(true)
+		require.NoError(t, r.EmitBatch(newBatch("b")))
+		require.NoError(t, r.EmitZipkinBatch([]*zipkincore.Span{{Name: "z"}}))
+		wrapped.setDown(false)
+		// The spool is not empty, so the batch is spooled behind the older ones
+		require.NoError(t, r.EmitBatch(newBatch("c")))
+		assert.Equal(t, []string{"a"}, operations(wrapped.Spans()))
+		files, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 3)
+
+		r.replay()
+		assert.Equal(t, []string{"a", "b", "c"}, operations(wrapped.Spans()))
+		require.Len(t, wrapped.ZipkinSpans(), 1)
+		assert.Equal(t, "z", wrapped.ZipkinSpans()[0].Name)
+		files, err = ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+
+		mFactory.AssertCounterMetrics(t,
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches.spooled", Value: 3},
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches.replayed", Value: 3},
+		)
+		mFactory.AssertGaugeMetrics(t,
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches", Value: 0},
+			metricstest.ExpectedMetric{Name: "reporter.spool.size_bytes", Value: 0},
+		)
+	})
+}
+
+func TestSpoolingReporterSpoolBatch(t *testing.T) {
+	withSpool(t, func(dir string) {
+		wrapped := &unreliableReporter{InMemoryReporter: testutils.NewInMemoryReporter()}
+		mFactory := metricstest.NewFactory(0)
+		r := newSpoolingReporter(t, wrapped, dir, 1024*1024, mFactory)
+		defer r.Close()
+
+		// the batch was accepted by the wrapped reporter but failed to send later
+		require.NoError(t, r.SpoolBatch(newBatch("a")))
+		assert.Empty(t, wrapped.Spans())
+		assert.EqualError(t, r.SpoolBatch(&jaeger.Batch{}), "cannot spool a batch without process")
+
+		r.replay()
+		assert.Equal(t, []string{"a"}, operations(wrapped.Spans()))
+		mFactory.AssertCounterMetrics(t,
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches.spooled", Value: 1},
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches.dropped", Value: 1},
+		)
+	})
+}
+
+func TestSpoolingReporterReplayWith(t *testing.T) {
+	withSpool(t, func(dir string) {
+		// the wrapped reporter accepts batches to send them later, the replayer sends them right away
+		wrapped := testutils.NewInMemoryReporter()
+		replayer := &unreliableReporter{InMemoryReporter: testutils.NewInMemoryReporter(), down: true}
+		mFactory := metricstest.NewFactory(0)
+		r := newSpoolingReporter(t, wrapped, dir, 1024*1024, mFactory)
+		defer r.Close()
+		r.ReplayWith(replayer)
+
+		require.NoError(t, r.SpoolBatch(newBatch("a")))
+		r.replay()
+		assert.Empty(t, wrapped.Spans())
+		assert.Len(t, r.batches, 1)
+
+		replayer.setDown(false)
+		r.replay()
+		assert.Empty(t, wrapped.Spans())
+		assert.Equal(t, []string{"a"}, operations(replayer.Spans()))
+		assert.Empty(t, r.batches)
+		mFactory.AssertCounterMetrics(t,
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches.spooled", Value: 1},
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches.replayed", Value: 1},
+		)
+	})
+}
+
+func TestSpoolingReporterStopsReplayOnFailure(t *testing.T) {
+	withSpool(t, func(dir string) {
+		wrapped := &unreliableReporter{InMemoryReporter: testutils.NewInMemoryReporter(), down: true}
+		r := newSpoolingReporter(t, wrapped, dir, 1024*1024, metrics.NullFactory)
+		defer r.Close()
+
+		require.NoError(t, r.EmitBatch(newBatch("a")))
+		r.replay()
+		assert.Empty(t, wrapped.Spans())
+		assert.Len(t, r.batches, 1)
+	})
+}
+
+func TestSpoolingReporterEviction(t *testing.T) {
+	withSpool(t, func(dir string) {
+		wrapped := &unreliableReporter{InMemoryReporter: testutils.NewInMemoryReporter(), down: true}
+		mFactory := metricstest.NewFactory(0)
+		r := newSpoolingReporter(t, wrapped, dir, 1024*1024, mFactory)
+		require.NoError(t, r.EmitBatch(newBatch("a")))
+		batchSize := r.size
+		r.Close()
+
+		// Room for two batches
+		r = newSpoolingReporter(t, wrapped, dir, 2*batchSize, mFactory)
+		defer r.Close()
+		require.NoError(t, r.EmitBatch(newBatch("b")))
+		require.NoError(t, r.EmitBatch(newBatch("c")))
+		assert.Len(t, r.batches, 2)
+
+		big := newBatch("d")
+		big.Spans[0].OperationName = string(make([]byte, 2*batchSize))
+		err := r.EmitBatch(big)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds the maximum spool size")
+		assert.EqualError(t, r.EmitBatch(&jaeger.Batch{}), "cannot spool a batch without process")
+
+		wrapped.setDown(false)
+		r.replay()
+		assert.Equal(t, []string{"b", "c"}, operations(wrapped.Spans()))
+		mFactory.AssertCounterMetrics(t,
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches.evicted", Value: 1},
+			metricstest.ExpectedMetric{Name: "reporter.spool.batches.dropped", Value: 2},
+		)
+	})
+}
+
+func TestSpoolingReporterLoad(t *testing.T) {
+	withSpool(t, func(dir string) {
+		wrapped := &unreliableReporter{InMemoryReporter: testutils.NewInMemoryReporter(), down: true}
+		r := newSpoolingReporter(t, wrapped, dir, 1024*1024, metrics.NullFactory)
+		require.NoError(t, r.EmitBatch(newBatch("a")))
+		require.NoError(t, r.Close())
+
+		// Leftovers of an interrupted write, unknown and corrupted files
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "0000000000000005.jaeger.tmp"), []byte("x"), 0600))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "foo"), []byte("x"), 0600))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "0000000000000001.jaeger"), []byte("x"), 0600))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "0000000000000002.bar"), []byte("x"), 0600))
+
+		r = newSpoolingReporter(t, wrapped, dir, 1024*1024, metrics.NullFactory)
+		defer r.Close()
+		assert.Len(t, r.batches, 3)
+		assert.EqualValues(t, 3, r.nextSeq)
+		_, err := os.Stat(filepath.Join(dir, "0000000000000005.jaeger.tmp"))
+		assert.True(t, os.IsNotExist(err))
+
+		require.NoError(t, r.EmitBatch(newBatch("b")))
+		wrapped.setDown(false)
+		r.replay()
+		assert.Equal(t, []string{"a", "b"}, operations(wrapped.Spans()))
+		assert.Empty(t, r.batches)
+	})
+}
+
+func TestSpoolingReporterReplayLoop(t *testing.T) {
+	withSpool(t, func(dir string) {
+		wrapped := &unreliableReporter{InMemoryReporter: testutils.NewInMemoryReporter(), down: true}
+		options := SpoolOptions{Directory: dir, MaxSizeBytes: 1024 * 1024, RetryInterval: time.Millisecond}
+		r, err := NewSpoolingReporter(wrapped, options, metrics.NullFactory, zap.NewNop())
+		require.NoError(t, err)
+		defer r.Close()
+
+		require.NoError(t, r.EmitBatch(newBatch("a")))
+		wrapped.setDown(false)
+		for i := 0; i < 100 && len(wrapped.Spans()) == 0; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, []string{"a"}, operations(wrapped.Spans()))
+	})
+}
+
+func TestSpoolingReporterDirectoryError(t *testing.T) {
+	options := SpoolOptions{Directory: "/dev/null/spool", MaxSizeBytes: 1024, RetryInterval: time.Second}
+	_, err := NewSpoolingReporter(nil, options, metrics.NullFactory, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestSpoolingReporterInvalidOptions(t *testing.T) {
+	withSpool(t, func(dir string) {
+		_, err := NewSpoolingReporter(nil, SpoolOptions{Directory: dir, RetryInterval: time.Second}, metrics.NullFactory, zap.NewNop())
+		assert.EqualError(t, err, "spool maximum size must be positive, got 0")
+		_, err = NewSpoolingReporter(nil, SpoolOptions{Directory: dir, MaxSizeBytes: 1024, RetryInterval: -time.Second}, metrics.NullFactory, zap.NewNop())
+		assert.EqualError(t, err, "spool retry interval must be positive, got -1s")
+	})
+}
@@ -45,8 +45,10 @@ import (
 	collectorApp "github.com/jaegertracing/jaeger/cmd/collector/app"
 	collector "github.com/jaegertracing/jaeger/cmd/collector/app/builder"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/grpcserver"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/httpserver"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/tailsampling"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/zipkin"
 	"github.com/jaegertracing/jaeger/cmd/docs"
 	"github.com/jaegertracing/jaeger/cmd/env"
@@ -54,11 +56,13 @@ import (
 	queryApp "github.com/jaegertracing/jaeger/cmd/query/app"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
 	"github.com/jaegertracing/jaeger/pkg/version"
 	ss "github.com/jaegertracing/jaeger/plugin/sampling/strategystore"
 	"github.com/jaegertracing/jaeger/plugin/storage"
+	"github.com/jaegertracing/jaeger/plugin/storage/badger"
 	"github.com/jaegertracing/jaeger/ports"
 	istorage "github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
@@ -104,6 +108,9 @@ by default uses only in-memory database.`,
 			if err := storageFactory.Initialize(metricsFactory, logger); err != nil {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
+			for route, handler := range storageFactory.AdminHandlers() {
+				svc.Admin.Handle(route, handler)
+			}
 			spanReader, err := storageFactory.CreateSpanReader()
 			if err != nil {
 				logger.Fatal("Failed to create span reader", zap.Error(err))
@@ -112,13 +119,24 @@ by default uses only in-memory database.`,
 			if err != nil {
 				logger.Fatal("Failed to create span writer", zap.Error(err))
 			}
+			if tsOpts := new(tailsampling.Options).InitFromViper(v); tsOpts.Enabled() {
+				collectorMetricsFactory := metricsFactory.Namespace(metrics.NSOptions{Name: "collector"})
+				if spanWriter, err = tailsampling.NewWriter(spanWriter, *tsOpts, collectorMetricsFactory, logger); err != nil {
+					logger.Fatal("Failed to create tail sampling writer", zap.Error(err))
+				}
+			}
 			dependencyReader, err := storageFactory.CreateDependencyReader()
 			if err != nil {
 				logger.Fatal("Failed to create dependency reader", zap.Error(err))
 			}
 
 			strategyStoreFactory.InitFromViper(v)
-			strategyStore := initSamplingStrategyStore(strategyStoreFactory, metricsFactory, logger)
+			ssFactory, err := storageFactory.CreateSamplingStoreFactory()
+			if err != nil {
+				logger.Fatal("Failed to create sampling store factory", zap.Error(err))
+			}
+			strategyStore := initSamplingStrategyStore(strategyStoreFactory, metricsFactory, ssFactory, logger)
+			aggregator := initSamplingAggregator(strategyStoreFactory, logger)
 
 			aOpts := new(agentApp.Builder).InitFromViper(v)
 			repOpts := new(agentRep.Options).InitFromViper(v)
@@ -128,7 +146,7 @@ by default uses only in-memory database.`,
 			qOpts := new(queryApp.QueryOptions).InitFromViper(v)
 
 			startAgent(aOpts, repOpts, tchanBuilder, grpcBuilder, cOpts, logger, metricsFactory)
-			collectorSrv := startCollector(cOpts, spanWriter, logger, metricsFactory, strategyStore, svc.HC())
+			collectorSrv, spanBuilder := startCollector(cOpts, spanWriter, logger, metricsFactory, strategyStore, aggregator, svc.HC())
 			querySrv := startQuery(
 				svc, qOpts, archiveOptions(storageFactory, logger),
 				spanReader, dependencyReader,
@@ -137,6 +155,9 @@ by default uses only in-memory database.`,
 
 			svc.RunAndThen(func() {
 				collectorSrv.GracefulStop()
+				if err := spanBuilder.Close(); err != nil {
+					logger.Error("Failed to close span forwarder", zap.Error(err))
+				}
 				querySrv.Close()
 				if closer, ok := spanWriter.(io.Closer); ok {
 					err := closer.Close()
@@ -144,6 +165,24 @@ by default uses only in-memory database.`,
 						logger.Error("Failed to close span writer", zap.Error(err))
 					}
 				}
+				if aggregator != nil {
+					if err := aggregator.Close(); err != nil {
+						logger.Error("Failed to close sampling throughput aggregator", zap.Error(err))
+					}
+				}
+				if closer, ok := strategyStore.(io.Closer); ok {
+					if err := closer.Close(); err != nil {
+						logger.Error("Failed to close sampling strategy store", zap.Error(err))
+					}
+				}
+				for _, tlsOpts := range []*tlscfg.Options{&cOpts.CollectorHTTPTLS, &cOpts.CollectorZipkinTLS} {
+					if err := tlsOpts.Close(); err != nil {
+						logger.Error("Failed to stop watching TLS certificates", zap.Error(err))
+					}
+				}
+				if err := storageFactory.Close(); err != nil {
+					logger.Error("Failed to close storage factory", zap.Error(err))
+				}
 				tracerCloser.Close()
 			})
 			return nil
@@ -153,12 +192,14 @@ by default uses only in-memory database.`,
 	command.AddCommand(version.Command())
 	command.AddCommand(env.Command())
 	command.AddCommand(docs.Command(v))
+	command.AddCommand(badger.NewAdminCommand())
 
 	config.AddFlags(
 		v,
 		command,
 		svc.AddFlags,
 		storageFactory.AddFlags,
+		storageFactory.AddJobFlags,
 		agentApp.AddFlags,
 		agentRep.AddFlags,
 		agentTchanRep.AddFlags,
@@ -166,6 +207,7 @@ by default uses only in-memory database.`,
 		collector.AddFlags,
 		queryApp.AddFlags,
 		strategyStoreFactory.AddFlags,
+		tailsampling.AddFlags,
 	)
 
 	if err := command.Execute(); err != nil {
@@ -208,8 +250,9 @@ func startCollector(
 	logger *zap.Logger,
 	baseFactory metrics.Factory,
 	strategyStore strategystore.StrategyStore,
+	aggregator strategystore.Aggregator,
 	hc *healthcheck.HealthCheck,
-) *grpc.Server {
+) (*grpc.Server, *collector.SpanHandlerBuilder) {
 	metricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "collector", Tags: nil})
 
 	spanBuilder, err := collector.NewSpanHandlerBuilder(
@@ -222,7 +265,14 @@ func startCollector(
 		logger.Fatal("Unable to set up builder", zap.Error(err))
 	}
 
-	zipkinSpansHandler, jaegerBatchesHandler, grpcHandler := spanBuilder.BuildHandlers()
+	var preSave []collectorApp.ProcessSpan
+	if aggregator != nil {
+		preSave = append(preSave, collectorApp.HandleRootSpan(aggregator))
+	}
+	zipkinSpansHandler, jaegerBatchesHandler, grpcHandler, err := spanBuilder.BuildHandlers(preSave...)
+	if err != nil {
+		logger.Fatal("Unable to build span handlers", zap.Error(err))
+	}
 
 	{
 		ch, err := tchannel.NewChannel("jaeger-collector", &tchannel.ChannelOptions{})
@@ -252,20 +302,22 @@ func startCollector(
 		r := mux.NewRouter()
 		apiHandler := collectorApp.NewAPIHandler(jaegerBatchesHandler)
 		apiHandler.RegisterRoutes(r)
-		httpPortStr := ":" + strconv.Itoa(cOpts.CollectorHTTPPort)
 		recoveryHandler := recoveryhandler.NewRecoveryHandler(logger, true)
 
-		go startZipkinHTTPAPI(logger, cOpts.CollectorZipkinHTTPPort, zipkinSpansHandler, recoveryHandler)
+		if err := startZipkinHTTPAPI(logger, cOpts, zipkinSpansHandler, recoveryHandler); err != nil {
+			logger.Fatal("Could not launch Zipkin service", zap.Error(err))
+		}
 
 		logger.Info("Starting jaeger-collector HTTP server", zap.Int("http-port", cOpts.CollectorHTTPPort))
-		go func() {
-			if err := http.ListenAndServe(httpPortStr, recoveryHandler(r)); err != nil {
-				logger.Fatal("Could not launch jaeger-collector HTTP server", zap.Error(err))
-			}
+		_, _, err := httpserver.StartHTTPServer(cOpts.CollectorHTTPPort, recoveryHandler(r), &cOpts.CollectorHTTPTLS, logger, func(err error) {
 			hc.Set(healthcheck.Unavailable)
-		}()
+			logger.Fatal("Could not launch jaeger-collector HTTP server", zap.Error(err))
+		})
+		if err != nil {
+			logger.Fatal("Could not launch jaeger-collector HTTP server", zap.Error(err))
+		}
 	}
-	return server
+	return server, spanBuilder
 }
 
 func startGRPCServer(
@@ -286,21 +338,22 @@ func startGRPCServer(
 
 func startZipkinHTTPAPI(
 	logger *zap.Logger,
-	zipkinPort int,
+	cOpts *collector.CollectorOptions,
 	zipkinSpansHandler collectorApp.ZipkinSpansHandler,
 	recoveryHandler func(http.Handler) http.Handler,
-) {
-	if zipkinPort != 0 {
-		r := mux.NewRouter()
-		zHandler := zipkin.NewAPIHandler(zipkinSpansHandler)
-		zHandler.RegisterRoutes(r)
-		httpPortStr := ":" + strconv.Itoa(zipkinPort)
-		logger.Info("Listening for Zipkin HTTP traffic", zap.Int("zipkin.http-port", zipkinPort))
-
-		if err := http.ListenAndServe(httpPortStr, recoveryHandler(r)); err != nil {
-			logger.Fatal("Could not launch service", zap.Error(err))
-		}
+) error {
+	if cOpts.CollectorZipkinHTTPPort == 0 {
+		return nil
 	}
+	r := mux.NewRouter()
+	zHandler := zipkin.NewAPIHandler(zipkinSpansHandler)
+	zHandler.RegisterRoutes(r)
+	logger.Info("Listening for Zipkin HTTP traffic", zap.Int("zipkin.http-port", cOpts.CollectorZipkinHTTPPort))
+
+	_, _, err := httpserver.StartHTTPServer(cOpts.CollectorZipkinHTTPPort, recoveryHandler(r), &cOpts.CollectorZipkinTLS, logger, func(err error) {
+		logger.Fatal("Could not launch service", zap.Error(err))
+	})
+	return err
 }
 
 func startQuery(
@@ -313,8 +366,16 @@ func startQuery(
 	baseFactory metrics.Factory,
 ) *queryApp.Server {
 	spanReader = storageMetrics.NewReadMetricsDecorator(spanReader, baseFactory.Namespace(metrics.NSOptions{Name: "query"}))
+	authorizer, err := qOpts.Auth.NewAuthorizer()
+	if err != nil {
+		svc.Logger.Fatal("Failed to create authorizer", zap.Error(err))
+	}
+	queryOpts.Authorizer = authorizer
 	qs := querysvc.NewQueryService(spanReader, depReader, *queryOpts)
-	server := queryApp.NewServer(svc, qs, qOpts, opentracing.GlobalTracer())
+	server, err := queryApp.NewServer(svc, qs, qOpts, opentracing.GlobalTracer())
+	if err != nil {
+		svc.Logger.Fatal("Could not create jaeger-query service", zap.Error(err))
+	}
 	if err := server.Start(); err != nil {
 		svc.Logger.Fatal("Could not start jaeger-query service", zap.Error(err))
 	}
@@ -324,9 +385,10 @@ func startQuery(
 func initSamplingStrategyStore(
 	samplingStrategyStoreFactory *ss.Factory,
 	metricsFactory metrics.Factory,
+	ssFactory istorage.SamplingStoreFactory,
 	logger *zap.Logger,
 ) strategystore.StrategyStore {
-	if err := samplingStrategyStoreFactory.Initialize(metricsFactory, logger); err != nil {
+	if err := samplingStrategyStoreFactory.Initialize(metricsFactory, ssFactory, logger); err != nil {
 		logger.Fatal("Failed to init sampling strategy store factory", zap.Error(err))
 	}
 	strategyStore, err := samplingStrategyStoreFactory.CreateStrategyStore()
@@ -336,6 +398,17 @@ func initSamplingStrategyStore(
 	return strategyStore
 }
 
+func initSamplingAggregator(
+	samplingStrategyStoreFactory *ss.Factory,
+	logger *zap.Logger,
+) strategystore.Aggregator {
+	aggregator, err := samplingStrategyStoreFactory.CreateAggregator()
+	if err != nil {
+		logger.Fatal("Failed to create sampling throughput aggregator", zap.Error(err))
+	}
+	return aggregator
+}
+
 func archiveOptions(storageFactory istorage.Factory, logger *zap.Logger) *querysvc.QueryServiceOptions {
 	opts := &querysvc.QueryServiceOptions{}
 	if !opts.InitArchiveStorage(storageFactory, logger) {
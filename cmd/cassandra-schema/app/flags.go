@@ -0,0 +1,108 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"flag"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/plugin/storage/cassandra/schema"
+)
+
+const (
+	modeProd = "prod"
+	modeTest = "test"
+
+	mode              = "schema.mode"
+	datacenter        = "schema.datacenter"
+	replicationFactor = "schema.replication-factor"
+	traceTTL          = "schema.trace-ttl"
+	dependenciesTTL   = "schema.dependencies-ttl"
+	compactionWindow  = "schema.compaction-window"
+	dryRun            = "schema.dry-run"
+	dropLegacyTables  = "schema.drop-legacy-tables"
+
+	defaultTraceTTL         = 48 * time.Hour
+	defaultCompactionWindow = time.Hour
+)
+
+// Options holds the configuration of the schema of the keyspace.
+type Options struct {
+	Mode              string
+	Datacenter        string
+	ReplicationFactor int
+	TraceTTL          time.Duration
+	DependenciesTTL   time.Duration
+	CompactionWindow  time.Duration
+	DryRun            bool
+	DropLegacyTables  bool
+}
+
+// AddFlags adds flags for Options
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(mode, modeTest, "The mode of the keyspace, prod (NetworkTopologyStrategy in the datacenter) or test (SimpleStrategy, usable on a single node cluster)")
+	flagSet.String(datacenter, "", "The datacenter of the NetworkTopologyStrategy, required in prod mode")
+	flagSet.Int(replicationFactor, 0, "The replication factor of the keyspace (default 2 in prod mode, 1 in test mode)")
+	flagSet.Duration(traceTTL, defaultTraceTTL, "The time to live of the spans")
+	flagSet.Duration(dependenciesTTL, 0, "The time to live of the dependencies, 0 for no TTL")
+	flagSet.Duration(compactionWindow, defaultCompactionWindow, "The compaction window of the time series tables, a whole number of minutes")
+	flagSet.Bool(dryRun, false, "Print the CQL statements to stdout instead of executing them; Cassandra is still read to detect the current schema version")
+	flagSet.Bool(dropLegacyTables, false, "Drop the tables of the previous schema versions once their data has been copied")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.Mode = v.GetString(mode)
+	o.Datacenter = v.GetString(datacenter)
+	o.ReplicationFactor = v.GetInt(replicationFactor)
+	o.TraceTTL = v.GetDuration(traceTTL)
+	o.DependenciesTTL = v.GetDuration(dependenciesTTL)
+	o.CompactionWindow = v.GetDuration(compactionWindow)
+	o.DryRun = v.GetBool(dryRun)
+	o.DropLegacyTables = v.GetBool(dropLegacyTables)
+	return o
+}
+
+// Parameters returns the template parameters for the given keyspace.
+func (o *Options) Parameters(keyspace string) (schema.Parameters, error) {
+	params := schema.Parameters{
+		Keyspace:         keyspace,
+		TraceTTL:         o.TraceTTL,
+		DependenciesTTL:  o.DependenciesTTL,
+		CompactionWindow: o.CompactionWindow,
+	}
+	switch o.Mode {
+	case modeProd:
+		if o.Datacenter == "" {
+			return params, errors.Errorf("%s is required in %s mode", datacenter, modeProd)
+		}
+		params.Replication = schema.NetworkTopologyReplication(o.Datacenter, o.replicationFactor(2))
+	case modeTest:
+		params.Replication = schema.SimpleReplication(o.replicationFactor(1))
+	default:
+		return params, errors.Errorf("invalid %s '%s', expecting '%s' or '%s'", mode, o.Mode, modeProd, modeTest)
+	}
+	return params, params.Validate()
+}
+
+func (o *Options) replicationFactor(defaultFactor int) int {
+	if o.ReplicationFactor > 0 {
+		return o.ReplicationFactor
+	}
+	return defaultFactor
+}
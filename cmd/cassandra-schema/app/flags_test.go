@@ -0,0 +1,102 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/plugin/storage/cassandra/schema"
+)
+
+func TestFlagsDefaults(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		Mode:             "test",
+		TraceTTL:         48 * time.Hour,
+		CompactionWindow: time.Hour,
+	}, opts)
+
+	params, err := opts.Parameters("jaeger_v1_test")
+	require.NoError(t, err)
+	assert.Equal(t, schema.Parameters{
+		Keyspace:         "jaeger_v1_test",
+		Replication:      "{'class': 'SimpleStrategy', 'replication_factor': '1'}",
+		TraceTTL:         48 * time.Hour,
+		CompactionWindow: time.Hour,
+	}, params)
+}
+
+func TestFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--schema.mode=prod",
+		"--schema.datacenter=dc1",
+		"--schema.replication-factor=3",
+		"--schema.trace-ttl=168h",
+		"--schema.dependencies-ttl=720h",
+		"--schema.compaction-window=4h",
+		"--schema.dry-run=true",
+		"--schema.drop-legacy-tables=true",
+	})
+	opts := new(Options).InitFromViper(v)
+	assert.True(t, opts.DryRun)
+	assert.True(t, opts.DropLegacyTables)
+
+	params, err := opts.Parameters("jaeger_v1_dc1")
+	require.NoError(t, err)
+	assert.Equal(t, schema.Parameters{
+		Keyspace:         "jaeger_v1_dc1",
+		Replication:      "{'class': 'NetworkTopologyStrategy', 'dc1': '3'}",
+		TraceTTL:         168 * time.Hour,
+		DependenciesTTL:  720 * time.Hour,
+		CompactionWindow: 4 * time.Hour,
+	}, params)
+}
+
+func TestParametersErrors(t *testing.T) {
+	testCases := []struct {
+		opts          Options
+		expectedError string
+	}{
+		{
+			opts:          Options{Mode: "prod", TraceTTL: time.Hour, CompactionWindow: time.Hour},
+			expectedError: "schema.datacenter is required in prod mode",
+		},
+		{
+			opts:          Options{Mode: "staging", TraceTTL: time.Hour, CompactionWindow: time.Hour},
+			expectedError: "invalid schema.mode 'staging', expecting 'prod' or 'test'",
+		},
+		{
+			opts:          Options{Mode: "test", CompactionWindow: time.Hour},
+			expectedError: "trace TTL must be at least one second, got 0s",
+		},
+	}
+	for _, testCase := range testCases {
+		_, err := testCase.opts.Parameters("jaeger_v1_test")
+		assert.EqualError(t, err, testCase.expectedError)
+	}
+
+	prod := Options{Mode: "prod", Datacenter: "dc1", TraceTTL: time.Hour, CompactionWindow: time.Hour}
+	params, err := prod.Parameters("jaeger_v1_dc1")
+	require.NoError(t, err)
+	assert.Equal(t, "{'class': 'NetworkTopologyStrategy', 'dc1': '2'}", params.Replication)
+}
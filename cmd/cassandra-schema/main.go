@@ -0,0 +1,84 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/cassandra-schema/app"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/version"
+	"github.com/jaegertracing/jaeger/plugin/storage/cassandra"
+	"github.com/jaegertracing/jaeger/plugin/storage/cassandra/schema"
+)
+
+func main() {
+	v := viper.New()
+	cassandraOptions := cassandra.NewOptions("cassandra")
+	var command = &cobra.Command{
+		Use:   "jaeger-cassandra-schema",
+		Short: "Jaeger cassandra-schema creates or migrates the Jaeger schema in a Cassandra keyspace.",
+		Long: `Jaeger cassandra-schema detects the version of the Jaeger schema in the keyspace and
+creates the missing tables or migrates them to the latest version. It can be run repeatedly.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := zap.NewProduction()
+			if err != nil {
+				return err
+			}
+			cassandraOptions.InitFromViper(v)
+			opts := new(app.Options).InitFromViper(v)
+
+			// the keyspace may not exist yet, so the session must not be bound to it
+			cfg := *cassandraOptions.GetPrimary()
+			params, err := opts.Parameters(cfg.Keyspace)
+			if err != nil {
+				return err
+			}
+			cfg.Keyspace = ""
+			// a dry run connects as well: the statements to print depend on the tables found in the keyspace
+			session, err := cfg.NewSession()
+			if err != nil {
+				return errors.Wrap(err, "failed to connect to Cassandra")
+			}
+			defer session.Close()
+
+			migratorOpts := []schema.Option{schema.DropLegacyTables(opts.DropLegacyTables)}
+			if opts.DryRun {
+				migratorOpts = append(migratorOpts, schema.DryRun(os.Stdout))
+			}
+			return schema.NewMigrator(session, params, logger, migratorOpts...).Migrate()
+		},
+	}
+
+	command.AddCommand(version.Command())
+
+	config.AddFlags(
+		v,
+		command,
+		cassandraOptions.AddFlags,
+		app.AddFlags,
+	)
+
+	if err := command.Execute(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
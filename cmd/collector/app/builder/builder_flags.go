@@ -17,10 +17,12 @@ package builder
 
 import (
 	"flag"
+	"strings"
 
 	"github.com/spf13/viper"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/ports"
 )
 
@@ -37,6 +39,17 @@ const (
 	collectorZipkinHTTPort        = "collector.zipkin.http-port"
 	collectorZipkinAllowedOrigins = "collector.zipkin.allowed-origins"
 	collectorZipkinAllowedHeaders = "collector.zipkin.allowed-headers"
+	collectorForwardingPeers      = "collector.forwarding.peers"
+	collectorForwardingHostPort   = "collector.forwarding.host-port"
+)
+
+var (
+	httpTLSFlagsConfig = tlscfg.ServerFlagsConfig{
+		Prefix: "collector.http",
+	}
+	zipkinTLSFlagsConfig = tlscfg.ServerFlagsConfig{
+		Prefix: "collector.zipkin",
+	}
 )
 
 // CollectorOptions holds configuration for collector
@@ -59,12 +72,20 @@ type CollectorOptions struct {
 	CollectorGRPCClientCA string
 	// CollectorGRPCKey is the path to a TLS key file for the server
 	CollectorGRPCKey string
+	// CollectorHTTPTLS configures TLS for the HTTP port
+	CollectorHTTPTLS tlscfg.Options
 	// CollectorZipkinHTTPPort is the port that the Zipkin collector service listens in on for http requests
 	CollectorZipkinHTTPPort int
 	// CollectorZipkinAllowedOrigins is a list of origins a cross-domain request to the Zipkin collector service can be executed from
 	CollectorZipkinAllowedOrigins string
 	// CollectorZipkinAllowedHeaders is a list of headers that the Zipkin collector service allowes the client to use with cross-domain requests
 	CollectorZipkinAllowedHeaders string
+	// CollectorZipkinTLS configures TLS for the Zipkin HTTP port
+	CollectorZipkinTLS tlscfg.Options
+	// ForwardingPeers is the list of gRPC host:port of all collectors sharding spans by trace ID
+	ForwardingPeers []string
+	// ForwardingHostPort is the gRPC host:port of this collector in ForwardingPeers
+	ForwardingHostPort string
 }
 
 // AddFlags adds flags for CollectorOptions
@@ -81,6 +102,10 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.String(collectorGRPCClientCA, "", "Path to a TLS CA to verify certificates presented by clients (if unset, all clients are permitted)")
 	flags.String(collectorZipkinAllowedOrigins, "*", "Comma separated list of allowed origins for the Zipkin collector service, default accepts all")
 	flags.String(collectorZipkinAllowedHeaders, "content-type", "Comma separated list of allowed headers for the Zipkin collector service, default content-type")
+	flags.String(collectorForwardingPeers, "", "Comma separated list of gRPC host:port of all collectors, unless discovered by other means. When set, spans are sharded by trace ID and spans of traces owned by another collector are forwarded to it, e.g. for tail sampling")
+	flags.String(collectorForwardingHostPort, "", "The gRPC host:port of this collector, as it appears in the forwarding peers, required to forward spans")
+	httpTLSFlagsConfig.AddFlags(flags)
+	zipkinTLSFlagsConfig.AddFlags(flags)
 }
 
 // InitFromViper initializes CollectorOptions with properties from viper
@@ -97,5 +122,21 @@ func (cOpts *CollectorOptions) InitFromViper(v *viper.Viper) *CollectorOptions {
 	cOpts.CollectorZipkinHTTPPort = v.GetInt(collectorZipkinHTTPort)
 	cOpts.CollectorZipkinAllowedOrigins = v.GetString(collectorZipkinAllowedOrigins)
 	cOpts.CollectorZipkinAllowedHeaders = v.GetString(collectorZipkinAllowedHeaders)
+	cOpts.CollectorHTTPTLS = httpTLSFlagsConfig.InitFromViper(v)
+	cOpts.CollectorZipkinTLS = zipkinTLSFlagsConfig.InitFromViper(v)
+	if peers := v.GetString(collectorForwardingPeers); peers != "" {
+		cOpts.ForwardingPeers = strings.Split(peers, ",")
+	}
+	cOpts.ForwardingHostPort = v.GetString(collectorForwardingHostPort)
 	return cOpts
 }
+
+// GRPCTLS returns the TLS options of the gRPC port
+func (cOpts *CollectorOptions) GRPCTLS() tlscfg.Options {
+	return tlscfg.Options{
+		Enabled:      cOpts.CollectorGRPCTLS,
+		CertPath:     cOpts.CollectorGRPCCert,
+		KeyPath:      cOpts.CollectorGRPCKey,
+		ClientCAPath: cOpts.CollectorGRPCClientCA,
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+func TestCollectorTLSFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.grpc.tls=true",
+		"--collector.grpc.tls.cert=grpc-cert.pem",
+		"--collector.grpc.tls.key=grpc-key.pem",
+		"--collector.grpc.tls.client.ca=grpc-ca.pem",
+		"--collector.http.tls.enabled=true",
+		"--collector.http.tls.cert=http-cert.pem",
+		"--collector.http.tls.key=http-key.pem",
+		"--collector.zipkin.tls.enabled=true",
+		"--collector.zipkin.tls.cert=zipkin-cert.pem",
+		"--collector.zipkin.tls.key=zipkin-key.pem",
+		"--collector.zipkin.tls.client-ca=zipkin-ca.pem",
+	})
+	cOpts := new(CollectorOptions).InitFromViper(v)
+
+	assert.Equal(t, tlscfg.Options{
+		Enabled:      true,
+		CertPath:     "grpc-cert.pem",
+		KeyPath:      "grpc-key.pem",
+		ClientCAPath: "grpc-ca.pem",
+	}, cOpts.GRPCTLS())
+	assert.Equal(t, tlscfg.Options{
+		Enabled:  true,
+		CertPath: "http-cert.pem",
+		KeyPath:  "http-key.pem",
+	}, cOpts.CollectorHTTPTLS)
+	assert.Equal(t, tlscfg.Options{
+		Enabled:      true,
+		CertPath:     "zipkin-cert.pem",
+		KeyPath:      "zipkin-key.pem",
+		ClientCAPath: "zipkin-ca.pem",
+	}, cOpts.CollectorZipkinTLS)
+}
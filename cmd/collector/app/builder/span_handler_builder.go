@@ -16,6 +16,7 @@
 package builder
 
 import (
+	"errors"
 	"os"
 
 	"github.com/uber/jaeger-lib/metrics"
@@ -23,8 +24,10 @@ import (
 
 	basicB "github.com/jaegertracing/jaeger/cmd/builder"
 	"github.com/jaegertracing/jaeger/cmd/collector/app"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/forwarding"
 	zs "github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer/zipkin"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/discovery"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -34,6 +37,10 @@ type SpanHandlerBuilder struct {
 	metricsFactory metrics.Factory
 	collectorOpts  *CollectorOptions
 	spanWriter     spanstore.Writer
+
+	discoverer discovery.Discoverer
+	notifier   discovery.Notifier
+	forwarder  *forwarding.Forwarder
 }
 
 // NewSpanHandlerBuilder returns new SpanHandlerBuilder with configured span storage.
@@ -50,16 +57,31 @@ func NewSpanHandlerBuilder(cOpts *CollectorOptions, spanWriter spanstore.Writer,
 	return spanHb, nil
 }
 
-// BuildHandlers builds span handlers (Zipkin, Jaeger)
-func (spanHb *SpanHandlerBuilder) BuildHandlers() (
+// WithDiscoverer sets the service discovery of the collectors that spans are forwarded to.
+// It replaces the static list of forwarding peers.
+func (spanHb *SpanHandlerBuilder) WithDiscoverer(d discovery.Discoverer) *SpanHandlerBuilder {
+	spanHb.discoverer = d
+	return spanHb
+}
+
+// WithDiscoveryNotifier sets the notifier of the changes of the collectors that spans are forwarded to.
+func (spanHb *SpanHandlerBuilder) WithDiscoveryNotifier(n discovery.Notifier) *SpanHandlerBuilder {
+	spanHb.notifier = n
+	return spanHb
+}
+
+// BuildHandlers builds span handlers (Zipkin, Jaeger). The optional preSave processors are
+// invoked for every sanitized span before it is written to storage.
+func (spanHb *SpanHandlerBuilder) BuildHandlers(preSave ...app.ProcessSpan) (
 	app.ZipkinSpansHandler,
 	app.JaegerBatchesHandler,
 	*app.GRPCHandler,
+	error,
 ) {
 	hostname, _ := os.Hostname()
 	hostMetrics := spanHb.metricsFactory.Namespace(metrics.NSOptions{Name: "", Tags: map[string]string{"host": hostname}})
 
-	spanProcessor := app.NewSpanProcessor(
+	var spanProcessor app.SpanProcessor = app.NewSpanProcessor(
 		spanHb.spanWriter,
 		app.Options.ServiceMetrics(spanHb.metricsFactory),
 		app.Options.HostMetrics(hostMetrics),
@@ -67,11 +89,60 @@ func (spanHb *SpanHandlerBuilder) BuildHandlers() (
 		app.Options.SpanFilter(defaultSpanFilter),
 		app.Options.NumWorkers(spanHb.collectorOpts.NumWorkers),
 		app.Options.QueueSize(spanHb.collectorOpts.QueueSize),
+		app.Options.PreSave(app.ChainedProcessSpan(preSave...)),
 	)
+	forwarder, err := spanHb.buildForwarder(spanProcessor)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if forwarder != nil {
+		spanHb.forwarder = forwarder
+		spanProcessor = forwarder
+	}
 
 	return app.NewZipkinSpanHandler(spanHb.logger, spanProcessor, zs.NewChainedSanitizer(zs.StandardSanitizers...)),
 		app.NewJaegerSpanHandler(spanHb.logger, spanProcessor),
-		app.NewGRPCHandler(spanHb.logger, spanProcessor)
+		app.NewGRPCHandler(spanHb.logger, spanProcessor),
+		nil
+}
+
+func (spanHb *SpanHandlerBuilder) buildForwarder(spanProcessor app.SpanProcessor) (*forwarding.Forwarder, error) {
+	// Use static collectors if specified.
+	if len(spanHb.collectorOpts.ForwardingPeers) > 0 {
+		spanHb.discoverer = discovery.FixedDiscoverer(spanHb.collectorOpts.ForwardingPeers)
+		spanHb.notifier = &discovery.Dispatcher{}
+	}
+	if spanHb.discoverer == nil && spanHb.notifier == nil {
+		return nil, nil
+	}
+	if spanHb.discoverer == nil || spanHb.notifier == nil {
+		return nil, errors.New("both discovery.Discoverer and discovery.Notifier must be specified")
+	}
+	if spanHb.collectorOpts.ForwardingHostPort == "" {
+		// without it this collector would own no trace and forward all spans
+		return nil, errors.New("the host:port of this collector must be set with " + collectorForwardingHostPort + " to forward spans")
+	}
+	dialOptions, err := forwarding.DialOptions(spanHb.collectorOpts.GRPCTLS())
+	if err != nil {
+		return nil, err
+	}
+	return forwarding.NewForwarder(
+		spanProcessor,
+		spanHb.collectorOpts.ForwardingHostPort,
+		spanHb.discoverer,
+		spanHb.notifier,
+		dialOptions,
+		spanHb.metricsFactory,
+		spanHb.logger,
+	)
+}
+
+// Close closes the connections to the collectors that spans are forwarded to, if any.
+func (spanHb *SpanHandlerBuilder) Close() error {
+	if spanHb.forwarder != nil {
+		return spanHb.forwarder.Close()
+	}
+	return nil
 }
 
 func defaultSpanFilter(*model.Span) bool {
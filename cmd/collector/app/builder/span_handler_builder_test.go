@@ -25,7 +25,9 @@ import (
 
 	"github.com/jaegertracing/jaeger/cmd/builder"
 	"github.com/jaegertracing/jaeger/cmd/flags"
+	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/discovery"
 	"github.com/jaegertracing/jaeger/plugin/storage/memory"
 )
 
@@ -45,12 +47,75 @@ func TestNewSpanHandlerBuilder(t *testing.T) {
 	)
 	require.NoError(t, err)
 	assert.NotNil(t, handler)
-	zipkin, jaeger, grpc := handler.BuildHandlers()
+	zipkin, jaeger, grpc, err := handler.BuildHandlers()
+	require.NoError(t, err)
 	assert.NotNil(t, zipkin)
 	assert.NotNil(t, jaeger)
 	assert.NotNil(t, grpc)
+
+	zipkin, jaeger, grpc, err = handler.BuildHandlers(func(*model.Span) {})
+	require.NoError(t, err)
+	assert.NotNil(t, zipkin)
+	assert.NotNil(t, jaeger)
+	assert.NotNil(t, grpc)
+	assert.NoError(t, handler.Close())
 }
 
 func TestDefaultSpanFilter(t *testing.T) {
 	assert.True(t, defaultSpanFilter(nil))
 }
+
+func TestSpanHandlerBuilderWithForwarding(t *testing.T) {
+	v, command := config.Viperize(flags.AddFlags, AddFlags)
+
+	command.ParseFlags([]string{
+		"--collector.forwarding.peers=collector-1:14250,collector-2:14250",
+		"--collector.forwarding.host-port=collector-1:14250",
+	})
+	cOpts := new(CollectorOptions).InitFromViper(v)
+	assert.Equal(t, []string{"collector-1:14250", "collector-2:14250"}, cOpts.ForwardingPeers)
+	assert.Equal(t, "collector-1:14250", cOpts.ForwardingHostPort)
+
+	handler, err := NewSpanHandlerBuilder(
+		cOpts,
+		memory.NewStore(),
+		builder.Options.LoggerOption(zap.NewNop()),
+		builder.Options.MetricsFactoryOption(metrics.NullFactory),
+	)
+	require.NoError(t, err)
+	zipkin, jaeger, grpc, err := handler.BuildHandlers()
+	require.NoError(t, err)
+	assert.NotNil(t, zipkin)
+	assert.NotNil(t, jaeger)
+	assert.NotNil(t, grpc)
+	assert.NoError(t, handler.Close())
+}
+
+func TestSpanHandlerBuilderWithDiscoveryErrors(t *testing.T) {
+	v, command := config.Viperize(flags.AddFlags, AddFlags)
+	command.ParseFlags([]string{"--collector.forwarding.host-port=collector-1:14250"})
+	cOpts := new(CollectorOptions).InitFromViper(v)
+
+	handler, err := NewSpanHandlerBuilder(
+		cOpts,
+		memory.NewStore(),
+		builder.Options.LoggerOption(zap.NewNop()),
+		builder.Options.MetricsFactoryOption(metrics.NullFactory),
+	)
+	require.NoError(t, err)
+	handler.WithDiscoverer(discovery.FixedDiscoverer{"collector-1:14250"})
+	_, _, _, err = handler.BuildHandlers()
+	assert.EqualError(t, err, "both discovery.Discoverer and discovery.Notifier must be specified")
+
+	handler.WithDiscoveryNotifier(&discovery.Dispatcher{})
+	cOpts.ForwardingHostPort = ""
+	_, _, _, err = handler.BuildHandlers()
+	assert.EqualError(t, err, "the host:port of this collector must be set with collector.forwarding.host-port to forward spans")
+
+	cOpts.ForwardingHostPort = "collector-1:14250"
+	cOpts.CollectorGRPCTLS = true
+	cOpts.CollectorGRPCCert = "invalid/path"
+	cOpts.CollectorGRPCKey = "invalid/path"
+	_, _, _, err = handler.BuildHandlers()
+	assert.Error(t, err)
+}
@@ -0,0 +1,410 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forwarding
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/discovery"
+	"github.com/jaegertracing/jaeger/pkg/discovery/hashring"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+const (
+	// forwardTimeout is the timeout for forwarding a batch of spans to another collector
+	forwardTimeout = 5 * time.Second
+	// forwardQueueSize is the number of batches waiting to be forwarded to a collector, the spans
+	// are processed locally when its queue is full
+	forwardQueueSize = 100
+	// forwardMaxRetries is the number of times forwarding a batch is retried before its spans are processed locally
+	forwardMaxRetries = 3
+	// forwardRetryDelay is the delay before the first retry, it doubles with every retry
+	forwardRetryDelay = 100 * time.Millisecond
+)
+
+// errPeerRemoved is returned when forwarding spans to a collector removed from the peers
+var errPeerRemoved = errors.New("collector was removed from the peers")
+
+// forwarderMetrics keeps track of the forwarded spans
+type forwarderMetrics struct {
+	SpansForwarded metrics.Counter `metric:"spans_forwarded"`
+	ForwardErrors  metrics.Counter `metric:"forward_errors"`
+	ForwardRetries metrics.Counter `metric:"forward_retries"`
+	QueueFull      metrics.Counter `metric:"forward_queue_full"`
+}
+
+// Forwarder is a SpanProcessor which shards spans by trace ID over a set of collectors.
+// Spans owned by this collector are processed locally, the others are forwarded over gRPC
+// to the collector owning them, so that every collector receives whole traces. Spans are
+// forwarded in the background through a queue per collector, so that a slow collector
+// does not hold up the ingestion of the others.
+type Forwarder struct {
+	processor  app.SpanProcessor
+	hostPort   string
+	logger     *zap.Logger
+	metrics    forwarderMetrics
+	dial       func(hostPort string) (*grpc.ClientConn, error)
+	retryDelay time.Duration
+
+	notifier discovery.Notifier
+	discoCh  chan []string  // used to receive notifications
+	exitWG   sync.WaitGroup // used to block Close() until the notifications go-routine exits
+
+	stop      chan struct{}  // used to stop retrying on Close()
+	forwardWG sync.WaitGroup // used to block Close() until the forwarding go-routines exit
+
+	mux    sync.RWMutex
+	ring   *hashring.Ring
+	peers  map[string]struct{}
+	conns  map[string]*grpc.ClientConn
+	queues map[string]chan *forwardedBatch
+	closed bool
+}
+
+// forwardedBatch holds spans waiting to be forwarded, and the options to process them locally if that fails
+type forwardedBatch struct {
+	spans   []*model.Span
+	options app.ProcessSpansOptions
+}
+
+// NewForwarder creates a Forwarder in front of processor. hostPort is the gRPC address of this
+// collector, as it appears in the list of peers; all collectors and agents must use the same addresses.
+// The peers are seeded by the discoverer and updated by the notifier; dialOptions are used to
+// connect to them, see DialOptions.
+func NewForwarder(
+	processor app.SpanProcessor,
+	hostPort string,
+	discoverer discovery.Discoverer,
+	notifier discovery.Notifier,
+	dialOptions []grpc.DialOption,
+	metricsFactory metrics.Factory,
+	logger *zap.Logger,
+) (*Forwarder, error) {
+	peers, err := discoverer.Instances()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get initial set of collectors")
+	}
+	f := &Forwarder{
+		processor: processor,
+		hostPort:  hostPort,
+		logger:    logger,
+		dial: func(hostPort string) (*grpc.ClientConn, error) {
+			return grpc.Dial(hostPort, dialOptions...)
+		},
+		retryDelay: forwardRetryDelay,
+		notifier:   notifier,
+		discoCh:    make(chan []string, 100),
+		stop:       make(chan struct{}),
+		conns:      make(map[string]*grpc.ClientConn),
+		queues:     make(map[string]chan *forwardedBatch),
+	}
+	metrics.Init(&f.metrics, metricsFactory.Namespace(metrics.NSOptions{Name: "forwarding"}), nil)
+	f.SetPeers(peers)
+
+	f.exitWG.Add(1)
+	go f.processDiscoveryNotifications()
+	notifier.Register(f.discoCh)
+	return f, nil
+}
+
+// DialOptions returns the options to connect to the other collectors. If the gRPC port of the collectors
+// uses TLS, so do the connections between them: this collector presents its own certificate, and verifies
+// the others with the client CA if set, otherwise with the system CAs.
+func DialOptions(tlsOpts tlscfg.Options) ([]grpc.DialOption, error) {
+	if !tlsOpts.Enabled {
+		return []grpc.DialOption{grpc.WithInsecure()}, nil
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Clean(tlsOpts.CertPath), filepath.Clean(tlsOpts.KeyPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load TLS cert and key for forwarding")
+	}
+	var certPool *x509.CertPool
+	if tlsOpts.ClientCAPath != "" {
+		caPEM, err := ioutil.ReadFile(filepath.Clean(tlsOpts.ClientCAPath))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load TLS CA for forwarding")
+		}
+		certPool = x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("failed to parse TLS CA %s", tlsOpts.ClientCAPath)
+		}
+	} else if certPool, err = x509.SystemCertPool(); err != nil {
+		return nil, errors.Wrap(err, "failed to load system CAs for forwarding")
+	}
+	creds := credentials.NewTLS(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      certPool,
+	})
+	return []grpc.DialOption{grpc.WithTransportCredentials(creds)}, nil
+}
+
+func (f *Forwarder) processDiscoveryNotifications() {
+	defer f.exitWG.Done()
+	for peers := range f.discoCh {
+		f.SetPeers(peers)
+	}
+}
+
+// SetPeers replaces the set of collectors sharing the traces, and closes the connections to the removed ones.
+// The spans still waiting to be forwarded to them are processed locally.
+func (f *Forwarder) SetPeers(peers []string) {
+	ring := hashring.New(peers)
+	found := false
+	current := make(map[string]struct{}, len(peers))
+	for _, peer := range ring.Peers() {
+		found = found || peer == f.hostPort
+		current[peer] = struct{}{}
+	}
+	if !found {
+		f.logger.Warn("This collector is not in the list of peers, all spans will be forwarded",
+			zap.String("host-port", f.hostPort), zap.Strings("peers", peers))
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.ring = ring
+	f.peers = current
+	for hostPort, queue := range f.queues {
+		if _, ok := current[hostPort]; !ok {
+			close(queue)
+			delete(f.queues, hostPort)
+		}
+	}
+	for hostPort, conn := range f.conns {
+		if _, ok := current[hostPort]; !ok {
+			f.logger.Info("Removing inactive collector", zap.String("peer", hostPort))
+			conn.Close()
+			delete(f.conns, hostPort)
+		}
+	}
+}
+
+// ProcessSpans implements app.SpanProcessor. The spans owned by other collectors are reported as processed
+// once they are queued to be forwarded; spans that fail to be forwarded are processed locally.
+func (f *Forwarder) ProcessSpans(spans []*model.Span, options app.ProcessSpansOptions) ([]bool, error) {
+	if options.Forwarded {
+		return f.processor.ProcessSpans(spans, options)
+	}
+	f.mux.RLock()
+	ring := f.ring
+	f.mux.RUnlock()
+
+	local := &shard{}
+	remote := make(map[string]*shard)
+	for i, span := range spans {
+		owner := ring.Get(hashring.TraceKey(span.TraceID))
+		if owner == "" || owner == f.hostPort {
+			local.add(i, span)
+			continue
+		}
+		if _, ok := remote[owner]; !ok {
+			remote[owner] = &shard{}
+		}
+		remote[owner].add(i, span)
+	}
+
+	oks := make([]bool, len(spans))
+	for owner, ownerShard := range remote {
+		if !f.enqueue(owner, &forwardedBatch{spans: ownerShard.spans, options: options}) {
+			for j, span := range ownerShard.spans {
+				local.add(ownerShard.indexes[j], span)
+			}
+			continue
+		}
+		for _, i := range ownerShard.indexes {
+			oks[i] = true
+		}
+	}
+	if len(local.spans) == 0 {
+		return oks, nil
+	}
+	localOks, err := f.processor.ProcessSpans(local.spans, options)
+	if err != nil {
+		return nil, err
+	}
+	for j, ok := range localOks {
+		oks[local.indexes[j]] = ok
+	}
+	return oks, nil
+}
+
+// shard holds the spans owned by one collector and their indexes in the processed batch
+type shard struct {
+	spans   []*model.Span
+	indexes []int
+}
+
+func (s *shard) add(index int, span *model.Span) {
+	s.spans = append(s.spans, span)
+	s.indexes = append(s.indexes, index)
+}
+
+// enqueue hands the batch to the go-routine forwarding spans to hostPort, starting it if needed. It returns
+// false if the batch must be processed locally: the queue is full, the collector was removed from the peers,
+// or the forwarder is closed.
+func (f *Forwarder) enqueue(hostPort string, batch *forwardedBatch) bool {
+	f.mux.RLock()
+	_, ok := f.queues[hostPort]
+	f.mux.RUnlock()
+	if !ok {
+		f.startForwarding(hostPort)
+	}
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	queue, ok := f.queues[hostPort]
+	if !ok {
+		return false
+	}
+	select {
+	case queue <- batch:
+		return true
+	default:
+		f.metrics.QueueFull.Inc(1)
+		return false
+	}
+}
+
+// startForwarding creates the queue of hostPort and the go-routine forwarding its batches, unless
+// they exist already, the collector is not a peer, or the forwarder is closed.
+func (f *Forwarder) startForwarding(hostPort string) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	_, isPeer := f.peers[hostPort]
+	if _, ok := f.queues[hostPort]; ok || !isPeer || f.closed {
+		return
+	}
+	queue := make(chan *forwardedBatch, forwardQueueSize)
+	f.queues[hostPort] = queue
+	f.forwardWG.Add(1)
+	go f.forwardLoop(hostPort, queue)
+}
+
+// forwardLoop forwards the batches queued for hostPort until the queue is closed.
+func (f *Forwarder) forwardLoop(hostPort string, queue <-chan *forwardedBatch) {
+	defer f.forwardWG.Done()
+	for batch := range queue {
+		if err := f.forwardWithRetries(hostPort, batch.spans); err != nil {
+			f.logger.Error("Failed to forward spans, processing them locally", zap.String("peer", hostPort), zap.Error(err))
+			f.metrics.ForwardErrors.Inc(1)
+			if _, err := f.processor.ProcessSpans(batch.spans, batch.options); err != nil {
+				f.logger.Error("Failed to process spans that could not be forwarded", zap.Int("spans", len(batch.spans)), zap.Error(err))
+			}
+			continue
+		}
+		f.metrics.SpansForwarded.Inc(int64(len(batch.spans)))
+	}
+}
+
+// forwardWithRetries forwards the spans, retrying with an exponential backoff until forwardMaxRetries is
+// reached, the collector is removed from the peers, or the forwarder is closed.
+func (f *Forwarder) forwardWithRetries(hostPort string, spans []*model.Span) error {
+	delay := f.retryDelay
+	for retries := 0; ; retries++ {
+		err := f.forward(hostPort, spans)
+		if err == nil || err == errPeerRemoved || retries == forwardMaxRetries {
+			return err
+		}
+		f.metrics.ForwardRetries.Inc(1)
+		select {
+		case <-time.After(delay):
+		case <-f.stop:
+			return err
+		}
+		delay *= 2
+	}
+}
+
+func (f *Forwarder) forward(hostPort string, spans []*model.Span) error {
+	conn, err := f.getConn(hostPort)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), forwardTimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, app.ForwardedMetadataKey, f.hostPort)
+	_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(ctx, &api_v2.PostSpansRequest{
+		Batch: model.Batch{Spans: spans},
+	})
+	return err
+}
+
+func (f *Forwarder) getConn(hostPort string) (*grpc.ClientConn, error) {
+	f.mux.RLock()
+	conn, ok := f.conns[hostPort]
+	f.mux.RUnlock()
+	if ok {
+		return conn, nil
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if conn, ok := f.conns[hostPort]; ok {
+		return conn, nil
+	}
+	if _, ok := f.peers[hostPort]; !ok {
+		return nil, errPeerRemoved
+	}
+	conn, err := f.dial(hostPort)
+	if err != nil {
+		return nil, err
+	}
+	f.conns[hostPort] = conn
+	return conn, nil
+}
+
+// Close stops listening to the notifier, forwards the queued spans without retrying, and closes the
+// connections to the other collectors.
+func (f *Forwarder) Close() error {
+	f.notifier.Unregister(f.discoCh)
+	close(f.discoCh)
+	f.exitWG.Wait()
+
+	f.mux.Lock()
+	f.closed = true
+	for hostPort, queue := range f.queues {
+		close(queue)
+		delete(f.queues, hostPort)
+	}
+	f.mux.Unlock()
+	close(f.stop)
+	f.forwardWG.Wait()
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	var lastErr error
+	for hostPort, conn := range f.conns {
+		if err := conn.Close(); err != nil {
+			lastErr = err
+		}
+		delete(f.conns, hostPort)
+	}
+	return lastErr
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forwarding
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/discovery"
+	"github.com/jaegertracing/jaeger/pkg/discovery/hashring"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+var _ app.SpanProcessor = new(Forwarder)
+
+type mockSpanProcessor struct {
+	sync.Mutex
+	spans   []*model.Span
+	options []app.ProcessSpansOptions
+	err     error
+}
+
+func (p *mockSpanProcessor) ProcessSpans(spans []*model.Span, options app.ProcessSpansOptions) ([]bool, error) {
+	p.Lock()
+	defer p.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.spans = append(p.spans, spans...)
+	p.options = append(p.options, options)
+	oks := make([]bool, len(spans))
+	for i := range oks {
+		oks[i] = true
+	}
+	return oks, nil
+}
+
+func (p *mockSpanProcessor) getSpans() []*model.Span {
+	p.Lock()
+	defer p.Unlock()
+	return p.spans
+}
+
+func startCollector(t *testing.T, processor app.SpanProcessor) (*grpc.Server, string) {
+	server := grpc.NewServer()
+	api_v2.RegisterCollectorServiceServer(server, app.NewGRPCHandler(zap.NewNop(), processor))
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go server.Serve(lis)
+	return server, lis.Addr().String()
+}
+
+func newTestForwarder(
+	t *testing.T,
+	processor app.SpanProcessor,
+	hostPort string,
+	peers []string,
+	notifier discovery.Notifier,
+	mf *metricstest.Factory,
+) *Forwarder {
+	dialOptions := []grpc.DialOption{grpc.WithInsecure()}
+	f, err := NewForwarder(processor, hostPort, discovery.FixedDiscoverer(peers), notifier, dialOptions, mf, zap.NewNop())
+	require.NoError(t, err)
+	return f
+}
+
+func makeSpans(n int) []*model.Span {
+	var spans []*model.Span
+	for i := 1; i <= n; i++ {
+		spans = append(spans, &model.Span{
+			TraceID: model.NewTraceID(0, uint64(i)),
+			SpanID:  model.NewSpanID(1),
+			Process: model.NewProcess("svc", nil),
+		})
+	}
+	return spans
+}
+
+func TestForwarder(t *testing.T) {
+	remoteProcessor := &mockSpanProcessor{}
+	server, remoteHostPort := startCollector(t, remoteProcessor)
+	defer server.Stop()
+
+	localHostPort := "local:14250"
+	peers := []string{localHostPort, remoteHostPort}
+	localProcessor := &mockSpanProcessor{}
+	mf := metricstest.NewFactory(time.Hour)
+	f := newTestForwarder(t, localProcessor, localHostPort, peers, &discovery.Dispatcher{}, mf)
+
+	spans := makeSpans(20)
+	oks, err := f.ProcessSpans(spans, app.ProcessSpansOptions{InboundTransport: app.TChannelTransport})
+	require.NoError(t, err)
+	assert.Len(t, oks, 20)
+	for _, ok := range oks {
+		assert.True(t, ok)
+	}
+	// the spans are forwarded in the background, closing waits for them
+	require.NoError(t, f.Close())
+
+	ring := hashring.New(peers)
+	var expectedLocal, expectedRemote []model.TraceID
+	for _, span := range spans {
+		if ring.Get(hashring.TraceKey(span.TraceID)) == localHostPort {
+			expectedLocal = append(expectedLocal, span.TraceID)
+		} else {
+			expectedRemote = append(expectedRemote, span.TraceID)
+		}
+	}
+	require.NotEmpty(t, expectedLocal)
+	require.NotEmpty(t, expectedRemote)
+	assert.Equal(t, expectedLocal, traceIDs(localProcessor.getSpans()))
+	assert.Equal(t, expectedRemote, traceIDs(remoteProcessor.getSpans()))
+	assert.False(t, localProcessor.options[0].Forwarded)
+	assert.True(t, remoteProcessor.options[0].Forwarded, "forwarded spans are marked as such")
+	mf.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "forwarding.spans_forwarded", Value: len(expectedRemote)})
+}
+
+func TestForwarderDoesNotForwardForwardedSpans(t *testing.T) {
+	processor := &mockSpanProcessor{}
+	f := newTestForwarder(t, processor, "local:14250", []string{"remote:14250"}, &discovery.Dispatcher{}, metricstest.NewFactory(time.Hour))
+	defer f.Close()
+	spans := makeSpans(5)
+	_, err := f.ProcessSpans(spans, app.ProcessSpansOptions{Forwarded: true})
+	require.NoError(t, err)
+	assert.Equal(t, spans, processor.getSpans())
+}
+
+func TestForwarderProcessesLocallyOnError(t *testing.T) {
+	processor := &mockSpanProcessor{}
+	mf := metricstest.NewFactory(time.Hour)
+	f := newTestForwarder(t, processor, "local:14250", nil, &discovery.Dispatcher{}, mf)
+	f.dial = func(string) (*grpc.ClientConn, error) {
+		return nil, errors.New("dial error")
+	}
+	f.retryDelay = time.Millisecond
+	// no peers, all spans are local
+	spans := makeSpans(5)
+	_, err := f.ProcessSpans(spans, app.ProcessSpansOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, spans, processor.getSpans())
+
+	processor.err = errors.New("busy")
+	_, err = f.ProcessSpans(spans, app.ProcessSpansOptions{})
+	assert.EqualError(t, err, "busy")
+
+	processor.err = nil
+	processor.spans = nil
+	f.SetPeers([]string{"remote:14250"})
+	oks, err := f.ProcessSpans(spans, app.ProcessSpansOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true, true, true, true}, oks)
+	for i := 0; i < 100 && len(processor.getSpans()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, f.Close())
+	assert.Equal(t, spans, processor.getSpans(), "spans that failed to be forwarded are processed locally")
+	mf.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "forwarding.forward_retries", Value: forwardMaxRetries},
+		metricstest.ExpectedMetric{Name: "forwarding.forward_errors", Value: 1})
+}
+
+// blockingSpanProcessor blocks until it is released
+type blockingSpanProcessor struct {
+	mockSpanProcessor
+	release chan struct{}
+}
+
+func (p *blockingSpanProcessor) ProcessSpans(spans []*model.Span, options app.ProcessSpansOptions) ([]bool, error) {
+	<-p.release
+	return p.mockSpanProcessor.ProcessSpans(spans, options)
+}
+
+func TestForwarderProcessesLocallyWhenQueueIsFull(t *testing.T) {
+	remoteProcessor := &blockingSpanProcessor{release: make(chan struct{})}
+	server, remoteHostPort := startCollector(t, remoteProcessor)
+	defer server.Stop()
+
+	localProcessor := &mockSpanProcessor{}
+	mf := metricstest.NewFactory(time.Hour)
+	f := newTestForwarder(t, localProcessor, "local:14250", []string{remoteHostPort}, &discovery.Dispatcher{}, mf)
+
+	queueLen := func() int {
+		f.mux.RLock()
+		defer f.mux.RUnlock()
+		return len(f.queues[remoteHostPort])
+	}
+	// the first batch blocks the forwarding go-routine, the next ones fill the queue
+	spans := makeSpans(1)
+	_, err := f.ProcessSpans(spans, app.ProcessSpansOptions{})
+	require.NoError(t, err)
+	for i := 0; i < 100 && queueLen() > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, 0, queueLen())
+	for i := 0; i < forwardQueueSize; i++ {
+		_, err := f.ProcessSpans(spans, app.ProcessSpansOptions{})
+		require.NoError(t, err)
+	}
+	oks, err := f.ProcessSpans(spans, app.ProcessSpansOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, oks)
+	close(remoteProcessor.release)
+	require.NoError(t, f.Close())
+
+	mf.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "forwarding.forward_queue_full", Value: 1})
+	assert.Equal(t, spans, localProcessor.getSpans(), "the spans that do not fit in the queue are processed locally")
+	assert.Len(t, remoteProcessor.getSpans(), forwardQueueSize+1)
+}
+
+func TestForwarderDoesNotForwardToRemovedPeers(t *testing.T) {
+	processor := &mockSpanProcessor{}
+	f := newTestForwarder(t, processor, "local:14250", []string{"remote:14250"}, &discovery.Dispatcher{}, metricstest.NewFactory(time.Hour))
+	defer f.Close()
+	f.retryDelay = time.Millisecond
+
+	assert.False(t, f.enqueue("other:14250", &forwardedBatch{}), "batches are only queued for peers")
+	_, err := f.getConn("other:14250")
+	assert.Equal(t, errPeerRemoved, err)
+
+	require.True(t, f.enqueue("remote:14250", &forwardedBatch{}))
+	f.SetPeers([]string{"local:14250"})
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	assert.Empty(t, f.queues, "the queues of removed peers are closed")
+}
+
+func TestForwarderFollowsDiscoveryNotifications(t *testing.T) {
+	notifier := &discovery.Dispatcher{}
+	f := newTestForwarder(t, &mockSpanProcessor{}, "local:14250", []string{"local:14250"}, notifier, metricstest.NewFactory(time.Hour))
+	defer f.Close()
+
+	peers := []string{"local:14250", "remote:14250"}
+	notifier.Notify(peers)
+	for i := 0; i < 100; i++ {
+		f.mux.RLock()
+		ring := f.ring
+		f.mux.RUnlock()
+		if len(ring.Peers()) == len(peers) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	assert.Equal(t, peers, f.ring.Peers())
+}
+
+func TestForwarderSetPeersClosesRemovedConnections(t *testing.T) {
+	f := newTestForwarder(t, &mockSpanProcessor{}, "local:14250", []string{"local:14250", "remote:14250"}, &discovery.Dispatcher{}, metricstest.NewFactory(time.Hour))
+	defer f.Close()
+
+	_, err := f.getConn("remote:14250")
+	require.NoError(t, err)
+	f.SetPeers([]string{"local:14250"})
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	assert.Empty(t, f.conns)
+}
+
+type failingDiscoverer struct{}
+
+func (failingDiscoverer) Instances() ([]string, error) {
+	return nil, errors.New("no collectors")
+}
+
+func TestForwarderDiscovererError(t *testing.T) {
+	_, err := NewForwarder(&mockSpanProcessor{}, "local:14250", failingDiscoverer{}, &discovery.Dispatcher{}, nil, metricstest.NewFactory(time.Hour), zap.NewNop())
+	assert.EqualError(t, err, "cannot get initial set of collectors: no collectors")
+}
+
+func TestDialOptions(t *testing.T) {
+	opts, err := DialOptions(tlscfg.Options{})
+	require.NoError(t, err)
+	assert.Len(t, opts, 1)
+
+	_, err = DialOptions(tlscfg.Options{Enabled: true, CertPath: "invalid/path", KeyPath: "invalid/path"})
+	assert.Contains(t, err.Error(), "failed to load TLS cert and key for forwarding")
+}
+
+func traceIDs(spans []*model.Span) []model.TraceID {
+	var ids []model.TraceID
+	for _, span := range spans {
+		ids = append(ids, span.TraceID)
+	}
+	return ids
+}
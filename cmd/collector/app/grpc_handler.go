@@ -18,10 +18,16 @@ import (
 	"context"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
 
+	// registers the compressors that agents may use
+	_ "github.com/jaegertracing/jaeger/pkg/compression"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
+// ForwardedMetadataKey is the gRPC metadata key set on span batches forwarded by another collector
+const ForwardedMetadataKey = "jaeger-forwarded"
+
 // GRPCHandler implements gRPC CollectorService.
 type GRPCHandler struct {
 	logger        *zap.Logger
@@ -46,6 +52,7 @@ func (g *GRPCHandler) PostSpans(ctx context.Context, r *api_v2.PostSpansRequest)
 	_, err := g.spanProcessor.ProcessSpans(r.GetBatch().Spans, ProcessSpansOptions{
 		InboundTransport: GRPCTransport,
 		SpanFormat:       ProtoSpanFormat,
+		Forwarded:        isForwarded(ctx),
 	})
 	if err != nil {
 		g.logger.Error("cannot process spans", zap.Error(err))
@@ -53,3 +60,8 @@ func (g *GRPCHandler) PostSpans(ctx context.Context, r *api_v2.PostSpansRequest)
 	}
 	return &api_v2.PostSpansResponse{}, nil
 }
+
+func isForwarded(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(ForwardedMetadataKey)) > 0
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+// StartHTTPServer starts an HTTP server for the handler on the given port, secured with TLS
+// if tlsOpts is enabled. The certificates are reloaded when their files change;
+// call tlsOpts.Close() once the server is stopped.
+func StartHTTPServer(
+	port int,
+	handler http.Handler,
+	tlsOpts *tlscfg.Options,
+	logger *zap.Logger,
+	serveErr func(error),
+) (*http.Server, net.Addr, error) {
+	server := &http.Server{Handler: handler}
+	if tlsOpts.Enabled {
+		tlsCfg, err := tlsOpts.Config(logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		server.TLSConfig = tlsCfg
+	}
+
+	lis, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		tlsOpts.Close()
+		return nil, nil, errors.Wrap(err, "failed to listen on HTTP port")
+	}
+
+	go func() {
+		var err error
+		if tlsOpts.Enabled {
+			// the certificate is provided by TLSConfig.GetCertificate
+			err = server.ServeTLS(lis, "", "")
+		} else {
+			err = server.Serve(lis)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logger.Error("Could not launch HTTP server", zap.Error(err))
+			serveErr(err)
+		}
+	}()
+	return server, lis.Addr(), nil
+}
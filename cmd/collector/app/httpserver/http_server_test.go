@@ -0,0 +1,105 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusAccepted)
+})
+
+func failOnServeErr(t *testing.T) func(error) {
+	return func(err error) {
+		t.Errorf("unexpected serve error: %v", err)
+	}
+}
+
+func TestFailToListen(t *testing.T) {
+	server, addr, err := StartHTTPServer(-1, okHandler, &tlscfg.Options{}, zap.NewNop(), failOnServeErr(t))
+	assert.Nil(t, server)
+	assert.Nil(t, addr)
+	assert.EqualError(t, err, "failed to listen on HTTP port: listen tcp: address -1: invalid port")
+}
+
+func TestInvalidTLS(t *testing.T) {
+	tlsOpts := &tlscfg.Options{Enabled: true, CertPath: "invalid", KeyPath: "invalid"}
+	server, _, err := StartHTTPServer(0, okHandler, tlsOpts, zap.NewNop(), failOnServeErr(t))
+	assert.Nil(t, server)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load server TLS cert and key")
+}
+
+func TestPlaintext(t *testing.T) {
+	server, addr, err := StartHTTPServer(0, okHandler, &tlscfg.Options{}, zap.NewNop(), failOnServeErr(t))
+	require.NoError(t, err)
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d", addr.(*net.TCPAddr).Port))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestMutualTLS(t *testing.T) {
+	tlsOpts := &tlscfg.Options{
+		Enabled:      true,
+		CertPath:     "testdata/server.jaeger.io.pem",
+		KeyPath:      "testdata/server.jaeger.io-key.pem",
+		ClientCAPath: "testdata/rootCA.pem",
+	}
+	server, addr, err := StartHTTPServer(0, okHandler, tlsOpts, zap.NewNop(), failOnServeErr(t))
+	require.NoError(t, err)
+	defer tlsOpts.Close()
+	defer server.Close()
+
+	caPEM, err := ioutil.ReadFile("testdata/rootCA.pem")
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caPEM))
+	clientCert, err := tls.LoadX509KeyPair("testdata/client.jaeger.io-client.pem", "testdata/client.jaeger.io-client-key.pem")
+	require.NoError(t, err)
+	url := fmt.Sprintf("https://localhost:%d", addr.(*net.TCPAddr).Port)
+
+	get := func(certs []tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "server.jaeger.io",
+			Certificates: certs,
+		}}}
+		return client.Get(url)
+	}
+
+	resp, err := get([]tls.Certificate{clientCert})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	_, err = get(nil)
+	assert.Error(t, err, "client certificate is required")
+}
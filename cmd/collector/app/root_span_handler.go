@@ -0,0 +1,45 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	samplingModel "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// HandleRootSpan returns a ProcessSpan that records the throughput of root spans with the aggregator.
+func HandleRootSpan(aggregator strategystore.Aggregator) ProcessSpan {
+	return func(span *model.Span) {
+		// Checking the parent ID alone is not enough to identify a root span,
+		// but only root spans carry the sampler tags.
+		if span.ParentSpanID() != model.SpanID(0) || span.Process == nil {
+			return
+		}
+		service := span.Process.ServiceName
+		if service == "" || span.OperationName == "" {
+			return
+		}
+		samplerType := span.GetSamplerType()
+		if samplerType != samplingModel.SamplerTypeProbabilistic && samplerType != samplingModel.SamplerTypeLowerBound {
+			return
+		}
+		probability, ok := span.GetSamplerParam()
+		if !ok {
+			return
+		}
+		aggregator.RecordThroughput(service, span.OperationName, samplerType, probability)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type mockAggregator struct {
+	callCount int
+	service   string
+	operation string
+	sampler   string
+	param     float64
+}
+
+func (t *mockAggregator) RecordThroughput(service, operation, samplerType string, probability float64) {
+	t.callCount++
+	t.service, t.operation, t.sampler, t.param = service, operation, samplerType, probability
+}
+func (t *mockAggregator) Start()       {}
+func (t *mockAggregator) Close() error { return nil }
+
+func TestHandleRootSpan(t *testing.T) {
+	aggregator := &mockAggregator{}
+	processor := HandleRootSpan(aggregator)
+
+	traceID := model.NewTraceID(0, 1)
+	samplerTags := model.KeyValues{
+		model.String("sampler.type", "probabilistic"),
+		model.Float64("sampler.param", 0.001),
+	}
+
+	// not a root span
+	processor(&model.Span{
+		TraceID:    traceID,
+		References: []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(2))},
+		Process:    &model.Process{ServiceName: "service"},
+		Tags:       samplerTags,
+	})
+	// no process
+	processor(&model.Span{TraceID: traceID, OperationName: "op", Tags: samplerTags})
+	// no service name or operation
+	processor(&model.Span{TraceID: traceID, Process: &model.Process{}, Tags: samplerTags})
+	// unsupported sampler type
+	processor(&model.Span{
+		TraceID:       traceID,
+		OperationName: "op",
+		Process:       &model.Process{ServiceName: "service"},
+		Tags:          model.KeyValues{model.String("sampler.type", "const"), model.Bool("sampler.param", true)},
+	})
+	// missing sampler param
+	processor(&model.Span{
+		TraceID:       traceID,
+		OperationName: "op",
+		Process:       &model.Process{ServiceName: "service"},
+		Tags:          model.KeyValues{model.String("sampler.type", "probabilistic")},
+	})
+	assert.Equal(t, 0, aggregator.callCount)
+
+	processor(&model.Span{
+		TraceID:       traceID,
+		OperationName: "GET",
+		Process:       &model.Process{ServiceName: "service"},
+		Tags:          samplerTags,
+	})
+	assert.Equal(t, 1, aggregator.callCount)
+	assert.Equal(t, "service", aggregator.service)
+	assert.Equal(t, "GET", aggregator.operation)
+	assert.Equal(t, "probabilistic", aggregator.sampler)
+	assert.Equal(t, 0.001, aggregator.param)
+}
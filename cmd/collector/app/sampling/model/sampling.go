@@ -15,6 +15,15 @@
 
 package model
 
+const (
+	// SamplerTypeProbabilistic is the value of the sampler.type tag set by probabilistic samplers.
+	SamplerTypeProbabilistic = "probabilistic"
+
+	// SamplerTypeLowerBound is the value of the sampler.type tag set by the lower bound sampler
+	// of adaptive (per-operation) samplers.
+	SamplerTypeLowerBound = "lowerbound"
+)
+
 // Throughput keeps track of the queries an operation received.
 type Throughput struct {
 	Service       string
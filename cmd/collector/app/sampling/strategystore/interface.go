@@ -15,6 +15,8 @@
 package strategystore
 
 import (
+	"io"
+
 	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
 )
 
@@ -23,3 +25,15 @@ type StrategyStore interface {
 	// GetSamplingStrategy retrieves the sampling strategy for the specified service.
 	GetSamplingStrategy(serviceName string) (*sampling.SamplingStrategyResponse, error)
 }
+
+// Aggregator aggregates the throughput of operations observed by the collector.
+type Aggregator interface {
+	// Close stops the aggregator and flushes any pending throughput.
+	io.Closer
+
+	// RecordThroughput records a root span of an operation sampled by the given sampler type and probability.
+	RecordThroughput(service, operation, samplerType string, probability float64)
+
+	// Start starts the periodic flushing of aggregated throughput.
+	Start()
+}
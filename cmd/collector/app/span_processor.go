@@ -31,6 +31,8 @@ import (
 type ProcessSpansOptions struct {
 	SpanFormat       SpanFormat
 	InboundTransport InboundTransport
+	// Forwarded is true for spans forwarded by another collector, which must not be forwarded again
+	Forwarded bool
 }
 
 // SpanProcessor handles model spans
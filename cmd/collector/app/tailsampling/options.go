@@ -0,0 +1,105 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	tailSamplingPrefix  = "collector.tail-sampling."
+	decisionWait        = tailSamplingPrefix + "decision-wait"
+	maxTraces           = tailSamplingPrefix + "max-traces"
+	sampleErrors        = tailSamplingPrefix + "sample-errors"
+	minRootDuration     = tailSamplingPrefix + "min-root-duration"
+	services            = tailSamplingPrefix + "services"
+	tags                = tailSamplingPrefix + "tags"
+	probability         = tailSamplingPrefix + "probability"
+	defaultMaxTraces    = 50000
+	defaultProbability  = 0.1
+	defaultDecisionWait = 0
+)
+
+// Options holds configuration for the tail sampling stage of the collector.
+type Options struct {
+	// DecisionWait is how long spans of a trace are buffered before the sampling decision is made,
+	// 0 disables tail sampling
+	DecisionWait time.Duration
+	// MaxTraces is the maximum number of traces buffered, the oldest trace is decided early when exceeded
+	MaxTraces int
+	// SampleErrors keeps traces with at least one span tagged with error=true
+	SampleErrors bool
+	// MinRootDuration keeps traces whose root span is at least this long, 0 disables the policy
+	MinRootDuration time.Duration
+	// Services keeps traces with at least one span from one of these services
+	Services []string
+	// Tags keeps traces with at least one span with one of these tags, as key=value
+	Tags map[string]string
+	// Probability is the probability of keeping a trace not matched by any other policy
+	Probability float64
+}
+
+// AddFlags adds flags for Options
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Duration(decisionWait, defaultDecisionWait, "How long spans are buffered by trace ID before the tail sampling decision is made. Zero value disables tail sampling")
+	flagSet.Int(maxTraces, defaultMaxTraces, "The maximum number of traces buffered for tail sampling, the oldest traces are decided early when exceeded")
+	flagSet.Bool(sampleErrors, true, "Keep traces with at least one span tagged with error=true")
+	flagSet.Duration(minRootDuration, 0, "Keep traces whose root span duration is at least this long. Zero value disables the policy")
+	flagSet.String(services, "", "Comma separated list of services whose traces are always kept")
+	flagSet.String(tags, "", "Comma separated list of key=value tags, traces with a matching span are always kept")
+	flagSet.Float64(probability, defaultProbability, "The probability of keeping a trace which is not kept by any other tail sampling policy")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (opts *Options) InitFromViper(v *viper.Viper) *Options {
+	opts.DecisionWait = v.GetDuration(decisionWait)
+	opts.MaxTraces = v.GetInt(maxTraces)
+	opts.SampleErrors = v.GetBool(sampleErrors)
+	opts.MinRootDuration = v.GetDuration(minRootDuration)
+	opts.Services = splitList(v.GetString(services))
+	opts.Tags = parseTags(v.GetString(tags))
+	opts.Probability = v.GetFloat64(probability)
+	return opts
+}
+
+// Enabled returns true if tail sampling is configured.
+func (opts *Options) Enabled() bool {
+	return opts.DecisionWait > 0
+}
+
+func splitList(list string) []string {
+	var ret []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+func parseTags(list string) map[string]string {
+	ret := make(map[string]string)
+	for _, tag := range splitList(list) {
+		kv := strings.SplitN(tag, "=", 2)
+		if len(kv) == 2 {
+			ret[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return ret
+}
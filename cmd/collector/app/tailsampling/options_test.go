@@ -0,0 +1,59 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.tail-sampling.decision-wait=10s",
+		"--collector.tail-sampling.max-traces=100",
+		"--collector.tail-sampling.sample-errors=false",
+		"--collector.tail-sampling.min-root-duration=2s",
+		"--collector.tail-sampling.services=foo, bar,",
+		"--collector.tail-sampling.tags=http.status_code=500,invalid,debug = true",
+		"--collector.tail-sampling.probability=0.5",
+	})
+	opts := new(Options).InitFromViper(v)
+
+	assert.True(t, opts.Enabled())
+	assert.Equal(t, 10*time.Second, opts.DecisionWait)
+	assert.Equal(t, 100, opts.MaxTraces)
+	assert.False(t, opts.SampleErrors)
+	assert.Equal(t, 2*time.Second, opts.MinRootDuration)
+	assert.Equal(t, []string{"foo", "bar"}, opts.Services)
+	assert.Equal(t, map[string]string{"http.status_code": "500", "debug": "true"}, opts.Tags)
+	assert.Equal(t, 0.5, opts.Probability)
+}
+
+func TestDefaultOptions(t *testing.T) {
+	v, _ := config.Viperize(AddFlags)
+	opts := new(Options).InitFromViper(v)
+
+	assert.False(t, opts.Enabled())
+	assert.Equal(t, defaultMaxTraces, opts.MaxTraces)
+	assert.True(t, opts.SampleErrors)
+	assert.Empty(t, opts.Services)
+	assert.Empty(t, opts.Tags)
+	assert.Equal(t, defaultProbability, opts.Probability)
+}
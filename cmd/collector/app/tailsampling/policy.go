@@ -0,0 +1,118 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// probabilisticHashSalt keeps the probabilistic decisions independent from span storage downsampling
+const probabilisticHashSalt = "tail-sampling-salt"
+
+// Policy decides whether the spans of a trace are kept
+type Policy func(spans []*model.Span) bool
+
+// PoliciesFromOptions creates the policies configured in options. The probabilistic policy
+// is always last, as it is the fallback for traces not kept by the other policies.
+func PoliciesFromOptions(options Options) []Policy {
+	var policies []Policy
+	if options.SampleErrors {
+		policies = append(policies, ErrorPolicy())
+	}
+	if options.MinRootDuration > 0 {
+		policies = append(policies, RootDurationPolicy(options.MinRootDuration))
+	}
+	if len(options.Services) > 0 {
+		policies = append(policies, ServicePolicy(options.Services))
+	}
+	if len(options.Tags) > 0 {
+		policies = append(policies, TagPolicy(options.Tags))
+	}
+	return append(policies, ProbabilisticPolicy(options.Probability))
+}
+
+// ErrorPolicy keeps traces with at least one span tagged with error=true
+func ErrorPolicy() Policy {
+	return TagPolicy(map[string]string{"error": "true"})
+}
+
+// RootDurationPolicy keeps traces whose root span is at least minDuration long
+func RootDurationPolicy(minDuration time.Duration) Policy {
+	return func(spans []*model.Span) bool {
+		for _, span := range spans {
+			if span.ParentSpanID() == 0 && span.Duration >= minDuration {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ServicePolicy keeps traces with at least one span from one of the services
+func ServicePolicy(services []string) Policy {
+	serviceSet := make(map[string]struct{}, len(services))
+	for _, service := range services {
+		serviceSet[service] = struct{}{}
+	}
+	return func(spans []*model.Span) bool {
+		for _, span := range spans {
+			if span.Process == nil {
+				continue
+			}
+			if _, ok := serviceSet[span.Process.ServiceName]; ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// TagPolicy keeps traces with at least one span that has one of the tags
+func TagPolicy(tags map[string]string) Policy {
+	return func(spans []*model.Span) bool {
+		for _, span := range spans {
+			for _, tag := range span.Tags {
+				if value, ok := tags[tag.Key]; ok && tag.AsString() == value {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// ProbabilisticPolicy keeps traces with the given probability. The decision only depends
+// on the trace ID, so that all collectors make the same decision for a trace.
+func ProbabilisticPolicy(probability float64) Policy {
+	sampler := spanstore.NewSampler(probability, probabilisticHashSalt)
+	return func(spans []*model.Span) bool {
+		return len(spans) > 0 && probability > 0 && sampler.ShouldSample(spans[0])
+	}
+}
+
+// anyPolicy keeps traces kept by at least one of the policies
+func anyPolicy(policies []Policy) Policy {
+	return func(spans []*model.Span) bool {
+		for _, policy := range policies {
+			if policy(spans) {
+				return true
+			}
+		}
+		return false
+	}
+}
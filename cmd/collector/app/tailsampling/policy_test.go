@@ -0,0 +1,103 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeSpan(traceID uint64, parentID uint64, service string, duration time.Duration, tags ...model.KeyValue) *model.Span {
+	span := &model.Span{
+		TraceID:  model.NewTraceID(0, traceID),
+		SpanID:   model.NewSpanID(parentID + 1),
+		Duration: duration,
+		Process:  model.NewProcess(service, nil),
+		Tags:     tags,
+	}
+	if parentID != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(span.TraceID, model.NewSpanID(parentID))}
+	}
+	return span
+}
+
+func TestErrorPolicy(t *testing.T) {
+	policy := ErrorPolicy()
+	assert.True(t, policy([]*model.Span{makeSpan(1, 0, "svc", 0), makeSpan(1, 1, "svc", 0, model.Bool("error", true))}))
+	assert.True(t, policy([]*model.Span{makeSpan(1, 0, "svc", 0, model.String("error", "true"))}))
+	assert.False(t, policy([]*model.Span{makeSpan(1, 0, "svc", 0, model.Bool("error", false))}))
+	assert.False(t, policy([]*model.Span{makeSpan(1, 0, "svc", 0)}))
+}
+
+func TestRootDurationPolicy(t *testing.T) {
+	policy := RootDurationPolicy(time.Second)
+	assert.True(t, policy([]*model.Span{makeSpan(1, 0, "svc", 2*time.Second)}))
+	assert.True(t, policy([]*model.Span{makeSpan(1, 0, "svc", time.Second)}))
+	assert.False(t, policy([]*model.Span{makeSpan(1, 0, "svc", time.Millisecond)}))
+	assert.False(t, policy([]*model.Span{makeSpan(1, 1, "svc", 2*time.Second)}), "only root spans are considered")
+}
+
+func TestServicePolicy(t *testing.T) {
+	policy := ServicePolicy([]string{"foo", "bar"})
+	assert.True(t, policy([]*model.Span{makeSpan(1, 0, "baz", 0), makeSpan(1, 1, "bar", 0)}))
+	assert.False(t, policy([]*model.Span{makeSpan(1, 0, "baz", 0), {TraceID: model.NewTraceID(0, 1)}}))
+}
+
+func TestTagPolicy(t *testing.T) {
+	policy := TagPolicy(map[string]string{"http.status_code": "500"})
+	assert.True(t, policy([]*model.Span{makeSpan(1, 0, "svc", 0, model.Int64("http.status_code", 500))}))
+	assert.False(t, policy([]*model.Span{makeSpan(1, 0, "svc", 0, model.Int64("http.status_code", 200))}))
+	assert.False(t, policy([]*model.Span{makeSpan(1, 0, "svc", 0, model.String("status_code", "500"))}))
+}
+
+func TestProbabilisticPolicy(t *testing.T) {
+	trace := []*model.Span{makeSpan(1, 0, "svc", 0)}
+	assert.True(t, ProbabilisticPolicy(1)(trace))
+	assert.False(t, ProbabilisticPolicy(0)(trace))
+	assert.False(t, ProbabilisticPolicy(1)(nil))
+
+	policy := ProbabilisticPolicy(0.5)
+	sampled := 0
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		trace := []*model.Span{makeSpan(r.Uint64(), 0, "svc", 0)}
+		decision := policy(trace)
+		assert.Equal(t, decision, policy(trace), "decision must be stable for a trace")
+		if decision {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
+
+func TestPoliciesFromOptions(t *testing.T) {
+	assert.Len(t, PoliciesFromOptions(Options{}), 1)
+	assert.Len(t, PoliciesFromOptions(Options{
+		SampleErrors:    true,
+		MinRootDuration: time.Second,
+		Services:        []string{"foo"},
+		Tags:            map[string]string{"k": "v"},
+	}), 5)
+
+	policy := anyPolicy(PoliciesFromOptions(Options{SampleErrors: true, Services: []string{"foo"}}))
+	assert.True(t, policy([]*model.Span{makeSpan(1, 0, "foo", 0)}))
+	assert.True(t, policy([]*model.Span{makeSpan(1, 0, "bar", 0, model.Bool("error", true))}))
+	assert.False(t, policy([]*model.Span{makeSpan(1, 0, "bar", 0)}))
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/cache"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// minDecisionTickInterval bounds how often the pending traces are checked for a decision, the interval
+// is otherwise a quarter of the decision wait
+const minDecisionTickInterval = time.Millisecond
+
+// writerMetrics keeps track of the tail sampling decisions
+type writerMetrics struct {
+	TracesSampled  metrics.Counter `metric:"traces_sampled"`
+	TracesDropped  metrics.Counter `metric:"traces_dropped"`
+	TracesEvicted  metrics.Counter `metric:"traces_evicted"`
+	SpansSampled   metrics.Counter `metric:"spans_sampled"`
+	SpansDropped   metrics.Counter `metric:"spans_dropped"`
+	LateSpans      metrics.Counter `metric:"late_spans"`
+	WriteErrors    metrics.Counter `metric:"write_errors"`
+	TracesBuffered metrics.Gauge   `metric:"traces_buffered"`
+}
+
+type pendingTrace struct {
+	firstSeen time.Time
+	spans     []*model.Span
+}
+
+// Writer is a span Writer that buffers spans by trace ID for a decision window and
+// only writes the traces kept by the sampling policies to the wrapped span writer.
+type Writer struct {
+	spanWriter   spanstore.Writer
+	policy       Policy
+	logger       *zap.Logger
+	metrics      writerMetrics
+	decisionWait time.Duration
+	maxTraces    int
+	timeNow      func() time.Time
+
+	mux sync.Mutex
+	// traces holds the spans of the traces awaiting a decision
+	traces map[model.TraceID]*pendingTrace
+	// order holds the IDs of the pending traces, oldest first
+	order []model.TraceID
+	// decisions remembers recent decisions, so that late spans follow the decision of their trace
+	decisions cache.Cache
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewWriter creates a tail sampling Writer wrapping spanWriter and starts its decision loop.
+func NewWriter(spanWriter spanstore.Writer, options Options, metricsFactory metrics.Factory, logger *zap.Logger) (*Writer, error) {
+	if options.MaxTraces <= 0 {
+		return nil, fmt.Errorf("the maximum number of buffered traces must be positive, got %d", options.MaxTraces)
+	}
+	if options.DecisionWait <= 0 {
+		return nil, fmt.Errorf("the decision wait must be positive, got %v", options.DecisionWait)
+	}
+	w := newWriter(spanWriter, options, metricsFactory, logger)
+	tickInterval := w.decisionWait / 4
+	if tickInterval < minDecisionTickInterval {
+		tickInterval = minDecisionTickInterval
+	}
+	w.wg.Add(1)
+	go w.runDecisionLoop(tickInterval)
+	return w, nil
+}
+
+func newWriter(spanWriter spanstore.Writer, options Options, metricsFactory metrics.Factory, logger *zap.Logger) *Writer {
+	w := &Writer{
+		spanWriter:   spanWriter,
+		policy:       anyPolicy(PoliciesFromOptions(options)),
+		logger:       logger,
+		decisionWait: options.DecisionWait,
+		maxTraces:    options.MaxTraces,
+		timeNow:      time.Now,
+		traces:       make(map[model.TraceID]*pendingTrace),
+		decisions:    cache.NewLRU(options.MaxTraces),
+		stop:         make(chan struct{}),
+	}
+	metrics.Init(&w.metrics, metricsFactory.Namespace(metrics.NSOptions{Name: "tail_sampling"}), nil)
+	return w
+}
+
+// WriteSpan buffers the span until the sampling decision for its trace is made.
+// Spans arriving after the decision are written or dropped according to that decision.
+func (w *Writer) WriteSpan(span *model.Span) error {
+	w.mux.Lock()
+	if decision := w.decisions.Get(span.TraceID.String()); decision != nil {
+		w.mux.Unlock()
+		w.metrics.LateSpans.Inc(1)
+		if decision.(bool) {
+			return w.writeSpans([]*model.Span{span})
+		}
+		w.metrics.SpansDropped.Inc(1)
+		return nil
+	}
+	trace, ok := w.traces[span.TraceID]
+	if !ok {
+		trace = &pendingTrace{firstSeen: w.timeNow()}
+		w.traces[span.TraceID] = trace
+		w.order = append(w.order, span.TraceID)
+	}
+	trace.spans = append(trace.spans, span)
+	var sampled [][]*model.Span
+	for len(w.traces) > w.maxTraces {
+		w.metrics.TracesEvicted.Inc(1)
+		if spans := w.decideOldest(); spans != nil {
+			sampled = append(sampled, spans)
+		}
+	}
+	w.mux.Unlock()
+
+	w.writeTraces(sampled)
+	return nil
+}
+
+// Close decides all pending traces and closes the wrapped span writer.
+func (w *Writer) Close() error {
+	close(w.stop)
+	w.wg.Wait()
+	w.flush(time.Time{}, true)
+	if closer, ok := w.spanWriter.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (w *Writer) runDecisionLoop(tickInterval time.Duration) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush(w.timeNow(), false)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// flush decides the traces first seen at least decisionWait before now, or all traces if all is true.
+func (w *Writer) flush(now time.Time, all bool) {
+	var sampled [][]*model.Span
+	w.mux.Lock()
+	for len(w.order) > 0 {
+		if !all && now.Sub(w.traces[w.order[0]].firstSeen) < w.decisionWait {
+			break
+		}
+		if spans := w.decideOldest(); spans != nil {
+			sampled = append(sampled, spans)
+		}
+	}
+	w.metrics.TracesBuffered.Update(int64(len(w.traces)))
+	w.mux.Unlock()
+
+	w.writeTraces(sampled)
+}
+
+// decideOldest removes the oldest pending trace and records the sampling decision for it,
+// it must be called with the lock held. It returns the spans of the trace if it is sampled.
+func (w *Writer) decideOldest() []*model.Span {
+	traceID := w.order[0]
+	w.order = w.order[1:]
+	trace := w.traces[traceID]
+	delete(w.traces, traceID)
+
+	sampled := w.policy(trace.spans)
+	w.decisions.Put(traceID.String(), sampled)
+	if !sampled {
+		w.metrics.TracesDropped.Inc(1)
+		w.metrics.SpansDropped.Inc(int64(len(trace.spans)))
+		return nil
+	}
+	w.metrics.TracesSampled.Inc(1)
+	return trace.spans
+}
+
+func (w *Writer) writeTraces(traces [][]*model.Span) {
+	for _, spans := range traces {
+		if err := w.writeSpans(spans); err != nil {
+			w.logger.Error("Failed to save tail sampled trace", zap.Stringer("trace-id", spans[0].TraceID), zap.Error(err))
+		}
+	}
+}
+
+func (w *Writer) writeSpans(spans []*model.Span) error {
+	var lastErr error
+	for _, span := range spans {
+		if err := w.spanWriter.WriteSpan(span); err != nil {
+			w.metrics.WriteErrors.Inc(1)
+			lastErr = err
+			continue
+		}
+		w.metrics.SpansSampled.Inc(1)
+	}
+	return lastErr
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsampling
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var _ spanstore.Writer = new(Writer)
+
+type fakeWriter struct {
+	sync.Mutex
+	spans  []*model.Span
+	err    error
+	closed bool
+}
+
+func (w *fakeWriter) WriteSpan(span *model.Span) error {
+	w.Lock()
+	defer w.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.spans = append(w.spans, span)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func (w *fakeWriter) writtenSpans() []*model.Span {
+	w.Lock()
+	defer w.Unlock()
+	return append([]*model.Span(nil), w.spans...)
+}
+
+func withWriter(options Options, test func(w *Writer, sw *fakeWriter, mf *metricstest.Factory, now *time.Time)) {
+	sw := &fakeWriter{}
+	mf := metricstest.NewFactory(time.Hour)
+	w := newWriter(sw, options, mf, zap.NewNop())
+	now := time.Unix(1000, 0)
+	w.timeNow = func() time.Time { return now }
+	test(w, sw, mf, &now)
+}
+
+func TestWriterDecisions(t *testing.T) {
+	options := Options{DecisionWait: time.Second, MaxTraces: 10, SampleErrors: true}
+	withWriter(options, func(w *Writer, sw *fakeWriter, mf *metricstest.Factory, now *time.Time) {
+		require.NoError(t, w.WriteSpan(makeSpan(1, 0, "svc", 0)))
+		require.NoError(t, w.WriteSpan(makeSpan(2, 0, "svc", 0)))
+		*now = now.Add(500 * time.Millisecond)
+		require.NoError(t, w.WriteSpan(makeSpan(1, 1, "svc", 0, model.Bool("error", true))))
+		require.NoError(t, w.WriteSpan(makeSpan(3, 0, "svc", 0, model.Bool("error", true))))
+
+		w.flush(*now, false)
+		assert.Empty(t, sw.writtenSpans(), "nothing is decided before the decision wait")
+
+		*now = now.Add(500 * time.Millisecond)
+		w.flush(*now, false)
+		assert.Len(t, sw.writtenSpans(), 2, "trace 1 has an error")
+		mf.AssertCounterMetrics(t,
+			metricstest.ExpectedMetric{Name: "tail_sampling.traces_sampled", Value: 1},
+			metricstest.ExpectedMetric{Name: "tail_sampling.traces_dropped", Value: 1},
+			metricstest.ExpectedMetric{Name: "tail_sampling.spans_dropped", Value: 1},
+		)
+		mf.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "tail_sampling.traces_buffered", Value: 1})
+
+		// late spans follow the decision of their trace
+		require.NoError(t, w.WriteSpan(makeSpan(1, 2, "svc", 0)))
+		require.NoError(t, w.WriteSpan(makeSpan(2, 2, "svc", 0)))
+		assert.Len(t, sw.writtenSpans(), 3)
+		mf.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "tail_sampling.late_spans", Value: 2})
+
+		require.NoError(t, w.Close())
+		assert.Len(t, sw.writtenSpans(), 4, "pending traces are decided on close")
+		assert.True(t, sw.closed)
+	})
+}
+
+func TestWriterEvictsOldestTraces(t *testing.T) {
+	options := Options{DecisionWait: time.Minute, MaxTraces: 2, Probability: 1}
+	withWriter(options, func(w *Writer, sw *fakeWriter, mf *metricstest.Factory, now *time.Time) {
+		for i := uint64(1); i <= 3; i++ {
+			require.NoError(t, w.WriteSpan(makeSpan(i, 0, "svc", 0)))
+		}
+		spans := sw.writtenSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, model.NewTraceID(0, 1), spans[0].TraceID)
+		mf.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "tail_sampling.traces_evicted", Value: 1})
+	})
+}
+
+func TestWriterWriteErrors(t *testing.T) {
+	options := Options{DecisionWait: time.Second, MaxTraces: 10, Probability: 1}
+	withWriter(options, func(w *Writer, sw *fakeWriter, mf *metricstest.Factory, now *time.Time) {
+		sw.err = errors.New("write error")
+		require.NoError(t, w.WriteSpan(makeSpan(1, 0, "svc", 0)))
+		w.flush(now.Add(time.Second), false)
+		mf.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "tail_sampling.write_errors", Value: 1})
+
+		assert.EqualError(t, w.WriteSpan(makeSpan(1, 1, "svc", 0)), "write error")
+	})
+}
+
+func TestWriterDecisionLoop(t *testing.T) {
+	sw := &fakeWriter{}
+	w, err := NewWriter(sw, Options{DecisionWait: 10 * time.Millisecond, MaxTraces: 10, Probability: 1}, metricstest.NewFactory(time.Hour), zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, w.WriteSpan(makeSpan(1, 0, "svc", 0)))
+	for i := 0; i < 100 && len(sw.writtenSpans()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Len(t, sw.writtenSpans(), 1)
+	require.NoError(t, w.Close())
+}
+
+func TestNewWriterInvalidOptions(t *testing.T) {
+	_, err := NewWriter(&fakeWriter{}, Options{DecisionWait: time.Second, Probability: 1}, metricstest.NewFactory(time.Hour), zap.NewNop())
+	assert.EqualError(t, err, "the maximum number of buffered traces must be positive, got 0")
+	_, err = NewWriter(&fakeWriter{}, Options{MaxTraces: 10, Probability: 1}, metricstest.NewFactory(time.Hour), zap.NewNop())
+	assert.EqualError(t, err, "the decision wait must be positive, got 0s")
+}
+
+func TestNewWriterTinyDecisionWait(t *testing.T) {
+	// a quarter of the decision wait rounds down to zero, which is not a valid tick interval
+	w, err := NewWriter(&fakeWriter{}, Options{DecisionWait: 3 * time.Nanosecond, MaxTraces: 10, Probability: 1}, metricstest.NewFactory(time.Hour), zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
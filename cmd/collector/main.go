@@ -41,19 +41,25 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/collector/app"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/builder"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/grpcserver"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/httpserver"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/tailsampling"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/zipkin"
 	"github.com/jaegertracing/jaeger/cmd/docs"
 	"github.com/jaegertracing/jaeger/cmd/env"
 	"github.com/jaegertracing/jaeger/cmd/flags"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
 	"github.com/jaegertracing/jaeger/pkg/version"
 	ss "github.com/jaegertracing/jaeger/plugin/sampling/strategystore"
 	"github.com/jaegertracing/jaeger/plugin/storage"
+	"github.com/jaegertracing/jaeger/plugin/storage/badger"
+	"github.com/jaegertracing/jaeger/plugin/storage/es"
 	"github.com/jaegertracing/jaeger/ports"
+	istorage "github.com/jaegertracing/jaeger/storage"
 	jc "github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 	sc "github.com/jaegertracing/jaeger/thrift-gen/sampling"
 	zc "github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
@@ -90,10 +96,18 @@ func main() {
 			if err := storageFactory.Initialize(baseFactory, logger); err != nil {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
+			for route, handler := range storageFactory.AdminHandlers() {
+				svc.Admin.Handle(route, handler)
+			}
 			spanWriter, err := storageFactory.CreateSpanWriter()
 			if err != nil {
 				logger.Fatal("Failed to create span writer", zap.Error(err))
 			}
+			if tsOpts := new(tailsampling.Options).InitFromViper(v); tsOpts.Enabled() {
+				if spanWriter, err = tailsampling.NewWriter(spanWriter, *tsOpts, metricsFactory, logger); err != nil {
+					logger.Fatal("Failed to create tail sampling writer", zap.Error(err))
+				}
+			}
 
 			builderOpts := new(builder.CollectorOptions).InitFromViper(v)
 			handlerBuilder, err := builder.NewSpanHandlerBuilder(
@@ -106,9 +120,22 @@ func main() {
 				logger.Fatal("Unable to set up builder", zap.Error(err))
 			}
 
-			zipkinSpansHandler, jaegerBatchesHandler, grpcHandler := handlerBuilder.BuildHandlers()
 			strategyStoreFactory.InitFromViper(v)
-			strategyStore := initSamplingStrategyStore(strategyStoreFactory, metricsFactory, logger)
+			ssFactory, err := storageFactory.CreateSamplingStoreFactory()
+			if err != nil {
+				logger.Fatal("Failed to create sampling store factory", zap.Error(err))
+			}
+			strategyStore := initSamplingStrategyStore(strategyStoreFactory, metricsFactory, ssFactory, logger)
+			aggregator := initSamplingAggregator(strategyStoreFactory, logger)
+
+			var preSave []app.ProcessSpan
+			if aggregator != nil {
+				preSave = append(preSave, app.HandleRootSpan(aggregator))
+			}
+			zipkinSpansHandler, jaegerBatchesHandler, grpcHandler, err := handlerBuilder.BuildHandlers(preSave...)
+			if err != nil {
+				logger.Fatal("Unable to build span handlers", zap.Error(err))
+			}
 
 			{
 				ch, err := tchannel.NewChannel(serviceName, &tchannel.ChannelOptions{})
@@ -129,7 +156,8 @@ func main() {
 				ch.Serve(listener)
 			}
 
-			server, err := startGRPCServer(builderOpts, grpcHandler, strategyStore, logger)
+			grpcTLS := builderOpts.GRPCTLS()
+			server, err := startGRPCServer(builderOpts, &grpcTLS, grpcHandler, strategyStore, logger)
 			if err != nil {
 				logger.Fatal("Could not start gRPC collector", zap.Error(err))
 			}
@@ -138,19 +166,21 @@ func main() {
 				r := mux.NewRouter()
 				apiHandler := app.NewAPIHandler(jaegerBatchesHandler)
 				apiHandler.RegisterRoutes(r)
-				httpPortStr := ":" + strconv.Itoa(builderOpts.CollectorHTTPPort)
 				recoveryHandler := recoveryhandler.NewRecoveryHandler(logger, true)
 				httpHandler := recoveryHandler(r)
 
-				go startZipkinHTTPAPI(logger, builderOpts.CollectorZipkinHTTPPort, builderOpts.CollectorZipkinAllowedOrigins, builderOpts.CollectorZipkinAllowedHeaders, zipkinSpansHandler, recoveryHandler)
+				if err := startZipkinHTTPAPI(logger, builderOpts, zipkinSpansHandler, recoveryHandler); err != nil {
+					logger.Fatal("Could not launch Zipkin service", zap.Error(err))
+				}
 
 				logger.Info("Starting jaeger-collector HTTP server", zap.Int("http-port", builderOpts.CollectorHTTPPort))
-				go func() {
-					if err := http.ListenAndServe(httpPortStr, httpHandler); err != nil {
-						logger.Fatal("Could not launch service", zap.Error(err))
-					}
+				_, _, err := httpserver.StartHTTPServer(builderOpts.CollectorHTTPPort, httpHandler, &builderOpts.CollectorHTTPTLS, logger, func(err error) {
 					svc.HC().Set(healthcheck.Unavailable)
-				}()
+					logger.Fatal("Could not launch service", zap.Error(err))
+				})
+				if err != nil {
+					logger.Fatal("Could not launch service", zap.Error(err))
+				}
 			}
 
 			svc.RunAndThen(func() {
@@ -161,6 +191,27 @@ func main() {
 						logger.Error("Failed to close span writer", zap.Error(err))
 					}
 				}
+				if err := handlerBuilder.Close(); err != nil {
+					logger.Error("Failed to close span forwarder", zap.Error(err))
+				}
+				if aggregator != nil {
+					if err := aggregator.Close(); err != nil {
+						logger.Error("Failed to close sampling throughput aggregator", zap.Error(err))
+					}
+				}
+				if closer, ok := strategyStore.(io.Closer); ok {
+					if err := closer.Close(); err != nil {
+						logger.Error("Failed to close sampling strategy store", zap.Error(err))
+					}
+				}
+				for _, tlsOpts := range []*tlscfg.Options{&grpcTLS, &builderOpts.CollectorHTTPTLS, &builderOpts.CollectorZipkinTLS} {
+					if err := tlsOpts.Close(); err != nil {
+						logger.Error("Failed to stop watching TLS certificates", zap.Error(err))
+					}
+				}
+				if err := storageFactory.Close(); err != nil {
+					logger.Error("Failed to close storage factory", zap.Error(err))
+				}
 			})
 			return nil
 		},
@@ -169,6 +220,8 @@ func main() {
 	command.AddCommand(version.Command())
 	command.AddCommand(env.Command())
 	command.AddCommand(docs.Command(v))
+	command.AddCommand(es.NewIndexCommand())
+	command.AddCommand(badger.NewAdminCommand())
 
 	config.AddFlags(
 		v,
@@ -176,7 +229,9 @@ func main() {
 		svc.AddFlags,
 		builder.AddFlags,
 		storageFactory.AddFlags,
+		storageFactory.AddJobFlags,
 		strategyStoreFactory.AddFlags,
+		tailsampling.AddFlags,
 	)
 
 	if err := command.Execute(); err != nil {
@@ -187,17 +242,15 @@ func main() {
 
 func startGRPCServer(
 	opts *builder.CollectorOptions,
+	tlsOpts *tlscfg.Options,
 	handler *app.GRPCHandler,
 	samplingStore strategystore.StrategyStore,
 	logger *zap.Logger,
 ) (*grpc.Server, error) {
 	var server *grpc.Server
 
-	if opts.CollectorGRPCTLS { // user requested a server with TLS, setup creds
-		tlsCfg, err := grpcserver.TLSConfig(
-			opts.CollectorGRPCCert,
-			opts.CollectorGRPCKey,
-			opts.CollectorGRPCClientCA)
+	if tlsOpts.Enabled { // user requested a server with TLS, setup creds
+		tlsCfg, err := tlsOpts.Config(logger)
 		if err != nil {
 			return nil, err
 		}
@@ -218,41 +271,40 @@ func startGRPCServer(
 
 func startZipkinHTTPAPI(
 	logger *zap.Logger,
-	zipkinPort int,
-	allowedOrigins string,
-	allowedHeaders string,
+	opts *builder.CollectorOptions,
 	zipkinSpansHandler app.ZipkinSpansHandler,
 	recoveryHandler func(http.Handler) http.Handler,
-) {
-	if zipkinPort != 0 {
-		zHandler := zipkin.NewAPIHandler(zipkinSpansHandler)
-		r := mux.NewRouter()
-		zHandler.RegisterRoutes(r)
-
-		origins := strings.Split(strings.Replace(allowedOrigins, " ", "", -1), ",")
-		headers := strings.Split(strings.Replace(allowedHeaders, " ", "", -1), ",")
+) error {
+	if opts.CollectorZipkinHTTPPort == 0 {
+		return nil
+	}
+	zHandler := zipkin.NewAPIHandler(zipkinSpansHandler)
+	r := mux.NewRouter()
+	zHandler.RegisterRoutes(r)
 
-		c := cors.New(cors.Options{
-			AllowedOrigins: origins,
-			AllowedMethods: []string{"POST"}, // Allowing only POST, because that's the only handled one
-			AllowedHeaders: headers,
-		})
+	origins := strings.Split(strings.Replace(opts.CollectorZipkinAllowedOrigins, " ", "", -1), ",")
+	headers := strings.Split(strings.Replace(opts.CollectorZipkinAllowedHeaders, " ", "", -1), ",")
 
-		httpPortStr := ":" + strconv.Itoa(zipkinPort)
-		logger.Info("Listening for Zipkin HTTP traffic", zap.Int("zipkin.http-port", zipkinPort))
+	c := cors.New(cors.Options{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{"POST"}, // Allowing only POST, because that's the only handled one
+		AllowedHeaders: headers,
+	})
 
-		if err := http.ListenAndServe(httpPortStr, c.Handler(recoveryHandler(r))); err != nil {
-			logger.Fatal("Could not launch service", zap.Error(err))
-		}
-	}
+	logger.Info("Listening for Zipkin HTTP traffic", zap.Int("zipkin.http-port", opts.CollectorZipkinHTTPPort))
+	_, _, err := httpserver.StartHTTPServer(opts.CollectorZipkinHTTPPort, c.Handler(recoveryHandler(r)), &opts.CollectorZipkinTLS, logger, func(err error) {
+		logger.Fatal("Could not launch service", zap.Error(err))
+	})
+	return err
 }
 
 func initSamplingStrategyStore(
 	samplingStrategyStoreFactory *ss.Factory,
 	metricsFactory metrics.Factory,
+	ssFactory istorage.SamplingStoreFactory,
 	logger *zap.Logger,
 ) strategystore.StrategyStore {
-	if err := samplingStrategyStoreFactory.Initialize(metricsFactory, logger); err != nil {
+	if err := samplingStrategyStoreFactory.Initialize(metricsFactory, ssFactory, logger); err != nil {
 		logger.Fatal("Failed to init sampling strategy store factory", zap.Error(err))
 	}
 	strategyStore, err := samplingStrategyStoreFactory.CreateStrategyStore()
@@ -261,3 +313,14 @@ func initSamplingStrategyStore(
 	}
 	return strategyStore
 }
+
+func initSamplingAggregator(
+	samplingStrategyStoreFactory *ss.Factory,
+	logger *zap.Logger,
+) strategystore.Aggregator {
+	aggregator, err := samplingStrategyStoreFactory.CreateAggregator()
+	if err != nil {
+		logger.Fatal("Failed to create sampling throughput aggregator", zap.Error(err))
+	}
+	return aggregator
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const bearerScheme = "Bearer"
+
+type apiKeysAuthenticator struct {
+	// keys maps an API key to its identity
+	keys map[string]string
+}
+
+// NewAPIKeysAuthenticator returns an Authenticator that accepts static API keys sent as
+// bearer tokens. The file contains one "identity:key" entry per line, lines starting with # are ignored.
+// Every identity must have its own key.
+func NewAPIKeysAuthenticator(path string) (Authenticator, error) {
+	entries, err := readEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	identities := make([]string, 0, len(entries))
+	for identity := range entries {
+		identities = append(identities, identity)
+	}
+	sort.Strings(identities)
+	keys := make(map[string]string, len(entries))
+	for _, identity := range identities {
+		key := entries[identity]
+		if other, ok := keys[key]; ok {
+			return nil, fmt.Errorf("identities %q and %q have the same API key in %s", other, identity, path)
+		}
+		keys[key] = identity
+	}
+	return &apiKeysAuthenticator{keys: keys}, nil
+}
+
+func (a *apiKeysAuthenticator) Authenticate(credentials Credentials) (string, error) {
+	var identity string
+	// compare against every key to not leak which prefix of a key matched through timing
+	for key, id := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(credentials.Token)) == 1 {
+			identity = id
+		}
+	}
+	if identity == "" {
+		return "", ErrUnauthenticated
+	}
+	return identity, nil
+}
+
+func (a *apiKeysAuthenticator) Scheme() string {
+	return bearerScheme
+}
+
+// readEntries reads a file of "identity:secret" lines into a map keyed by identity.
+func readEntries(path string) (map[string]string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid entry at %s:%d, expecting identity:secret", path, lineNum)
+		}
+		if _, ok := entries[parts[0]]; ok {
+			return nil, fmt.Errorf("duplicate identity %q at %s:%d", parts[0], path, lineNum)
+		}
+		entries[parts[0]] = parts[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrUnauthenticated is returned when the client presented no credentials or invalid ones.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden is returned when the authenticated identity is not allowed to access a service.
+	ErrForbidden = errors.New("access to the service is forbidden")
+
+	errUnsupportedScheme = errors.New("unsupported authorization scheme")
+)
+
+// Credentials are the contents of the Authorization header (or gRPC metadata) of a request.
+type Credentials struct {
+	// Scheme is the authorization scheme, e.g. Bearer or Basic
+	Scheme string
+	// Token is the value following the scheme
+	Token string
+}
+
+// ParseCredentials splits the value of an Authorization header into scheme and token.
+func ParseCredentials(authorization string) Credentials {
+	parts := strings.SplitN(strings.TrimSpace(authorization), " ", 2)
+	if len(parts) != 2 {
+		return Credentials{}
+	}
+	return Credentials{Scheme: parts[0], Token: strings.TrimSpace(parts[1])}
+}
+
+// Authenticator verifies the credentials presented by a client.
+type Authenticator interface {
+	// Authenticate returns the identity owning the credentials, or ErrUnauthenticated.
+	Authenticate(credentials Credentials) (string, error)
+	// Scheme returns the authorization scheme understood by this authenticator.
+	Scheme() string
+}
+
+type chainAuthenticator []Authenticator
+
+// NewChainAuthenticator returns an Authenticator that delegates the credentials to the
+// authenticators understanding their scheme, and succeeds as soon as one of them does.
+func NewChainAuthenticator(authenticators ...Authenticator) Authenticator {
+	return chainAuthenticator(authenticators)
+}
+
+func (c chainAuthenticator) Authenticate(credentials Credentials) (string, error) {
+	for _, authenticator := range c {
+		if !strings.EqualFold(authenticator.Scheme(), credentials.Scheme) {
+			continue
+		}
+		if identity, err := authenticator.Authenticate(credentials); err == nil {
+			return identity, nil
+		}
+	}
+	return "", ErrUnauthenticated
+}
+
+// Scheme returns the scheme of the first authenticator.
+func (c chainAuthenticator) Scheme() string {
+	if len(c) == 0 {
+		return ""
+	}
+	return c[0].Scheme()
+}
+
+// schemes returns the distinct schemes understood by the authenticator, in order.
+func schemes(authenticator Authenticator) []string {
+	chain, ok := authenticator.(chainAuthenticator)
+	if !ok {
+		return []string{authenticator.Scheme()}
+	}
+	var ret []string
+	seen := make(map[string]bool)
+	for _, a := range chain {
+		if scheme := a.Scheme(); !seen[scheme] {
+			seen[scheme] = true
+			ret = append(ret, scheme)
+		}
+	}
+	return ret
+}
+
+type identityKeyType string
+
+const identityKey = identityKeyType("identity")
+
+// ContextWithIdentity returns a new context carrying the authenticated identity.
+func ContextWithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// IdentityFromContext returns the authenticated identity carried by the context, if any.
+func IdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(identityKey).(string)
+	return identity, ok
+}
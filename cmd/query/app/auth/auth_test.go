@@ -0,0 +1,147 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticAuthenticator struct {
+	scheme   string
+	token    string
+	identity string
+}
+
+func (a staticAuthenticator) Authenticate(credentials Credentials) (string, error) {
+	if credentials.Token != a.token {
+		return "", ErrUnauthenticated
+	}
+	return a.identity, nil
+}
+
+func (a staticAuthenticator) Scheme() string {
+	return a.scheme
+}
+
+func writeTempFile(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "auth")
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	return f.Name()
+}
+
+func TestParseCredentials(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected Credentials
+	}{
+		{header: "", expected: Credentials{}},
+		{header: "token", expected: Credentials{}},
+		{header: "Bearer abc", expected: Credentials{Scheme: "Bearer", Token: "abc"}},
+		{header: " Basic  dXNlcjpwYXNz ", expected: Credentials{Scheme: "Basic", Token: "dXNlcjpwYXNz"}},
+	}
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, ParseCredentials(test.header), test.header)
+	}
+}
+
+func TestChainAuthenticator(t *testing.T) {
+	chain := NewChainAuthenticator(
+		staticAuthenticator{scheme: "Bearer", token: "key", identity: "api-key"},
+		staticAuthenticator{scheme: "Bearer", token: "jwt", identity: "jwt"},
+		staticAuthenticator{scheme: "Basic", token: "basic", identity: "basic"},
+	)
+	assert.Equal(t, "Bearer", chain.Scheme())
+	assert.Equal(t, []string{"Bearer", "Basic"}, schemes(chain))
+
+	testCases := []struct {
+		credentials Credentials
+		identity    string
+	}{
+		{credentials: Credentials{Scheme: "Bearer", Token: "key"}, identity: "api-key"},
+		{credentials: Credentials{Scheme: "bearer", Token: "jwt"}, identity: "jwt"},
+		{credentials: Credentials{Scheme: "Basic", Token: "basic"}, identity: "basic"},
+		{credentials: Credentials{Scheme: "Basic", Token: "key"}},
+		{credentials: Credentials{Scheme: "Bearer", Token: "invalid"}},
+		{credentials: Credentials{}},
+	}
+	for _, test := range testCases {
+		identity, err := chain.Authenticate(test.credentials)
+		if test.identity == "" {
+			assert.Equal(t, ErrUnauthenticated, err)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, test.identity, identity)
+	}
+
+	assert.Equal(t, "", NewChainAuthenticator().Scheme())
+}
+
+func TestIdentityContext(t *testing.T) {
+	_, ok := IdentityFromContext(context.Background())
+	assert.False(t, ok)
+
+	identity, ok := IdentityFromContext(ContextWithIdentity(context.Background(), "alice"))
+	assert.True(t, ok)
+	assert.Equal(t, "alice", identity)
+}
+
+func TestAPIKeysAuthenticator(t *testing.T) {
+	path := writeTempFile(t, "# API keys\nalice:key-a\n\nbob:key-b\n")
+	defer os.Remove(path)
+
+	a, err := NewAPIKeysAuthenticator(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", a.Scheme())
+
+	identity, err := a.Authenticate(Credentials{Scheme: "Bearer", Token: "key-b"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", identity)
+
+	_, err = a.Authenticate(Credentials{Scheme: "Bearer", Token: "key-c"})
+	assert.Equal(t, ErrUnauthenticated, err)
+}
+
+func TestAPIKeysAuthenticatorErrors(t *testing.T) {
+	_, err := NewAPIKeysAuthenticator("invalid-path")
+	assert.Error(t, err)
+
+	invalid := writeTempFile(t, "alice\n")
+	defer os.Remove(invalid)
+	_, err = NewAPIKeysAuthenticator(invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid entry at")
+
+	duplicate := writeTempFile(t, "alice:a\nalice:b\n")
+	defer os.Remove(duplicate)
+	_, err = NewAPIKeysAuthenticator(duplicate)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `duplicate identity "alice"`)
+
+	sharedKey := writeTempFile(t, "bob:a\nalice:a\n")
+	defer os.Remove(sharedKey)
+	_, err = NewAPIKeysAuthenticator(sharedKey)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `identities "alice" and "bob" have the same API key`)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Rules maps identities to the services they are allowed to see. Services are matched
+// with shell patterns as in path.Match, e.g. "*" allows every service.
+type Rules struct {
+	// Identities maps an identity to the patterns of the services it may access
+	Identities map[string][]string `json:"identities"`
+	// Default lists the patterns of the services for identities missing from Identities,
+	// including anonymous requests when authentication is disabled
+	Default []string `json:"default"`
+}
+
+// Authorizer enforces Rules for the identity carried in the request context.
+type Authorizer struct {
+	rules Rules
+}
+
+// NewAuthorizer creates an Authorizer for the rules.
+func NewAuthorizer(rules Rules) (*Authorizer, error) {
+	validate := func(patterns []string) error {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return errors.Wrapf(err, "invalid service pattern %q", pattern)
+			}
+		}
+		return nil
+	}
+	if err := validate(rules.Default); err != nil {
+		return nil, err
+	}
+	for _, patterns := range rules.Identities {
+		if err := validate(patterns); err != nil {
+			return nil, err
+		}
+	}
+	return &Authorizer{rules: rules}, nil
+}
+
+// NewAuthorizerFromFile creates an Authorizer for the rules in a JSON file.
+func NewAuthorizerFromFile(rulesPath string) (*Authorizer, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(rulesPath))
+	if err != nil {
+		return nil, err
+	}
+	var rules Rules
+	if err := json.Unmarshal(content, &rules); err != nil {
+		return nil, errors.Wrapf(err, "invalid authorization rules file %s", rulesPath)
+	}
+	return NewAuthorizer(rules)
+}
+
+func (a *Authorizer) patterns(ctx context.Context) []string {
+	if identity, ok := IdentityFromContext(ctx); ok {
+		if patterns, ok := a.rules.Identities[identity]; ok {
+			return patterns
+		}
+	}
+	return a.rules.Default
+}
+
+func matchAny(patterns []string, service string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, service); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowService returns whether the identity in the context may access the service.
+func (a *Authorizer) AllowService(ctx context.Context, service string) bool {
+	return matchAny(a.patterns(ctx), service)
+}
+
+// FilterServices returns the services the identity in the context may access.
+func (a *Authorizer) FilterServices(ctx context.Context, services []string) []string {
+	patterns := a.patterns(ctx)
+	allowed := make([]string, 0, len(services))
+	for _, service := range services {
+		if matchAny(patterns, service) {
+			allowed = append(allowed, service)
+		}
+	}
+	return allowed
+}
+
+// FilterTrace returns the part of the trace the identity in the context may see, made of the
+// spans and processes of the allowed services, or nil if none of its spans may be seen.
+func (a *Authorizer) FilterTrace(ctx context.Context, trace *model.Trace) *model.Trace {
+	return filterTrace(a.patterns(ctx), trace)
+}
+
+func filterTrace(patterns []string, trace *model.Trace) *model.Trace {
+	filtered := &model.Trace{Warnings: trace.Warnings}
+	for _, span := range trace.Spans {
+		if span.Process != nil && matchAny(patterns, span.Process.ServiceName) {
+			filtered.Spans = append(filtered.Spans, span)
+		}
+	}
+	if len(filtered.Spans) == 0 {
+		return nil
+	}
+	for _, mapping := range trace.ProcessMap {
+		if matchAny(patterns, mapping.Process.ServiceName) {
+			filtered.ProcessMap = append(filtered.ProcessMap, mapping)
+		}
+	}
+	if len(filtered.Spans) == len(trace.Spans) && len(filtered.ProcessMap) == len(trace.ProcessMap) {
+		return trace
+	}
+	return filtered
+}
+
+// FilterTraces returns the parts of the traces the identity in the context may see, see FilterTrace.
+func (a *Authorizer) FilterTraces(ctx context.Context, traces []*model.Trace) []*model.Trace {
+	patterns := a.patterns(ctx)
+	allowed := make([]*model.Trace, 0, len(traces))
+	for _, trace := range traces {
+		if filtered := filterTrace(patterns, trace); filtered != nil {
+			allowed = append(allowed, filtered)
+		}
+	}
+	return allowed
+}
+
+// FilterDependencies returns the dependency links the identity in the context may see,
+// which are those where both the parent and the child are allowed services.
+func (a *Authorizer) FilterDependencies(ctx context.Context, links []model.DependencyLink) []model.DependencyLink {
+	patterns := a.patterns(ctx)
+	allowed := make([]model.DependencyLink, 0, len(links))
+	for _, link := range links {
+		if matchAny(patterns, link.Parent) && matchAny(patterns, link.Child) {
+			allowed = append(allowed, link)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func newTestAuthorizer(t *testing.T) *Authorizer {
+	a, err := NewAuthorizer(Rules{
+		Identities: map[string][]string{
+			"alice": {"frontend", "billing-*"},
+			"admin": {"*"},
+		},
+		Default: []string{"public"},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+func traceOf(services ...string) *model.Trace {
+	trace := &model.Trace{}
+	for _, service := range services {
+		trace.Spans = append(trace.Spans, &model.Span{Process: model.NewProcess(service, nil)})
+	}
+	return trace
+}
+
+func TestAuthorizer(t *testing.T) {
+	a := newTestAuthorizer(t)
+	alice := ContextWithIdentity(context.Background(), "alice")
+	admin := ContextWithIdentity(context.Background(), "admin")
+	unknown := ContextWithIdentity(context.Background(), "bob")
+	anonymous := context.Background()
+
+	assert.True(t, a.AllowService(alice, "frontend"))
+	assert.True(t, a.AllowService(alice, "billing-api"))
+	assert.False(t, a.AllowService(alice, "driver"))
+	assert.False(t, a.AllowService(alice, "public"))
+	assert.True(t, a.AllowService(admin, "driver"))
+	assert.True(t, a.AllowService(unknown, "public"))
+	assert.False(t, a.AllowService(unknown, "frontend"))
+	assert.True(t, a.AllowService(anonymous, "public"))
+
+	services := []string{"billing-api", "driver", "frontend", "public"}
+	assert.Equal(t, []string{"billing-api", "frontend"}, a.FilterServices(alice, services))
+	assert.Equal(t, services, a.FilterServices(admin, services))
+	assert.Equal(t, []string{"public"}, a.FilterServices(anonymous, services))
+
+	shared := traceOf("driver", "frontend")
+	shared.ProcessMap = []model.Trace_ProcessMapping{
+		{ProcessID: "p1", Process: *shared.Spans[0].Process},
+		{ProcessID: "p2", Process: *shared.Spans[1].Process},
+	}
+	shared.Warnings = []string{"clock skew"}
+	private := traceOf("driver", "redis")
+	owned := traceOf("frontend", "billing-api")
+	expected := &model.Trace{
+		Spans:      shared.Spans[1:],
+		ProcessMap: shared.ProcessMap[1:],
+		Warnings:   shared.Warnings,
+	}
+	assert.Equal(t, expected, a.FilterTrace(alice, shared), "spans and processes of other services are removed")
+	assert.Equal(t, shared, a.FilterTrace(admin, shared))
+	assert.True(t, owned == a.FilterTrace(alice, owned), "whole traces are returned as is")
+	assert.Nil(t, a.FilterTrace(alice, private))
+	assert.Nil(t, a.FilterTrace(alice, &model.Trace{Spans: []*model.Span{{}}}))
+	assert.Equal(t, []*model.Trace{expected, owned}, a.FilterTraces(alice, []*model.Trace{shared, private, owned}))
+
+	links := []model.DependencyLink{
+		{Parent: "frontend", Child: "driver"},
+		{Parent: "driver", Child: "redis"},
+		{Parent: "frontend", Child: "billing-api"},
+	}
+	assert.Equal(t, []model.DependencyLink{links[2]}, a.FilterDependencies(alice, links))
+	assert.Equal(t, links, a.FilterDependencies(admin, links))
+	assert.Empty(t, a.FilterDependencies(unknown, links))
+}
+
+func TestAuthorizerInvalidPattern(t *testing.T) {
+	_, err := NewAuthorizer(Rules{Default: []string{"["}})
+	assert.EqualError(t, err, `invalid service pattern "[": syntax error in pattern`)
+
+	_, err = NewAuthorizer(Rules{Identities: map[string][]string{"alice": {"["}}})
+	assert.EqualError(t, err, `invalid service pattern "[": syntax error in pattern`)
+}
+
+func TestAuthorizerFromFile(t *testing.T) {
+	path := writeTempFile(t, `{"identities": {"alice": ["frontend"]}, "default": ["public"]}`)
+	defer os.Remove(path)
+	a, err := NewAuthorizerFromFile(path)
+	require.NoError(t, err)
+	assert.True(t, a.AllowService(ContextWithIdentity(context.Background(), "alice"), "frontend"))
+	assert.True(t, a.AllowService(context.Background(), "public"))
+
+	_, err = NewAuthorizerFromFile("invalid-path")
+	assert.Error(t, err)
+
+	invalid := writeTempFile(t, `{"identities": []}`)
+	defer os.Remove(invalid)
+	_, err = NewAuthorizerFromFile(invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid authorization rules file")
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/sha1" // #nosec, {SHA} is the weakest of the hashes supported by htpasswd
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	basicScheme = "Basic"
+	shaPrefix   = "{SHA}"
+)
+
+type htpasswdAuthenticator struct {
+	// hashes maps a user name to its password hash
+	hashes map[string]string
+}
+
+// NewHtpasswdAuthenticator returns an Authenticator for HTTP basic auth with the users of
+// an htpasswd file. Only bcrypt (htpasswd -B) and SHA1 (htpasswd -s) hashes are supported.
+func NewHtpasswdAuthenticator(path string) (Authenticator, error) {
+	hashes, err := readEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	for user, hash := range hashes {
+		if !isBcrypt(hash) && !strings.HasPrefix(hash, shaPrefix) {
+			return nil, fmt.Errorf("unsupported password hash for user %q in %s, use bcrypt or SHA1", user, path)
+		}
+	}
+	return &htpasswdAuthenticator{hashes: hashes}, nil
+}
+
+func (a *htpasswdAuthenticator) Authenticate(credentials Credentials) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(credentials.Token)
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return "", ErrUnauthenticated
+	}
+	user, password := parts[0], parts[1]
+	hash, ok := a.hashes[user]
+	if !ok || !checkPassword(hash, password) {
+		return "", ErrUnauthenticated
+	}
+	return user, nil
+}
+
+func (a *htpasswdAuthenticator) Scheme() string {
+	return basicScheme
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func checkPassword(hash, password string) bool {
+	if isBcrypt(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+	sum := sha1.Sum([]byte(password)) // #nosec
+	expected := shaPrefix + base64.StdEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) == 1
+}
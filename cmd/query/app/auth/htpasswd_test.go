@@ -0,0 +1,75 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func basicCredentials(user, password string) Credentials {
+	return Credentials{Scheme: "Basic", Token: base64.StdEncoding.EncodeToString([]byte(user + ":" + password))}
+}
+
+func TestHtpasswdAuthenticator(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	// generated with htpasswd -nbs bob password
+	path := writeTempFile(t, "alice:"+string(hash)+"\nbob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n")
+	defer os.Remove(path)
+
+	a, err := NewHtpasswdAuthenticator(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Basic", a.Scheme())
+
+	testCases := []struct {
+		credentials Credentials
+		identity    string
+	}{
+		{credentials: basicCredentials("alice", "secret"), identity: "alice"},
+		{credentials: basicCredentials("bob", "password"), identity: "bob"},
+		{credentials: basicCredentials("alice", "password")},
+		{credentials: basicCredentials("bob", "secret")},
+		{credentials: basicCredentials("carol", "secret")},
+		{credentials: Credentials{Scheme: "Basic", Token: base64.StdEncoding.EncodeToString([]byte("alice"))}},
+		{credentials: Credentials{Scheme: "Basic", Token: "not base64"}},
+	}
+	for _, test := range testCases {
+		identity, err := a.Authenticate(test.credentials)
+		if test.identity == "" {
+			assert.Equal(t, ErrUnauthenticated, err)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, test.identity, identity)
+	}
+}
+
+func TestHtpasswdAuthenticatorErrors(t *testing.T) {
+	_, err := NewHtpasswdAuthenticator("invalid-path")
+	assert.Error(t, err)
+
+	// MD5 hash generated with htpasswd -nbm alice secret
+	path := writeTempFile(t, "alice:$apr1$6FDgtz7J$zL2bPjV3zH6ugvwDs4XFH1\n")
+	defer os.Remove(path)
+	_, err = NewHtpasswdAuthenticator(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported password hash for user "alice"`)
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// JWTOptions describes how bearer tokens issued by an identity provider are validated.
+type JWTOptions struct {
+	// JWKSPath is the path to a JSON Web Key Set file with the keys used to sign the tokens
+	JWKSPath string
+	// Issuer, if set, must match the iss claim of the tokens
+	Issuer string
+	// Audience, if set, must be one of the aud claims of the tokens
+	Audience string
+	// IdentityClaim is the claim holding the identity, sub by default
+	IdentityClaim string
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtAlgorithm struct {
+	hash crypto.Hash
+	kty  string
+	// keySize is the size in bytes of each of r and s in ECDSA signatures
+	keySize int
+}
+
+var jwtAlgorithms = map[string]jwtAlgorithm{
+	"RS256": {hash: crypto.SHA256, kty: "RSA"},
+	"RS384": {hash: crypto.SHA384, kty: "RSA"},
+	"RS512": {hash: crypto.SHA512, kty: "RSA"},
+	"ES256": {hash: crypto.SHA256, kty: "EC", keySize: 32},
+	"ES384": {hash: crypto.SHA384, kty: "EC", keySize: 48},
+	"ES512": {hash: crypto.SHA512, kty: "EC", keySize: 66},
+}
+
+type jwtAuthenticator struct {
+	options JWTOptions
+	// keys maps a key ID to an *rsa.PublicKey or *ecdsa.PublicKey
+	keys    map[string]crypto.PublicKey
+	timeNow func() time.Time
+}
+
+// NewJWTAuthenticator returns an Authenticator for bearer JSON Web Tokens signed with
+// one of the RSA or EC keys of a JWKS file.
+func NewJWTAuthenticator(options JWTOptions) (Authenticator, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(options.JWKSPath))
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJWKS(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid JWKS file %s", options.JWKSPath)
+	}
+	if options.IdentityClaim == "" {
+		options.IdentityClaim = "sub"
+	}
+	return &jwtAuthenticator{
+		options: options,
+		keys:    keys,
+		timeNow: time.Now,
+	}, nil
+}
+
+func parseJWKS(content []byte) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %q", jwk.Kid)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys found")
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (a *jwtAuthenticator) Authenticate(credentials Credentials) (string, error) {
+	claims, err := a.verify(credentials.Token)
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+	identity, ok := claims[a.options.IdentityClaim].(string)
+	if !ok || identity == "" {
+		return "", ErrUnauthenticated
+	}
+	return identity, nil
+}
+
+func (a *jwtAuthenticator) Scheme() string {
+	return bearerScheme
+}
+
+// verify checks the signature and the registered claims of the token and returns all its claims.
+func (a *jwtAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	alg, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+	key, err := a.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	h := alg.hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(alg, key, h.Sum(nil), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return claims, a.validateClaims(claims)
+}
+
+func (a *jwtAuthenticator) key(kid string) (crypto.PublicKey, error) {
+	if key, ok := a.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func verifySignature(alg jwtAlgorithm, key crypto.PublicKey, digest, signature []byte) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg.kty != "RSA" {
+			return errors.New("algorithm does not match the key type")
+		}
+		return rsa.VerifyPKCS1v15(k, alg.hash, digest, signature)
+	case *ecdsa.PublicKey:
+		if alg.kty != "EC" || len(signature) != 2*alg.keySize {
+			return errors.New("algorithm does not match the key type")
+		}
+		r := new(big.Int).SetBytes(signature[:alg.keySize])
+		s := new(big.Int).SetBytes(signature[alg.keySize:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return errors.New("unsupported key")
+}
+
+func (a *jwtAuthenticator) validateClaims(claims map[string]interface{}) error {
+	now := a.timeNow()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token has no expiration time")
+	}
+	if !now.Before(time.Unix(int64(exp), 0)) {
+		return errors.New("token has expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token is not valid yet")
+	}
+	if a.options.Issuer != "" && claims["iss"] != a.options.Issuer {
+		return errors.New("unexpected issuer")
+	}
+	if a.options.Audience != "" && !hasAudience(claims["aud"], a.options.Audience) {
+		return errors.New("unexpected audience")
+	}
+	return nil
+}
+
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
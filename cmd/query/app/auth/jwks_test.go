@@ -0,0 +1,219 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSigner struct {
+	kid  string
+	alg  string
+	rsa  *rsa.PrivateKey
+	ecds *ecdsa.PrivateKey
+}
+
+func newRSASigner(t *testing.T, kid string) *testSigner {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &testSigner{kid: kid, alg: "RS256", rsa: key}
+}
+
+func newECSigner(t *testing.T, kid string) *testSigner {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &testSigner{kid: kid, alg: "ES256", ecds: key}
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func (s *testSigner) jwk() jsonWebKey {
+	if s.rsa != nil {
+		return jsonWebKey{
+			Kty: "RSA",
+			Kid: s.kid,
+			Use: "sig",
+			N:   encodeBigInt(s.rsa.N),
+			E:   encodeBigInt(big.NewInt(int64(s.rsa.E))),
+		}
+	}
+	return jsonWebKey{
+		Kty: "EC",
+		Kid: s.kid,
+		Crv: "P-256",
+		X:   encodeBigInt(s.ecds.X),
+		Y:   encodeBigInt(s.ecds.Y),
+	}
+}
+
+func (s *testSigner) sign(t *testing.T, claims map[string]interface{}) string {
+	header, err := json.Marshal(jwtHeader{Alg: s.alg, Kid: s.kid})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+
+	var signature []byte
+	if s.rsa != nil {
+		signature, err = rsa.SignPKCS1v15(rand.Reader, s.rsa, crypto.SHA256, digest.Sum(nil))
+		require.NoError(t, err)
+	} else {
+		r, ss, err := ecdsa.Sign(rand.Reader, s.ecds, digest.Sum(nil))
+		require.NoError(t, err)
+		// r and s are left-padded to the key size
+		signature = make([]byte, 64)
+		rBytes, sBytes := r.Bytes(), ss.Bytes()
+		copy(signature[32-len(rBytes):32], rBytes)
+		copy(signature[64-len(sBytes):], sBytes)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJWKS(t *testing.T, signers ...*testSigner) string {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for _, s := range signers {
+		jwks.Keys = append(jwks.Keys, s.jwk())
+	}
+	content, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	return writeTempFile(t, string(content))
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaSigner := newRSASigner(t, "rsa")
+	ecSigner := newECSigner(t, "ec")
+	path := writeJWKS(t, rsaSigner, ecSigner)
+	defer os.Remove(path)
+
+	a, err := NewJWTAuthenticator(JWTOptions{JWKSPath: path, Issuer: "idp", Audience: "jaeger"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", a.Scheme())
+	now := time.Unix(1500000000, 0)
+	a.(*jwtAuthenticator).timeNow = func() time.Time { return now }
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "alice",
+			"iss": "idp",
+			"aud": []string{"other", "jaeger"},
+			"exp": now.Add(time.Minute).Unix(),
+			"nbf": now.Add(-time.Minute).Unix(),
+		}
+	}
+	withClaim := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	otherSigner := newRSASigner(t, "rsa")
+	unknownKeySigner := newECSigner(t, "unknown")
+	mismatchedSigner := &testSigner{kid: "ec", alg: "RS256", rsa: rsaSigner.rsa}
+
+	testCases := []struct {
+		name     string
+		token    string
+		identity string
+	}{
+		{name: "RSA", token: rsaSigner.sign(t, validClaims()), identity: "alice"},
+		{name: "EC", token: ecSigner.sign(t, validClaims()), identity: "alice"},
+		{name: "string audience", token: rsaSigner.sign(t, withClaim("aud", "jaeger")), identity: "alice"},
+		{name: "no exp", token: rsaSigner.sign(t, withClaim("exp", nil))},
+		{name: "expired", token: rsaSigner.sign(t, withClaim("exp", now.Unix()))},
+		{name: "not valid yet", token: rsaSigner.sign(t, withClaim("nbf", now.Add(time.Second).Unix()))},
+		{name: "wrong issuer", token: rsaSigner.sign(t, withClaim("iss", "other"))},
+		{name: "wrong audience", token: rsaSigner.sign(t, withClaim("aud", "other"))},
+		{name: "missing identity", token: rsaSigner.sign(t, withClaim("sub", nil))},
+		{name: "wrong signature", token: otherSigner.sign(t, validClaims())},
+		{name: "unknown key", token: unknownKeySigner.sign(t, validClaims())},
+		{name: "algorithm mismatch", token: mismatchedSigner.sign(t, validClaims())},
+		{name: "malformed", token: "abc.def"},
+		{name: "invalid header", token: "abc.def.ghi"},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			identity, err := a.Authenticate(Credentials{Scheme: "Bearer", Token: test.token})
+			if test.identity == "" {
+				assert.Equal(t, ErrUnauthenticated, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.identity, identity)
+		})
+	}
+}
+
+func TestJWTAuthenticatorSingleKeyWithoutKid(t *testing.T) {
+	signer := newECSigner(t, "")
+	path := writeJWKS(t, signer)
+	defer os.Remove(path)
+
+	a, err := NewJWTAuthenticator(JWTOptions{JWKSPath: path, IdentityClaim: "email"})
+	require.NoError(t, err)
+	identity, err := a.Authenticate(Credentials{Scheme: "Bearer", Token: signer.sign(t, map[string]interface{}{
+		"sub":   "123",
+		"email": "alice@example.com",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	})})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", identity)
+}
+
+func TestJWTAuthenticatorErrors(t *testing.T) {
+	_, err := NewJWTAuthenticator(JWTOptions{JWKSPath: "invalid-path"})
+	assert.Error(t, err)
+
+	testCases := []struct {
+		jwks string
+		err  string
+	}{
+		{jwks: "{", err: "unexpected end of JSON input"},
+		{jwks: `{"keys": []}`, err: "no signing keys found"},
+		{jwks: `{"keys": [{"kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`, err: "no signing keys found"},
+		{jwks: `{"keys": [{"kty": "oct", "kid": "k"}]}`, err: `invalid key "k": unsupported key type "oct"`},
+		{jwks: `{"keys": [{"kty": "EC", "kid": "k", "crv": "P-192"}]}`, err: `invalid key "k": unsupported curve "P-192"`},
+		{jwks: `{"keys": [{"kty": "EC", "kid": "k", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`, err: `invalid key "k": point is not on the curve`},
+		{jwks: `{"keys": [{"kty": "RSA", "kid": "k", "n": "!", "e": "AQAB"}]}`, err: `invalid key "k": illegal base64 data`},
+	}
+	for _, test := range testCases {
+		path := writeTempFile(t, test.jwks)
+		_, err := NewJWTAuthenticator(JWTOptions{JWKSPath: path})
+		os.Remove(path)
+		require.Error(t, err, test.jwks)
+		assert.Contains(t, err.Error(), test.err)
+	}
+}
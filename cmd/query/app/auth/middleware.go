@@ -0,0 +1,101 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationMetadataKey = "authorization"
+
+// HTTPHandler returns a handler that authenticates requests using the Authorization header
+// and passes the identity to h in the request context. Other requests are rejected with 401.
+func HTTPHandler(authenticator Authenticator, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := authenticator.Authenticate(ParseCredentials(r.Header.Get("Authorization")))
+		if err != nil {
+			for _, scheme := range schemes(authenticator) {
+				w.Header().Add("WWW-Authenticate", fmt.Sprintf("%s realm=%q", scheme, "jaeger"))
+			}
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(ContextWithIdentity(r.Context(), identity)))
+	})
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor that authenticates calls using the
+// authorization metadata, if authenticator is not nil, and reports ErrForbidden as PermissionDenied.
+func UnaryServerInterceptor(authenticator Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, authenticator)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		return resp, toStatusError(err)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor(authenticator Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), authenticator)
+		if err != nil {
+			return err
+		}
+		return toStatusError(handler(srv, &serverStream{ServerStream: stream, ctx: ctx}))
+	}
+}
+
+func authenticate(ctx context.Context, authenticator Authenticator) (context.Context, error) {
+	if authenticator == nil {
+		return ctx, nil
+	}
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationMetadataKey); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	identity, err := authenticator.Authenticate(ParseCredentials(authorization))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return ContextWithIdentity(ctx, identity), nil
+}
+
+func toStatusError(err error) error {
+	if err == ErrForbidden {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return err
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testAuthenticator = NewChainAuthenticator(
+	staticAuthenticator{scheme: "Bearer", token: "token", identity: "alice"},
+	staticAuthenticator{scheme: "Basic", token: "basic", identity: "bob"},
+)
+
+func TestHTTPHandler(t *testing.T) {
+	handler := HTTPHandler(testAuthenticator, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := IdentityFromContext(r.Context())
+		w.Write([]byte(identity))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/services", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "alice", rec.Body.String())
+
+	for _, header := range []string{"", "Bearer invalid", "Basic token"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/services", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, header)
+		assert.Equal(t, []string{`Bearer realm="jaeger"`, `Basic realm="jaeger"`}, rec.Header()["Www-Authenticate"])
+	}
+}
+
+func incomingContext(authorization string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationMetadataKey, authorization))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	handlerErr := errors.New("handler error")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, _ := IdentityFromContext(ctx)
+		switch req {
+		case "forbidden":
+			return nil, ErrForbidden
+		case "error":
+			return nil, handlerErr
+		}
+		return identity, nil
+	}
+	interceptor := UnaryServerInterceptor(testAuthenticator)
+
+	resp, err := interceptor(incomingContext("Bearer token"), "ok", &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", resp)
+
+	_, err = interceptor(incomingContext("Bearer token"), "forbidden", &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = interceptor(incomingContext("Bearer token"), "error", &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, handlerErr, err)
+
+	_, err = interceptor(incomingContext("Bearer invalid"), "ok", &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = interceptor(context.Background(), "ok", &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// without authenticator, only errors are translated
+	resp, err = UnaryServerInterceptor(nil)(context.Background(), "ok", &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "", resp)
+	_, err = UnaryServerInterceptor(nil)(context.Background(), "forbidden", &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var identity string
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		identity, _ = IdentityFromContext(stream.Context())
+		if srv == "forbidden" {
+			return ErrForbidden
+		}
+		return nil
+	}
+	interceptor := StreamServerInterceptor(testAuthenticator)
+
+	err := interceptor("ok", testServerStream{ctx: incomingContext("Basic basic")}, &grpc.StreamServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", identity)
+
+	err = interceptor("forbidden", testServerStream{ctx: incomingContext("Basic basic")}, &grpc.StreamServerInfo{}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = interceptor("ok", testServerStream{ctx: incomingContext("Basic invalid")}, &grpc.StreamServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
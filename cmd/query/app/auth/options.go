@@ -0,0 +1,110 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	authAPIKeysFile      = "query.auth.api-keys-file"
+	authHtpasswdFile     = "query.auth.htpasswd-file"
+	authJWKSFile         = "query.auth.jwks-file"
+	authJWTIssuer        = "query.auth.jwt-issuer"
+	authJWTAudience      = "query.auth.jwt-audience"
+	authJWTIdentityClaim = "query.auth.jwt-identity-claim"
+	authRulesFile        = "query.auth.rules-file"
+)
+
+// Options holds the configuration of authentication and authorization in the query service
+type Options struct {
+	// APIKeysFile is the path to a file of identity:key API keys
+	APIKeysFile string
+	// HtpasswdFile is the path to an htpasswd file for basic auth
+	HtpasswdFile string
+	// JWT configures the validation of JSON Web Tokens, enabled when JWT.JWKSPath is set
+	JWT JWTOptions
+	// RulesFile is the path to a JSON file with the authorization Rules
+	RulesFile string
+}
+
+// AddFlags adds flags for Options
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(authAPIKeysFile, "", "The path to a file with one identity:key line per API key accepted as bearer token")
+	flagSet.String(authHtpasswdFile, "", "The path to an htpasswd file with bcrypt or SHA1 hashes, enables basic auth")
+	flagSet.String(authJWKSFile, "", "The path to a JSON Web Key Set file, enables bearer JSON Web Tokens signed with its keys")
+	flagSet.String(authJWTIssuer, "", "If set, the issuer (iss claim) required in JSON Web Tokens")
+	flagSet.String(authJWTAudience, "", "If set, the audience (aud claim) required in JSON Web Tokens")
+	flagSet.String(authJWTIdentityClaim, "sub", "The claim of JSON Web Tokens holding the identity")
+	flagSet.String(authRulesFile, "", "The path to a JSON file mapping identities to the services they are allowed to see, e.g. {\"identities\": {\"alice\": [\"frontend\", \"billing-*\"]}, \"default\": []}")
+}
+
+// InitFromViper initializes Options with properties from viper
+func (o *Options) InitFromViper(v *viper.Viper) *Options {
+	o.APIKeysFile = v.GetString(authAPIKeysFile)
+	o.HtpasswdFile = v.GetString(authHtpasswdFile)
+	o.JWT = JWTOptions{
+		JWKSPath:      v.GetString(authJWKSFile),
+		Issuer:        v.GetString(authJWTIssuer),
+		Audience:      v.GetString(authJWTAudience),
+		IdentityClaim: v.GetString(authJWTIdentityClaim),
+	}
+	o.RulesFile = v.GetString(authRulesFile)
+	return o
+}
+
+// NewAuthenticator creates the Authenticator for all configured methods, or returns nil if none is configured.
+func (o *Options) NewAuthenticator() (Authenticator, error) {
+	var authenticators []Authenticator
+	if o.APIKeysFile != "" {
+		a, err := NewAPIKeysAuthenticator(o.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if o.JWT.JWKSPath != "" {
+		a, err := NewJWTAuthenticator(o.JWT)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if o.HtpasswdFile != "" {
+		a, err := NewHtpasswdAuthenticator(o.HtpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	switch len(authenticators) {
+	case 0:
+		return nil, nil
+	case 1:
+		return authenticators[0], nil
+	default:
+		return NewChainAuthenticator(authenticators...), nil
+	}
+}
+
+// NewAuthorizer creates the Authorizer for the rules file, or returns nil if it is not configured.
+func (o *Options) NewAuthorizer() (*Authorizer, error) {
+	if o.RulesFile == "" {
+		return nil, nil
+	}
+	return NewAuthorizerFromFile(o.RulesFile)
+}
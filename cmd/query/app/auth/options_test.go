@@ -0,0 +1,103 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--query.auth.api-keys-file=keys",
+		"--query.auth.htpasswd-file=htpasswd",
+		"--query.auth.jwks-file=jwks.json",
+		"--query.auth.jwt-issuer=idp",
+		"--query.auth.jwt-audience=jaeger",
+		"--query.auth.rules-file=rules.json",
+	})
+	opts := new(Options).InitFromViper(v)
+	assert.Equal(t, &Options{
+		APIKeysFile:  "keys",
+		HtpasswdFile: "htpasswd",
+		JWT: JWTOptions{
+			JWKSPath:      "jwks.json",
+			Issuer:        "idp",
+			Audience:      "jaeger",
+			IdentityClaim: "sub",
+		},
+		RulesFile: "rules.json",
+	}, opts)
+}
+
+func TestOptionsNewAuthenticator(t *testing.T) {
+	keys := writeTempFile(t, "alice:key\n")
+	defer os.Remove(keys)
+	htpasswd := writeTempFile(t, "bob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n")
+	defer os.Remove(htpasswd)
+	signer := newECSigner(t, "ec")
+	jwks := writeJWKS(t, signer)
+	defer os.Remove(jwks)
+
+	a, err := (&Options{}).NewAuthenticator()
+	require.NoError(t, err)
+	assert.Nil(t, a)
+
+	a, err = (&Options{APIKeysFile: keys}).NewAuthenticator()
+	require.NoError(t, err)
+	assert.IsType(t, &apiKeysAuthenticator{}, a)
+
+	a, err = (&Options{APIKeysFile: keys, HtpasswdFile: htpasswd, JWT: JWTOptions{JWKSPath: jwks}}).NewAuthenticator()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer", "Basic"}, schemes(a))
+	token := signer.sign(t, map[string]interface{}{"sub": "carol", "exp": time.Now().Add(time.Minute).Unix()})
+	for credentials, expected := range map[Credentials]string{
+		{Scheme: "Bearer", Token: "key"}:    "alice",
+		basicCredentials("bob", "password"): "bob",
+		{Scheme: "Bearer", Token: token}:    "carol",
+	} {
+		identity, err := a.Authenticate(credentials)
+		require.NoError(t, err)
+		assert.Equal(t, expected, identity)
+	}
+
+	for _, opts := range []*Options{
+		{APIKeysFile: "invalid-path"},
+		{HtpasswdFile: "invalid-path"},
+		{JWT: JWTOptions{JWKSPath: "invalid-path"}},
+	} {
+		_, err := opts.NewAuthenticator()
+		assert.Error(t, err)
+	}
+}
+
+func TestOptionsNewAuthorizer(t *testing.T) {
+	a, err := (&Options{}).NewAuthorizer()
+	require.NoError(t, err)
+	assert.Nil(t, a)
+
+	rules := writeTempFile(t, `{"default": ["*"]}`)
+	defer os.Remove(rules)
+	a, err = (&Options{RulesFile: rules}).NewAuthorizer()
+	require.NoError(t, err)
+	assert.NotNil(t, a)
+}
@@ -20,6 +20,8 @@ import (
 
 	"github.com/spf13/viper"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/auth"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/ports"
 )
 
@@ -31,6 +33,10 @@ const (
 	queryTokenPropagation = "query.bearer-token-propagation"
 )
 
+var tlsFlagsConfig = tlscfg.ServerFlagsConfig{
+	Prefix: "query",
+}
+
 // QueryOptions holds configuration for query service
 type QueryOptions struct {
 	// Port is the port that the query service listens in on
@@ -43,6 +49,10 @@ type QueryOptions struct {
 	UIConfig string
 	// BearerTokenPropagation activate/deactivate bearer token propagation to storage
 	BearerTokenPropagation bool
+	// TLS configures secure transport for both the HTTP and gRPC endpoints
+	TLS tlscfg.Options
+	// Auth configures authentication and per-service authorization of API requests
+	Auth auth.Options
 }
 
 // AddFlags adds flags for QueryOptions
@@ -52,7 +62,8 @@ func AddFlags(flagSet *flag.FlagSet) {
 	flagSet.String(queryStaticFiles, "", "The directory path override for the static assets for the UI")
 	flagSet.String(queryUIConfig, "", "The path to the UI configuration file in JSON format")
 	flagSet.Bool(queryTokenPropagation, false, "Allow propagation of bearer token to be used by storage plugins")
-
+	tlsFlagsConfig.AddFlags(flagSet)
+	auth.AddFlags(flagSet)
 }
 
 // InitFromViper initializes QueryOptions with properties from viper
@@ -62,5 +73,7 @@ func (qOpts *QueryOptions) InitFromViper(v *viper.Viper) *QueryOptions {
 	qOpts.StaticAssets = v.GetString(queryStaticFiles)
 	qOpts.UIConfig = v.GetString(queryUIConfig)
 	qOpts.BearerTokenPropagation = v.GetBool(queryTokenPropagation)
+	qOpts.TLS = tlsFlagsConfig.InitFromViper(v)
+	qOpts.Auth.InitFromViper(v)
 	return qOpts
 }
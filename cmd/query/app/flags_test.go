@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 )
 
 func TestQueryBuilderFlags(t *testing.T) {
@@ -30,10 +31,22 @@ func TestQueryBuilderFlags(t *testing.T) {
 		"--query.ui-config=some.json",
 		"--query.base-path=/jaeger",
 		"--query.port=80",
+		"--query.tls.enabled=true",
+		"--query.tls.cert=cert.pem",
+		"--query.tls.key=key.pem",
+		"--query.tls.client-ca=ca.pem",
+		"--query.tls.min-version=1.3",
 	})
 	qOpts := new(QueryOptions).InitFromViper(v)
 	assert.Equal(t, "/dev/null", qOpts.StaticAssets)
 	assert.Equal(t, "some.json", qOpts.UIConfig)
 	assert.Equal(t, "/jaeger", qOpts.BasePath)
 	assert.Equal(t, 80, qOpts.Port)
+	assert.Equal(t, tlscfg.Options{
+		Enabled:      true,
+		CertPath:     "cert.pem",
+		KeyPath:      "key.pem",
+		ClientCAPath: "ca.pem",
+		MinVersion:   "1.3",
+	}, qOpts.TLS)
 }
@@ -18,7 +18,10 @@ import (
 	"context"
 
 	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
@@ -88,8 +91,19 @@ func (g *GRPCHandler) FindTraces(r *api_v2.FindTracesRequest, stream api_v2.Quer
 		DurationMax:   query.DurationMax,
 		NumTraces:     int(query.SearchDepth),
 	}
+	for _, f := range query.TagFilters {
+		// the values of TagFilter_Operator follow the order of spanstore.TagOperator
+		queryParams.TagFilters = append(queryParams.TagFilters, spanstore.TagFilter{
+			Key:      f.Key,
+			Operator: spanstore.TagOperator(f.Operator),
+			Value:    f.Value,
+		})
+	}
 	traces, err := g.queryService.FindTraces(stream.Context(), &queryParams)
 	if err != nil {
+		if errors.Cause(err) == spanstore.ErrUnsupportedTagOperator {
+			return status.Error(codes.Unimplemented, err.Error())
+		}
 		g.logger.Error("Error fetching traces", zap.Error(err))
 		return err
 	}
@@ -139,11 +153,37 @@ func (g *GRPCHandler) GetOperations(ctx context.Context, r *api_v2.GetOperations
 	return &api_v2.GetOperationsResponse{Operations: operations}, nil
 }
 
+// GetTagKeys is the GRPC handler to fetch the tag keys of a service.
+func (g *GRPCHandler) GetTagKeys(ctx context.Context, r *api_v2.GetTagKeysRequest) (*api_v2.GetTagKeysResponse, error) {
+	keys, err := g.queryService.GetTagKeys(ctx, r.Service)
+	if err != nil {
+		return nil, g.tagsError(err)
+	}
+	return &api_v2.GetTagKeysResponse{Keys: keys}, nil
+}
+
+// GetTagValues is the GRPC handler to fetch the values of a tag of a service.
+func (g *GRPCHandler) GetTagValues(ctx context.Context, r *api_v2.GetTagValuesRequest) (*api_v2.GetTagValuesResponse, error) {
+	values, err := g.queryService.GetTagValues(ctx, r.Service, r.Key, r.Prefix)
+	if err != nil {
+		return nil, g.tagsError(err)
+	}
+	return &api_v2.GetTagValuesResponse{Values: values}, nil
+}
+
+func (g *GRPCHandler) tagsError(err error) error {
+	if err == spanstore.ErrTagsNotSupported {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	g.logger.Error("Error fetching tags", zap.Error(err))
+	return err
+}
+
 // GetDependencies is the GRPC handler to fetch dependencies.
 func (g *GRPCHandler) GetDependencies(ctx context.Context, r *api_v2.GetDependenciesRequest) (*api_v2.GetDependenciesResponse, error) {
 	startTime := r.StartTime
 	endTime := r.EndTime
-	dependencies, err := g.queryService.GetDependencies(startTime, endTime.Sub(startTime))
+	dependencies, err := g.queryService.GetDependencies(ctx, startTime, endTime.Sub(startTime))
 	if err != nil {
 		g.logger.Error("Error fetching dependencies", zap.Error(err))
 		return nil, err
@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
@@ -390,6 +391,52 @@ func TestSearchFailure_GRPC(t *testing.T) {
 	})
 }
 
+func TestSearchTagFiltersGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		expectedFilters := []spanstore.TagFilter{
+			{Key: "http.status_code", Operator: spanstore.TagGreaterThanOrEqual, Value: "500"},
+			{Key: "error", Operator: spanstore.TagExists},
+		}
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+			return assert.ObjectsAreEqual(expectedFilters, q.TagFilters)
+		})).Return([]*model.Trace{mockTraceGRPC}, nil).Once()
+
+		queryParams := &api_v2.TraceQueryParameters{
+			ServiceName:  "service",
+			StartTimeMin: time.Now().Add(time.Duration(-10) * time.Minute),
+			StartTimeMax: time.Now(),
+			TagFilters: []*api_v2.TagFilter{
+				{Key: "http.status_code", Operator: api_v2.TagFilter_GREATER_THAN_OR_EQUAL, Value: "500"},
+				{Key: "error", Operator: api_v2.TagFilter_EXISTS},
+			},
+		}
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
+			Query: queryParams,
+		})
+		require.NoError(t, err)
+		spanResChunk, err := res.Recv()
+		require.NoError(t, err)
+		assert.Len(t, spanResChunk.Spans, len(mockTraceGRPC.Spans))
+	})
+}
+
+func TestSearchUnsupportedTagOperatorGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+			Return(nil, spanstore.ErrUnsupportedTagOperator).Once()
+
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{
+				ServiceName: "service",
+				TagFilters:  []*api_v2.TagFilter{{Key: "error", Operator: api_v2.TagFilter_NOT_EQUALS, Value: "true"}},
+			},
+		})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
 func TestGetServicesSuccessGRPC(t *testing.T) {
 	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
 		expectedServices := []string{"trifle", "bling"}
@@ -437,6 +484,42 @@ func TestGetOperationsFailureGRPC(t *testing.T) {
 	})
 }
 
+func TestGetTagsGRPC(t *testing.T) {
+	tagReader := &struct {
+		spanstoremocks.Reader
+		spanstoremocks.TagReader
+	}{}
+	q := querysvc.NewQueryService(tagReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	server, addr := newGRPCServer(t, q, zap.NewNop(), opentracing.NoopTracer{})
+	defer server.Stop()
+	client := newGRPCClient(t, addr)
+	defer client.conn.Close()
+
+	tagReader.TagReader.On("GetTagKeys", mock.Anything, "trifle").Return([]string{"http.method"}, nil).Once()
+	tagReader.TagReader.On("GetTagValues", mock.Anything, "trifle", "http.method", "G").Return([]string{"GET"}, nil).Once()
+	tagReader.TagReader.On("GetTagValues", mock.Anything, "trifle", "error", "").Return(nil, errStorageGRPC).Once()
+
+	keys, err := client.GetTagKeys(context.Background(), &api_v2.GetTagKeysRequest{Service: "trifle"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http.method"}, keys.Keys)
+
+	values, err := client.GetTagValues(context.Background(), &api_v2.GetTagValuesRequest{Service: "trifle", Key: "http.method", Prefix: "G"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET"}, values.Values)
+
+	_, err = client.GetTagValues(context.Background(), &api_v2.GetTagValuesRequest{Service: "trifle", Key: "error"})
+	assert.EqualError(t, err, errStatusStorageGRPC.Error())
+}
+
+func TestGetTagsNotSupportedGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		_, err := client.GetTagKeys(context.Background(), &api_v2.GetTagKeysRequest{Service: "trifle"})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		_, err = client.GetTagValues(context.Background(), &api_v2.GetTagValuesRequest{Service: "trifle", Key: "http.method"})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
 func TestGetDependenciesSuccessGRPC(t *testing.T) {
 	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
 		expectedDependencies := []model.DependencyLink{{Parent: "killer", Child: "queen", CallCount: 12}}
@@ -20,6 +20,8 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/auth"
 )
 
 // HandlerOption is a function that sets some option on the APIHandler
@@ -65,3 +67,10 @@ func (handlerOptions) Tracer(tracer opentracing.Tracer) HandlerOption {
 		apiHandler.tracer = tracer
 	}
 }
+
+// Authenticator creates a HandlerOption that requires API requests to be authenticated
+func (handlerOptions) Authenticator(authenticator auth.Authenticator) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.authenticator = authenticator
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/auth"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
 	uiconv "github.com/jaegertracing/jaeger/model/converter/json"
@@ -42,12 +43,16 @@ const (
 	traceIDParam  = "traceID"
 	endTsParam    = "endTs"
 	lookbackParam = "lookback"
+	keyParam      = "key"
+	prefixParam   = "prefix"
 
 	defaultDependencyLookbackDuration = time.Hour * 24
 	defaultTraceQueryLookbackDuration = time.Hour * 24 * 2
 	defaultAPIPrefix                  = "api"
 )
 
+var errKeyParameterRequired = fmt.Errorf("parameter '%s' is required", keyParam)
+
 // HTTPHandler handles http requests
 type HTTPHandler interface {
 	RegisterRoutes(router *mux.Router)
@@ -74,12 +79,13 @@ func NewRouter() *mux.Router {
 
 // APIHandler implements the query service public API by registering routes at httpPrefix
 type APIHandler struct {
-	queryService *querysvc.QueryService
-	queryParser  queryParser
-	basePath     string
-	apiPrefix    string
-	logger       *zap.Logger
-	tracer       opentracing.Tracer
+	queryService  *querysvc.QueryService
+	queryParser   queryParser
+	basePath      string
+	apiPrefix     string
+	logger        *zap.Logger
+	tracer        opentracing.Tracer
+	authenticator auth.Authenticator
 }
 
 // NewAPIHandler returns an APIHandler
@@ -118,6 +124,8 @@ func (aH *APIHandler) RegisterRoutes(router *mux.Router) {
 	// TODO - remove this when UI catches up
 	aH.handleFunc(router, aH.getOperationsLegacy, "/services/{%s}/operations", serviceParam).Methods(http.MethodGet)
 	aH.handleFunc(router, aH.dependencies, "/dependencies").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getTagKeys, "/tags").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getTagValues, "/tags/values").Methods(http.MethodGet)
 }
 
 func (aH *APIHandler) handleFunc(
@@ -127,9 +135,13 @@ func (aH *APIHandler) handleFunc(
 	args ...interface{},
 ) *mux.Route {
 	route = aH.route(route, args...)
+	var handler http.Handler = http.HandlerFunc(f)
+	if aH.authenticator != nil {
+		handler = auth.HTTPHandler(aH.authenticator, handler)
+	}
 	traceMiddleware := nethttp.Middleware(
 		aH.tracer,
-		http.HandlerFunc(f),
+		handler,
 		nethttp.OperationNameFunc(func(r *http.Request) string {
 			return route
 		}))
@@ -186,6 +198,45 @@ func (aH *APIHandler) getOperations(w http.ResponseWriter, r *http.Request) {
 	aH.writeJSON(w, r, &structuredRes)
 }
 
+func (aH *APIHandler) getTagKeys(w http.ResponseWriter, r *http.Request) {
+	service := r.FormValue(serviceParam)
+	if service == "" {
+		aH.handleError(w, ErrServiceParameterRequired, http.StatusBadRequest)
+		return
+	}
+	keys, err := aH.queryService.GetTagKeys(r.Context(), service)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data:  keys,
+		Total: len(keys),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+func (aH *APIHandler) getTagValues(w http.ResponseWriter, r *http.Request) {
+	service := r.FormValue(serviceParam)
+	if service == "" {
+		aH.handleError(w, ErrServiceParameterRequired, http.StatusBadRequest)
+		return
+	}
+	key := r.FormValue(keyParam)
+	if key == "" {
+		aH.handleError(w, errKeyParameterRequired, http.StatusBadRequest)
+		return
+	}
+	values, err := aH.queryService.GetTagValues(r.Context(), service, key, r.FormValue(prefixParam))
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data:  values,
+		Total: len(values),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
 func (aH *APIHandler) search(w http.ResponseWriter, r *http.Request) {
 	tQuery, err := aH.queryParser.parse(r)
 	if aH.handleError(w, err, http.StatusBadRequest) {
@@ -260,7 +311,7 @@ func (aH *APIHandler) dependencies(w http.ResponseWriter, r *http.Request) {
 	}
 	endTs := time.Unix(0, 0).Add(time.Duration(endTsMillis) * time.Millisecond)
 
-	dependencies, err := aH.queryService.GetDependencies(endTs, lookback)
+	dependencies, err := aH.queryService.GetDependencies(r.Context(), endTs, lookback)
 	if aH.handleError(w, err, http.StatusInternalServerError) {
 		return
 	}
@@ -406,6 +457,12 @@ func (aH *APIHandler) handleError(w http.ResponseWriter, err error, statusCode i
 	if err == nil {
 		return false
 	}
+	if err == auth.ErrForbidden {
+		statusCode = http.StatusForbidden
+	}
+	if err == spanstore.ErrTagsNotSupported || errors.Cause(err) == spanstore.ErrUnsupportedTagOperator {
+		statusCode = http.StatusNotImplemented
+	}
 	if statusCode == http.StatusInternalServerError {
 		aH.logger.Error("HTTP handler, Internal Server Error", zap.Error(err))
 	}
@@ -18,16 +18,17 @@ package app
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/gogo/protobuf/jsonpb"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	testHttp "github.com/stretchr/testify/http"
 	"github.com/stretchr/testify/mock"
@@ -36,6 +37,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/auth"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
@@ -339,6 +341,19 @@ func TestSearchSuccess(t *testing.T) {
 	assert.Len(t, response.Errors, 0)
 }
 
+func TestSearchUnsupportedTagOperator(t *testing.T) {
+	server, readMock, _ := initializeTestServer()
+	defer server.Close()
+	readMock.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return len(q.TagFilters) == 1 && q.TagFilters[0].Operator == spanstore.TagGreaterThan
+	})).Return(nil, errors.Wrap(spanstore.ErrUnsupportedTagOperator, "cannot filter tag 'http.status_code'")).Once()
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/traces?service=service&tags=%7B%22http.status_code%22%3A%7B%22%3E%22%3A499%7D%7D`, &response)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "501 error from server")
+}
+
 func TestSearchByTraceIDSuccess(t *testing.T) {
 	server, readMock, _ := initializeTestServer()
 	defer server.Close()
@@ -515,6 +530,64 @@ func TestGetOperationsStorageFailure(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetTags(t *testing.T) {
+	tagReader := &struct {
+		spanstoremocks.Reader
+		spanstoremocks.TagReader
+	}{}
+	r := NewRouter()
+	NewAPIHandler(querysvc.NewQueryService(tagReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	tagReader.TagReader.On("GetTagKeys", mock.Anything, "abc/trifle").Return([]string{"http.method"}, nil).Once()
+	tagReader.TagReader.On("GetTagValues", mock.Anything, "abc/trifle", "http.method", "G").Return([]string{"GET"}, nil).Once()
+	tagReader.TagReader.On("GetTagValues", mock.Anything, "abc/trifle", "error", "").Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	require.NoError(t, getJSON(server.URL+"/api/tags?service=abc%2Ftrifle", &response))
+	assert.Equal(t, []interface{}{"http.method"}, response.Data)
+
+	require.NoError(t, getJSON(server.URL+"/api/tags/values?service=abc%2Ftrifle&key=http.method&prefix=G", &response))
+	assert.Equal(t, []interface{}{"GET"}, response.Data)
+
+	err := getJSON(server.URL+"/api/tags/values?service=abc%2Ftrifle&key=error", &response)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "500 error from server")
+}
+
+func TestGetTagsBadRequest(t *testing.T) {
+	server, _, _ := initializeTestServer()
+	defer server.Close()
+
+	testCases := []struct {
+		url           string
+		expectedError string
+	}{
+		{url: "/api/tags", expectedError: "parameter 'service' is required"},
+		{url: "/api/tags/values?key=http.method", expectedError: "parameter 'service' is required"},
+		{url: "/api/tags/values?service=trifle", expectedError: "parameter 'key' is required"},
+	}
+	for _, test := range testCases {
+		t.Run(test.url, func(t *testing.T) {
+			var response structuredResponse
+			err := getJSON(server.URL+test.url, &response)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "400 error from server")
+			assert.Contains(t, err.Error(), test.expectedError)
+		})
+	}
+}
+
+func TestGetTagsNotSupported(t *testing.T) {
+	server, _, _ := initializeTestServer()
+	defer server.Close()
+
+	var response structuredResponse
+	err := getJSON(server.URL+"/api/tags?service=trifle", &response)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "501 error from server")
+}
+
 func TestGetOperationsLegacySuccess(t *testing.T) {
 	server, readMock, _ := initializeTestServer()
 	defer server.Close()
@@ -596,3 +669,42 @@ func execJSON(req *http.Request, out interface{}) error {
 func parsedError(code int, err string) string {
 	return fmt.Sprintf(`%d error from server: {"data":null,"total":0,"limit":0,"offset":0,"errors":[{"code":%d,"msg":"%s"}]}`+"\n", code, code, err)
 }
+
+func TestAuthenticationAndAuthorization(t *testing.T) {
+	keysFile, err := ioutil.TempFile("", "api-keys")
+	require.NoError(t, err)
+	defer os.Remove(keysFile.Name())
+	_, err = keysFile.WriteString("alice:alice-key\n")
+	require.NoError(t, err)
+	keysFile.Close()
+	authenticator, err := auth.NewAPIKeysAuthenticator(keysFile.Name())
+	require.NoError(t, err)
+	authorizer, err := auth.NewAuthorizer(auth.Rules{Identities: map[string][]string{"alice": {"frontend"}}})
+	require.NoError(t, err)
+
+	withTestServer(t, func(ts *testServer) {
+		ts.spanReader.On("GetServices", mock.Anything).Return([]string{"driver", "frontend"}, nil)
+		get := func(path, authorization string) (int, string) {
+			req, err := http.NewRequest(http.MethodGet, ts.server.URL+path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", authorization)
+			resp, err := httpClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			return resp.StatusCode, string(body)
+		}
+
+		status, body := get("/api/services", "Bearer alice-key")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"data":["frontend"]`)
+
+		status, _ = get("/api/services", "Bearer invalid")
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, body = get("/api/operations?service=driver", "Bearer alice-key")
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Contains(t, body, auth.ErrForbidden.Error())
+	}, querysvc.QueryServiceOptions{Authorizer: authorizer}, HandlerOptions.Authenticator(authenticator))
+}
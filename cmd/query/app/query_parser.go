@@ -16,9 +16,11 @@
 package app
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -77,6 +79,11 @@ type traceQueryParameters struct {
 //     key := strValue
 //     keyValue := strValue ':' strValue
 //     tags :== 'tags=' jsonMap
+//     jsonMap ::= '{' jsonTag | jsonTag ',' ... '}'
+//     jsonTag ::= key ':' jsonString | key ':' '{' operator ':' jsonValue | operator ':' jsonValue ',' ... '}'
+//     operator ::= '=' | '!=' | 'exists' | '>' | '>=' | '<' | '<=' | '=~'
+//
+// For example tags={"error":"true","http.status_code":{">=":500,"<":600},"peer.service":{"exists":true}}
 func (p *queryParser) parse(r *http.Request) (*traceQueryParameters, error) {
 	service := r.FormValue(serviceParam)
 	operation := r.FormValue(operationParam)
@@ -90,7 +97,7 @@ func (p *queryParser) parse(r *http.Request) (*traceQueryParameters, error) {
 		return nil, err
 	}
 
-	tags, err := p.parseTags(r.Form[tagParam], r.Form[tagsParam])
+	tags, tagFilters, err := p.parseTags(r.Form[tagParam], r.Form[tagsParam])
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +138,7 @@ func (p *queryParser) parse(r *http.Request) (*traceQueryParameters, error) {
 			StartTimeMin:  startTime,
 			StartTimeMax:  endTime,
 			Tags:          tags,
+			TagFilters:    tagFilters,
 			NumTraces:     limit,
 			DurationMin:   minDuration,
 			DurationMax:   maxDuration,
@@ -183,24 +191,88 @@ func (p *queryParser) validateQuery(traceQuery *traceQueryParameters) error {
 	return nil
 }
 
-func (p *queryParser) parseTags(simpleTags []string, jsonTags []string) (map[string]string, error) {
+func (p *queryParser) parseTags(simpleTags []string, jsonTags []string) (map[string]string, []spanstore.TagFilter, error) {
 	retMe := make(map[string]string)
+	var filters []spanstore.TagFilter
 	for _, tag := range simpleTags {
 		keyAndValue := strings.Split(tag, ":")
 		if l := len(keyAndValue); l > 1 {
 			retMe[keyAndValue[0]] = strings.Join(keyAndValue[1:], ":")
 		} else {
-			return nil, fmt.Errorf("malformed 'tag' parameter, expecting key:value, received: %s", tag)
+			return nil, nil, fmt.Errorf("malformed 'tag' parameter, expecting key:value, received: %s", tag)
 		}
 	}
 	for _, tags := range jsonTags {
-		var fromJSON map[string]string
+		var fromJSON map[string]json.RawMessage
 		if err := json.Unmarshal([]byte(tags), &fromJSON); err != nil {
-			return nil, fmt.Errorf("malformed 'tags' parameter, cannot unmarshal JSON: %s", err)
+			return nil, nil, fmt.Errorf("malformed 'tags' parameter, cannot unmarshal JSON: %s", err)
+		}
+		keys := make([]string, 0, len(fromJSON))
+		for k := range fromJSON {
+			keys = append(keys, k)
 		}
-		for k, v := range fromJSON {
+		sort.Strings(keys)
+		for _, k := range keys {
+			raw := bytes.TrimSpace(fromJSON[k])
+			if len(raw) > 0 && raw[0] == '{' {
+				tagFilters, err := p.parseTagFilters(k, raw)
+				if err != nil {
+					return nil, nil, err
+				}
+				filters = append(filters, tagFilters...)
+				continue
+			}
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, nil, fmt.Errorf("malformed 'tags' parameter, cannot unmarshal JSON: %s", err)
+			}
 			retMe[k] = v
 		}
 	}
-	return retMe, nil
+	return retMe, filters, nil
+}
+
+// parseTagFilters parses the operators of a tag, e.g. {">=":500,"<":600}
+func (p *queryParser) parseTagFilters(key string, raw []byte) ([]spanstore.TagFilter, error) {
+	var operators map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&operators); err != nil {
+		return nil, fmt.Errorf("malformed 'tags' parameter, cannot unmarshal JSON: %s", err)
+	}
+	symbols := make([]string, 0, len(operators))
+	for symbol := range operators {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	filters := make([]spanstore.TagFilter, 0, len(symbols))
+	for _, symbol := range symbols {
+		operator, err := spanstore.ParseTagOperator(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("malformed 'tags' parameter, %s for tag '%s'", err, key)
+		}
+		var value string
+		switch v := operators[symbol].(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("malformed 'tags' parameter, expecting a string, number or boolean for '%s' of tag '%s'", symbol, key)
+		}
+		if operator == spanstore.TagExists {
+			if value != "true" {
+				return nil, fmt.Errorf("malformed 'tags' parameter, expecting true for 'exists' of tag '%s'", key)
+			}
+			value = ""
+		}
+		filter := spanstore.TagFilter{Key: key, Operator: operator, Value: value}
+		if _, err := spanstore.NewTagMatcher(filter); err != nil {
+			return nil, fmt.Errorf("malformed 'tags' parameter, %s", err)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
 }
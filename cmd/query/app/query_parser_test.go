@@ -72,6 +72,31 @@ func TestParseTraceQuery(t *testing.T) {
 				},
 			},
 		},
+		// tags=JSON with operators
+		{`x?service=service&start=0&end=0&operation=operation&limit=200&tags={"x":"y","http.status_code":{">=":500,"<":"600"},"error":{"exists":true},"peer":{"!=":"db","=~":"web-.*"}}`, noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:   "service",
+					OperationName: "operation",
+					StartTimeMin:  time.Unix(0, 0),
+					StartTimeMax:  time.Unix(0, 0),
+					NumTraces:     200,
+					Tags:          map[string]string{"x": "y"},
+					TagFilters: []spanstore.TagFilter{
+						{Key: "error", Operator: spanstore.TagExists},
+						{Key: "http.status_code", Operator: spanstore.TagLessThan, Value: "600"},
+						{Key: "http.status_code", Operator: spanstore.TagGreaterThanOrEqual, Value: "500"},
+						{Key: "peer", Operator: spanstore.TagNotEquals, Value: "db"},
+						{Key: "peer", Operator: spanstore.TagRegex, Value: "web-.*"},
+					},
+				},
+			},
+		},
+		{`x?service=service&tags={"x":{"~":"y"}}`, "malformed 'tags' parameter, unknown tag operator '~' for tag 'x'", nil},
+		{`x?service=service&tags={"x":{">":"y"}}`, "malformed 'tags' parameter, tag filter 'x>y' requires a numeric value", nil},
+		{`x?service=service&tags={"x":{"exists":false}}`, "malformed 'tags' parameter, expecting true for 'exists' of tag 'x'", nil},
+		{`x?service=service&tags={"x":{"=":["y"]}}`, "malformed 'tags' parameter, expecting a string, number or boolean for '=' of tag 'x'", nil},
+		{`x?service=service&tags={"x":{"=":}}`, "malformed 'tags' parameter, cannot unmarshal JSON: invalid character '}' looking for beginning of value", nil},
 		// tags=url_encode(JSON)
 		{`x?service=service&start=0&end=0&operation=operation&limit=200&tag=k:v&tags=%7B%22x%22%3A%22y%22%7D`, noErr,
 			&traceQueryParameters{
@@ -21,6 +21,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/auth"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/multierror"
@@ -38,6 +39,8 @@ type QueryServiceOptions struct {
 	ArchiveSpanReader spanstore.Reader
 	ArchiveSpanWriter spanstore.Writer
 	Adjuster          adjuster.Adjuster
+	// Authorizer, if set, restricts the services visible to the identity in the request context
+	Authorizer *auth.Authorizer
 }
 
 // QueryService contains span utils required by the query-service.
@@ -63,40 +66,109 @@ func NewQueryService(spanReader spanstore.Reader, dependencyReader dependencysto
 
 // GetTrace is the queryService implementation of spanstore.Reader.GetTrace
 func (qs QueryService) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	trace, err := qs.getStoredTrace(ctx, traceID)
+	if err != nil || qs.options.Authorizer == nil {
+		return trace, err
+	}
+	if trace = qs.options.Authorizer.FilterTrace(ctx, trace); trace == nil {
+		// do not reveal the existence of traces the caller may not see
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return trace, nil
+}
+
+// getStoredTrace returns the trace from the span storage, or from the archive storage if it is not found,
+// without filtering it.
+func (qs QueryService) getStoredTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
 	trace, err := qs.spanReader.GetTrace(ctx, traceID)
-	if err == spanstore.ErrTraceNotFound {
-		if qs.options.ArchiveSpanReader == nil {
-			return nil, err
-		}
-		trace, err = qs.options.ArchiveSpanReader.GetTrace(ctx, traceID)
+	if err == spanstore.ErrTraceNotFound && qs.options.ArchiveSpanReader != nil {
+		return qs.options.ArchiveSpanReader.GetTrace(ctx, traceID)
 	}
 	return trace, err
 }
 
 // GetServices is the queryService implementation of spanstore.Reader.GetServices
 func (qs QueryService) GetServices(ctx context.Context) ([]string, error) {
-	return qs.spanReader.GetServices(ctx)
+	services, err := qs.spanReader.GetServices(ctx)
+	if err != nil || qs.options.Authorizer == nil {
+		return services, err
+	}
+	return qs.options.Authorizer.FilterServices(ctx, services), nil
 }
 
 // GetOperations is the queryService implementation of spanstore.Reader.GetOperations
 func (qs QueryService) GetOperations(ctx context.Context, service string) ([]string, error) {
+	if qs.options.Authorizer != nil && !qs.options.Authorizer.AllowService(ctx, service) {
+		return nil, auth.ErrForbidden
+	}
 	return qs.spanReader.GetOperations(ctx, service)
 }
 
+// GetTagKeys is the queryService implementation of spanstore.TagReader.GetTagKeys
+func (qs QueryService) GetTagKeys(ctx context.Context, service string) ([]string, error) {
+	tagReader, err := qs.tagReader(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	return tagReader.GetTagKeys(ctx, service)
+}
+
+// GetTagValues is the queryService implementation of spanstore.TagReader.GetTagValues
+func (qs QueryService) GetTagValues(ctx context.Context, service, key, prefix string) ([]string, error) {
+	tagReader, err := qs.tagReader(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	return tagReader.GetTagValues(ctx, service, key, prefix)
+}
+
+func (qs QueryService) tagReader(ctx context.Context, service string) (spanstore.TagReader, error) {
+	if qs.options.Authorizer != nil && !qs.options.Authorizer.AllowService(ctx, service) {
+		return nil, auth.ErrForbidden
+	}
+	tagReader, ok := qs.spanReader.(spanstore.TagReader)
+	if !ok {
+		return nil, spanstore.ErrTagsNotSupported
+	}
+	return tagReader, nil
+}
+
 // FindTraces is the queryService implementation of spanstore.Reader.FindTraces
 func (qs QueryService) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
-	return qs.spanReader.FindTraces(ctx, query)
+	if qs.options.Authorizer == nil {
+		return qs.spanReader.FindTraces(ctx, query)
+	}
+	if !qs.options.Authorizer.AllowService(ctx, query.ServiceName) {
+		return nil, auth.ErrForbidden
+	}
+	traces, err := qs.spanReader.FindTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return qs.options.Authorizer.FilterTraces(ctx, traces), nil
 }
 
-// ArchiveTrace is the queryService utility to archive traces.
+// ArchiveTrace is the queryService utility to archive traces. The trace is archived as stored; with an
+// Authorizer, archiving a trace with spans the caller may not see is forbidden, since archiving only the
+// visible spans would store a truncated trace in place of the whole one.
 func (qs QueryService) ArchiveTrace(ctx context.Context, traceID model.TraceID) error {
 	if qs.options.ArchiveSpanWriter == nil {
 		return errNoArchiveSpanStorage
 	}
-	trace, err := qs.GetTrace(ctx, traceID)
+	trace, err := qs.getStoredTrace(ctx, traceID)
 	if err != nil {
 		return err
 	}
+	if qs.options.Authorizer != nil {
+		filtered := qs.options.Authorizer.FilterTrace(ctx, trace)
+		if filtered == nil {
+			// do not reveal the existence of traces the caller may not see
+			return spanstore.ErrTraceNotFound
+		}
+		if filtered != trace {
+			return auth.ErrForbidden
+		}
+	}
 
 	var writeErrors []error
 	for _, span := range trace.Spans {
@@ -114,8 +186,12 @@ func (qs QueryService) Adjust(trace *model.Trace) (*model.Trace, error) {
 }
 
 // GetDependencies implements dependencystore.Reader.GetDependencies
-func (qs QueryService) GetDependencies(endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
-	return qs.dependencyReader.GetDependencies(endTs, lookback)
+func (qs QueryService) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	links, err := qs.dependencyReader.GetDependencies(endTs, lookback)
+	if err != nil || qs.options.Authorizer == nil {
+		return links, err
+	}
+	return qs.options.Authorizer.FilterDependencies(ctx, links), nil
 }
 
 // InitArchiveStorage tries to initialize archive storage reader/writer if storage factory supports them.
@@ -22,9 +22,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/auth"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/storage"
@@ -157,6 +159,35 @@ func TestGetOperations(t *testing.T) {
 	assert.Equal(t, expectedOperations, actualOperations)
 }
 
+type tagReaderMock struct {
+	spanstoremocks.Reader
+	spanstoremocks.TagReader
+}
+
+// Test QueryService.GetTagKeys() and QueryService.GetTagValues() for success.
+func TestGetTags(t *testing.T) {
+	readMock := &tagReaderMock{}
+	qs := NewQueryService(readMock, &depsmocks.Reader{}, QueryServiceOptions{})
+	readMock.TagReader.On("GetTagKeys", mock.Anything, "trifle").Return([]string{"http.method"}, nil).Once()
+	readMock.TagReader.On("GetTagValues", mock.Anything, "trifle", "http.method", "G").Return([]string{"GET"}, nil).Once()
+
+	keys, err := qs.GetTagKeys(context.Background(), "trifle")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http.method"}, keys)
+	values, err := qs.GetTagValues(context.Background(), "trifle", "http.method", "G")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET"}, values)
+}
+
+// Test QueryService.GetTagKeys() and QueryService.GetTagValues() with a storage that cannot list tags.
+func TestGetTagsNotSupported(t *testing.T) {
+	qs, _, _ := initializeTestService()
+	_, err := qs.GetTagKeys(context.Background(), "trifle")
+	assert.Equal(t, spanstore.ErrTagsNotSupported, err)
+	_, err = qs.GetTagValues(context.Background(), "trifle", "http.method", "")
+	assert.Equal(t, spanstore.ErrTagsNotSupported, err)
+}
+
 // Test QueryService.FindTraces() for success.
 func TestFindTraces(t *testing.T) {
 	qs, readMock, _ := initializeTestService()
@@ -254,11 +285,119 @@ func TestGetDependencies(t *testing.T) {
 	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
 	depsMock.On("GetDependencies", endTs, defaultDependencyLookbackDuration).Return(expectedDependencies, nil).Times(1)
 
-	actualDependencies, err := qs.GetDependencies(time.Unix(0, 1476374248550*millisToNanosMultiplier), defaultDependencyLookbackDuration)
+	actualDependencies, err := qs.GetDependencies(context.Background(), time.Unix(0, 1476374248550*millisToNanosMultiplier), defaultDependencyLookbackDuration)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedDependencies, actualDependencies)
 }
 
+func initializeTestServiceWithAuthorizer(t *testing.T) (*QueryService, *spanstoremocks.Reader, *depsmocks.Reader, *spanstoremocks.Reader) {
+	authorizer, err := auth.NewAuthorizer(auth.Rules{
+		Identities: map[string][]string{"alice": {"frontend", "billing"}},
+	})
+	require.NoError(t, err)
+	readStorage := &spanstoremocks.Reader{}
+	dependencyStorage := &depsmocks.Reader{}
+	archiveReadStorage := &spanstoremocks.Reader{}
+	qs := NewQueryService(readStorage, dependencyStorage, QueryServiceOptions{
+		ArchiveSpanReader: archiveReadStorage,
+		Authorizer:        authorizer,
+	})
+	return qs, readStorage, dependencyStorage, archiveReadStorage
+}
+
+func TestAuthorization(t *testing.T) {
+	alice := auth.ContextWithIdentity(context.Background(), "alice")
+	frontendTrace := &model.Trace{Spans: []*model.Span{{Process: model.NewProcess("frontend", nil)}}}
+	driverTrace := &model.Trace{Spans: []*model.Span{{Process: model.NewProcess("driver", nil)}}}
+	sharedTrace := &model.Trace{Spans: []*model.Span{frontendTrace.Spans[0], driverTrace.Spans[0]}}
+
+	t.Run("GetServices", func(t *testing.T) {
+		qs, readMock, _, _ := initializeTestServiceWithAuthorizer(t)
+		readMock.On("GetServices", mock.Anything).Return([]string{"driver", "frontend"}, nil)
+		services, err := qs.GetServices(alice)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"frontend"}, services)
+		services, err = qs.GetServices(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, services)
+	})
+
+	t.Run("GetOperations", func(t *testing.T) {
+		qs, readMock, _, _ := initializeTestServiceWithAuthorizer(t)
+		readMock.On("GetOperations", mock.Anything, "frontend").Return([]string{"GET"}, nil)
+		operations, err := qs.GetOperations(alice, "frontend")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"GET"}, operations)
+		_, err = qs.GetOperations(alice, "driver")
+		assert.Equal(t, auth.ErrForbidden, err)
+	})
+
+	t.Run("GetTags", func(t *testing.T) {
+		qs, _, _, _ := initializeTestServiceWithAuthorizer(t)
+		_, err := qs.GetTagKeys(alice, "driver")
+		assert.Equal(t, auth.ErrForbidden, err)
+		_, err = qs.GetTagValues(alice, "driver", "http.method", "")
+		assert.Equal(t, auth.ErrForbidden, err)
+	})
+
+	t.Run("FindTraces", func(t *testing.T) {
+		qs, readMock, _, _ := initializeTestServiceWithAuthorizer(t)
+		readMock.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{frontendTrace, driverTrace}, nil)
+		traces, err := qs.FindTraces(alice, &spanstore.TraceQueryParameters{ServiceName: "frontend"})
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.Trace{frontendTrace}, traces)
+		_, err = qs.FindTraces(alice, &spanstore.TraceQueryParameters{ServiceName: "driver"})
+		assert.Equal(t, auth.ErrForbidden, err)
+	})
+
+	t.Run("GetTrace", func(t *testing.T) {
+		qs, readMock, _, archiveMock := initializeTestServiceWithAuthorizer(t)
+		readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 1)).Return(frontendTrace, nil)
+		readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 2)).Return(driverTrace, nil)
+		readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 3)).Return(nil, spanstore.ErrTraceNotFound)
+		archiveMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 3)).Return(driverTrace, nil)
+		readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 4)).Return(sharedTrace, nil)
+		trace, err := qs.GetTrace(alice, model.NewTraceID(0, 1))
+		assert.NoError(t, err)
+		assert.Equal(t, frontendTrace, trace)
+		_, err = qs.GetTrace(alice, model.NewTraceID(0, 2))
+		assert.Equal(t, spanstore.ErrTraceNotFound, err)
+		_, err = qs.GetTrace(alice, model.NewTraceID(0, 3))
+		assert.Equal(t, spanstore.ErrTraceNotFound, err)
+		trace, err = qs.GetTrace(alice, model.NewTraceID(0, 4))
+		assert.NoError(t, err)
+		assert.Equal(t, frontendTrace, trace, "spans of other services are removed")
+	})
+
+	t.Run("ArchiveTrace", func(t *testing.T) {
+		qs, readMock, _, _ := initializeTestServiceWithAuthorizer(t)
+		writeMock := &spanstoremocks.Writer{}
+		qs.options.ArchiveSpanWriter = writeMock
+		readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 1)).Return(frontendTrace, nil)
+		readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 2)).Return(driverTrace, nil)
+		readMock.On("GetTrace", mock.Anything, model.NewTraceID(0, 4)).Return(sharedTrace, nil)
+		writeMock.On("WriteSpan", frontendTrace.Spans[0]).Return(nil).Once()
+		assert.NoError(t, qs.ArchiveTrace(alice, model.NewTraceID(0, 1)))
+		assert.Equal(t, spanstore.ErrTraceNotFound, qs.ArchiveTrace(alice, model.NewTraceID(0, 2)))
+		assert.Equal(t, auth.ErrForbidden, qs.ArchiveTrace(alice, model.NewTraceID(0, 4)),
+			"traces with spans of other services cannot be archived")
+		writeMock.AssertExpectations(t)
+	})
+
+	t.Run("GetDependencies", func(t *testing.T) {
+		qs, _, depsMock, _ := initializeTestServiceWithAuthorizer(t)
+		links := []model.DependencyLink{
+			{Parent: "frontend", Child: "billing"},
+			{Parent: "frontend", Child: "driver"},
+			{Parent: "driver", Child: "redis"},
+		}
+		depsMock.On("GetDependencies", mock.Anything, mock.Anything).Return(links, nil)
+		dependencies, err := qs.GetDependencies(alice, time.Now(), time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, links[:1], dependencies)
+	})
+}
+
 type fakeStorageFactory1 struct {
 }
 
@@ -15,6 +15,7 @@
 package app
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -27,6 +28,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/jaegertracing/jaeger/cmd/flags"
+	"github.com/jaegertracing/jaeger/cmd/query/app/auth"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
@@ -47,29 +49,39 @@ type Server struct {
 }
 
 // NewServer creates and initializes Server
-func NewServer(svc *flags.Service, querySvc *querysvc.QueryService, options *QueryOptions, tracer opentracing.Tracer) *Server {
+func NewServer(svc *flags.Service, querySvc *querysvc.QueryService, options *QueryOptions, tracer opentracing.Tracer) (*Server, error) {
+	authenticator, err := options.Auth.NewAuthenticator()
+	if err != nil {
+		return nil, err
+	}
 	return &Server{
 		svc:          svc,
 		querySvc:     querySvc,
 		queryOptions: options,
 		tracer:       tracer,
-		grpcServer:   createGRPCServer(querySvc, svc.Logger, tracer),
-		httpServer:   createHTTPServer(querySvc, options, tracer, svc.Logger),
-	}
+		grpcServer:   createGRPCServer(querySvc, authenticator, svc.Logger, tracer),
+		httpServer:   createHTTPServer(querySvc, options, authenticator, tracer, svc.Logger),
+	}, nil
 }
 
-func createGRPCServer(querySvc *querysvc.QueryService, logger *zap.Logger, tracer opentracing.Tracer) *grpc.Server {
-	srv := grpc.NewServer()
+func createGRPCServer(querySvc *querysvc.QueryService, authenticator auth.Authenticator, logger *zap.Logger, tracer opentracing.Tracer) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.UnaryServerInterceptor(authenticator)),
+		grpc.StreamInterceptor(auth.StreamServerInterceptor(authenticator)),
+	)
 	handler := NewGRPCHandler(querySvc, logger, tracer)
 	api_v2.RegisterQueryServiceServer(srv, handler)
 	return srv
 }
 
-func createHTTPServer(querySvc *querysvc.QueryService, queryOpts *QueryOptions, tracer opentracing.Tracer, logger *zap.Logger) *http.Server {
+func createHTTPServer(querySvc *querysvc.QueryService, queryOpts *QueryOptions, authenticator auth.Authenticator, tracer opentracing.Tracer, logger *zap.Logger) *http.Server {
 	apiHandlerOptions := []HandlerOption{
 		HandlerOptions.Logger(logger),
 		HandlerOptions.Tracer(tracer),
 	}
+	if authenticator != nil {
+		apiHandlerOptions = append(apiHandlerOptions, HandlerOptions.Authenticator(authenticator))
+	}
 	apiHandler := NewAPIHandler(
 		querySvc,
 		apiHandlerOptions...)
@@ -97,14 +109,24 @@ func (s *Server) Start() error {
 	if err != nil {
 		return err
 	}
+	if s.queryOptions.TLS.Enabled {
+		tlsCfg, err := s.queryOptions.TLS.Config(s.svc.Logger)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		// TLS is terminated before cmux so that it can inspect the decrypted stream
+		conn = tls.NewListener(conn, negotiateALPN(tlsCfg))
+	}
 	s.conn = conn
 
 	// cmux server acts as a reverse-proxy between HTTP and GRPC backends.
 	cmuxServer := cmux.New(s.conn)
 
-	grpcListener := cmuxServer.Match(
-		cmux.HTTP2HeaderField("content-type", "application/grpc"),
-		cmux.HTTP2HeaderField("content-type", "application/grpc+proto"))
+	// gRPC clients wait for the server SETTINGS frame before sending headers, so the matcher must send it.
+	grpcListener := cmuxServer.MatchWithWriters(
+		cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"),
+		cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc+proto"))
 	httpListener := cmuxServer.Match(cmux.Any())
 
 	go func() {
@@ -149,4 +171,36 @@ func (s *Server) Close() {
 	s.grpcServer.Stop()
 	s.httpServer.Close()
 	s.conn.Close()
+	s.queryOptions.TLS.Close()
+}
+
+// negotiateALPN selects the application protocol for each TLS connection. gRPC clients
+// only offer h2, while browsers and other HTTP clients are kept on http/1.1 because
+// the HTTP server behind cmux receives plain connections and cannot upgrade them to HTTP/2.
+func negotiateALPN(cfg *tls.Config) *tls.Config {
+	getConfigForClient := cfg.GetConfigForClient
+	base := cfg.Clone()
+	base.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		clientCfg := cfg
+		if getConfigForClient != nil {
+			c, err := getConfigForClient(hello)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				clientCfg = c
+			}
+		}
+		clientCfg = clientCfg.Clone()
+		clientCfg.GetConfigForClient = nil
+		clientCfg.NextProtos = []string{"h2"}
+		for _, proto := range hello.SupportedProtos {
+			if proto == "http/1.1" {
+				clientCfg.NextProtos = []string{"http/1.1"}
+				break
+			}
+		}
+		return clientCfg, nil
+	}
+	return base
 }
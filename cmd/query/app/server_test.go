@@ -15,19 +15,37 @@
 package app
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
 	"testing"
 	"time"
 
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/flags"
+	"github.com/jaegertracing/jaeger/cmd/query/app/auth"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/ports"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
 )
 
 func TestServerError(t *testing.T) {
@@ -46,8 +64,9 @@ func TestServer(t *testing.T) {
 	querySvc := &querysvc.QueryService{}
 	tracer := opentracing.NoopTracer{}
 
-	server := NewServer(flagsSvc, querySvc, &QueryOptions{Port: ports.QueryAdminHTTP,
+	server, err := NewServer(flagsSvc, querySvc, &QueryOptions{Port: ports.QueryAdminHTTP,
 		BearerTokenPropagation: true}, tracer)
+	require.NoError(t, err)
 	assert.NoError(t, server.Start())
 
 	// TODO wait for servers to come up and test http and grpc endpoints
@@ -73,7 +92,8 @@ func TestServerGracefulExit(t *testing.T) {
 
 	querySvc := &querysvc.QueryService{}
 	tracer := opentracing.NoopTracer{}
-	server := NewServer(flagsSvc, querySvc, &QueryOptions{Port: ports.QueryAdminHTTP}, tracer)
+	server, err := NewServer(flagsSvc, querySvc, &QueryOptions{Port: ports.QueryAdminHTTP}, tracer)
+	require.NoError(t, err)
 	assert.NoError(t, server.Start())
 
 	// Wait for servers to come up before we can call .Close()
@@ -85,3 +105,140 @@ func TestServerGracefulExit(t *testing.T) {
 			fmt.Sprintf("Error log found on server exit: %v", logEntry))
 	}
 }
+
+func TestServerTLSError(t *testing.T) {
+	flagsSvc := flags.NewService(ports.AgentAdminHTTP)
+	flagsSvc.Logger = zap.NewNop()
+	server, err := NewServer(flagsSvc, &querysvc.QueryService{}, &QueryOptions{
+		Port: ports.QueryAdminHTTP,
+		TLS:  tlscfg.Options{Enabled: true, CertPath: "invalid", KeyPath: "invalid"},
+	}, opentracing.NoopTracer{})
+	require.NoError(t, err)
+	assert.Error(t, server.Start())
+}
+
+func TestServerTLS(t *testing.T) {
+	flagsSvc := flags.NewService(ports.AgentAdminHTTP)
+	flagsSvc.Logger = zap.NewNop()
+
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetServices", mock.Anything).Return([]string{"svc"}, nil)
+	querySvc := querysvc.NewQueryService(spanReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+
+	server, err := NewServer(flagsSvc, querySvc, &QueryOptions{
+		Port: ports.QueryAdminHTTP,
+		TLS: tlscfg.Options{
+			Enabled:      true,
+			CertPath:     "fixture/tls/server.jaeger.io.pem",
+			KeyPath:      "fixture/tls/server.jaeger.io-key.pem",
+			ClientCAPath: "fixture/tls/rootCA.pem",
+		},
+	}, opentracing.NoopTracer{})
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Close()
+
+	caPEM, err := ioutil.ReadFile("fixture/tls/rootCA.pem")
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caPEM))
+	clientCert, err := tls.LoadX509KeyPair("fixture/tls/client.jaeger.io-client.pem", "fixture/tls/client.jaeger.io-client-key.pem")
+	require.NoError(t, err)
+	clientTLS := &tls.Config{
+		RootCAs:      roots,
+		ServerName:   "server.jaeger.io",
+		Certificates: []tls.Certificate{clientCert},
+	}
+	addr := fmt.Sprintf("localhost:%d", ports.QueryAdminHTTP)
+
+	t.Run("HTTP", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+		var resp *http.Response
+		for i := 0; i < 100; i++ {
+			if resp, err = client.Get("https://" + addr + "/api/services"); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "HTTP/1.1", resp.Proto)
+	})
+
+	t.Run("HTTP without client certificate", func(t *testing.T) {
+		insecureTLS := clientTLS.Clone()
+		insecureTLS.Certificates = nil
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: insecureTLS}}
+		_, err := client.Get("https://" + addr + "/api/services")
+		assert.Error(t, err)
+	})
+
+	t.Run("gRPC", func(t *testing.T) {
+		conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(credentials.NewTLS(clientTLS)))
+		require.NoError(t, err)
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		res, err := api_v2.NewQueryServiceClient(conn).GetServices(ctx, &api_v2.GetServicesRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"svc"}, res.Services)
+	})
+}
+
+func TestServerAuth(t *testing.T) {
+	keysFile, err := ioutil.TempFile("", "api-keys")
+	require.NoError(t, err)
+	defer os.Remove(keysFile.Name())
+	_, err = keysFile.WriteString("alice:alice-key\n")
+	require.NoError(t, err)
+	keysFile.Close()
+
+	flagsSvc := flags.NewService(ports.AgentAdminHTTP)
+	flagsSvc.Logger = zap.NewNop()
+	authorizer, err := auth.NewAuthorizer(auth.Rules{Identities: map[string][]string{"alice": {"frontend"}}})
+	require.NoError(t, err)
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetServices", mock.Anything).Return([]string{"driver", "frontend"}, nil)
+	querySvc := querysvc.NewQueryService(spanReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{Authorizer: authorizer})
+
+	server, err := NewServer(flagsSvc, querySvc, &QueryOptions{
+		Port: ports.QueryAdminHTTP,
+		Auth: auth.Options{APIKeysFile: keysFile.Name()},
+	}, opentracing.NoopTracer{})
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Close()
+
+	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", ports.QueryAdminHTTP), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := api_v2.NewQueryServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	aliceCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer alice-key")
+
+	res, err := client.GetServices(aliceCtx, &api_v2.GetServicesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend"}, res.Services)
+
+	_, err = client.GetOperations(aliceCtx, &api_v2.GetOperationsRequest{Service: "driver"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/api/services", ports.QueryAdminHTTP))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestServerAuthError(t *testing.T) {
+	flagsSvc := flags.NewService(ports.AgentAdminHTTP)
+	flagsSvc.Logger = zap.NewNop()
+	_, err := NewServer(flagsSvc, &querysvc.QueryService{}, &QueryOptions{
+		Auth: auth.Options{APIKeysFile: "invalid-path"},
+	}, opentracing.NoopTracer{})
+	assert.Error(t, err)
+}
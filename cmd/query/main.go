@@ -87,6 +87,9 @@ func main() {
 			if err := storageFactory.Initialize(baseFactory, logger); err != nil {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
+			for route, handler := range storageFactory.AdminHandlers() {
+				svc.Admin.Handle(route, handler)
+			}
 			spanReader, err := storageFactory.CreateSpanReader()
 			if err != nil {
 				logger.Fatal("Failed to create span reader", zap.Error(err))
@@ -97,12 +100,19 @@ func main() {
 				logger.Fatal("Failed to create dependency reader", zap.Error(err))
 			}
 			queryServiceOptions := archiveOptions(storageFactory, logger)
+			queryServiceOptions.Authorizer, err = queryOpts.Auth.NewAuthorizer()
+			if err != nil {
+				logger.Fatal("Failed to create authorizer", zap.Error(err))
+			}
 			queryService := querysvc.NewQueryService(
 				spanReader,
 				dependencyReader,
 				*queryServiceOptions)
 
-			server := app.NewServer(svc, queryService, queryOpts, tracer)
+			server, err := app.NewServer(svc, queryService, queryOpts, tracer)
+			if err != nil {
+				logger.Fatal("Could not create server", zap.Error(err))
+			}
 
 			if err := server.Start(); err != nil {
 				logger.Fatal("Could not start servers", zap.Error(err))
@@ -21,7 +21,7 @@ import (
 	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
 )
 
-// SerializeThrift is only used in tests.
+// SerializeThrift encodes a list of spans to Thrift bytes.
 func SerializeThrift(spans []*zipkincore.Span) []byte {
 	t := thrift.NewTMemoryBuffer()
 	p := thrift.NewTBinaryProtocolTransport(t)
@@ -18,6 +18,7 @@ package model
 import (
 	"encoding/gob"
 	"io"
+	"strconv"
 
 	"github.com/opentracing/opentracing-go/ext"
 )
@@ -29,6 +30,7 @@ const (
 	DebugFlag = Flags(2)
 
 	samplerType        = "sampler.type"
+	samplerParam       = "sampler.param"
 	samplerTypeUnknown = "unknown"
 )
 
@@ -63,6 +65,24 @@ func (s *Span) GetSamplerType() string {
 	return samplerTypeUnknown
 }
 
+// GetSamplerParam returns the sampler parameter for span, e.g. the sampling probability of
+// a probabilistic sampler, and false if the parameter is missing or not numeric.
+func (s *Span) GetSamplerParam() (float64, bool) {
+	tag, ok := KeyValues(s.Tags).FindByKey(samplerParam)
+	if !ok {
+		return 0, false
+	}
+	switch tag.VType {
+	case Float64Type:
+		return tag.Float64(), true
+	case StringType:
+		// spans converted from Zipkin carry all tags as strings
+		param, err := strconv.ParseFloat(tag.VStr, 64)
+		return param, err == nil
+	}
+	return 0, false
+}
+
 // IsRPCClient returns true if the span represents a client side of an RPC,
 // as indicated by the `span.kind` tag set to `client`.
 func (s *Span) IsRPCClient() bool {
@@ -216,6 +216,25 @@ func TestSamplerType(t *testing.T) {
 	assert.Equal(t, "unknown", span.GetSamplerType())
 }
 
+func TestSamplerParam(t *testing.T) {
+	tests := []struct {
+		tag   model.KeyValue
+		param float64
+		ok    bool
+	}{
+		{tag: model.Float64("sampler.param", 0.001), param: 0.001, ok: true},
+		{tag: model.String("sampler.param", "0.5"), param: 0.5, ok: true},
+		{tag: model.String("sampler.param", "x"), ok: false},
+		{tag: model.Bool("sampler.param", true), ok: false},
+		{tag: model.KeyValue{}, ok: false},
+	}
+	for _, test := range tests {
+		param, ok := makeSpan(test.tag).GetSamplerParam()
+		assert.Equal(t, test.ok, ok)
+		assert.Equal(t, test.param, param)
+	}
+}
+
 func TestIsSampled(t *testing.T) {
 	flags := model.Flags(0)
 	flags.SetSampled()
@@ -0,0 +1,46 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compression registers the gRPC compressors that agents can use to send spans to the
+// collectors, so that the collectors can decompress them.
+package compression
+
+import (
+	"fmt"
+
+	// gzip is registered by its package
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+const (
+	// None disables compression
+	None = "none"
+	// Gzip compresses with gzip, which has the better ratio
+	Gzip = gzip.Name
+	// Snappy compresses with snappy, which uses less CPU
+	Snappy = snappyName
+)
+
+// Names lists the supported compressions.
+var Names = []string{None, Gzip, Snappy}
+
+// Validate returns an error if name is not a supported compression.
+func Validate(name string) error {
+	for _, n := range Names {
+		if n == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown compression %q, must be one of %v", name, Names)
+}
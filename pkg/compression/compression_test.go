@@ -0,0 +1,56 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestValidate(t *testing.T) {
+	for _, name := range Names {
+		assert.NoError(t, Validate(name))
+	}
+	assert.EqualError(t, Validate("lz4"), `unknown compression "lz4", must be one of [none gzip snappy]`)
+}
+
+func TestCompressors(t *testing.T) {
+	data := bytes.Repeat([]byte("jaeger"), 1000)
+	for _, name := range []string{Gzip, Snappy} {
+		c := encoding.GetCompressor(name)
+		require.NotNil(t, c, name)
+		// twice, to reuse pooled writers and readers
+		for i := 0; i < 2; i++ {
+			var compressed bytes.Buffer
+			w, err := c.Compress(&compressed)
+			require.NoError(t, err)
+			_, err = w.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			assert.True(t, compressed.Len() < len(data), name)
+
+			r, err := c.Decompress(&compressed)
+			require.NoError(t, err)
+			decompressed, err := ioutil.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, data, decompressed, name)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"google.golang.org/grpc/encoding"
+)
+
+const snappyName = "snappy"
+
+func init() {
+	encoding.RegisterCompressor(newSnappyCompressor())
+}
+
+// snappyCompressor implements encoding.Compressor with the snappy framing format.
+type snappyCompressor struct {
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func newSnappyCompressor() *snappyCompressor {
+	c := &snappyCompressor{}
+	c.writers.New = func() interface{} {
+		return &snappyWriter{Writer: snappy.NewBufferedWriter(nil), pool: &c.writers}
+	}
+	c.readers.New = func() interface{} {
+		return &snappyReader{Reader: snappy.NewReader(nil), pool: &c.readers}
+	}
+	return c
+}
+
+type snappyWriter struct {
+	*snappy.Writer
+	pool *sync.Pool
+}
+
+// Close flushes the buffered data and returns the writer to the pool.
+func (w *snappyWriter) Close() error {
+	defer w.pool.Put(w)
+	return w.Writer.Close()
+}
+
+type snappyReader struct {
+	*snappy.Reader
+	pool *sync.Pool
+}
+
+// Read returns the reader to the pool once all data is read.
+func (r *snappyReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r)
+	}
+	return n, err
+}
+
+// Compress implements encoding.Compressor
+func (c *snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	sw := c.writers.Get().(*snappyWriter)
+	sw.Reset(w)
+	return sw, nil
+}
+
+// Decompress implements encoding.Compressor
+func (c *snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	sr := c.readers.Get().(*snappyReader)
+	sr.Reset(r)
+	return sr, nil
+}
+
+// Name implements encoding.Compressor
+func (c *snappyCompressor) Name() string {
+	return snappyName
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscfg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// certWatcher keeps the latest server certificate and client CA pool in memory and
+// reloads them whenever the files change on disk. A failed reload keeps the previous values.
+type certWatcher struct {
+	opts    Options
+	logger  *zap.Logger
+	watcher *fsnotify.Watcher
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	cas  *x509.CertPool
+}
+
+func newCertWatcher(opts *Options, logger *zap.Logger) (*certWatcher, error) {
+	w := &certWatcher{
+		opts:   *opts,
+		logger: logger,
+	}
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// watching the directories rather than the files survives atomic replacements,
+	// e.g. when a Kubernetes secret volume swaps its symlinks
+	dirs := make(map[string]bool)
+	for _, path := range []string{opts.CertPath, opts.KeyPath, opts.ClientCAPath} {
+		if path == "" {
+			continue
+		}
+		dir := filepath.Dir(filepath.Clean(path))
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	w.watcher = watcher
+	go w.watch()
+	return w, nil
+}
+
+func (w *certWatcher) load() error {
+	cert, err := loadCertificate(w.opts.CertPath, w.opts.KeyPath)
+	if err != nil {
+		return err
+	}
+	var cas *x509.CertPool
+	if w.opts.ClientCAPath != "" {
+		if cas, err = loadCertPool(w.opts.ClientCAPath); err != nil {
+			return err
+		}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cert = cert
+	w.cas = cas
+	return nil
+}
+
+func (w *certWatcher) watch() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&fsnotify.Chmod == fsnotify.Chmod {
+				continue
+			}
+			if err := w.load(); err != nil {
+				w.logger.Error("failed to reload TLS certificates, keeping previous certificates", zap.Error(err))
+				continue
+			}
+			w.logger.Info("reloaded TLS certificates", zap.String("cert", w.opts.CertPath))
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error("error watching TLS certificates", zap.Error(err))
+		}
+	}
+}
+
+func (w *certWatcher) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.cert, nil
+}
+
+func (w *certWatcher) clientCAs() *x509.CertPool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.cas
+}
+
+// Close stops the file watcher.
+func (w *certWatcher) Close() error {
+	return w.watcher.Close()
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscfg
+
+import (
+	"flag"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	tlsPrefix     = ".tls"
+	tlsEnabled    = tlsPrefix + ".enabled"
+	tlsCert       = tlsPrefix + ".cert"
+	tlsKey        = tlsPrefix + ".key"
+	tlsClientCA   = tlsPrefix + ".client-ca"
+	tlsMinVersion = tlsPrefix + ".min-version"
+)
+
+// ServerFlagsConfig describes which CLI flags for TLS are enabled for a server and the prefix used for them.
+type ServerFlagsConfig struct {
+	Prefix string
+}
+
+// AddFlags adds flags for TLS to the FlagSet.
+func (c ServerFlagsConfig) AddFlags(flags *flag.FlagSet) {
+	flags.Bool(c.Prefix+tlsEnabled, false, "Enable TLS on the server")
+	flags.String(c.Prefix+tlsCert, "", "Path to a TLS Certificate file, used to identify this server to clients")
+	flags.String(c.Prefix+tlsKey, "", "Path to a TLS Private Key file, used to identify this server to clients")
+	flags.String(c.Prefix+tlsClientCA, "", "Path to a TLS CA (Certification Authority) file used to verify certificates presented by clients (if unset, client certificates are not required)")
+	flags.String(c.Prefix+tlsMinVersion, "", "Minimum TLS version supported by the server (1.0, 1.1, 1.2 or 1.3), the Go default if empty")
+}
+
+// InitFromViper creates tls.Options populated with values retrieved from Viper.
+func (c ServerFlagsConfig) InitFromViper(v *viper.Viper) Options {
+	return Options{
+		Enabled:      v.GetBool(c.Prefix + tlsEnabled),
+		CertPath:     v.GetString(c.Prefix + tlsCert),
+		KeyPath:      v.GetString(c.Prefix + tlsKey),
+		ClientCAPath: v.GetString(c.Prefix + tlsClientCA),
+		MinVersion:   v.GetString(c.Prefix + tlsMinVersion),
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestServerFlags(t *testing.T) {
+	flagCfg := ServerFlagsConfig{Prefix: "prefix"}
+	v, command := config.Viperize(flagCfg.AddFlags)
+
+	err := command.ParseFlags([]string{
+		"--prefix.tls.enabled=true",
+		"--prefix.tls.cert=cert-file",
+		"--prefix.tls.key=key-file",
+		"--prefix.tls.client-ca=client-ca-file",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Options{
+		Enabled:      true,
+		CertPath:     "cert-file",
+		KeyPath:      "key-file",
+		ClientCAPath: "client-ca-file",
+	}, flagCfg.InitFromViper(v))
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscfg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Options describes the configuration properties for a TLS-enabled server
+type Options struct {
+	Enabled      bool
+	CertPath     string
+	KeyPath      string
+	ClientCAPath string
+	MinVersion   string
+
+	certWatcher *certWatcher
+}
+
+// Config loads the certificates and returns a server *tls.Config. The certificate, key and
+// client CA files are watched for changes and reloaded without restarting the server.
+// If ClientCAPath is set, clients are required to present a certificate signed by that CA.
+func (o *Options) Config(logger *zap.Logger) (*tls.Config, error) {
+	if o.CertPath == "" || o.KeyPath == "" {
+		return nil, errors.New("you requested TLS but configuration does not include a path to cert and/or key")
+	}
+	minVersion, err := parseVersion(o.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := o.getCertWatcher(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: watcher.getCertificate,
+	}
+	if o.ClientCAPath != "" {
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsCfg.ClientCAs = watcher.clientCAs()
+		// GetConfigForClient lets every new handshake see the latest client CA pool
+		tlsCfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := tlsCfg.Clone()
+			cfg.GetConfigForClient = nil
+			cfg.ClientCAs = watcher.clientCAs()
+			return cfg, nil
+		}
+	}
+	return tlsCfg, nil
+}
+
+// getCertWatcher returns the watcher of the previous Config call if it watches the same files,
+// so that repeated calls do not leak watchers. Otherwise the previous watcher is closed.
+func (o *Options) getCertWatcher(logger *zap.Logger) (*certWatcher, error) {
+	if w := o.certWatcher; w != nil {
+		if w.opts.CertPath == o.CertPath && w.opts.KeyPath == o.KeyPath && w.opts.ClientCAPath == o.ClientCAPath {
+			return w, nil
+		}
+	}
+	watcher, err := newCertWatcher(o, logger)
+	if err != nil {
+		return nil, err
+	}
+	if o.certWatcher != nil {
+		o.certWatcher.Close()
+	}
+	o.certWatcher = watcher
+	return watcher, nil
+}
+
+// Close stops watching the certificate files.
+func (o *Options) Close() error {
+	if o.certWatcher != nil {
+		return o.certWatcher.Close()
+	}
+	return nil
+}
+
+func parseVersion(version string) (uint16, error) {
+	if version == "" {
+		// Leaves the minimum version to the default of crypto/tls
+		return 0, nil
+	}
+	if v, ok := versions[version]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q, must be one of 1.0, 1.1, 1.2, 1.3", version)
+}
+
+func loadCertificate(certPath, keyPath string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(filepath.Clean(certPath), filepath.Clean(keyPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load server TLS cert and key")
+	}
+	return &cert, nil
+}
+
+func loadCertPool(caPath string) (*x509.CertPool, error) {
+	caPEM, err := ioutil.ReadFile(filepath.Clean(caPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load TLS client CA")
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("failed to parse TLS client CA %s", caPath)
+	}
+	return certPool, nil
+}
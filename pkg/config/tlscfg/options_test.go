@@ -0,0 +1,299 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscfg
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, commonName string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) write(t *testing.T, certPath, keyPath string) {
+	require.NoError(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600))
+	if keyPath == "" {
+		return
+	}
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+type testFiles struct {
+	dir      string
+	ca       *testCert
+	caPath   string
+	certPath string
+	keyPath  string
+}
+
+func newTestFiles(t *testing.T) *testFiles {
+	dir, err := ioutil.TempDir("", "tlscfg")
+	require.NoError(t, err)
+	f := &testFiles{
+		dir:      dir,
+		ca:       newTestCert(t, "ca", nil),
+		caPath:   filepath.Join(dir, "ca.pem"),
+		certPath: filepath.Join(dir, "server.pem"),
+		keyPath:  filepath.Join(dir, "server-key.pem"),
+	}
+	f.ca.write(t, f.caPath, "")
+	newTestCert(t, "server", f.ca).write(t, f.certPath, f.keyPath)
+	return f
+}
+
+func (f *testFiles) close() {
+	os.RemoveAll(f.dir)
+}
+
+func TestOptionsConfigErrors(t *testing.T) {
+	files := newTestFiles(t)
+	defer files.close()
+
+	testCases := []struct {
+		name    string
+		options Options
+		err     string
+	}{
+		{
+			name:    "missing cert",
+			options: Options{KeyPath: files.keyPath},
+			err:     "you requested TLS but configuration does not include a path to cert and/or key",
+		},
+		{
+			name:    "bad version",
+			options: Options{CertPath: files.certPath, KeyPath: files.keyPath, MinVersion: "2.0"},
+			err:     `unsupported TLS version "2.0", must be one of 1.0, 1.1, 1.2, 1.3`,
+		},
+		{
+			name:    "missing key file",
+			options: Options{CertPath: files.certPath, KeyPath: filepath.Join(files.dir, "missing.pem")},
+			err:     "failed to load server TLS cert and key",
+		},
+		{
+			name:    "missing client CA file",
+			options: Options{CertPath: files.certPath, KeyPath: files.keyPath, ClientCAPath: filepath.Join(files.dir, "missing.pem")},
+			err:     "failed to load TLS client CA",
+		},
+		{
+			name:    "invalid client CA file",
+			options: Options{CertPath: files.certPath, KeyPath: files.keyPath, ClientCAPath: files.keyPath},
+			err:     "failed to parse TLS client CA",
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := test.options.Config(zap.NewNop())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+			assert.Nil(t, cfg)
+			assert.NoError(t, test.options.Close())
+		})
+	}
+}
+
+func TestOptionsConfigDefaultMinVersion(t *testing.T) {
+	files := newTestFiles(t)
+	defer files.close()
+
+	opts := &Options{CertPath: files.certPath, KeyPath: files.keyPath}
+	cfg, err := opts.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer opts.Close()
+
+	// The minimum version is left to crypto/tls
+	assert.Zero(t, cfg.MinVersion)
+}
+
+func TestOptionsConfig(t *testing.T) {
+	files := newTestFiles(t)
+	defer files.close()
+
+	opts := &Options{CertPath: files.certPath, KeyPath: files.keyPath, MinVersion: "1.3"}
+	cfg, err := opts.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer opts.Close()
+
+	assert.EqualValues(t, tls.VersionTLS13, cfg.MinVersion)
+	assert.Equal(t, tls.NoClientCert, cfg.ClientAuth)
+	assert.Nil(t, cfg.GetConfigForClient)
+	cert, err := cfg.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.NotNil(t, cert)
+}
+
+func TestOptionsConfigReusesWatcher(t *testing.T) {
+	files := newTestFiles(t)
+	defer files.close()
+
+	opts := &Options{CertPath: files.certPath, KeyPath: files.keyPath}
+	_, err := opts.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer opts.Close()
+	watcher := opts.certWatcher
+
+	_, err = opts.Config(zap.NewNop())
+	require.NoError(t, err)
+	assert.True(t, watcher == opts.certWatcher, "the watcher of the same files is reused")
+
+	opts.ClientCAPath = files.caPath
+	_, err = opts.Config(zap.NewNop())
+	require.NoError(t, err)
+	assert.False(t, watcher == opts.certWatcher, "a new watcher is created for other files")
+}
+
+func TestMutualTLS(t *testing.T) {
+	files := newTestFiles(t)
+	defer files.close()
+
+	opts := &Options{CertPath: files.certPath, KeyPath: files.keyPath, ClientCAPath: files.caPath}
+	cfg, err := opts.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer opts.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(files.ca.cert)
+	dial := func(certs []tls.Certificate) error {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			Certificates: certs,
+		})
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		// with TLS 1.3 a rejected client certificate is only reported on the first read
+		_, err = conn.Read(make([]byte, 2))
+		return err
+	}
+
+	assert.NoError(t, dial([]tls.Certificate{newTestCert(t, "client", files.ca).tlsCertificate()}))
+	assert.Error(t, dial(nil), "client certificate is required")
+	untrusted := newTestCert(t, "client", newTestCert(t, "other-ca", nil))
+	assert.Error(t, dial([]tls.Certificate{untrusted.tlsCertificate()}), "client certificate must be signed by the client CA")
+}
+
+func TestCertificateReload(t *testing.T) {
+	files := newTestFiles(t)
+	defer files.close()
+
+	opts := &Options{CertPath: files.certPath, KeyPath: files.keyPath, ClientCAPath: files.caPath}
+	cfg, err := opts.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer opts.Close()
+
+	original, err := cfg.GetCertificate(nil)
+	require.NoError(t, err)
+
+	// a broken file must not replace the loaded certificate
+	require.NoError(t, ioutil.WriteFile(files.certPath, []byte("invalid"), 0600))
+	time.Sleep(100 * time.Millisecond)
+	cert, err := cfg.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, original, cert)
+
+	updated := newTestCert(t, "server-2", files.ca)
+	updated.write(t, files.certPath, files.keyPath)
+	for i := 0; i < 100; i++ {
+		if cert, _ = cfg.GetCertificate(nil); cert != original {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NotEqual(t, original, cert)
+	assert.Equal(t, updated.der, cert.Certificate[0])
+
+	newCA := newTestCert(t, "ca-2", nil)
+	newCA.write(t, files.caPath, "")
+	for i := 0; i < 100; i++ {
+		clientCfg, err := cfg.GetConfigForClient(nil)
+		require.NoError(t, err)
+		if len(clientCfg.ClientCAs.Subjects()) == 1 && string(clientCfg.ClientCAs.Subjects()[0]) == string(newCA.cert.RawSubject) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("client CA was not reloaded")
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashring
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// replicas is the number of points each peer occupies on the ring, which evens out the load
+const replicas = 100
+
+// Ring is a consistent hash ring of peers. Rings built from the same set of peers
+// map keys to the same peers, regardless of the order in which the peers are given,
+// and only the keys of added or removed peers move when the set changes.
+type Ring struct {
+	points []uint64
+	owners map[uint64]string
+	peers  []string
+}
+
+// New creates a Ring of the given peers, duplicates are ignored.
+func New(peers []string) *Ring {
+	r := &Ring{owners: make(map[uint64]string, len(peers)*replicas)}
+	seen := make(map[string]struct{}, len(peers))
+	for _, peer := range peers {
+		if _, ok := seen[peer]; ok {
+			continue
+		}
+		seen[peer] = struct{}{}
+		r.peers = append(r.peers, peer)
+		for i := 0; i < replicas; i++ {
+			point := hashString(peer + "#" + strconv.Itoa(i))
+			// on the (unlikely) collision the lexicographically smaller peer wins, to stay order independent
+			if owner, ok := r.owners[point]; ok && owner < peer {
+				continue
+			} else if !ok {
+				r.points = append(r.points, point)
+			}
+			r.owners[point] = peer
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	sort.Strings(r.peers)
+	return r
+}
+
+// Peers returns the sorted peers of the ring.
+func (r *Ring) Peers() []string {
+	return r.peers
+}
+
+// Get returns the peer owning the key, or an empty string if the ring is empty.
+func (r *Ring) Get(key uint64) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= key })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// TraceKey returns the ring key of a trace ID.
+func TraceKey(traceID model.TraceID) uint64 {
+	return mix(traceID.High ^ mix(traceID.Low))
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return mix(h.Sum64())
+}
+
+// mix is the finalizer of splitmix64, it spreads small differences of the input over all bits
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
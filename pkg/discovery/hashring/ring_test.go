@@ -0,0 +1,63 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashring
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestEmptyRing(t *testing.T) {
+	r := New(nil)
+	assert.Empty(t, r.Peers())
+	assert.Equal(t, "", r.Get(42))
+}
+
+func TestRingIsOrderIndependent(t *testing.T) {
+	r1 := New([]string{"a:1", "b:1", "c:1"})
+	r2 := New([]string{"c:1", "a:1", "b:1", "a:1"})
+	assert.Equal(t, []string{"a:1", "b:1", "c:1"}, r2.Peers())
+	for i := uint64(0); i < 1000; i++ {
+		key := TraceKey(model.NewTraceID(0, i))
+		assert.Equal(t, r1.Get(key), r2.Get(key))
+	}
+}
+
+func TestRingDistribution(t *testing.T) {
+	peers := []string{"a:1", "b:1", "c:1", "d:1"}
+	r := New(peers)
+	counts := make(map[string]int)
+	for i := uint64(0); i < 10000; i++ {
+		counts[r.Get(TraceKey(model.NewTraceID(0, i)))]++
+	}
+	for _, peer := range peers {
+		assert.InDelta(t, 2500, counts[peer], 750, fmt.Sprintf("peer %s", peer))
+	}
+}
+
+func TestRingRemovePeerOnlyMovesItsKeys(t *testing.T) {
+	r1 := New([]string{"a:1", "b:1", "c:1"})
+	r2 := New([]string{"a:1", "b:1"})
+	for i := uint64(0); i < 1000; i++ {
+		key := TraceKey(model.NewTraceID(i, i))
+		if owner := r1.Get(key); owner != "c:1" {
+			assert.Equal(t, owner, r2.Get(key))
+		}
+	}
+}
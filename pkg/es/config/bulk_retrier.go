@@ -0,0 +1,303 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/es/deadletter"
+)
+
+const (
+	// retryQueueSize is the maximum number of batches waiting to be retried
+	retryQueueSize       = 100
+	reasonRequestFailed  = "request_failed"
+	reasonRetryQueueFull = "retry_queue_full"
+)
+
+// retriableStatuses are the bulk item statuses that indicate a transient failure,
+// e.g. 429 when Elasticsearch rejects documents because its write queue is full.
+var retriableStatuses = map[int]bool{408: true, 429: true, 502: true, 503: true, 504: true, 507: true}
+
+type bulkFunc func(ctx context.Context, requests []elastic.BulkableRequest) (*elastic.BulkResponse, error)
+
+// bulkFailure is a bulk request that Elasticsearch did not accept.
+type bulkFailure struct {
+	request   elastic.BulkableRequest
+	status    int
+	reason    string
+	err       string
+	retriable bool
+}
+
+type retryBatch struct {
+	failures []bulkFailure
+	attempt  int
+}
+
+// bulkRetrier inspects bulk responses item by item, retries transient failures with
+// exponential backoff and hands permanently rejected documents to a dead-letter sink.
+// Each batch is retried on its own timer, so that a slow retry doesn't hold back the others.
+type bulkRetrier struct {
+	bulk       bulkFunc
+	sink       deadletter.Sink
+	logger     *zap.Logger
+	factory    metrics.Factory
+	maxRetries int
+	backoff    elastic.Backoff
+	// maxBackoff is the delay of the attempts beyond those the backoff accepts
+	maxBackoff time.Duration
+
+	retries      metrics.Counter
+	deadLettered metrics.Counter
+	dropped      metrics.Counter
+	failures     sync.Map // reason -> metrics.Counter
+
+	mux sync.Mutex
+	// pending holds the timers of the batches waiting to be retried
+	pending map[*retryBatch]*time.Timer
+	closed  bool
+	// wg tracks the pending and in-flight retries
+	wg sync.WaitGroup
+}
+
+func newBulkRetrier(
+	bulk bulkFunc,
+	sink deadletter.Sink,
+	maxRetries int,
+	backoff elastic.Backoff,
+	maxBackoff time.Duration,
+	logger *zap.Logger,
+	metricsFactory metrics.Factory,
+) *bulkRetrier {
+	r := &bulkRetrier{
+		bulk:         bulk,
+		sink:         sink,
+		logger:       logger,
+		factory:      metricsFactory,
+		maxRetries:   maxRetries,
+		backoff:      backoff,
+		maxBackoff:   maxBackoff,
+		retries:      metricsFactory.Counter(metrics.Options{Name: "bulk_index.retries"}),
+		deadLettered: metricsFactory.Counter(metrics.Options{Name: "bulk_index.dead_lettered"}),
+		dropped:      metricsFactory.Counter(metrics.Options{Name: "bulk_index.dropped"}),
+		pending:      make(map[*retryBatch]*time.Timer),
+	}
+	return r
+}
+
+// handle processes the outcome of a bulk request made on the given attempt, 0 being the first one.
+// The bulk processor keeps and resends the requests that failed as a whole, so err must only be
+// passed for the retries sent by the retrier itself.
+func (r *bulkRetrier) handle(requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error, attempt int) {
+	var retry []bulkFailure
+	for _, failure := range bulkFailures(requests, response, err) {
+		r.failureCounter(failure.reason).Inc(1)
+		if failure.retriable && attempt < r.maxRetries {
+			retry = append(retry, failure)
+			continue
+		}
+		r.deadLetter(failure, attempt+1)
+	}
+	if len(retry) == 0 {
+		return
+	}
+	batch := &retryBatch{failures: retry, attempt: attempt + 1}
+	if reason, ok := r.schedule(batch); !ok {
+		for _, failure := range retry {
+			if reason != "" {
+				failure.reason = reason
+			}
+			r.deadLetter(failure, attempt+1)
+		}
+	}
+}
+
+// schedule starts the timer of the batch retry. If the batch cannot be retried, it returns false
+// with the reason to dead-letter it with, empty to keep the reason of each failure.
+func (r *bulkRetrier) schedule(batch *retryBatch) (string, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.closed {
+		return "", false
+	}
+	if len(r.pending) >= retryQueueSize {
+		return reasonRetryQueueFull, false
+	}
+	r.wg.Add(1)
+	r.pending[batch] = time.AfterFunc(r.delay(batch.attempt), func() { r.retry(batch) })
+	r.retries.Inc(int64(len(batch.failures)))
+	return "", true
+}
+
+func (r *bulkRetrier) retry(batch *retryBatch) {
+	defer r.wg.Done()
+	r.mux.Lock()
+	delete(r.pending, batch)
+	r.mux.Unlock()
+
+	requests := make([]elastic.BulkableRequest, len(batch.failures))
+	for i, failure := range batch.failures {
+		requests[i] = failure.request
+	}
+	response, err := r.bulk(context.Background(), requests)
+	r.handle(requests, response, err, batch.attempt)
+}
+
+func bulkFailures(requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) []bulkFailure {
+	var failures []bulkFailure
+	if err != nil {
+		for _, request := range requests {
+			failures = append(failures, bulkFailure{request: request, reason: reasonRequestFailed, err: err.Error(), retriable: true})
+		}
+		return failures
+	}
+	if response == nil || !response.Errors {
+		return nil
+	}
+	// the response items are in the same order as the requests
+	for i, item := range response.Items {
+		if i >= len(requests) {
+			break
+		}
+		for _, result := range item {
+			if result.Error == nil && result.Status < 300 {
+				continue
+			}
+			failure := bulkFailure{
+				request:   requests[i],
+				status:    result.Status,
+				reason:    "status_" + strconv.Itoa(result.Status),
+				retriable: retriableStatuses[result.Status],
+			}
+			if result.Error != nil {
+				if result.Error.Type != "" {
+					failure.reason = result.Error.Type
+				}
+				failure.err = result.Error.Reason
+			}
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+func (r *bulkRetrier) failureCounter(reason string) metrics.Counter {
+	if counter, ok := r.failures.Load(reason); ok {
+		return counter.(metrics.Counter)
+	}
+	counter := r.factory.Counter(metrics.Options{Name: "bulk_index.failures", Tags: map[string]string{"reason": reason}})
+	actual, _ := r.failures.LoadOrStore(reason, counter)
+	return actual.(metrics.Counter)
+}
+
+// delay returns the backoff before the given attempt. Exponential backoffs refuse the attempts
+// whose delay would exceed their maximum, they are retried after the maximum backoff.
+func (r *bulkRetrier) delay(attempt int) time.Duration {
+	if delay, ok := r.backoff.Next(attempt); ok {
+		return delay
+	}
+	return r.maxBackoff
+}
+
+func (r *bulkRetrier) deadLetterBatch(batch *retryBatch) {
+	for _, failure := range batch.failures {
+		r.deadLetter(failure, batch.attempt)
+	}
+}
+
+func (r *bulkRetrier) deadLetter(failure bulkFailure, attempts int) {
+	entry := newDeadLetterEntry(failure, attempts)
+	fields := []zap.Field{
+		zap.String("index", entry.Index),
+		zap.Int("status", entry.Status),
+		zap.String("reason", entry.Reason),
+		zap.String("error", entry.Error),
+		zap.Int("attempts", attempts),
+	}
+	if r.sink == nil {
+		r.logger.Error("Elasticsearch rejected document, dropping it", fields...)
+		r.dropped.Inc(1)
+		return
+	}
+	if err := r.sink.Write(entry); err != nil {
+		r.logger.Error("Failed to write rejected document to dead-letter sink", append(fields, zap.Error(err))...)
+		r.dropped.Inc(1)
+		return
+	}
+	r.logger.Warn("Elasticsearch rejected document, sent it to dead-letter sink", fields...)
+	r.deadLettered.Inc(1)
+}
+
+func newDeadLetterEntry(failure bulkFailure, attempts int) *deadletter.Entry {
+	entry := &deadletter.Entry{
+		Timestamp: time.Now(),
+		Status:    failure.status,
+		Reason:    failure.reason,
+		Error:     failure.err,
+		Attempts:  attempts,
+	}
+	lines, err := failure.request.Source()
+	if err != nil || len(lines) == 0 {
+		return entry
+	}
+	var action map[string]struct {
+		Index string `json:"_index"`
+		Type  string `json:"_type"`
+		ID    string `json:"_id"`
+	}
+	if json.Unmarshal([]byte(lines[0]), &action) == nil {
+		for _, meta := range action {
+			entry.Index, entry.Type, entry.ID = meta.Index, meta.Type, meta.ID
+		}
+	}
+	if len(lines) > 1 && json.Valid([]byte(lines[1])) {
+		entry.Document = json.RawMessage(lines[1])
+	}
+	return entry
+}
+
+// Close stops retrying, dead-letters the pending retries and closes the sink once the
+// retries in flight are done.
+func (r *bulkRetrier) Close() error {
+	r.mux.Lock()
+	r.closed = true
+	var stopped []*retryBatch
+	for batch, timer := range r.pending {
+		// the batches whose timer already fired are retried one last time
+		if timer.Stop() {
+			delete(r.pending, batch)
+			stopped = append(stopped, batch)
+		}
+	}
+	r.mux.Unlock()
+	for _, batch := range stopped {
+		r.deadLetterBatch(batch)
+		r.wg.Done()
+	}
+	r.wg.Wait()
+	if r.sink != nil {
+		return r.sink.Close()
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/es/deadletter"
+)
+
+type fakeSink struct {
+	sync.Mutex
+	entries []*deadletter.Entry
+	err     error
+	closed  bool
+}
+
+func (s *fakeSink) Write(entry *deadletter.Entry) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *fakeSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSink) written() []*deadletter.Entry {
+	s.Lock()
+	defer s.Unlock()
+	return append([]*deadletter.Entry(nil), s.entries...)
+}
+
+func indexRequest(id string) elastic.BulkableRequest {
+	return elastic.NewBulkIndexRequest().Index("jaeger-span-2019-01-01").Type("span").Id(id).Doc(map[string]string{"traceID": id})
+}
+
+func itemResponse(statuses ...int) *elastic.BulkResponse {
+	response := &elastic.BulkResponse{}
+	for _, status := range statuses {
+		item := &elastic.BulkResponseItem{Status: status}
+		switch status {
+		case 429:
+			item.Error = &elastic.ErrorDetails{Type: "es_rejected_execution_exception", Reason: "rejected execution"}
+		case 400:
+			item.Error = &elastic.ErrorDetails{Type: "mapper_parsing_exception", Reason: "failed to parse"}
+		}
+		if status >= 300 {
+			response.Errors = true
+		}
+		response.Items = append(response.Items, map[string]*elastic.BulkResponseItem{"index": item})
+	}
+	return response
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for i := 0; i < 1000 && !condition(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	require.True(t, condition())
+}
+
+func TestBulkRetrierRetriesRejectedItems(t *testing.T) {
+	var mu sync.Mutex
+	var retried []elastic.BulkableRequest
+	bulk := func(ctx context.Context, requests []elastic.BulkableRequest) (*elastic.BulkResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		retried = append(retried, requests...)
+		return itemResponse(201), nil
+	}
+	sink := &fakeSink{}
+	metricsFactory := metricstest.NewFactory(0)
+	r := newBulkRetrier(bulk, sink, 3, elastic.NewConstantBackoff(time.Millisecond), time.Millisecond, zap.NewNop(), metricsFactory)
+
+	requests := []elastic.BulkableRequest{indexRequest("1"), indexRequest("2"), indexRequest("3")}
+	r.handle(requests, itemResponse(201, 429, 400), nil, 0)
+
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(retried) == 1
+	})
+	assert.Equal(t, requests[1], retried[0])
+	require.NoError(t, r.Close())
+	assert.True(t, sink.closed)
+
+	entries := sink.written()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "jaeger-span-2019-01-01", entries[0].Index)
+	assert.Equal(t, "span", entries[0].Type)
+	assert.Equal(t, "3", entries[0].ID)
+	assert.Equal(t, 400, entries[0].Status)
+	assert.Equal(t, "mapper_parsing_exception", entries[0].Reason)
+	assert.Equal(t, "failed to parse", entries[0].Error)
+	assert.Equal(t, 1, entries[0].Attempts)
+	assert.JSONEq(t, `{"traceID":"3"}`, string(entries[0].Document))
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "bulk_index.retries", Value: 1},
+		metricstest.ExpectedMetric{Name: "bulk_index.dead_lettered", Value: 1},
+		metricstest.ExpectedMetric{Name: "bulk_index.dropped", Value: 0},
+		metricstest.ExpectedMetric{Name: "bulk_index.failures", Tags: map[string]string{"reason": "es_rejected_execution_exception"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "bulk_index.failures", Tags: map[string]string{"reason": "mapper_parsing_exception"}, Value: 1},
+	)
+}
+
+func TestBulkRetrierExhaustsRetries(t *testing.T) {
+	bulk := func(ctx context.Context, requests []elastic.BulkableRequest) (*elastic.BulkResponse, error) {
+		return nil, errors.New("connection refused")
+	}
+	sink := &fakeSink{}
+	metricsFactory := metricstest.NewFactory(0)
+	r := newBulkRetrier(bulk, sink, 2, elastic.NewConstantBackoff(time.Millisecond), time.Millisecond, zap.NewNop(), metricsFactory)
+
+	r.handle([]elastic.BulkableRequest{indexRequest("1")}, nil, errors.New("connection refused"), 0)
+
+	waitFor(t, func() bool { return len(sink.written()) == 1 })
+	require.NoError(t, r.Close())
+
+	entry := sink.written()[0]
+	assert.Equal(t, reasonRequestFailed, entry.Reason)
+	assert.Equal(t, "connection refused", entry.Error)
+	assert.Equal(t, 3, entry.Attempts)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "bulk_index.retries", Value: 2},
+		metricstest.ExpectedMetric{Name: "bulk_index.dead_lettered", Value: 1},
+		metricstest.ExpectedMetric{Name: "bulk_index.failures", Tags: map[string]string{"reason": reasonRequestFailed}, Value: 3},
+	)
+}
+
+func TestBulkRetrierFullQueue(t *testing.T) {
+	sink := &fakeSink{}
+	metricsFactory := metricstest.NewFactory(0)
+	r := newBulkRetrier(nil, sink, 1, elastic.NewConstantBackoff(time.Hour), time.Hour, zap.NewNop(), metricsFactory)
+
+	for i := 0; i < retryQueueSize+2; i++ {
+		r.handle([]elastic.BulkableRequest{indexRequest("1")}, itemResponse(429), nil, 0)
+	}
+	require.NoError(t, r.Close())
+
+	reasons := make(map[string]int)
+	for _, entry := range sink.written() {
+		reasons[entry.Reason]++
+	}
+	assert.Equal(t, map[string]int{reasonRetryQueueFull: 2, "es_rejected_execution_exception": retryQueueSize}, reasons)
+}
+
+func TestBulkRetrierRetriesBatchesIndependently(t *testing.T) {
+	block := make(chan struct{})
+	retried := make(chan string, 2)
+	bulk := func(ctx context.Context, requests []elastic.BulkableRequest) (*elastic.BulkResponse, error) {
+		lines, _ := requests[0].Source()
+		if strings.Contains(lines[1], `"1"`) {
+			<-block
+		}
+		retried <- lines[1]
+		return itemResponse(201), nil
+	}
+	r := newBulkRetrier(bulk, nil, 1, elastic.NewConstantBackoff(time.Millisecond), time.Millisecond, zap.NewNop(), metricstest.NewFactory(0))
+
+	r.handle([]elastic.BulkableRequest{indexRequest("1")}, itemResponse(429), nil, 0)
+	r.handle([]elastic.BulkableRequest{indexRequest("2")}, itemResponse(429), nil, 0)
+	// the second batch is retried while the first one is still in flight
+	select {
+	case doc := <-retried:
+		assert.JSONEq(t, `{"traceID":"2"}`, doc)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second batch was not retried")
+	}
+	close(block)
+	require.NoError(t, r.Close())
+	assert.JSONEq(t, `{"traceID":"1"}`, <-retried)
+}
+
+func TestBulkRetrierCloseDeadLettersPendingRetries(t *testing.T) {
+	bulk := func(ctx context.Context, requests []elastic.BulkableRequest) (*elastic.BulkResponse, error) {
+		t.Error("retry should not be attempted after close")
+		return nil, nil
+	}
+	sink := &fakeSink{}
+	r := newBulkRetrier(bulk, sink, 3, elastic.NewConstantBackoff(time.Hour), time.Hour, zap.NewNop(), metricstest.NewFactory(0))
+
+	r.handle([]elastic.BulkableRequest{indexRequest("1"), indexRequest("2")}, itemResponse(503, 503), nil, 0)
+	require.NoError(t, r.Close())
+
+	entries := sink.written()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "status_503", entries[0].Reason)
+	assert.Equal(t, 1, entries[0].Attempts)
+}
+
+func TestBulkRetrierDropsWithoutSink(t *testing.T) {
+	testCases := []struct {
+		name string
+		sink deadletter.Sink
+	}{
+		{name: "no sink"},
+		{name: "failing sink", sink: &fakeSink{err: errors.New("disk full")}},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			metricsFactory := metricstest.NewFactory(0)
+			r := newBulkRetrier(nil, test.sink, 0, elastic.StopBackoff{}, 0, zap.NewNop(), metricsFactory)
+			r.handle([]elastic.BulkableRequest{indexRequest("1")}, itemResponse(400), nil, 0)
+			require.NoError(t, r.Close())
+			metricsFactory.AssertCounterMetrics(t,
+				metricstest.ExpectedMetric{Name: "bulk_index.dead_lettered", Value: 0},
+				metricstest.ExpectedMetric{Name: "bulk_index.dropped", Value: 1},
+			)
+		})
+	}
+}
+
+func TestNewDeadLetterSink(t *testing.T) {
+	c := &Configuration{DeadLetterFile: "/tmp/rejected.json", DeadLetterKafkaTopic: "rejected"}
+	_, err := c.newDeadLetterSink(zap.NewNop())
+	assert.EqualError(t, err, "only one of the dead-letter file and Kafka topic can be configured")
+
+	c = &Configuration{}
+	sink, err := c.newDeadLetterSink(zap.NewNop())
+	require.NoError(t, err)
+	assert.Nil(t, sink)
+
+	c = &Configuration{DeadLetterFile: t.Name() + ".json"}
+	sink, err = c.newDeadLetterSink(zap.NewNop())
+	require.NoError(t, err)
+	defer os.Remove(c.DeadLetterFile)
+	assert.IsType(t, &deadletter.FileSink{}, sink)
+	require.NoError(t, sink.Close())
+}
+
+func TestBulkRetrierDelay(t *testing.T) {
+	r := newBulkRetrier(nil, nil, 100, elastic.NewExponentialBackoff(time.Millisecond, 100*time.Millisecond), 100*time.Millisecond, zap.NewNop(), metricstest.NewFactory(0))
+	defer r.Close()
+	first := r.delay(0)
+	assert.True(t, first >= time.Millisecond && first < 100*time.Millisecond, "first delay %v", first)
+	// the exponential backoff refuses attempts past its maximum
+	_, ok := r.backoff.Next(50)
+	require.False(t, ok)
+	assert.Equal(t, 100*time.Millisecond, r.delay(50))
+}
@@ -33,36 +33,49 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/pkg/es"
+	"github.com/jaegertracing/jaeger/pkg/es/deadletter"
 	"github.com/jaegertracing/jaeger/pkg/es/wrapper"
+	"github.com/jaegertracing/jaeger/pkg/kafka/producer"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 	storageMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 )
 
 // Configuration describes the configuration properties needed to connect to an ElasticSearch cluster
 type Configuration struct {
-	Servers               []string
-	Username              string
-	Password              string
-	TokenFilePath         string
-	AllowTokenFromContext bool
-	Sniffer               bool          // https://github.com/olivere/elastic/wiki/Sniffing
-	MaxNumSpans           int           // defines maximum number of spans to fetch from storage per query
-	MaxSpanAge            time.Duration `yaml:"max_span_age"` // configures the maximum lookback on span reads
-	NumShards             int64         `yaml:"shards"`
-	NumReplicas           int64         `yaml:"replicas"`
-	Timeout               time.Duration `validate:"min=500"`
-	BulkSize              int
-	BulkWorkers           int
-	BulkActions           int
-	BulkFlushInterval     time.Duration
-	IndexPrefix           string
-	TagsFilePath          string
-	AllTagsAsFields       bool
-	TagDotReplacement     string
-	Enabled               bool
-	TLS                   TLSConfig
-	UseReadWriteAliases   bool
-	CreateIndexTemplates  bool
+	Servers                []string
+	Username               string
+	Password               string
+	TokenFilePath          string
+	AllowTokenFromContext  bool
+	Sniffer                bool          // https://github.com/olivere/elastic/wiki/Sniffing
+	MaxNumSpans            int           // defines maximum number of spans to fetch from storage per query
+	MaxSpanAge             time.Duration `yaml:"max_span_age"` // configures the maximum lookback on span reads
+	NumShards              int64         `yaml:"shards"`
+	NumReplicas            int64         `yaml:"replicas"`
+	Timeout                time.Duration `validate:"min=500"`
+	BulkSize               int
+	BulkWorkers            int
+	BulkActions            int
+	BulkFlushInterval      time.Duration
+	BulkMaxRetries         int
+	BulkRetryBackoff       time.Duration
+	BulkRetryMaxBackoff    time.Duration
+	DeadLetterFile         string
+	DeadLetterKafkaTopic   string
+	DeadLetterKafkaBrokers []string
+	IndexPrefix            string
+	IndexDateLayout        string
+	IndexFrequency         string
+	ServiceCacheTTL        time.Duration
+	ServiceCacheSize       int
+	ServiceCachePrewarm    bool
+	TagsFilePath           string
+	AllTagsAsFields        bool
+	TagDotReplacement      string
+	Enabled                bool
+	TLS                    TLSConfig
+	UseReadWriteAliases    bool
+	CreateIndexTemplates   bool
 }
 
 // TLSConfig describes the configuration properties to connect tls enabled ElasticSearch cluster
@@ -77,11 +90,17 @@ type TLSConfig struct {
 // ClientBuilder creates new es.Client
 type ClientBuilder interface {
 	NewClient(logger *zap.Logger, metricsFactory metrics.Factory) (es.Client, error)
+	NewRawClient(logger *zap.Logger) (*elastic.Client, error)
 	GetNumShards() int64
 	GetNumReplicas() int64
 	GetMaxSpanAge() time.Duration
 	GetMaxNumSpans() int
 	GetIndexPrefix() string
+	GetIndexDateLayout() string
+	GetIndexFrequency() string
+	GetServiceCacheTTL() time.Duration
+	GetServiceCacheSize() int
+	GetServiceCachePrewarm() bool
 	GetTagsFilePath() string
 	GetAllTagsAsFields() bool
 	GetTagDotReplacement() string
@@ -93,18 +112,26 @@ type ClientBuilder interface {
 
 // NewClient creates a new ElasticSearch client
 func (c *Configuration) NewClient(logger *zap.Logger, metricsFactory metrics.Factory) (es.Client, error) {
-	if len(c.Servers) < 1 {
-		return nil, errors.New("No servers specified")
-	}
-	options, err := c.getConfigOptions(logger)
+	rawClient, err := c.NewRawClient(logger)
 	if err != nil {
 		return nil, err
 	}
 
-	rawClient, err := elastic.NewClient(options...)
+	sink, err := c.newDeadLetterSink(logger)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "failed to create dead-letter sink")
 	}
+	retrier := newBulkRetrier(
+		func(ctx context.Context, requests []elastic.BulkableRequest) (*elastic.BulkResponse, error) {
+			return rawClient.Bulk().Add(requests...).Do(ctx)
+		},
+		sink,
+		c.BulkMaxRetries,
+		elastic.NewExponentialBackoff(c.BulkRetryBackoff, c.BulkRetryMaxBackoff),
+		c.BulkRetryMaxBackoff,
+		logger,
+		metricsFactory,
+	)
 
 	sm := storageMetrics.NewWriteMetrics(metricsFactory, "bulk_index")
 	m := sync.Map{}
@@ -120,40 +147,27 @@ func (c *Configuration) NewClient(logger *zap.Logger, metricsFactory metrics.Fac
 			}
 			m.Delete(id)
 
-			// log individual errors, note that err might be false and these errors still present
-			if response != nil && response.Errors {
-				for _, it := range response.Items {
-					for key, val := range it {
-						if val.Error != nil {
-							logger.Error("Elasticsearch part of bulk request failed", zap.String("map-key", key),
-								zap.Reflect("response", val))
-						}
-					}
-				}
-			}
-
 			sm.Emit(err, time.Since(start.(time.Time)))
 			if err != nil {
-				var failed int
-				if response == nil {
-					failed = 0
-				} else {
-					failed = len(response.Failed())
-				}
-				total := len(requests)
 				logger.Error("Elasticsearch could not process bulk request",
-					zap.Int("request_count", total),
-					zap.Int("failed_count", failed),
-					zap.Error(err),
-					zap.Any("response", response))
+					zap.Int("request_count", len(requests)),
+					zap.Error(err))
+			}
+			// the bulk processor retries failed requests with its backoff and keeps them until
+			// they are sent, only the items rejected by a successful request are retried here
+			if err == nil {
+				retrier.handle(requests, response, nil, 0)
 			}
 		}).
 		BulkSize(c.BulkSize).
 		Workers(c.BulkWorkers).
 		BulkActions(c.BulkActions).
 		FlushInterval(c.BulkFlushInterval).
+		// rejected items are retried by the retrier, which does not block the workers
+		RetryItemStatusCodes().
 		Do(context.Background())
 	if err != nil {
+		retrier.Close()
 		return nil, err
 	}
 
@@ -168,7 +182,53 @@ func (c *Configuration) NewClient(logger *zap.Logger, metricsFactory metrics.Fac
 	}
 	logger.Info("Elasticsearch detected", zap.Int("version", esVersion))
 
-	return eswrapper.WrapESClient(rawClient, service, esVersion), nil
+	return &bulkClient{ClientWrapper: eswrapper.WrapESClient(rawClient, service, esVersion), retrier: retrier}, nil
+}
+
+// bulkClient closes the bulk retrier once the bulk processor is flushed.
+type bulkClient struct {
+	eswrapper.ClientWrapper
+	retrier *bulkRetrier
+}
+
+// Close implements io.Closer.
+func (c *bulkClient) Close() error {
+	err := c.ClientWrapper.Close()
+	if retrierErr := c.retrier.Close(); err == nil {
+		err = retrierErr
+	}
+	return err
+}
+
+func (c *Configuration) newDeadLetterSink(logger *zap.Logger) (deadletter.Sink, error) {
+	if c.DeadLetterFile != "" && c.DeadLetterKafkaTopic != "" {
+		return nil, errors.New("only one of the dead-letter file and Kafka topic can be configured")
+	}
+	if c.DeadLetterFile != "" {
+		return deadletter.NewFileSink(c.DeadLetterFile)
+	}
+	if c.DeadLetterKafkaTopic != "" {
+		producerConfig := producer.Configuration{Brokers: c.DeadLetterKafkaBrokers}
+		kafkaProducer, err := producerConfig.NewProducer()
+		if err != nil {
+			return nil, err
+		}
+		return deadletter.NewKafkaSink(kafkaProducer, c.DeadLetterKafkaTopic, logger), nil
+	}
+	return nil, nil
+}
+
+// NewRawClient creates a new elastic client without the bulk processor, for administrative
+// tasks such as index maintenance.
+func (c *Configuration) NewRawClient(logger *zap.Logger) (*elastic.Client, error) {
+	if len(c.Servers) < 1 {
+		return nil, errors.New("No servers specified")
+	}
+	options, err := c.getConfigOptions(logger)
+	if err != nil {
+		return nil, err
+	}
+	return elastic.NewClient(options...)
 }
 
 // ApplyDefaults copies settings from source unless its own value is non-zero.
@@ -206,6 +266,27 @@ func (c *Configuration) ApplyDefaults(source *Configuration) {
 	if c.BulkFlushInterval == 0 {
 		c.BulkFlushInterval = source.BulkFlushInterval
 	}
+	if c.BulkMaxRetries == 0 {
+		c.BulkMaxRetries = source.BulkMaxRetries
+	}
+	if c.BulkRetryBackoff == 0 {
+		c.BulkRetryBackoff = source.BulkRetryBackoff
+	}
+	if c.BulkRetryMaxBackoff == 0 {
+		c.BulkRetryMaxBackoff = source.BulkRetryMaxBackoff
+	}
+	if c.ServiceCacheTTL == 0 {
+		c.ServiceCacheTTL = source.ServiceCacheTTL
+	}
+	if c.ServiceCacheSize == 0 {
+		c.ServiceCacheSize = source.ServiceCacheSize
+	}
+	if c.IndexDateLayout == "" {
+		c.IndexDateLayout = source.IndexDateLayout
+	}
+	if c.IndexFrequency == "" {
+		c.IndexFrequency = source.IndexFrequency
+	}
 }
 
 // GetNumShards returns number of shards from Configuration
@@ -233,6 +314,31 @@ func (c *Configuration) GetIndexPrefix() string {
 	return c.IndexPrefix
 }
 
+// GetIndexDateLayout returns the layout of the date in index names
+func (c *Configuration) GetIndexDateLayout() string {
+	return c.IndexDateLayout
+}
+
+// GetIndexFrequency returns how often a new date based index is created
+func (c *Configuration) GetIndexFrequency() string {
+	return c.IndexFrequency
+}
+
+// GetServiceCacheTTL returns how long a service and operation pair is cached before it is written again
+func (c *Configuration) GetServiceCacheTTL() time.Duration {
+	return c.ServiceCacheTTL
+}
+
+// GetServiceCacheSize returns the maximum number of cached service and operation pairs
+func (c *Configuration) GetServiceCacheSize() int {
+	return c.ServiceCacheSize
+}
+
+// GetServiceCachePrewarm indicates whether the service and operation cache is loaded from storage on startup
+func (c *Configuration) GetServiceCachePrewarm() bool {
+	return c.ServiceCachePrewarm
+}
+
 // GetTagsFilePath returns a path to file containing tag keys
 func (c *Configuration) GetTagsFilePath() string {
 	return c.TagsFilePath
@@ -0,0 +1,40 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Entry is a document that Elasticsearch permanently rejected.
+type Entry struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Index     string          `json:"index,omitempty"`
+	Type      string          `json:"type,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Status    int             `json:"status,omitempty"`
+	Reason    string          `json:"reason"`
+	Error     string          `json:"error,omitempty"`
+	Attempts  int             `json:"attempts"`
+	Document  json.RawMessage `json:"document,omitempty"`
+}
+
+// Sink stores rejected documents so that they can be inspected or replayed later.
+type Sink interface {
+	Write(entry *Entry) error
+	io.Closer
+}
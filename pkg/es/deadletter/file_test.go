@@ -0,0 +1,71 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deadletter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rejected.json")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	entry := &Entry{
+		Timestamp: time.Date(2019, 6, 10, 0, 0, 0, 0, time.UTC),
+		Index:     "jaeger-span-2019-06-10",
+		Type:      "span",
+		Status:    400,
+		Reason:    "mapper_parsing_exception",
+		Error:     "failed to parse",
+		Attempts:  1,
+		Document:  json.RawMessage(`{"traceID":"1"}`),
+	}
+	require.NoError(t, sink.Write(entry))
+	require.NoError(t, sink.Write(&Entry{Reason: "request_failed", Attempts: 4}))
+	require.NoError(t, sink.Close())
+
+	// entries are appended to existing files
+	sink, err = NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(&Entry{Reason: "status_429", Attempts: 4}))
+	require.NoError(t, sink.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	var actual Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &actual))
+	assert.Equal(t, *entry, actual)
+	assert.Contains(t, lines[2], `"reason":"status_429"`)
+}
+
+func TestFileSinkError(t *testing.T) {
+	_, err := NewFileSink("/does/not/exist/rejected.json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletter
+
+import (
+	"encoding/json"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// KafkaSink publishes entries as JSON messages to a Kafka topic.
+type KafkaSink struct {
+	producer sarama.AsyncProducer
+	topic    string
+}
+
+// NewKafkaSink creates a KafkaSink publishing to topic. Delivery failures are logged.
+func NewKafkaSink(producer sarama.AsyncProducer, topic string, logger *zap.Logger) *KafkaSink {
+	go func() {
+		for range producer.Successes() {
+		}
+	}()
+	go func() {
+		for e := range producer.Errors() {
+			logger.Error("Failed to publish dead letter to Kafka", zap.String("topic", topic), zap.Error(e.Err))
+		}
+	}()
+	return &KafkaSink{producer: producer, topic: topic}
+}
+
+// Write implements Sink.
+func (s *KafkaSink) Write(entry *Entry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	msg := &sarama.ProducerMessage{Topic: s.topic, Value: sarama.ByteEncoder(value)}
+	if entry.Index != "" {
+		msg.Key = sarama.StringEncoder(entry.Index)
+	}
+	s.producer.Input() <- msg
+	return nil
+}
+
+// Close implements io.Closer.
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletter
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	saramaMocks "github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+)
+
+func TestKafkaSink(t *testing.T) {
+	producer := saramaMocks.NewAsyncProducer(t, nil)
+	logger, logBuffer := testutils.NewLogger()
+	sink := NewKafkaSink(producer, "dead-letters", logger)
+
+	var published []byte
+	producer.ExpectInputWithCheckerFunctionAndSucceed(func(value []byte) error {
+		published = value
+		return nil
+	})
+	producer.ExpectInputAndFail(errors.New("broker down"))
+
+	require.NoError(t, sink.Write(&Entry{Index: "jaeger-span-2019-06-10", Reason: "status_429", Attempts: 4}))
+	require.NoError(t, sink.Write(&Entry{Reason: "request_failed", Attempts: 4}))
+	require.NoError(t, sink.Close())
+
+	var entry Entry
+	require.NoError(t, json.Unmarshal(published, &entry))
+	assert.Equal(t, Entry{Index: "jaeger-span-2019-06-10", Reason: "status_429", Attempts: 4}, entry)
+	for i := 0; i < 100 && !strings.Contains(logBuffer.String(), "broker down"); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Contains(t, logBuffer.String(), "Failed to publish dead letter to Kafka")
+}
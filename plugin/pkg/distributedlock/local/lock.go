@@ -0,0 +1,38 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"time"
+)
+
+// Lock is a lock for storage backends that are local to a single process, such as memory and Badger.
+// Since there is only one participant, every lease is always granted.
+type Lock struct{}
+
+// NewLock creates a new local lock.
+func NewLock() *Lock {
+	return &Lock{}
+}
+
+// Acquire implements distributedlock.Lock#Acquire and always acquires the lease.
+func (l *Lock) Acquire(resource string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+// Forfeit implements distributedlock.Lock#Forfeit and always forfeits the lease.
+func (l *Lock) Forfeit(resource string) (bool, error) {
+	return true, nil
+}
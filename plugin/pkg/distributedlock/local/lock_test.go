@@ -0,0 +1,37 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/pkg/distributedlock"
+)
+
+var _ distributedlock.Lock = new(Lock)
+
+func TestLock(t *testing.T) {
+	l := NewLock()
+	acquired, err := l.Acquire("resource", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	forfeited, err := l.Forfeit("resource")
+	assert.NoError(t, err)
+	assert.True(t, forfeited)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptive
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+// maxProbabilities caps the number of distinct probabilities recorded per operation in one interval.
+const maxProbabilities = 10
+
+// aggregator counts root spans per service and operation and periodically
+// flushes the counts to storage, where they are picked up by the processor.
+type aggregator struct {
+	sync.Mutex
+
+	storage             samplingstore.Store
+	logger              *zap.Logger
+	aggregationInterval time.Duration
+
+	// currentThroughput holds the throughput of the current interval.
+	currentThroughput serviceOperationThroughput
+
+	operationsCounter metrics.Counter
+	servicesCounter   metrics.Counter
+	flushErrors       metrics.Counter
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewAggregator creates a throughput aggregator that flushes aggregated throughput to storage
+// every aggregationInterval.
+func NewAggregator(
+	aggregationInterval time.Duration,
+	storage samplingstore.Store,
+	metricsFactory metrics.Factory,
+	logger *zap.Logger,
+) ss.Aggregator {
+	metricsFactory = metricsFactory.Namespace(metrics.NSOptions{Name: "adaptive_sampling_aggregator"})
+	return &aggregator{
+		storage:             storage,
+		logger:              logger,
+		aggregationInterval: aggregationInterval,
+		currentThroughput:   make(serviceOperationThroughput),
+		operationsCounter:   metricsFactory.Counter(metrics.Options{Name: "operations_recorded"}),
+		servicesCounter:     metricsFactory.Counter(metrics.Options{Name: "services_recorded"}),
+		flushErrors:         metricsFactory.Counter(metrics.Options{Name: "flush_errors"}),
+		stop:                make(chan struct{}),
+	}
+}
+
+// Start implements Aggregator#Start.
+func (a *aggregator) Start() {
+	a.wg.Add(1)
+	go a.runAggregationLoop()
+}
+
+// Close implements io.Closer.
+func (a *aggregator) Close() error {
+	close(a.stop)
+	a.wg.Wait()
+	return nil
+}
+
+func (a *aggregator) runAggregationLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(a.aggregationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.flush()
+		case <-a.stop:
+			a.flush()
+			return
+		}
+	}
+}
+
+// flush swaps out the current throughput and saves it to storage.
+func (a *aggregator) flush() {
+	a.Lock()
+	current := a.currentThroughput
+	a.currentThroughput = make(serviceOperationThroughput)
+	a.Unlock()
+
+	if len(current) == 0 {
+		return
+	}
+	var throughput []*model.Throughput
+	for _, operations := range current {
+		a.servicesCounter.Inc(1)
+		for _, t := range operations {
+			throughput = append(throughput, t)
+		}
+	}
+	a.operationsCounter.Inc(int64(len(throughput)))
+	if err := a.storage.InsertThroughput(throughput); err != nil {
+		a.logger.Error("failed to save throughput", zap.Error(err))
+		a.flushErrors.Inc(1)
+	}
+}
+
+// RecordThroughput implements Aggregator#RecordThroughput.
+func (a *aggregator) RecordThroughput(service, operation, samplerType string, probability float64) {
+	a.Lock()
+	defer a.Unlock()
+	if _, ok := a.currentThroughput[service]; !ok {
+		a.currentThroughput[service] = make(map[string]*model.Throughput)
+	}
+	throughput, ok := a.currentThroughput[service][operation]
+	if !ok {
+		throughput = &model.Throughput{
+			Service:       service,
+			Operation:     operation,
+			Probabilities: make(map[string]struct{}),
+		}
+		a.currentThroughput[service][operation] = throughput
+	}
+	if len(throughput.Probabilities) < maxProbabilities {
+		throughput.Probabilities[TruncateFloat(probability)] = struct{}{}
+	}
+	// Only probabilistically sampled root spans are counted. Spans sampled by the lower bound
+	// sampler still create an entry with zero count, so that the processor learns about the operation.
+	if samplerType == model.SamplerTypeProbabilistic {
+		throughput.Count++
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptive
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jaegertracing/jaeger/storage/samplingstore/mocks"
+)
+
+func TestAggregator(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+
+	flushed := make(chan []*model.Throughput, 1)
+	mockStorage := &mocks.Store{}
+	mockStorage.On("InsertThroughput", mock.AnythingOfType("[]*model.Throughput")).Return(nil).Run(func(args mock.Arguments) {
+		flushed <- args.Get(0).([]*model.Throughput)
+	})
+
+	a := NewAggregator(time.Millisecond, mockStorage, metricsFactory, zap.NewNop())
+	a.RecordThroughput("A", "GET", model.SamplerTypeProbabilistic, 0.001)
+	a.RecordThroughput("B", "POST", model.SamplerTypeProbabilistic, 0.001)
+	a.RecordThroughput("C", "GET", model.SamplerTypeProbabilistic, 0.001)
+	a.RecordThroughput("A", "POST", model.SamplerTypeProbabilistic, 0.001)
+	a.RecordThroughput("A", "GET", model.SamplerTypeProbabilistic, 0.001)
+	a.RecordThroughput("A", "GET", model.SamplerTypeLowerBound, 0.001)
+
+	a.Start()
+	select {
+	case throughput := <-flushed:
+		assert.Len(t, throughput, 4)
+	case <-time.After(5 * time.Second):
+		t.Fatal("throughput was not flushed")
+	}
+	require.NoError(t, a.Close())
+
+	metricsFactory.AssertCounterMetrics(t, []metricstest.ExpectedMetric{
+		{Name: "adaptive_sampling_aggregator.operations_recorded", Value: 4},
+		{Name: "adaptive_sampling_aggregator.services_recorded", Value: 3},
+	}...)
+}
+
+func TestAggregatorFlushError(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+
+	mockStorage := &mocks.Store{}
+	mockStorage.On("InsertThroughput", mock.Anything).Return(errors.New("storage error"))
+
+	a := NewAggregator(time.Hour, mockStorage, metricsFactory, zap.NewNop())
+	a.Start()
+	a.RecordThroughput("A", "GET", model.SamplerTypeProbabilistic, 0.001)
+	// Close flushes the pending throughput
+	require.NoError(t, a.Close())
+
+	mockStorage.AssertNumberOfCalls(t, "InsertThroughput", 1)
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "adaptive_sampling_aggregator.flush_errors", Value: 1,
+	})
+}
+
+func TestRecordThroughput(t *testing.T) {
+	a := NewAggregator(time.Hour, &mocks.Store{}, metrics.NullFactory, zap.NewNop()).(*aggregator)
+
+	// lower bound sampled spans are recorded with zero count
+	a.RecordThroughput("A", "GET", model.SamplerTypeLowerBound, 0.001)
+	require.Len(t, a.currentThroughput["A"], 1)
+	assert.Equal(t, int64(0), a.currentThroughput["A"]["GET"].Count)
+
+	a.RecordThroughput("A", "GET", model.SamplerTypeProbabilistic, 0.001)
+	a.RecordThroughput("A", "GET", model.SamplerTypeProbabilistic, 0.002)
+	assert.Equal(t, int64(2), a.currentThroughput["A"]["GET"].Count)
+	assert.Equal(t, map[string]struct{}{"0.001000": {}, "0.002000": {}}, a.currentThroughput["A"]["GET"].Probabilities)
+
+	// the number of distinct probabilities is capped
+	for i := 0; i < maxProbabilities*2; i++ {
+		a.RecordThroughput("A", "GET", model.SamplerTypeProbabilistic, float64(i)/100)
+	}
+	assert.Len(t, a.currentThroughput["A"]["GET"].Probabilities, maxProbabilities)
+}
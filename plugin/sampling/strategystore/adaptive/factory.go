@@ -15,20 +15,32 @@
 package adaptive
 
 import (
+	"errors"
 	"flag"
+	"os"
 
 	"github.com/spf13/viper"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
+	"github.com/jaegertracing/jaeger/pkg/distributedlock"
+	"github.com/jaegertracing/jaeger/plugin/sampling/leaderelection"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
 )
 
+const samplingLock = "sampling_lock"
+
+var errNoSamplingStore = errors.New("adaptive sampling requires a storage backend that supports sampling storage")
+
 // Factory implements strategystore.Factory for an adaptive strategy store.
 type Factory struct {
 	options        Options
 	logger         *zap.Logger
 	metricsFactory metrics.Factory
+	lock           distributedlock.Lock
+	store          samplingstore.Store
 }
 
 // NewFactory creates a new Factory.
@@ -50,14 +62,53 @@ func (f *Factory) InitFromViper(v *viper.Viper) {
 }
 
 // Initialize implements strategystore.Factory
-func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+func (f *Factory) Initialize(metricsFactory metrics.Factory, ssFactory storage.SamplingStoreFactory, logger *zap.Logger) error {
+	if ssFactory == nil {
+		return errNoSamplingStore
+	}
 	f.logger = logger
 	f.metricsFactory = metricsFactory
+	var err error
+	if f.lock, err = ssFactory.CreateLock(); err != nil {
+		return err
+	}
+	if f.store, err = ssFactory.CreateSamplingStore(); err != nil {
+		return err
+	}
 	return nil
 }
 
 // CreateStrategyStore implements strategystore.Factory
 func (f *Factory) CreateStrategyStore() (strategystore.StrategyStore, error) {
-	// TODO
-	return nil, nil
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	participant := leaderelection.NewElectionParticipant(f.lock, samplingLock, leaderelection.ElectionParticipantOptions{
+		LeaderLeaseRefreshInterval:   f.options.LeaderLeaseRefreshInterval,
+		FollowerLeaseRefreshInterval: f.options.FollowerLeaseRefreshInterval,
+		Logger:                       f.logger,
+	})
+	p, err := NewProcessor(f.options, hostname, f.store, participant, f.metricsFactory, f.logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := participant.Start(); err != nil {
+		return nil, err
+	}
+	if err := p.(*processor).Start(); err != nil {
+		participant.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// CreateAggregator implements strategystore.AggregatorFactory
+func (f *Factory) CreateAggregator() (strategystore.Aggregator, error) {
+	if f.options.CalculationInterval == 0 {
+		return nil, errNonZero
+	}
+	a := NewAggregator(f.options.CalculationInterval, f.store, f.metricsFactory, f.logger)
+	a.Start()
+	return a, nil
 }
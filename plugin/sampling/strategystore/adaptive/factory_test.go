@@ -15,20 +15,29 @@
 package adaptive
 
 import (
+	"errors"
+	"io"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
 	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	lmocks "github.com/jaegertracing/jaeger/pkg/distributedlock/mocks"
 	"github.com/jaegertracing/jaeger/plugin"
+	smocks "github.com/jaegertracing/jaeger/storage/mocks"
+	sstoreMocks "github.com/jaegertracing/jaeger/storage/samplingstore/mocks"
 )
 
 var _ ss.Factory = new(Factory)
 var _ plugin.Configurable = new(Factory)
+var _ ss.AggregatorFactory = new(Factory)
 
 func TestFactory(t *testing.T) {
 	f := NewFactory()
@@ -61,7 +70,51 @@ func TestFactory(t *testing.T) {
 	assert.Equal(t, time.Second, f.options.LeaderLeaseRefreshInterval)
 	assert.Equal(t, time.Second*2, f.options.FollowerLeaseRefreshInterval)
 
-	assert.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	lock := &lmocks.Lock{}
+	lock.On("Acquire", samplingLock, mock.Anything).Return(true, nil)
+	store := &sstoreMocks.Store{}
+	store.On("GetLatestProbabilities").Return(model.ServiceOperationProbabilities{}, nil)
+	store.On("GetThroughput", mock.Anything, mock.Anything).Return([]*model.Throughput{}, nil)
+	ssFactory := &smocks.SamplingStoreFactory{}
+	ssFactory.On("CreateLock").Return(lock, nil)
+	ssFactory.On("CreateSamplingStore").Return(store, nil)
+
+	assert.NoError(t, f.Initialize(metrics.NullFactory, ssFactory, zap.NewNop()))
+	strategyStore, err := f.CreateStrategyStore()
+	require.NoError(t, err)
+	assert.NotNil(t, strategyStore)
+	assert.NoError(t, strategyStore.(io.Closer).Close())
+
+	aggregator, err := f.CreateAggregator()
+	require.NoError(t, err)
+	assert.NotNil(t, aggregator)
+	assert.NoError(t, aggregator.Close())
+}
+
+func TestFactoryInitializeErrors(t *testing.T) {
+	f := NewFactory()
+	assert.Equal(t, errNoSamplingStore, f.Initialize(metrics.NullFactory, nil, zap.NewNop()))
+
+	ssFactory := &smocks.SamplingStoreFactory{}
+	ssFactory.On("CreateLock").Return(nil, errors.New("lock error"))
+	assert.EqualError(t, f.Initialize(metrics.NullFactory, ssFactory, zap.NewNop()), "lock error")
+
+	ssFactory = &smocks.SamplingStoreFactory{}
+	ssFactory.On("CreateLock").Return(&lmocks.Lock{}, nil)
+	ssFactory.On("CreateSamplingStore").Return(nil, errors.New("store error"))
+	assert.EqualError(t, f.Initialize(metrics.NullFactory, ssFactory, zap.NewNop()), "store error")
+}
+
+func TestFactoryCreateStrategyStoreError(t *testing.T) {
+	f := NewFactory()
+	ssFactory := &smocks.SamplingStoreFactory{}
+	ssFactory.On("CreateLock").Return(&lmocks.Lock{}, nil)
+	ssFactory.On("CreateSamplingStore").Return(&sstoreMocks.Store{}, nil)
+	require.NoError(t, f.Initialize(metrics.NullFactory, ssFactory, zap.NewNop()))
+
+	// options are not initialized, so the processor cannot be created
 	_, err := f.CreateStrategyStore()
-	assert.NoError(t, err)
+	assert.Equal(t, errNonZero, err)
+	_, err = f.CreateAggregator()
+	assert.Equal(t, errNonZero, err)
 }
@@ -24,14 +24,17 @@ import (
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
 	"github.com/jaegertracing/jaeger/plugin"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategystore/adaptive"
 	"github.com/jaegertracing/jaeger/plugin/sampling/strategystore/static"
+	"github.com/jaegertracing/jaeger/storage"
 )
 
 const (
-	staticStrategyStoreType = "static"
+	staticStrategyStoreType   = "static"
+	adaptiveStrategyStoreType = "adaptive"
 )
 
-var allSamplingTypes = []string{staticStrategyStoreType} // TODO support adaptive
+var allSamplingTypes = []string{staticStrategyStoreType, adaptiveStrategyStoreType}
 
 // Factory implements strategystore.Factory interface as a meta-factory for strategy storage components.
 type Factory struct {
@@ -61,6 +64,8 @@ func (f *Factory) getFactoryOfType(factoryType string) (strategystore.Factory, e
 	switch factoryType {
 	case staticStrategyStoreType:
 		return static.NewFactory(), nil
+	case adaptiveStrategyStoreType:
+		return adaptive.NewFactory(), nil
 	default:
 		return nil, fmt.Errorf("unknown sampling strategy store type %s. Valid types are %v", factoryType, allSamplingTypes)
 	}
@@ -85,9 +90,9 @@ func (f *Factory) InitFromViper(v *viper.Viper) {
 }
 
 // Initialize implements strategystore.Factory
-func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+func (f *Factory) Initialize(metricsFactory metrics.Factory, ssFactory storage.SamplingStoreFactory, logger *zap.Logger) error {
 	for _, factory := range f.factories {
-		if err := factory.Initialize(metricsFactory, logger); err != nil {
+		if err := factory.Initialize(metricsFactory, ssFactory, logger); err != nil {
 			return err
 		}
 	}
@@ -102,3 +107,16 @@ func (f *Factory) CreateStrategyStore() (strategystore.StrategyStore, error) {
 	}
 	return factory.CreateStrategyStore()
 }
+
+// CreateAggregator returns the throughput aggregator required by the configured strategy store,
+// or nil if the strategy store does not need one.
+func (f *Factory) CreateAggregator() (strategystore.Aggregator, error) {
+	factory, ok := f.factories[f.StrategyStoreType]
+	if !ok {
+		return nil, fmt.Errorf("no %s strategy store registered", f.StrategyStoreType)
+	}
+	if aggregatorFactory, ok := factory.(strategystore.AggregatorFactory); ok {
+		return aggregatorFactory.CreateAggregator()
+	}
+	return nil, nil
+}
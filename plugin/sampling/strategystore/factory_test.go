@@ -28,6 +28,7 @@ import (
 
 	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
 	"github.com/jaegertracing/jaeger/plugin"
+	"github.com/jaegertracing/jaeger/storage"
 )
 
 var _ ss.Factory = new(Factory)
@@ -43,13 +44,13 @@ func TestNewFactory(t *testing.T) {
 	mock := new(mockFactory)
 	f.factories[staticStrategyStoreType] = mock
 
-	assert.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	assert.NoError(t, f.Initialize(metrics.NullFactory, nil, zap.NewNop()))
 	_, err = f.CreateStrategyStore()
 	assert.NoError(t, err)
 
 	// force the mock to return errors
 	mock.retError = true
-	assert.EqualError(t, f.Initialize(metrics.NullFactory, zap.NewNop()), "error initializing store")
+	assert.EqualError(t, f.Initialize(metrics.NullFactory, nil, zap.NewNop()), "error initializing store")
 	_, err = f.CreateStrategyStore()
 	assert.EqualError(t, err, "error creating store")
 
@@ -57,11 +58,39 @@ func TestNewFactory(t *testing.T) {
 	_, err = f.CreateStrategyStore()
 	assert.EqualError(t, err, "no nonsense strategy store registered")
 
+	f, err = NewFactory(FactoryConfig{StrategyStoreType: adaptiveStrategyStoreType})
+	require.NoError(t, err)
+	assert.NotEmpty(t, f.factories[adaptiveStrategyStoreType])
+
 	_, err = NewFactory(FactoryConfig{StrategyStoreType: "nonsense"})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "unknown sampling strategy store type")
 }
 
+func TestCreateAggregator(t *testing.T) {
+	f, err := NewFactory(FactoryConfig{StrategyStoreType: staticStrategyStoreType})
+	require.NoError(t, err)
+
+	f.factories[staticStrategyStoreType] = new(mockFactory)
+	a, err := f.CreateAggregator()
+	assert.NoError(t, err)
+	assert.Nil(t, a)
+
+	mock := &mockAggregatorFactory{}
+	f.factories[staticStrategyStoreType] = mock
+	a, err = f.CreateAggregator()
+	assert.NoError(t, err)
+	assert.Equal(t, mock.aggregator, a)
+
+	mock.retError = true
+	_, err = f.CreateAggregator()
+	assert.EqualError(t, err, "error creating aggregator")
+
+	f.StrategyStoreType = "nonsense"
+	_, err = f.CreateAggregator()
+	assert.EqualError(t, err, "no nonsense strategy store registered")
+}
+
 func TestConfigurable(t *testing.T) {
 	clearEnv()
 	defer clearEnv()
@@ -105,9 +134,21 @@ func (f *mockFactory) CreateStrategyStore() (ss.StrategyStore, error) {
 	return nil, nil
 }
 
-func (f *mockFactory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+func (f *mockFactory) Initialize(metricsFactory metrics.Factory, ssFactory storage.SamplingStoreFactory, logger *zap.Logger) error {
 	if f.retError {
 		return errors.New("error initializing store")
 	}
 	return nil
 }
+
+type mockAggregatorFactory struct {
+	mockFactory
+	aggregator ss.Aggregator
+}
+
+func (f *mockAggregatorFactory) CreateAggregator() (ss.Aggregator, error) {
+	if f.retError {
+		return nil, errors.New("error creating aggregator")
+	}
+	return f.aggregator, nil
+}
@@ -16,27 +16,34 @@ package static
 
 import (
 	"flag"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 const (
-	samplingStrategiesFile = "sampling.strategies-file"
+	samplingStrategiesFile           = "sampling.strategies-file"
+	samplingStrategiesReloadInterval = "sampling.strategies-reload-interval"
 )
 
 // Options holds configuration for the static sampling strategy store.
 type Options struct {
-	// StrategiesFile is the path for the sampling strategies file in JSON format
+	// StrategiesFile is the path or http(s) URL for the sampling strategies file in JSON format
 	StrategiesFile string
+	// ReloadInterval is the time interval to reload sampling strategies fetched from a URL, 0 disables
+	// reloading. Files are reloaded whenever they change.
+	ReloadInterval time.Duration
 }
 
 // AddFlags adds flags for Options
 func AddFlags(flagSet *flag.FlagSet) {
-	flagSet.String(samplingStrategiesFile, "", "The path for the sampling strategies file in JSON format. See sampling documentation to see format of the file")
+	flagSet.String(samplingStrategiesFile, "", "The path for the sampling strategies file in JSON format, or an http(s) URL to fetch it from. See sampling documentation to see format of the file")
+	flagSet.Duration(samplingStrategiesReloadInterval, 0, "Reload interval to check and reload sampling strategies fetched from a URL, zero value means no reloading. Files are reloaded whenever they change")
 }
 
 // InitFromViper initializes Options with properties from viper
 func (opts *Options) InitFromViper(v *viper.Viper) *Options {
 	opts.StrategiesFile = v.GetString(samplingStrategiesFile)
+	opts.ReloadInterval = v.GetDuration(samplingStrategiesReloadInterval)
 	return opts
 }
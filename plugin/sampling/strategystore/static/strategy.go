@@ -35,7 +35,10 @@ type serviceStrategy struct {
 }
 
 // strategies holds a default sampling strategy and service specific sampling strategies.
+// The operation strategies of the default strategy apply to every service, unless the
+// service defines a strategy for the same operation.
 type strategies struct {
-	DefaultStrategy   *strategy          `json:"default_strategy"`
-	ServiceStrategies []*serviceStrategy `json:"service_strategies"`
+	DefaultStrategy                  *serviceStrategy   `json:"default_strategy"`
+	DefaultLowerBoundTracesPerSecond float64            `json:"default_lower_bound_traces_per_second"`
+	ServiceStrategies                []*serviceStrategy `json:"service_strategies"`
 }
@@ -16,11 +16,19 @@ package static
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
@@ -28,72 +36,321 @@ import (
 	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
 )
 
+// httpTimeout is the timeout for fetching the strategies from a URL
+const httpTimeout = 5 * time.Second
+
 type strategyStore struct {
 	logger *zap.Logger
 
+	// storedStrategies holds *storedStrategies, it is swapped atomically on reload
+	storedStrategies atomic.Value
+
+	cancelFunc context.CancelFunc
+	// watcher reloads the strategies when the file changes, nil for URLs
+	watcher *fsnotify.Watcher
+}
+
+type storedStrategies struct {
 	defaultStrategy   *sampling.SamplingStrategyResponse
 	serviceStrategies map[string]*sampling.SamplingStrategyResponse
 }
 
 // NewStrategyStore creates a strategy store that holds static sampling strategies.
+// The strategies are reloaded when options.StrategiesFile changes or, for URLs, every
+// options.ReloadInterval if it is positive, keeping the previous strategies if the new ones
+// fail to load or are invalid.
 func NewStrategyStore(options Options, logger *zap.Logger) (ss.StrategyStore, error) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
 	h := &strategyStore{
-		logger:            logger,
-		serviceStrategies: make(map[string]*sampling.SamplingStrategyResponse),
+		logger:     logger,
+		cancelFunc: cancelFunc,
 	}
-	strategies, err := loadStrategies(options.StrategiesFile)
+	content, err := readStrategies(options.StrategiesFile)
 	if err != nil {
+		cancelFunc()
 		return nil, err
 	}
-	h.parseStrategies(strategies)
+	strategies, err := parseStrategiesContent(content)
+	if err != nil {
+		cancelFunc()
+		return nil, err
+	}
+	if strategies != nil {
+		// invalid values are accepted at startup, as they were before they were validated
+		if err := validateStrategies(strategies); err != nil {
+			logger.Warn("Invalid sampling strategies, they will be rejected when reloaded", zap.Error(err))
+		}
+	}
+	h.storedStrategies.Store(h.parseStrategies(strategies))
+
+	switch {
+	case options.StrategiesFile == "":
+	case isURL(options.StrategiesFile):
+		if options.ReloadInterval > 0 {
+			go h.autoUpdateStrategies(ctx, options.StrategiesFile, options.ReloadInterval, content)
+		}
+	default:
+		if err := h.watchStrategies(options.StrategiesFile, content); err != nil {
+			cancelFunc()
+			return nil, errors.Wrap(err, "Failed to watch strategies file")
+		}
+	}
 	return h, nil
 }
 
 // GetSamplingStrategy implements StrategyStore#GetSamplingStrategy.
 func (h *strategyStore) GetSamplingStrategy(serviceName string) (*sampling.SamplingStrategyResponse, error) {
-	if strategy, ok := h.serviceStrategies[serviceName]; ok {
+	stored := h.storedStrategies.Load().(*storedStrategies)
+	if strategy, ok := stored.serviceStrategies[serviceName]; ok {
 		return strategy, nil
 	}
-	return h.defaultStrategy, nil
+	return stored.defaultStrategy, nil
+}
+
+// Close stops reloading the strategies.
+func (h *strategyStore) Close() error {
+	h.cancelFunc()
+	if h.watcher != nil {
+		return h.watcher.Close()
+	}
+	return nil
+}
+
+// watchStrategies reloads the strategies whenever the file changes.
+func (h *strategyStore) watchStrategies(file string, lastContent []byte) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// watching the directory rather than the file survives atomic replacements,
+	// e.g. when a Kubernetes config map volume swaps its symlinks
+	if err := watcher.Add(filepath.Dir(filepath.Clean(file))); err != nil {
+		watcher.Close()
+		return err
+	}
+	h.watcher = watcher
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&fsnotify.Chmod == fsnotify.Chmod {
+					continue
+				}
+				// unchanged content, e.g. for events of other files in the directory, is ignored
+				lastContent = h.reloadStrategies(file, lastContent)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				h.logger.Error("error watching sampling strategies file", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+func (h *strategyStore) autoUpdateStrategies(ctx context.Context, source string, interval time.Duration, lastContent []byte) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			lastContent = h.reloadStrategies(source, lastContent)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadStrategies loads the strategies from source and swaps them in if they changed and are valid.
+// It returns the content of the strategies currently in use.
+func (h *strategyStore) reloadStrategies(source string, lastContent []byte) []byte {
+	content, err := readStrategies(source)
+	if err != nil {
+		h.logger.Error("failed to reload sampling strategies, keeping previous strategies", zap.Error(err))
+		return lastContent
+	}
+	if bytes.Equal(content, lastContent) {
+		return lastContent
+	}
+	strategies, err := parseStrategiesContent(content)
+	if err == nil && strategies != nil {
+		if err = validateStrategies(strategies); err != nil {
+			err = errors.Wrap(err, "Invalid strategies")
+		}
+	}
+	if err != nil {
+		h.logger.Error("failed to reload sampling strategies, keeping previous strategies", zap.Error(err))
+		return lastContent
+	}
+	h.storedStrategies.Store(h.parseStrategies(strategies))
+	h.logger.Info("Reloaded sampling strategies", zap.String("source", source))
+	return content
 }
 
-// TODO good candidate for a global util function
-func loadStrategies(strategiesFile string) (*strategies, error) {
-	if strategiesFile == "" {
+// readStrategies reads the raw strategies from a file or, for http(s) URLs, from the network.
+func readStrategies(source string) ([]byte, error) {
+	if source == "" {
 		return nil, nil
 	}
-	bytes, err := ioutil.ReadFile(strategiesFile) /* nolint #nosec , this comes from an admin, not user */
+	if isURL(source) {
+		return downloadStrategies(source)
+	}
+	content, err := ioutil.ReadFile(source) /* nolint #nosec , this comes from an admin, not user */
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to open strategies file")
 	}
+	return content, nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+func downloadStrategies(url string) ([]byte, error) {
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to download strategies")
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read strategies")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Failed to download strategies: status code %d, body %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+func parseStrategiesContent(content []byte) (*strategies, error) {
+	if content == nil {
+		return nil, nil
+	}
 	var strategies strategies
-	if err := json.Unmarshal(bytes, &strategies); err != nil {
+	if err := json.Unmarshal(content, &strategies); err != nil {
 		return nil, errors.Wrap(err, "Failed to unmarshal strategies")
 	}
 	return &strategies, nil
 }
 
-func (h *strategyStore) parseStrategies(strategies *strategies) {
-	h.defaultStrategy = &defaultStrategy
+// validateStrategies checks the parameters of all strategies. Unknown strategy types are not
+// rejected here, they fall back to the default strategy when parsed.
+func validateStrategies(strategies *strategies) error {
+	if strategies.DefaultLowerBoundTracesPerSecond < 0 {
+		return fmt.Errorf("default lower bound traces per second must not be negative, got %v",
+			strategies.DefaultLowerBoundTracesPerSecond)
+	}
+	if strategies.DefaultStrategy != nil {
+		if err := validateStrategy(&strategies.DefaultStrategy.strategy); err != nil {
+			return errors.Wrap(err, "default strategy")
+		}
+		for _, op := range strategies.DefaultStrategy.OperationStrategies {
+			if err := validateStrategy(&op.strategy); err != nil {
+				return errors.Wrapf(err, "default strategy operation '%s'", op.Operation)
+			}
+		}
+	}
+	for _, s := range strategies.ServiceStrategies {
+		if err := validateStrategy(&s.strategy); err != nil {
+			return errors.Wrapf(err, "service '%s'", s.Service)
+		}
+		for _, op := range s.OperationStrategies {
+			if err := validateStrategy(&op.strategy); err != nil {
+				return errors.Wrapf(err, "service '%s' operation '%s'", s.Service, op.Operation)
+			}
+		}
+	}
+	return nil
+}
+
+func validateStrategy(strategy *strategy) error {
+	switch strategy.Type {
+	case samplerTypeProbabilistic:
+		if strategy.Param < 0 || strategy.Param > 1 {
+			return fmt.Errorf("probabilistic sampling rate must be between 0 and 1, got %v", strategy.Param)
+		}
+	case samplerTypeRateLimiting:
+		if strategy.Param < 0 || strategy.Param > math.MaxInt16 {
+			return fmt.Errorf("rate limiting traces per second must be between 0 and %d, got %v", math.MaxInt16, strategy.Param)
+		}
+	}
+	return nil
+}
+
+func (h *strategyStore) parseStrategies(strategies *strategies) *storedStrategies {
+	stored := &storedStrategies{
+		defaultStrategy:   &defaultStrategy,
+		serviceStrategies: make(map[string]*sampling.SamplingStrategyResponse),
+	}
 	if strategies == nil {
 		h.logger.Info("No sampling strategies provided, using defaults")
-		return
+		return stored
 	}
+	lowerBound := strategies.DefaultLowerBoundTracesPerSecond
 	if strategies.DefaultStrategy != nil {
-		h.defaultStrategy = h.parseStrategy(strategies.DefaultStrategy)
+		defaultServiceStrategy := *strategies.DefaultStrategy
+		if defaultServiceStrategy.Type == "" {
+			// the default strategy may only define operation strategies
+			defaultServiceStrategy.strategy = strategy{Type: samplerTypeProbabilistic, Param: defaultSamplingProbability}
+		}
+		stored.defaultStrategy = h.parseServiceStrategies(&defaultServiceStrategy, lowerBound)
 	}
+	defaultOpS := stored.defaultStrategy.OperationSampling
 	for _, s := range strategies.ServiceStrategies {
-		h.serviceStrategies[s.Service] = h.parseServiceStrategies(s)
+		resp := h.parseServiceStrategies(s, lowerBound)
+		if defaultOpS != nil {
+			if resp.OperationSampling != nil {
+				resp.OperationSampling.PerOperationStrategies = mergePerOperationStrategies(
+					resp.OperationSampling.PerOperationStrategies,
+					defaultOpS.PerOperationStrategies)
+			} else if resp.StrategyType == sampling.SamplingStrategyType_PROBABILISTIC {
+				// Clients ignore the service strategy when per-operation strategies are present,
+				// so the default operation strategies are only added to probabilistic services.
+				resp.OperationSampling = &sampling.PerOperationSamplingStrategies{
+					DefaultSamplingProbability:       resp.ProbabilisticSampling.SamplingRate,
+					DefaultLowerBoundTracesPerSecond: lowerBound,
+					PerOperationStrategies:           defaultOpS.PerOperationStrategies,
+				}
+			}
+		}
+		stored.serviceStrategies[s.Service] = resp
+	}
+	return stored
+}
+
+// mergePerOperationStrategies returns the service operation strategies followed by the
+// default operation strategies for operations not defined by the service.
+func mergePerOperationStrategies(
+	service []*sampling.OperationSamplingStrategy,
+	defaults []*sampling.OperationSamplingStrategy,
+) []*sampling.OperationSamplingStrategy {
+	operations := make(map[string]struct{}, len(service))
+	merged := make([]*sampling.OperationSamplingStrategy, 0, len(service)+len(defaults))
+	for _, s := range service {
+		operations[s.Operation] = struct{}{}
+		merged = append(merged, s)
+	}
+	for _, s := range defaults {
+		if _, ok := operations[s.Operation]; !ok {
+			merged = append(merged, s)
+		}
 	}
+	return merged
 }
 
-func (h *strategyStore) parseServiceStrategies(strategy *serviceStrategy) *sampling.SamplingStrategyResponse {
+func (h *strategyStore) parseServiceStrategies(strategy *serviceStrategy, lowerBound float64) *sampling.SamplingStrategyResponse {
 	resp := h.parseStrategy(&strategy.strategy)
 	if len(strategy.OperationStrategies) == 0 {
 		return resp
 	}
 	opS := &sampling.PerOperationSamplingStrategies{
-		DefaultSamplingProbability: defaultSamplingProbability,
+		DefaultSamplingProbability:       defaultSamplingProbability,
+		DefaultLowerBoundTracesPerSecond: lowerBound,
 	}
 	if resp.StrategyType == sampling.SamplingStrategyType_PROBABILISTIC {
 		opS.DefaultSamplingProbability = resp.ProbabilisticSampling.SamplingRate
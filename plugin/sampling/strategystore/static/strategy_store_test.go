@@ -15,12 +15,21 @@
 package static
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
 )
@@ -102,6 +111,64 @@ func TestPerOperationSamplingStrategies(t *testing.T) {
 	assert.EqualValues(t, makeResponse(sampling.SamplingStrategyType_PROBABILISTIC, 0.5), *s)
 }
 
+func TestDefaultOperationSamplingStrategies(t *testing.T) {
+	store, err := NewStrategyStore(Options{StrategiesFile: "fixtures/operation_strategies_with_defaults.json"}, zap.NewNop())
+	require.NoError(t, err)
+
+	// the default strategy carries the default operation strategies
+	s, err := store.GetSamplingStrategy("default")
+	require.NoError(t, err)
+	assert.EqualValues(t, 0.5, s.ProbabilisticSampling.SamplingRate)
+	require.NotNil(t, s.OperationSampling)
+	assert.EqualValues(t, 0.5, s.OperationSampling.DefaultSamplingProbability)
+	assert.EqualValues(t, 0.1, s.OperationSampling.DefaultLowerBoundTracesPerSecond)
+	assert.Equal(t, []*sampling.OperationSamplingStrategy{
+		makeOperationStrategy("/health", 0),
+		makeOperationStrategy("op1", 0.9),
+	}, s.OperationSampling.PerOperationStrategies)
+
+	// service operation strategies take precedence over the default ones
+	s, err = store.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	require.NotNil(t, s.OperationSampling)
+	assert.EqualValues(t, 0.8, s.OperationSampling.DefaultSamplingProbability)
+	assert.EqualValues(t, 0.1, s.OperationSampling.DefaultLowerBoundTracesPerSecond)
+	assert.Equal(t, []*sampling.OperationSamplingStrategy{
+		makeOperationStrategy("op1", 0.2),
+		makeOperationStrategy("/health", 0),
+	}, s.OperationSampling.PerOperationStrategies)
+
+	// services without operation strategies inherit the default ones
+	s, err = store.GetSamplingStrategy("bar")
+	require.NoError(t, err)
+	require.NotNil(t, s.OperationSampling)
+	assert.EqualValues(t, 0.3, s.OperationSampling.DefaultSamplingProbability)
+	assert.EqualValues(t, 0.1, s.OperationSampling.DefaultLowerBoundTracesPerSecond)
+	assert.Equal(t, []*sampling.OperationSamplingStrategy{
+		makeOperationStrategy("/health", 0),
+		makeOperationStrategy("op1", 0.9),
+	}, s.OperationSampling.PerOperationStrategies)
+
+	// rate limiting services are left untouched
+	s, err = store.GetSamplingStrategy("baz")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(sampling.SamplingStrategyType_RATE_LIMITING, 5), *s)
+}
+
+func TestDefaultOperationStrategiesWithoutType(t *testing.T) {
+	logger, buf := testutils.NewLogger()
+	content := []byte(`{"default_strategy": {"operation_strategies": [{"operation": "/health", "type": "probabilistic", "param": 0}]}}`)
+	strategies, err := parseStrategiesContent(content)
+	require.NoError(t, err)
+	store := &strategyStore{logger: logger}
+	stored := store.parseStrategies(strategies)
+	assert.Empty(t, buf.String())
+	assert.EqualValues(t, defaultSamplingProbability, stored.defaultStrategy.ProbabilisticSampling.SamplingRate)
+	require.NotNil(t, stored.defaultStrategy.OperationSampling)
+	assert.Equal(t, []*sampling.OperationSamplingStrategy{makeOperationStrategy("/health", 0)},
+		stored.defaultStrategy.OperationSampling.PerOperationStrategies)
+}
+
 func TestMissingServiceSamplingStrategyTypes(t *testing.T) {
 	logger, buf := testutils.NewLogger()
 	store, err := NewStrategyStore(Options{StrategiesFile: "fixtures/missing-service-types.json"}, logger)
@@ -180,6 +247,15 @@ func TestParseStrategy(t *testing.T) {
 	assert.Contains(t, buf.String(), "Failed to parse sampling strategy")
 }
 
+func makeOperationStrategy(operation string, probability float64) *sampling.OperationSamplingStrategy {
+	return &sampling.OperationSamplingStrategy{
+		Operation: operation,
+		ProbabilisticSampling: &sampling.ProbabilisticSamplingStrategy{
+			SamplingRate: probability,
+		},
+	}
+}
+
 func makeResponse(samplerType sampling.SamplingStrategyType, param float64) (resp sampling.SamplingStrategyResponse) {
 	resp.StrategyType = samplerType
 	if samplerType == sampling.SamplingStrategyType_PROBABILISTIC {
@@ -205,3 +281,153 @@ func TestDeepCopy(t *testing.T) {
 	assert.False(t, copy == s)
 	assert.EqualValues(t, copy, s)
 }
+
+func TestInvalidStrategies(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{
+			content: `{"default_strategy": {"type": "probabilistic", "param": 1.5}}`,
+			err:     "default strategy: probabilistic sampling rate must be between 0 and 1, got 1.5",
+		},
+		{
+			content: `{"service_strategies": [{"service": "foo", "type": "ratelimiting", "param": -1}]}`,
+			err:     "service 'foo': rate limiting traces per second must be between 0 and 32767, got -1",
+		},
+		{
+			content: `{"service_strategies": [{"service": "foo", "type": "probabilistic", "param": 0.1,
+				"operation_strategies": [{"operation": "op", "type": "probabilistic", "param": -0.1}]}]}`,
+			err: "service 'foo' operation 'op': probabilistic sampling rate must be between 0 and 1, got -0.1",
+		},
+		{
+			content: `{"default_strategy": {"operation_strategies": [{"operation": "op", "type": "probabilistic", "param": 2}]}}`,
+			err:     "default strategy operation 'op': probabilistic sampling rate must be between 0 and 1, got 2",
+		},
+		{
+			content: `{"default_lower_bound_traces_per_second": -1}`,
+			err:     "default lower bound traces per second must not be negative, got -1",
+		},
+	}
+	for _, test := range tests {
+		strategies, err := parseStrategiesContent([]byte(test.content))
+		require.NoError(t, err)
+		assert.EqualError(t, validateStrategies(strategies), test.err)
+	}
+}
+
+func TestInvalidStrategiesAtStartup(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "strategies")
+	require.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+	require.NoError(t, writeStrategies(tempFile.Name(), 1.5))
+
+	// files accepted before the strategies were validated still start the store
+	logger, buf := testutils.NewLogger()
+	store, err := NewStrategyStore(Options{StrategiesFile: tempFile.Name()}, logger)
+	require.NoError(t, err)
+	defer store.(*strategyStore).Close()
+	assert.Contains(t, buf.String(), "Invalid sampling strategies")
+	s, err := store.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(sampling.SamplingStrategyType_PROBABILISTIC, 1.5), *s)
+}
+
+func TestAutoUpdateStrategies(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "strategies")
+	require.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+	require.NoError(t, writeStrategies(tempFile.Name(), 0.5))
+
+	logger, buf := testutils.NewLogger()
+	store, err := NewStrategyStore(Options{StrategiesFile: tempFile.Name()}, logger)
+	require.NoError(t, err)
+	defer store.(*strategyStore).Close()
+
+	s, err := store.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(sampling.SamplingStrategyType_PROBABILISTIC, 0.5), *s)
+
+	require.NoError(t, writeStrategies(tempFile.Name(), 0.8))
+	waitForStrategy(t, store, 0.8)
+
+	// invalid strategies are rejected when reloaded and the previous ones are kept
+	require.NoError(t, ioutil.WriteFile(tempFile.Name(), []byte(`{"default_strategy": {"type": "probabilistic", "param": 2}}`), 0600))
+	for i := 0; i < 100 && !strings.Contains(buf.String(), "Invalid strategies"); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Contains(t, buf.String(), "failed to reload sampling strategies, keeping previous strategies")
+	s, err = store.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(sampling.SamplingStrategyType_PROBABILISTIC, 0.8), *s)
+}
+
+func TestAutoUpdateStrategiesFromURL(t *testing.T) {
+	var mu sync.Mutex
+	probability := 0.5
+	failed := false
+	setResponse := func(p float64, f bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		probability, failed = p, f
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(strategiesContent(probability))
+	}))
+	defer server.Close()
+
+	logger, buf := testutils.NewLogger()
+	h := &strategyStore{logger: logger}
+	content, err := readStrategies(server.URL)
+	require.NoError(t, err)
+	strategies, err := parseStrategiesContent(content)
+	require.NoError(t, err)
+	h.storedStrategies.Store(h.parseStrategies(strategies))
+
+	s, err := h.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(sampling.SamplingStrategyType_PROBABILISTIC, 0.5), *s)
+
+	setResponse(0.7, false)
+	content = h.reloadStrategies(server.URL, content)
+	assert.Equal(t, strategiesContent(0.7), content)
+	s, err = h.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(sampling.SamplingStrategyType_PROBABILISTIC, 0.7), *s)
+
+	setResponse(0.7, true)
+	assert.Equal(t, content, h.reloadStrategies(server.URL, content))
+	assert.Contains(t, buf.String(), "status code 503")
+	s, err = h.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(sampling.SamplingStrategyType_PROBABILISTIC, 0.7), *s)
+
+	_, err = readStrategies("http://localhost:0/strategies.json")
+	assert.Error(t, err)
+}
+
+func strategiesContent(probability float64) []byte {
+	return []byte(fmt.Sprintf(`{"default_strategy": {"type": "probabilistic", "param": %v}}`, probability))
+}
+
+func writeStrategies(file string, probability float64) error {
+	return ioutil.WriteFile(file, strategiesContent(probability), 0600)
+}
+
+func waitForStrategy(t *testing.T, store ss.StrategyStore, probability float64) {
+	for i := 0; i < 100; i++ {
+		s, err := store.GetSamplingStrategy("foo")
+		require.NoError(t, err)
+		if s.ProbabilisticSampling.SamplingRate == probability {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("strategy was not reloaded with probability %v", probability)
+}
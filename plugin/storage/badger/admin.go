@@ -0,0 +1,302 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package badger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	backupRoute  = "/badger/backup"
+	restoreRoute = "/badger/restore"
+	compactRoute = "/badger/compact"
+)
+
+// Backup writes a consistent snapshot of all entries with a version of at least since to w, and
+// returns the version to pass as since to a later, incremental, backup. Writes can continue while
+// the backup is in progress, they are not part of it.
+func (f *Factory) Backup(w io.Writer, since uint64) (uint64, error) {
+	start := time.Now()
+	version, err := f.store.Backup(&progressWriter{w: w, bytes: f.metrics.BackupBytes}, since)
+	if err != nil {
+		return 0, err
+	}
+	f.metrics.LastBackup.Update(start.UnixNano())
+	return version, nil
+}
+
+// BackupToFile writes a backup to the file at path, which must not exist yet. The file only appears
+// once the backup is complete.
+func (f *Factory) BackupToFile(path string, since uint64) (uint64, error) {
+	if _, err := os.Stat(path); err == nil {
+		return 0, fmt.Errorf("backup file %s already exists", path)
+	}
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return 0, err
+	}
+	version, err := f.Backup(file, since)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	return version, nil
+}
+
+// Restore loads a backup into a new store in the given directories, see RestoreBackup.
+// The store of the factory is not modified.
+func (f *Factory) Restore(r io.Reader, keyDirectory, valueDirectory string) error {
+	start := time.Now()
+	if err := RestoreBackup(&progressReader{r: r, bytes: f.metrics.RestoreBytes}, keyDirectory, valueDirectory); err != nil {
+		return err
+	}
+	f.metrics.LastRestore.Update(start.UnixNano())
+	return nil
+}
+
+// RestoreBackup loads a backup into a new store in the given directories, which must be empty
+// or not exist yet. The store can then be used by starting Jaeger with these directories.
+func RestoreBackup(r io.Reader, keyDirectory, valueDirectory string) error {
+	for _, dir := range []string{keyDirectory, valueDirectory} {
+		if dir == "" {
+			return fmt.Errorf("the directories to restore into must be set")
+		}
+		if files, err := ioutil.ReadDir(dir); err == nil && len(files) > 0 {
+			return fmt.Errorf("cannot restore into %s, the directory is not empty", dir)
+		}
+		initializeDir(dir)
+	}
+	opts := badger.DefaultOptions
+	opts.Dir = keyDirectory
+	opts.ValueDir = valueDirectory
+	store, err := badger.Open(opts)
+	if err != nil {
+		return err
+	}
+	err = store.Load(r)
+	if closeErr := store.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Compact runs the value log garbage collection until no more value log file can be rewritten.
+// Flattening the LSM tree is out of scope: the version of Badger in use doesn't support it, and
+// its levels keep being compacted in the background.
+func (f *Factory) Compact() error {
+	return f.runValueLogGC(time.Now())
+}
+
+// AdminHandlers implements storage.AdminFactory. The handlers are:
+//
+//	POST /badger/backup?since=VERSION
+//	  streams a backup in the response, only if backup streaming is enabled
+//	POST /badger/backup?file=PATH&since=VERSION
+//	  writes a backup to PATH on the server
+//	POST /badger/restore?file=PATH&key-dir=DIR&value-dir=DIR
+//	  loads the backup at PATH, or in the request body, into a new store in the given directories
+//	POST /badger/compact
+//	  runs the value log garbage collection, the LSM tree is not flattened; 409 if the
+//	  maintenance job is running it already
+//
+// All paths are relative to the configured backup directory, file backups and restores are
+// rejected if it isn't set.
+func (f *Factory) AdminHandlers() map[string]http.Handler {
+	return map[string]http.Handler{
+		backupRoute:  http.HandlerFunc(f.backupHandler),
+		restoreRoute: http.HandlerFunc(f.restoreHandler),
+		compactRoute: http.HandlerFunc(f.compactHandler),
+	}
+}
+
+func (f *Factory) backupHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "backup must be a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	var since uint64
+	if s := r.FormValue("since"); s != "" {
+		var err error
+		if since, err = strconv.ParseUint(s, 10, 64); err != nil {
+			http.Error(w, fmt.Sprintf("cannot parse since: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	name := r.URL.Query().Get("file")
+	if name == "" {
+		if !f.Options.GetPrimary().BackupStreaming {
+			http.Error(w, "streaming backups is disabled, see --"+f.Options.GetPrimary().namespace+suffixBackupStreaming, http.StatusForbidden)
+			return
+		}
+		// The version is only known once the whole backup is written
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Trailer", "X-Backup-Version")
+		version, err := f.Backup(w, since)
+		if err != nil {
+			// The status is already sent, the client sees a truncated body without the trailer
+			f.logger.Error("Failed to stream Badger backup", zap.Error(err))
+			return
+		}
+		w.Header().Set("X-Backup-Version", strconv.FormatUint(version, 10))
+		return
+	}
+	path, err := f.backupPath(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	version, err := f.BackupToFile(path, since)
+	if err != nil {
+		f.logger.Error("Failed to back up Badger", zap.String("file", path), zap.Error(err))
+		http.Error(w, fmt.Sprintf("backup failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	f.logger.Info("Backed up Badger", zap.String("file", path), zap.Uint64("version", version))
+	writeAdminResponse(w, map[string]interface{}{"file": name, "version": version})
+}
+
+func (f *Factory) restoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "restore must be a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	keyDirectory, err := f.backupPath(r.URL.Query().Get("key-dir"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	valueDirectory, err := f.backupPath(r.URL.Query().Get("value-dir"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var backup io.Reader = r.Body
+	if name := r.URL.Query().Get("file"); name != "" {
+		path, err := f.backupPath(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("cannot open backup: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		backup = file
+	}
+	if err := f.Restore(backup, keyDirectory, valueDirectory); err != nil {
+		f.logger.Error("Failed to restore Badger backup", zap.Error(err))
+		http.Error(w, fmt.Sprintf("restore failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	f.logger.Info("Restored Badger backup", zap.String("key-dir", keyDirectory), zap.String("value-dir", valueDirectory))
+	writeAdminResponse(w, map[string]interface{}{
+		"key-dir":   r.URL.Query().Get("key-dir"),
+		"value-dir": r.URL.Query().Get("value-dir"),
+	})
+}
+
+// backupPath resolves a path given to the admin endpoints inside the backup directory. Absolute
+// paths and paths with .. elements are rejected, so that requests cannot read or write elsewhere.
+func (f *Factory) backupPath(name string) (string, error) {
+	dir := f.Options.GetPrimary().BackupDirectory
+	if dir == "" {
+		return "", fmt.Errorf("file backups and restores are disabled, the backup directory is not set")
+	}
+	if name == "" {
+		return "", fmt.Errorf("the path must be set")
+	}
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("the path %s must be relative to the backup directory", name)
+	}
+	for _, element := range strings.Split(filepath.ToSlash(name), "/") {
+		if element == ".." {
+			return "", fmt.Errorf("the path %s must not contain ..", name)
+		}
+	}
+	return filepath.Join(dir, name), nil
+}
+
+func (f *Factory) compactHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "compact must be a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	err := f.Compact()
+	if err == badger.ErrRejected {
+		http.Error(w, "a value log garbage collection is already running", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		f.logger.Error("Failed to compact Badger", zap.Error(err))
+		http.Error(w, fmt.Sprintf("compaction failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	f.logger.Info("Ran Badger value log garbage collection, the LSM tree is not flattened")
+	writeAdminResponse(w, map[string]interface{}{"status": "ok", "flattened": false})
+}
+
+func writeAdminResponse(w http.ResponseWriter, response map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// progressWriter counts the bytes written through it, so that long backups can be followed.
+type progressWriter struct {
+	w     io.Writer
+	bytes metrics.Counter
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.bytes.Inc(int64(n))
+	return n, err
+}
+
+// progressReader counts the bytes read through it, so that long restores can be followed.
+type progressReader struct {
+	r     io.Reader
+	bytes metrics.Counter
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.bytes.Inc(int64(n))
+	return n, err
+}
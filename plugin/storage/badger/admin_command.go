@@ -0,0 +1,119 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package badger
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+const (
+	backupFile  = "badger.backup.file"
+	backupSince = "badger.backup.since"
+)
+
+var adminActions = []string{"backup", "restore", "compact"}
+
+// NewAdminCommand creates a command that backs up, restores and compacts a Badger store that is
+// not in use by a running Jaeger. It is configured with the same --badger.* flags as the storage;
+// restore loads the backup into the store directories, which must be empty.
+func NewAdminCommand() *cobra.Command {
+	v := viper.New()
+	options := NewOptions("badger")
+	c := &cobra.Command{
+		Use:   "badger ACTION",
+		Short: "Backs up, restores and compacts a Badger store",
+		Long: `Backs up, restores and compacts a Badger store that is not in use. ACTION is one of:
+  backup  - writes a backup of the store to --badger.backup.file
+  restore - loads --badger.backup.file into a new store in the (empty) store directories
+  compact - runs the value log garbage collection`,
+		ValidArgs: adminActions,
+		Args:      cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.InitFromViper(v)
+			logger, err := zap.NewProduction()
+			if err != nil {
+				return err
+			}
+			return runAdminAction(args[0], options, v.GetString(backupFile), uint64(v.GetInt64(backupSince)), logger)
+		},
+	}
+	config.AddFlags(v, c, options.AddFlags, addAdminFlags)
+	return c
+}
+
+func addAdminFlags(flagSet *flag.FlagSet) {
+	flagSet.String(backupFile, "", "The file to write the backup to, or to restore it from.")
+	flagSet.Uint64(backupSince, 0, "Only back up the entries above this version, as returned by a previous backup.")
+}
+
+func runAdminAction(action string, options *Options, file string, since uint64, logger *zap.Logger) error {
+	switch action {
+	case "backup", "restore":
+		if file == "" {
+			return fmt.Errorf("the %s action requires --%s", action, backupFile)
+		}
+	case "compact":
+	default:
+		return fmt.Errorf("unrecognized action %q, must be one of %v", action, adminActions)
+	}
+	if options.primary.Ephemeral {
+		return fmt.Errorf("the %s action requires a persistent store, set --badger.ephemeral=false", action)
+	}
+	if action == "restore" {
+		return restoreFromFile(file, options.primary, logger)
+	}
+
+	f := &Factory{Options: options, maintenanceDone: make(chan bool)}
+	if err := f.Initialize(metrics.NullFactory, logger); err != nil {
+		return errors.Wrap(err, "failed to open Badger store")
+	}
+	var err error
+	if action == "backup" {
+		var version uint64
+		if version, err = f.BackupToFile(file, since); err == nil {
+			logger.Info("Backed up Badger", zap.String("file", file), zap.Uint64("version", version))
+		}
+	} else {
+		err = f.Compact()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func restoreFromFile(file string, cfg *NamespaceConfig, logger *zap.Logger) error {
+	backup, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+	if err := RestoreBackup(backup, cfg.KeyDirectory, cfg.ValueDirectory); err != nil {
+		return err
+	}
+	logger.Info("Restored Badger backup", zap.String("file", file),
+		zap.String("key-dir", cfg.KeyDirectory), zap.String("value-dir", cfg.ValueDirectory))
+	return nil
+}
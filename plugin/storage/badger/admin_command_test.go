@@ -0,0 +1,76 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package badger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestAdminCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger-command")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storeDir, restoreDir, backup := filepath.Join(dir, "store"), filepath.Join(dir, "restored"), filepath.Join(dir, "backup")
+
+	storeFlags := []string{"--badger.ephemeral=false", "--badger.directory-key=" + storeDir, "--badger.directory-value=" + storeDir}
+	f := newTestFactory(t, metrics.NullFactory, storeFlags...)
+	traceID := model.NewTraceID(0, 1)
+	writeTestSpan(t, f, traceID)
+	require.NoError(t, f.Close())
+
+	for _, args := range [][]string{
+		append([]string{"compact"}, storeFlags...),
+		append([]string{"backup", "--badger.backup.file=" + backup}, storeFlags...),
+		{"restore", "--badger.backup.file=" + backup, "--badger.ephemeral=false",
+			"--badger.directory-key=" + restoreDir, "--badger.directory-value=" + restoreDir},
+	} {
+		c := NewAdminCommand()
+		c.SetArgs(args)
+		require.NoError(t, c.Execute(), args[0])
+	}
+
+	restored := newTestFactory(t, metrics.NullFactory,
+		"--badger.ephemeral=false", "--badger.directory-key="+restoreDir, "--badger.directory-value="+restoreDir)
+	assertTraceFound(t, restored, traceID)
+	require.NoError(t, restored.Close())
+}
+
+func TestAdminCommandErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{}, err: "accepts 1 arg(s), received 0"},
+		{args: []string{"foo"}, err: `unrecognized action "foo", must be one of [backup restore compact]`},
+		{args: []string{"backup"}, err: "the backup action requires --badger.backup.file"},
+		{args: []string{"compact"}, err: "the compact action requires a persistent store, set --badger.ephemeral=false"},
+		{args: []string{"restore", "--badger.backup.file=/does/not/exist", "--badger.ephemeral=false"}, err: "open /does/not/exist: no such file or directory"},
+	}
+	for _, test := range tests {
+		c := NewAdminCommand()
+		c.SilenceErrors, c.SilenceUsage = true, true
+		c.SetArgs(test.args)
+		assert.EqualError(t, c.Execute(), test.err)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package badger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"github.com/uber/jaeger-lib/metrics/metricstest"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+// newTestFactory returns an initialized factory with the given flags that is closed with the test.
+func newTestFactory(t *testing.T, metricsFactory metrics.Factory, flags ...string) *Factory {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags(flags))
+	f.InitFromViper(v)
+	require.NoError(t, f.Initialize(metricsFactory, zap.NewNop()))
+	return f
+}
+
+func writeTestSpan(t *testing.T, f *Factory, traceID model.TraceID) {
+	sw, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, sw.WriteSpan(&model.Span{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(1),
+		OperationName: "op",
+		Process:       &model.Process{ServiceName: "svc"},
+		StartTime:     time.Now(),
+		Duration:      time.Millisecond,
+	}))
+}
+
+func assertTraceFound(t *testing.T, f *Factory, traceID model.TraceID) {
+	sr, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	trace, err := sr.GetTrace(context.Background(), traceID)
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 1)
+}
+
+func TestBackupRestore(t *testing.T) {
+	mFactory := metricstest.NewFactory(0)
+	f := newTestFactory(t, mFactory)
+	defer f.Close()
+	traceID := model.NewTraceID(0, 1)
+	writeTestSpan(t, f, traceID)
+	writeTestSpan(t, f, model.NewTraceID(0, 2))
+
+	var backup bytes.Buffer
+	version, err := f.Backup(&backup, 0)
+	require.NoError(t, err)
+	assert.True(t, version > 0)
+
+	// An incremental backup only repeats the latest write when nothing was written since
+	var incremental bytes.Buffer
+	_, err = f.Backup(&incremental, version)
+	require.NoError(t, err)
+	assert.True(t, incremental.Len() < backup.Len())
+
+	dir, err := ioutil.TempDir("", "badger-restore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyDir, valueDir := filepath.Join(dir, "key"), filepath.Join(dir, "value")
+	require.NoError(t, f.Restore(bytes.NewReader(backup.Bytes()), keyDir, valueDir))
+
+	counters, gauges := mFactory.Snapshot()
+	assert.EqualValues(t, backup.Len()+incremental.Len(), counters[backupBytesName])
+	assert.EqualValues(t, backup.Len(), counters[restoreBytesName])
+	assert.True(t, gauges[lastBackupName] > 0)
+	assert.True(t, gauges[lastRestoreName] > 0)
+
+	restored := newTestFactory(t, metrics.NullFactory,
+		"--badger.ephemeral=false", "--badger.directory-key="+keyDir, "--badger.directory-value="+valueDir)
+	assertTraceFound(t, restored, traceID)
+	require.NoError(t, restored.Close())
+
+	// A store is never restored over existing data
+	err = f.Restore(bytes.NewReader(backup.Bytes()), keyDir, valueDir)
+	assert.EqualError(t, err, "cannot restore into "+keyDir+", the directory is not empty")
+	assert.EqualError(t, RestoreBackup(&backup, "", valueDir), "the directories to restore into must be set")
+}
+
+func TestBackupToFile(t *testing.T) {
+	f := newTestFactory(t, metrics.NullFactory)
+	defer f.Close()
+	writeTestSpan(t, f, model.NewTraceID(0, 1))
+
+	dir, err := ioutil.TempDir("", "badger-backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "backup")
+	_, err = f.BackupToFile(path, 0)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, info.Size() > 0)
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = f.BackupToFile(path, 0)
+	assert.EqualError(t, err, "backup file "+path+" already exists")
+	_, err = f.BackupToFile(filepath.Join(dir, "missing", "backup"), 0)
+	assert.Error(t, err)
+}
+
+func TestCompact(t *testing.T) {
+	mFactory := metricstest.NewFactory(0)
+	f := newTestFactory(t, mFactory)
+	defer f.Close()
+	require.NoError(t, f.Compact())
+	_, gauges := mFactory.Snapshot()
+	assert.True(t, gauges[lastValueLogCleanedName] > 0)
+}
+
+func TestAdminHandlers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger-admin")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	f := newTestFactory(t, metrics.NullFactory, "--badger.backup-directory="+dir, "--badger.backup-streaming=true")
+	defer f.Close()
+	traceID := model.NewTraceID(0, 1)
+	writeTestSpan(t, f, traceID)
+
+	mux := http.NewServeMux()
+	for route, handler := range f.AdminHandlers() {
+		mux.Handle(route, handler)
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// A streamed backup reports its version in a trailer
+	resp, err := http.Post(server.URL+backupRoute, "", nil)
+	require.NoError(t, err)
+	streamed, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	version, err := strconv.ParseUint(resp.Trailer.Get("X-Backup-Version"), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, version > 0)
+
+	resp, err = http.Post(server.URL+backupRoute+"?file=backup", "", nil)
+	require.NoError(t, err)
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	resp.Body.Close()
+	assert.Equal(t, "backup", response["file"])
+	assert.EqualValues(t, version, response["version"])
+	_, err = os.Stat(filepath.Join(dir, "backup"))
+	require.NoError(t, err)
+
+	// Both backups restore the same data
+	for name, restore := range map[string]func(keyDir, valueDir string) (*http.Response, error){
+		"file": func(keyDir, valueDir string) (*http.Response, error) {
+			return http.Post(server.URL+restoreRoute+"?file=backup&key-dir="+keyDir+"&value-dir="+valueDir, "", nil)
+		},
+		"body": func(keyDir, valueDir string) (*http.Response, error) {
+			return http.Post(server.URL+restoreRoute+"?key-dir="+keyDir+"&value-dir="+valueDir,
+				"application/octet-stream", bytes.NewReader(streamed))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := restore(name, name)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			keyDir := filepath.Join(dir, name)
+			restored := newTestFactory(t, metrics.NullFactory,
+				"--badger.ephemeral=false", "--badger.directory-key="+keyDir, "--badger.directory-value="+keyDir)
+			assertTraceFound(t, restored, traceID)
+			require.NoError(t, restored.Close())
+		})
+	}
+
+	resp, err = http.Post(server.URL+compactRoute, "", nil)
+	require.NoError(t, err)
+	response = nil
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, false, response["flattened"])
+
+	errorTests := []struct {
+		method string
+		url    string
+		status int
+	}{
+		{method: http.MethodGet, url: backupRoute, status: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, url: backupRoute + "?since=foo", status: http.StatusBadRequest},
+		{method: http.MethodGet, url: backupRoute + "?file=other", status: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, url: backupRoute + "?file=backup", status: http.StatusInternalServerError},
+		{method: http.MethodPost, url: backupRoute + "?file=" + filepath.Join(dir, "other"), status: http.StatusBadRequest},
+		{method: http.MethodPost, url: backupRoute + "?file=../other", status: http.StatusBadRequest},
+		{method: http.MethodGet, url: restoreRoute, status: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, url: restoreRoute + "?file=missing&key-dir=key&value-dir=value", status: http.StatusBadRequest},
+		{method: http.MethodPost, url: restoreRoute + "?file=backup&key-dir=/tmp/key&value-dir=value", status: http.StatusBadRequest},
+		{method: http.MethodPost, url: restoreRoute + "?file=backup&key-dir=key&value-dir=value/../../value", status: http.StatusBadRequest},
+		{method: http.MethodPost, url: restoreRoute + "?file=backup", status: http.StatusBadRequest},
+		{method: http.MethodPost, url: restoreRoute + "?file=backup&key-dir=body&value-dir=body", status: http.StatusInternalServerError},
+		{method: http.MethodGet, url: compactRoute, status: http.StatusMethodNotAllowed},
+	}
+	for _, test := range errorTests {
+		req, err := http.NewRequest(test.method, server.URL+test.url, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, test.status, resp.StatusCode, test.url)
+	}
+}
+
+func TestAdminHandlersWithoutBackupStreaming(t *testing.T) {
+	f := newTestFactory(t, metrics.NullFactory)
+	defer f.Close()
+	req := httptest.NewRequest(http.MethodPost, backupRoute, nil)
+	rec := httptest.NewRecorder()
+	f.AdminHandlers()[backupRoute].ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "streaming backups is disabled, see --badger.backup-streaming")
+}
+
+func TestAdminHandlersWithoutBackupDirectory(t *testing.T) {
+	f := newTestFactory(t, metrics.NullFactory)
+	defer f.Close()
+	for _, url := range []string{
+		backupRoute + "?file=backup",
+		restoreRoute + "?file=backup&key-dir=key&value-dir=value",
+		restoreRoute + "?key-dir=key&value-dir=value",
+	} {
+		req := httptest.NewRequest(http.MethodPost, url, nil)
+		rec := httptest.NewRecorder()
+		f.AdminHandlers()[req.URL.Path].ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, url)
+		assert.Contains(t, rec.Body.String(), "the backup directory is not set", url)
+	}
+}
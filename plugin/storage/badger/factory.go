@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/badger"
@@ -28,9 +29,13 @@ import (
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/pkg/distributedlock"
+	"github.com/jaegertracing/jaeger/plugin/pkg/distributedlock/local"
 	depStore "github.com/jaegertracing/jaeger/plugin/storage/badger/dependencystore"
+	badgerSamplingStore "github.com/jaegertracing/jaeger/plugin/storage/badger/samplingstore"
 	badgerStore "github.com/jaegertracing/jaeger/plugin/storage/badger/spanstore"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -39,6 +44,13 @@ const (
 	keyLogSpaceAvailableName   = "badger_key_log_bytes_available"
 	lastMaintenanceRunName     = "badger_storage_maintenance_last_run"
 	lastValueLogCleanedName    = "badger_storage_valueloggc_last_run"
+	lastBackupName             = "badger_storage_backup_last_run"
+	lastRestoreName            = "badger_storage_restore_last_run"
+	backupBytesName            = "badger_storage_backup_bytes"
+	restoreBytesName           = "badger_storage_restore_bytes"
+
+	// valueLogGCDiscardRatio is selected to rewrite a value log file if half of it can be discarded
+	valueLogGCDiscardRatio = 0.5
 )
 
 // Factory implements storage.Factory for Badger backend.
@@ -50,6 +62,8 @@ type Factory struct {
 
 	tmpDir          string
 	maintenanceDone chan bool
+	closeOnce       sync.Once
+	closeErr        error
 
 	// TODO initialize via reflection; convert comments to tag 'description'.
 	metrics struct {
@@ -61,6 +75,14 @@ type Factory struct {
 		LastMaintenanceRun metrics.Gauge
 		// LastValueLogCleaned stores the timestamp (UnixNano) of the previous ValueLogGC run
 		LastValueLogCleaned metrics.Gauge
+		// LastBackup stores the timestamp (UnixNano) of the previous successful backup
+		LastBackup metrics.Gauge
+		// LastRestore stores the timestamp (UnixNano) of the previous successful restore
+		LastRestore metrics.Gauge
+		// BackupBytes counts the bytes written by backups, including the one in progress
+		BackupBytes metrics.Counter
+		// RestoreBytes counts the bytes read by restores, including the one in progress
+		RestoreBytes metrics.Counter
 
 		// Expose badger's internal expvar metrics, which are all gauge's at this point
 		badgerMetrics map[string]metrics.Gauge
@@ -124,6 +146,10 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger)
 	f.metrics.KeyLogSpaceAvailable = metricsFactory.Gauge(metrics.Options{Name: keyLogSpaceAvailableName})
 	f.metrics.LastMaintenanceRun = metricsFactory.Gauge(metrics.Options{Name: lastMaintenanceRunName})
 	f.metrics.LastValueLogCleaned = metricsFactory.Gauge(metrics.Options{Name: lastValueLogCleanedName})
+	f.metrics.LastBackup = metricsFactory.Gauge(metrics.Options{Name: lastBackupName})
+	f.metrics.LastRestore = metricsFactory.Gauge(metrics.Options{Name: lastRestoreName})
+	f.metrics.BackupBytes = metricsFactory.Counter(metrics.Options{Name: backupBytesName})
+	f.metrics.RestoreBytes = metricsFactory.Counter(metrics.Options{Name: restoreBytesName})
 
 	f.registerBadgerExpvarMetrics(metricsFactory)
 
@@ -158,20 +184,33 @@ func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return depStore.NewDependencyStore(sr), nil
 }
 
-// Close Implements io.Closer and closes the underlying storage
-func (f *Factory) Close() error {
-	close(f.maintenanceDone)
-	err := f.store.Close()
+// CreateLock implements storage.SamplingStoreFactory
+func (f *Factory) CreateLock() (distributedlock.Lock, error) {
+	return local.NewLock(), nil
+}
 
-	// Remove tmp files if this was ephemeral storage
-	if f.Options.primary.Ephemeral {
-		errSecondary := os.RemoveAll(f.tmpDir)
-		if err == nil {
-			err = errSecondary
-		}
-	}
+// CreateSamplingStore implements storage.SamplingStoreFactory
+func (f *Factory) CreateSamplingStore() (samplingstore.Store, error) {
+	return badgerSamplingStore.NewSamplingStore(f.store, f.Options.primary.SpanStoreTTL), nil
+}
 
-	return err
+// Close Implements io.Closer and closes the underlying storage. The span writer closes it as well,
+// only the first call has an effect.
+func (f *Factory) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.maintenanceDone)
+		err := f.store.Close()
+
+		// Remove tmp files if this was ephemeral storage
+		if f.Options.primary.Ephemeral {
+			errSecondary := os.RemoveAll(f.tmpDir)
+			if err == nil {
+				err = errSecondary
+			}
+		}
+		f.closeErr = err
+	})
+	return f.closeErr
 }
 
 // Maintenance starts a background maintenance job for the badger K/V store, such as ValueLogGC
@@ -183,15 +222,7 @@ func (f *Factory) maintenance() {
 		case <-f.maintenanceDone:
 			return
 		case t := <-maintenanceTicker.C:
-			var err error
-
-			// After there's nothing to clean, the err is raised
-			for err == nil {
-				err = f.store.RunValueLogGC(0.5) // 0.5 is selected to rewrite a file if half of it can be discarded
-			}
-			if err == badger.ErrNoRewrite {
-				f.metrics.LastValueLogCleaned.Update(t.UnixNano())
-			} else {
+			if err := f.runValueLogGC(t); err != nil {
 				f.logger.Error("Failed to run ValueLogGC", zap.Error(err))
 			}
 
@@ -201,6 +232,21 @@ func (f *Factory) maintenance() {
 	}
 }
 
+// runValueLogGC rewrites value log files until there is nothing left to clean.
+func (f *Factory) runValueLogGC(t time.Time) error {
+	var err error
+
+	// After there's nothing to clean, the err is raised
+	for err == nil {
+		err = f.store.RunValueLogGC(valueLogGCDiscardRatio)
+	}
+	if err != badger.ErrNoRewrite {
+		return err
+	}
+	f.metrics.LastValueLogCleaned.Update(t.UnixNano())
+	return nil
+}
+
 func (f *Factory) metricsCopier() {
 	metricsTicker := time.NewTicker(f.Options.primary.MetricsUpdateInterval)
 	defer metricsTicker.Stop()
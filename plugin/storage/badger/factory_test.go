@@ -68,6 +68,12 @@ func TestForCodecov(t *testing.T) {
 	_, err = f.CreateDependencyReader()
 	assert.NoError(t, err)
 
+	_, err = f.CreateLock()
+	assert.NoError(t, err)
+
+	_, err = f.CreateSamplingStore()
+	assert.NoError(t, err)
+
 	// Now, remove the badger directories
 	err = os.RemoveAll(f.tmpDir)
 	assert.NoError(t, err)
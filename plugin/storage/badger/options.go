@@ -39,6 +39,8 @@ type NamespaceConfig struct {
 	SyncWrites            bool
 	MaintenanceInterval   time.Duration
 	MetricsUpdateInterval time.Duration
+	BackupDirectory       string // The admin endpoints only read and write backups and restores in it
+	BackupStreaming       bool   // The admin backup endpoint only streams backups in its response if set
 }
 
 const (
@@ -55,6 +57,8 @@ const (
 	suffixSyncWrite           = ".consistency"
 	suffixMaintenanceInterval = ".maintenance-interval"
 	suffixMetricsInterval     = ".metrics-update-interval" // Intended only for testing purposes
+	suffixBackupDirectory     = ".backup-directory"
+	suffixBackupStreaming     = ".backup-streaming"
 	defaultDataDir            = string(os.PathSeparator) + "data"
 	defaultValueDir           = defaultDataDir + string(os.PathSeparator) + "values"
 	defaultKeysDir            = defaultDataDir + string(os.PathSeparator) + "keys"
@@ -128,6 +132,16 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *NamespaceConfig) {
 		nsConfig.MetricsUpdateInterval,
 		"How often the badger metrics are collected by Jaeger. Format is time.Duration (https://golang.org/pkg/time/#Duration)",
 	)
+	flagSet.String(
+		nsConfig.namespace+suffixBackupDirectory,
+		nsConfig.BackupDirectory,
+		"Directory the admin backup and restore endpoints read backups from and write backups and restored stores to. File backups and restores through the admin endpoints are disabled if empty.",
+	)
+	flagSet.Bool(
+		nsConfig.namespace+suffixBackupStreaming,
+		nsConfig.BackupStreaming,
+		"Allow the admin backup endpoint to stream a backup of the whole store in its response. Anyone who can reach the admin port can then read all the spans.",
+	)
 }
 
 // InitFromViper initializes Options with properties from viper
@@ -143,6 +157,8 @@ func initFromViper(cfg *NamespaceConfig, v *viper.Viper) {
 	cfg.SpanStoreTTL = v.GetDuration(cfg.namespace + suffixSpanstoreTTL)
 	cfg.MaintenanceInterval = v.GetDuration(cfg.namespace + suffixMaintenanceInterval)
 	cfg.MetricsUpdateInterval = v.GetDuration(cfg.namespace + suffixMetricsInterval)
+	cfg.BackupDirectory = v.GetString(cfg.namespace + suffixBackupDirectory)
+	cfg.BackupStreaming = v.GetBool(cfg.namespace + suffixBackupStreaming)
 }
 
 // GetPrimary returns the primary namespace configuration
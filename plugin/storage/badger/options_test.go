@@ -43,6 +43,8 @@ func TestParseOptions(t *testing.T) {
 		"--badger.directory-key=/var/lib/badger",
 		"--badger.directory-value=/mnt/slow/badger",
 		"--badger.span-store-ttl=168h",
+		"--badger.backup-directory=/mnt/backups",
+		"--badger.backup-streaming=true",
 	})
 	opts.InitFromViper(v)
 
@@ -51,4 +53,6 @@ func TestParseOptions(t *testing.T) {
 	assert.Equal(t, time.Duration(168*time.Hour), opts.GetPrimary().SpanStoreTTL)
 	assert.Equal(t, "/var/lib/badger", opts.GetPrimary().KeyDirectory)
 	assert.Equal(t, "/mnt/slow/badger", opts.GetPrimary().ValueDirectory)
+	assert.Equal(t, "/mnt/backups", opts.GetPrimary().BackupDirectory)
+	assert.True(t, opts.GetPrimary().BackupStreaming)
 }
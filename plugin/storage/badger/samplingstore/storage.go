@@ -0,0 +1,199 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package samplingstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+)
+
+/*
+	Sampling keys share the badger instance with the span store. Span and index keys have the
+	first bit set to 1, sampling keys have it set to 0 so that they never collide.
+
+	Keys are prefix + BigEndian UnixNano timestamp + (optional) hostname, which keeps them sorted by time.
+*/
+
+const (
+	throughputKeyPrefix    byte = 0x08
+	probabilitiesKeyPrefix byte = 0x09
+)
+
+type probabilitiesAndQPS struct {
+	Hostname      string                              `json:"hostname"`
+	Probabilities model.ServiceOperationProbabilities `json:"probabilities"`
+	QPS           model.ServiceOperationQPS           `json:"qps"`
+}
+
+// SamplingStore handles all queries and insertions of adaptive sampling data in badger
+type SamplingStore struct {
+	store *badger.DB
+	ttl   time.Duration
+}
+
+// NewSamplingStore returns a SamplingStore which expires the stored data after ttl
+func NewSamplingStore(db *badger.DB, ttl time.Duration) *SamplingStore {
+	return &SamplingStore{
+		store: db,
+		ttl:   ttl,
+	}
+}
+
+// InsertThroughput implements samplingstore.Store#InsertThroughput.
+func (s *SamplingStore) InsertThroughput(throughput []*model.Throughput) error {
+	value, err := json.Marshal(throughput)
+	if err != nil {
+		return err
+	}
+	return s.insert(createKey(throughputKeyPrefix, time.Now(), ""), value)
+}
+
+// GetThroughput implements samplingstore.Store#GetThroughput.
+func (s *SamplingStore) GetThroughput(start, end time.Time) ([]*model.Throughput, error) {
+	var ret []*model.Throughput
+	err := s.scan(throughputKeyPrefix, start, end, func(value []byte) error {
+		var throughput []*model.Throughput
+		if err := json.Unmarshal(value, &throughput); err != nil {
+			return err
+		}
+		ret = append(ret, throughput...)
+		return nil
+	})
+	return ret, err
+}
+
+// InsertProbabilitiesAndQPS implements samplingstore.Store#InsertProbabilitiesAndQPS.
+func (s *SamplingStore) InsertProbabilitiesAndQPS(
+	hostname string,
+	probabilities model.ServiceOperationProbabilities,
+	qps model.ServiceOperationQPS,
+) error {
+	value, err := json.Marshal(&probabilitiesAndQPS{
+		Hostname:      hostname,
+		Probabilities: probabilities,
+		QPS:           qps,
+	})
+	if err != nil {
+		return err
+	}
+	return s.insert(createKey(probabilitiesKeyPrefix, time.Now(), hostname), value)
+}
+
+// GetLatestProbabilities implements samplingstore.Store#GetLatestProbabilities.
+func (s *SamplingStore) GetLatestProbabilities() (model.ServiceOperationProbabilities, error) {
+	var latest probabilitiesAndQPS
+	err := s.store.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// Reverse iteration starts from the largest key that is <= the seek key
+		prefix := []byte{probabilitiesKeyPrefix}
+		it.Seek([]byte{probabilitiesKeyPrefix + 1})
+		if !it.ValidForPrefix(prefix) {
+			return nil
+		}
+		value, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(value, &latest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if latest.Probabilities == nil {
+		return model.ServiceOperationProbabilities{}, nil
+	}
+	return latest.Probabilities, nil
+}
+
+// GetProbabilitiesAndQPS implements samplingstore.Store#GetProbabilitiesAndQPS.
+func (s *SamplingStore) GetProbabilitiesAndQPS(start, end time.Time) (map[string][]model.ServiceOperationData, error) {
+	ret := make(map[string][]model.ServiceOperationData)
+	err := s.scan(probabilitiesKeyPrefix, start, end, func(value []byte) error {
+		var entry probabilitiesAndQPS
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return err
+		}
+		ret[entry.Hostname] = append(ret[entry.Hostname], toServiceOperationData(entry.Probabilities, entry.QPS))
+		return nil
+	})
+	return ret, err
+}
+
+func (s *SamplingStore) insert(key, value []byte) error {
+	return s.store.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(&badger.Entry{
+			Key:       key,
+			Value:     value,
+			ExpiresAt: uint64(time.Now().Add(s.ttl).Unix()),
+		})
+	})
+}
+
+// scan calls process for the value of every key with the given prefix and a timestamp in (start, end]
+func (s *SamplingStore) scan(prefix byte, start, end time.Time, process func(value []byte) error) error {
+	return s.store.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		// Seek to the first key after start, the timestamp is exclusive
+		startKey := createKey(prefix, start.Add(time.Nanosecond), "")
+		endKey := createKey(prefix, end.Add(time.Nanosecond), "")
+		for it.Seek(startKey); it.ValidForPrefix([]byte{prefix}); it.Next() {
+			if bytes.Compare(it.Item().Key(), endKey) >= 0 {
+				break
+			}
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if err := process(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func createKey(prefix byte, ts time.Time, suffix string) []byte {
+	key := make([]byte, 9+len(suffix))
+	key[0] = prefix
+	binary.BigEndian.PutUint64(key[1:], uint64(ts.UnixNano()))
+	copy(key[9:], suffix)
+	return key
+}
+
+func toServiceOperationData(probabilities model.ServiceOperationProbabilities, qps model.ServiceOperationQPS) model.ServiceOperationData {
+	data := make(model.ServiceOperationData)
+	for svc, opProbabilities := range probabilities {
+		data[svc] = make(map[string]*model.ProbabilityAndQPS)
+		for op, probability := range opProbabilities {
+			data[svc][op] = &model.ProbabilityAndQPS{
+				Probability: probability,
+				QPS:         qps[svc][op],
+			}
+		}
+	}
+	return data
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package samplingstore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+var _ samplingstore.Store = new(SamplingStore)
+
+func runWithBadger(t *testing.T, test func(s *SamplingStore)) {
+	dir, err := ioutil.TempDir("", "badger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opts := badger.DefaultOptions
+	opts.SyncWrites = false
+	opts.Dir = dir
+	opts.ValueDir = dir
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+	defer db.Close()
+
+	test(NewSamplingStore(db, time.Hour))
+}
+
+func TestThroughput(t *testing.T) {
+	runWithBadger(t, func(s *SamplingStore) {
+		start := time.Now()
+
+		throughput, err := s.GetThroughput(start.Add(-time.Minute), time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, throughput)
+
+		require.NoError(t, s.InsertThroughput([]*model.Throughput{{Service: "svc", Operation: "op1", Count: 1}}))
+		require.NoError(t, s.InsertThroughput([]*model.Throughput{{Service: "svc", Operation: "op2", Count: 2}}))
+		end := time.Now()
+
+		throughput, err = s.GetThroughput(start.Add(-time.Nanosecond), end)
+		require.NoError(t, err)
+		require.Len(t, throughput, 2)
+		assert.Equal(t, "op1", throughput[0].Operation)
+		assert.Equal(t, "op2", throughput[1].Operation)
+
+		throughput, err = s.GetThroughput(end, end.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Empty(t, throughput)
+	})
+}
+
+func TestProbabilitiesAndQPS(t *testing.T) {
+	runWithBadger(t, func(s *SamplingStore) {
+		start := time.Now()
+
+		probabilities, err := s.GetLatestProbabilities()
+		require.NoError(t, err)
+		assert.Empty(t, probabilities)
+
+		require.NoError(t, s.InsertThroughput([]*model.Throughput{{Service: "svc", Operation: "op"}}))
+		require.NoError(t, s.InsertProbabilitiesAndQPS(
+			"host1",
+			model.ServiceOperationProbabilities{"svc": {"op": 0.1}},
+			model.ServiceOperationQPS{"svc": {"op": 5}},
+		))
+		require.NoError(t, s.InsertProbabilitiesAndQPS(
+			"host2",
+			model.ServiceOperationProbabilities{"svc": {"op": 0.2}},
+			model.ServiceOperationQPS{"svc": {"op": 6}},
+		))
+		end := time.Now()
+
+		probabilities, err = s.GetLatestProbabilities()
+		require.NoError(t, err)
+		assert.Equal(t, model.ServiceOperationProbabilities{"svc": {"op": 0.2}}, probabilities)
+
+		data, err := s.GetProbabilitiesAndQPS(start.Add(-time.Nanosecond), end)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]model.ServiceOperationData{
+			"host1": {{"svc": {"op": {Probability: 0.1, QPS: 5}}}},
+			"host2": {{"svc": {"op": {Probability: 0.2, QPS: 6}}}},
+		}, data)
+
+		data, err = s.GetProbabilitiesAndQPS(end, end.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Empty(t, data)
+	})
+}
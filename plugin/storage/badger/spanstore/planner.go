@@ -0,0 +1,383 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanstore
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+/*
+	Every condition of a query that has a secondary index becomes an index seek. The selectivity of
+	the seeks is estimated by counting their index keys in the time range of the query, and the most
+	selective one drives the query: its keys are read newest first and the traces they reference are
+	the candidates. The candidates are joined with the trace IDs of the other selective seeks, and the
+	seeks too broad to be read in full are checked on the spans of the candidate traces instead. The
+	scan stops once enough traces match, so limited queries do not read the whole time range.
+*/
+
+const (
+	// selectivityProbeLimit is the number of index keys after which a seek is considered too broad to be read in full
+	selectivityProbeLimit = 5000
+	sizeOfTimestamp       = 8
+)
+
+// indexSeek is a condition of the query answered by a secondary index
+type indexSeek struct {
+	// prefix of the index keys of an equality condition, nil for the duration range
+	prefix []byte
+	// durationMin and durationMax are the bounds of the duration range, in microseconds
+	durationMin uint64
+	durationMax uint64
+	// matches evaluates the condition on a span of a candidate trace
+	matches func(span *model.Span) bool
+	// estimate is the number of index keys in the time range, up to selectivityProbeLimit
+	estimate int
+}
+
+// queryPlan is the order in which the seeks of a query are executed
+type queryPlan struct {
+	driver    *indexSeek
+	joins     []map[model.TraceID]struct{}
+	residuals []*indexSeek
+	matchers  []*spanstore.TagMatcher
+}
+
+// indexSeeks returns the seeks of the query in order of preference when they are equally selective
+func indexSeeks(query *spanstore.TraceQueryParameters) []*indexSeek {
+	var seeks []*indexSeek
+	if service := query.ServiceName; service != "" {
+		tags := make([][2]string, 0, len(query.Tags)+len(query.TagFilters))
+		for k, v := range query.Tags {
+			tags = append(tags, [2]string{k, v})
+		}
+		sort.Slice(tags, func(i, j int) bool { return tags[i][0] < tags[j][0] })
+		for _, f := range query.TagFilters {
+			if f.Operator == spanstore.TagEquals {
+				tags = append(tags, [2]string{f.Key, f.Value})
+			}
+		}
+		for _, tag := range tags {
+			key, value := tag[0], tag[1]
+			seeks = append(seeks, &indexSeek{
+				prefix: tagSearchKey(service, key, value),
+				matches: func(span *model.Span) bool {
+					return span.Process.ServiceName == service && hasTag(span, key, value)
+				},
+			})
+		}
+		// The operation and tag indexes are keyed by service too, so the service index is only needed without them
+		if operation := query.OperationName; operation != "" {
+			seeks = append(seeks, &indexSeek{
+				prefix: append([]byte{operationNameIndexKey}, service+operation...),
+				matches: func(span *model.Span) bool {
+					return span.Process.ServiceName == service && span.OperationName == operation
+				},
+			})
+		} else if len(seeks) == 0 {
+			seeks = append(seeks, &indexSeek{
+				prefix: append([]byte{serviceNameIndexKey}, service...),
+				matches: func(span *model.Span) bool {
+					return span.Process.ServiceName == service
+				},
+			})
+		}
+	}
+	if query.DurationMin != 0 || query.DurationMax != 0 {
+		durationMin := uint64(model.DurationAsMicroseconds(query.DurationMin))
+		durationMax := uint64(model.DurationAsMicroseconds(query.DurationMax))
+		if query.DurationMax == 0 {
+			// Set MAX to infinite, if Min is missing, 0 is a fine search result for us
+			durationMax = math.MaxUint64
+		}
+		seeks = append(seeks, &indexSeek{
+			durationMin: durationMin,
+			durationMax: durationMax,
+			matches: func(span *model.Span) bool {
+				duration := uint64(model.DurationAsMicroseconds(span.Duration))
+				return duration >= durationMin && duration <= durationMax
+			},
+		})
+	}
+	return seeks
+}
+
+func hasTag(span *model.Span, key, value string) bool {
+	matches := func(kvs model.KeyValues) bool {
+		for _, kv := range kvs {
+			if kv.Key == key && kv.AsString() == value {
+				return true
+			}
+		}
+		return false
+	}
+	if matches(span.Tags) || matches(span.Process.Tags) {
+		return true
+	}
+	for _, log := range span.Logs {
+		if matches(log.Fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// planQuery estimates the selectivity of the seeks of the query and orders them, or returns nil if the query has no seek
+func (r *TraceReader) planQuery(query *spanstore.TraceQueryParameters, matchers []*spanstore.TagMatcher) (*queryPlan, error) {
+	seeks := indexSeeks(query)
+	if len(seeks) == 0 {
+		return nil, nil
+	}
+	plan := &queryPlan{matchers: matchers}
+	err := r.store.View(func(txn *badger.Txn) error {
+		for _, seek := range seeks {
+			seek.estimate = 0
+			scanSeek(txn, seek, query, func(uint64, model.TraceID) bool {
+				seek.estimate++
+				return seek.estimate < selectivityProbeLimit
+			})
+		}
+		sort.SliceStable(seeks, func(i, j int) bool { return seeks[i].estimate < seeks[j].estimate })
+
+		plan.driver = seeks[0]
+		for _, seek := range seeks[1:] {
+			if seek.estimate >= selectivityProbeLimit {
+				plan.residuals = append(plan.residuals, seek)
+				continue
+			}
+			traceIDs := make(map[model.TraceID]struct{}, seek.estimate)
+			scanSeek(txn, seek, query, func(_ uint64, traceID model.TraceID) bool {
+				traceIDs[traceID] = struct{}{}
+				return true
+			})
+			plan.joins = append(plan.joins, traceIDs)
+		}
+		return nil
+	})
+	return plan, err
+}
+
+// findTraces executes the plan and returns the IDs of at most query.NumTraces matching traces, the most
+// recent first, along with the traces themselves if they had to be read to check the residual seeks
+func (r *TraceReader) findTraces(query *spanstore.TraceQueryParameters, plan *queryPlan) ([]model.TraceID, []*model.Trace, error) {
+	if query.NumTraces <= 0 {
+		return []model.TraceID{}, nil, nil
+	}
+	traceIDs := make([]model.TraceID, 0, query.NumTraces)
+	var traces []*model.Trace
+	verify := len(plan.residuals) > 0 || len(plan.matchers) > 0
+	batch := make([]model.TraceID, 0, query.NumTraces)
+	var err error
+	check := func() bool {
+		var candidates []*model.Trace
+		candidates, err = r.getTraces(batch)
+		batch = batch[:0]
+		if err != nil {
+			return false
+		}
+		for _, trace := range candidates {
+			if plan.matches(trace, query) {
+				traceIDs = append(traceIDs, trace.Spans[0].TraceID)
+				traces = append(traces, trace)
+			}
+		}
+		return len(traceIDs) < query.NumTraces
+	}
+	scanErr := r.candidates(query, plan.driver, func(traceID model.TraceID) bool {
+		if !plan.joined(traceID) {
+			return true
+		}
+		if !verify {
+			traceIDs = append(traceIDs, traceID)
+			return len(traceIDs) < query.NumTraces
+		}
+		// Read as many candidates at once as there are matches missing
+		batch = append(batch, traceID)
+		if len(traceIDs)+len(batch) < query.NumTraces {
+			return true
+		}
+		return check()
+	})
+	if scanErr != nil {
+		return nil, nil, scanErr
+	}
+	if err == nil && len(batch) > 0 {
+		check()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return traceIDs, traces, nil
+}
+
+// candidates calls fn with the distinct trace IDs of the driver, the most recent first, until fn returns false
+func (r *TraceReader) candidates(query *spanstore.TraceQueryParameters, driver *indexSeek, fn func(model.TraceID) bool) error {
+	return r.store.View(func(txn *badger.Txn) error {
+		if driver.prefix != nil {
+			seen := make(map[model.TraceID]struct{})
+			scanSeek(txn, driver, query, func(_ uint64, traceID model.TraceID) bool {
+				if _, found := seen[traceID]; found {
+					return true
+				}
+				seen[traceID] = struct{}{}
+				return fn(traceID)
+			})
+			return nil
+		}
+
+		// The duration index is ordered by duration, so its trace IDs have to be sorted by time first
+		latest := make(map[model.TraceID]uint64)
+		scanSeek(txn, driver, query, func(startTime uint64, traceID model.TraceID) bool {
+			if startTime > latest[traceID] {
+				latest[traceID] = startTime
+			}
+			return true
+		})
+		traceIDs := make([]model.TraceID, 0, len(latest))
+		for traceID := range latest {
+			traceIDs = append(traceIDs, traceID)
+		}
+		sort.Slice(traceIDs, func(i, j int) bool {
+			if latest[traceIDs[i]] != latest[traceIDs[j]] {
+				return latest[traceIDs[i]] > latest[traceIDs[j]]
+			}
+			return traceIDs[j].High < traceIDs[i].High || (traceIDs[j].High == traceIDs[i].High && traceIDs[j].Low < traceIDs[i].Low)
+		})
+		for _, traceID := range traceIDs {
+			if !fn(traceID) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (p *queryPlan) joined(traceID model.TraceID) bool {
+	for _, traceIDs := range p.joins {
+		if _, found := traceIDs[traceID]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matches checks the residual seeks and the tag filters on the spans of the trace
+func (p *queryPlan) matches(trace *model.Trace, query *spanstore.TraceQueryParameters) bool {
+	timeMin := model.TimeAsEpochMicroseconds(query.StartTimeMin)
+	timeMax := model.TimeAsEpochMicroseconds(query.StartTimeMax)
+	for _, seek := range p.residuals {
+		found := false
+		for _, span := range trace.Spans {
+			startTime := model.TimeAsEpochMicroseconds(span.StartTime)
+			if startTime >= timeMin && startTime <= timeMax && seek.matches(span) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return len(p.matchers) == 0 || matchesTagFilters(trace, query.ServiceName, p.matchers)
+}
+
+// scanSeek calls fn with the start time and the trace ID of the index keys of the seek in the time range of
+// the query until it returns false. Equality seeks are read newest first, the duration range by duration.
+func scanSeek(txn *badger.Txn, seek *indexSeek, query *spanstore.TraceQueryParameters, fn func(startTime uint64, traceID model.TraceID) bool) {
+	timeMin := model.TimeAsEpochMicroseconds(query.StartTimeMin)
+	timeMax := model.TimeAsEpochMicroseconds(query.StartTimeMax)
+
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false // Don't fetch values since we're only interested in the keys
+
+	if seek.prefix != nil {
+		// KEY: indexKey<indexValue><startTime><traceId>
+		keyLength := len(seek.prefix) + sizeOfTimestamp + sizeOfTraceID
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// A reverse iterator seeks to the last key before the seek key, i.e. the newest one in the time range
+		seekKey := make([]byte, keyLength)
+		copy(seekKey, seek.prefix)
+		binary.BigEndian.PutUint64(seekKey[len(seek.prefix):], timeMax)
+		for i := len(seek.prefix) + sizeOfTimestamp; i < keyLength; i++ {
+			seekKey[i] = 0xFF
+		}
+		for it.Seek(seekKey); it.ValidForPrefix(seek.prefix); it.Next() {
+			key := it.Item().Key()
+			if len(key) != keyLength {
+				// An index value which starts with the one of the seek (for example service1 & service12)
+				continue
+			}
+			startTime := binary.BigEndian.Uint64(key[len(seek.prefix):])
+			if startTime < timeMin || !fn(startTime, traceIDFromIndexKey(key)) {
+				return
+			}
+		}
+		return
+	}
+
+	// KEY: indexKey<duration><startTime><traceId>
+	durationPrefix := []byte{durationIndexKey}
+	durationKey := func(duration uint64) []byte {
+		key := make([]byte, 1+2*sizeOfTimestamp)
+		key[0] = durationIndexKey
+		binary.BigEndian.PutUint64(key[1:], duration)
+		binary.BigEndian.PutUint64(key[1+sizeOfTimestamp:], timeMin)
+		return key
+	}
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	// Skip the keys outside of the time range of every duration instead of reading them
+	for it.Seek(durationKey(seek.durationMin)); it.ValidForPrefix(durationPrefix); {
+		key := it.Item().Key()
+		duration := binary.BigEndian.Uint64(key[1:])
+		if duration > seek.durationMax {
+			return
+		}
+		startTime := binary.BigEndian.Uint64(key[1+sizeOfTimestamp:])
+		switch {
+		case startTime < timeMin:
+			it.Seek(durationKey(duration))
+		case startTime > timeMax:
+			if duration == math.MaxUint64 {
+				return
+			}
+			it.Seek(durationKey(duration + 1))
+		default:
+			if !fn(startTime, traceIDFromIndexKey(key)) {
+				return
+			}
+			it.Next()
+		}
+	}
+}
+
+// traceIDFromIndexKey reads the trace ID stored in the last bytes of an index key
+func traceIDFromIndexKey(key []byte) model.TraceID {
+	traceID := key[len(key)-sizeOfTraceID:]
+	return model.TraceID{
+		High: binary.BigEndian.Uint64(traceID[:8]),
+		Low:  binary.BigEndian.Uint64(traceID[8:]),
+	}
+}
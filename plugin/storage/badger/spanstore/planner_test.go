@@ -0,0 +1,236 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// writePlannerTraces writes one span per trace, a millisecond apart, with trace IDs descending in time
+// so that the results cannot be ordered by trace ID by accident. Every tenth span is tagged rare=yes.
+func writePlannerTraces(t *testing.T, sw *SpanWriter, startT time.Time, count int) {
+	for i := 0; i < count; i++ {
+		tags := model.KeyValues{model.String("common", "yes")}
+		if i%10 == 0 {
+			tags = append(tags, model.String("rare", "yes"))
+		}
+		span := model.Span{
+			TraceID:       model.TraceID{High: 1, Low: uint64(count - i)},
+			SpanID:        model.SpanID(i),
+			OperationName: fmt.Sprintf("operation-%d", i%2),
+			Process:       &model.Process{ServiceName: "service"},
+			StartTime:     startT.Add(time.Duration(i) * time.Millisecond),
+			Duration:      time.Duration(i) * time.Millisecond,
+			Tags:          tags,
+		}
+		require.NoError(t, sw.WriteSpan(&span))
+	}
+}
+
+func TestIndexSeeksOrder(t *testing.T) {
+	query := &spanstore.TraceQueryParameters{
+		ServiceName:   "service",
+		OperationName: "operation",
+		Tags:          map[string]string{"b": "2", "a": "1"},
+		TagFilters: []spanstore.TagFilter{
+			{Key: "c", Operator: spanstore.TagEquals, Value: "3"},
+			{Key: "d", Operator: spanstore.TagGreaterThan, Value: "4"},
+		},
+		DurationMin: time.Millisecond,
+	}
+	seeks := indexSeeks(query)
+	require.Len(t, seeks, 5)
+	assert.Equal(t, tagSearchKey("service", "a", "1"), seeks[0].prefix)
+	assert.Equal(t, tagSearchKey("service", "b", "2"), seeks[1].prefix)
+	assert.Equal(t, tagSearchKey("service", "c", "3"), seeks[2].prefix)
+	assert.Equal(t, append([]byte{operationNameIndexKey}, "serviceoperation"...), seeks[3].prefix)
+	assert.Nil(t, seeks[4].prefix)
+	assert.Equal(t, uint64(1000), seeks[4].durationMin)
+	assert.Equal(t, uint64(1<<64-1), seeks[4].durationMax)
+
+	seeks = indexSeeks(&spanstore.TraceQueryParameters{ServiceName: "service"})
+	require.Len(t, seeks, 1)
+	assert.Equal(t, append([]byte{serviceNameIndexKey}, "service"...), seeks[0].prefix)
+
+	assert.Empty(t, indexSeeks(&spanstore.TraceQueryParameters{}))
+}
+
+func TestQueryPlan(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), nil)
+		rw := NewTraceReader(store, cache)
+		startT := time.Now()
+		writePlannerTraces(t, sw, startT, 50)
+
+		query := &spanstore.TraceQueryParameters{
+			ServiceName:   "service",
+			OperationName: "operation-0",
+			Tags:          map[string]string{"common": "yes", "rare": "yes"},
+			StartTimeMin:  startT,
+			StartTimeMax:  startT.Add(time.Hour),
+			NumTraces:     100,
+		}
+		plan, err := rw.planQuery(query, nil)
+		require.NoError(t, err)
+		// the rare tag drives the query, the other seeks are small enough to be joined
+		assert.Equal(t, tagSearchKey("service", "rare", "yes"), plan.driver.prefix)
+		assert.Equal(t, 5, plan.driver.estimate)
+		require.Len(t, plan.joins, 2)
+		assert.Len(t, plan.joins[0], 25)
+		assert.Len(t, plan.joins[1], 50)
+		assert.Empty(t, plan.residuals)
+
+		traceIDs, traces, err := rw.findTraces(query, plan)
+		require.NoError(t, err)
+		assert.Nil(t, traces)
+		assert.Equal(t, []model.TraceID{{High: 1, Low: 10}, {High: 1, Low: 20}, {High: 1, Low: 30}, {High: 1, Low: 40}, {High: 1, Low: 50}}, traceIDs)
+
+		// the time range bounds the estimates
+		query.StartTimeMin = startT.Add(25 * time.Millisecond)
+		plan, err = rw.planQuery(query, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, plan.driver.estimate)
+	})
+}
+
+func TestQueryPlanLimit(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), nil)
+		rw := NewTraceReader(store, cache)
+		startT := time.Now()
+		writePlannerTraces(t, sw, startT, 50)
+
+		query := &spanstore.TraceQueryParameters{
+			ServiceName:  "service",
+			StartTimeMin: startT,
+			StartTimeMax: startT.Add(40 * time.Millisecond),
+			NumTraces:    3,
+		}
+		var scanned []model.TraceID
+		plan, err := rw.planQuery(query, nil)
+		require.NoError(t, err)
+		require.NoError(t, rw.candidates(query, plan.driver, func(traceID model.TraceID) bool {
+			scanned = append(scanned, traceID)
+			return len(scanned) < query.NumTraces
+		}))
+		// the scan starts at the end of the time range and stops at the limit
+		assert.Equal(t, []model.TraceID{{High: 1, Low: 10}, {High: 1, Low: 11}, {High: 1, Low: 12}}, scanned)
+
+		traceIDs, err := rw.FindTraceIDs(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, scanned, traceIDs)
+
+		query.NumTraces = -1
+		traceIDs, err = rw.FindTraceIDs(context.Background(), query)
+		require.NoError(t, err)
+		assert.Empty(t, traceIDs)
+	})
+}
+
+func TestQueryPlanDuration(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), nil)
+		rw := NewTraceReader(store, cache)
+		startT := time.Now()
+		writePlannerTraces(t, sw, startT, 50)
+
+		// spans 20 to 29 have the durations, only 25 to 27 are in the time range
+		query := &spanstore.TraceQueryParameters{
+			StartTimeMin: startT.Add(25 * time.Millisecond),
+			StartTimeMax: startT.Add(27 * time.Millisecond),
+			DurationMin:  20 * time.Millisecond,
+			DurationMax:  29 * time.Millisecond,
+		}
+		traceIDs, err := rw.FindTraceIDs(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{{High: 1, Low: 23}, {High: 1, Low: 24}, {High: 1, Low: 25}}, traceIDs)
+
+		// the duration index is the most selective seek
+		query.ServiceName = "service"
+		query.StartTimeMin = startT
+		query.StartTimeMax = startT.Add(time.Hour)
+		plan, err := rw.planQuery(query, nil)
+		require.NoError(t, err)
+		assert.Nil(t, plan.driver.prefix)
+		assert.Equal(t, 10, plan.driver.estimate)
+		traces, err := rw.FindTraces(context.Background(), query)
+		require.NoError(t, err)
+		assert.Len(t, traces, 10)
+	})
+}
+
+func TestQueryPlanResiduals(t *testing.T) {
+	startT := time.Now()
+	span := &model.Span{
+		TraceID:       model.TraceID{High: 1, Low: 1},
+		OperationName: "operation",
+		Process: &model.Process{
+			ServiceName: "service",
+			Tags:        model.KeyValues{model.String("host", "edge-1")},
+		},
+		StartTime: startT,
+		Duration:  5 * time.Millisecond,
+		Logs: []model.Log{
+			{Timestamp: startT, Fields: model.KeyValues{model.String("event", "retry")}},
+		},
+	}
+	trace := &model.Trace{Spans: []*model.Span{span}}
+	query := &spanstore.TraceQueryParameters{
+		ServiceName:  "service",
+		StartTimeMin: startT.Add(-time.Minute),
+		StartTimeMax: startT.Add(time.Minute),
+	}
+
+	testCases := []struct {
+		caption string
+		query   spanstore.TraceQueryParameters
+		matches bool
+	}{
+		{caption: "service", query: spanstore.TraceQueryParameters{ServiceName: "service"}, matches: true},
+		{caption: "operation", query: spanstore.TraceQueryParameters{ServiceName: "service", OperationName: "operation"}, matches: true},
+		{caption: "other operation", query: spanstore.TraceQueryParameters{ServiceName: "service", OperationName: "other"}, matches: false},
+		{caption: "process tag", query: spanstore.TraceQueryParameters{ServiceName: "service", Tags: map[string]string{"host": "edge-1"}}, matches: true},
+		{caption: "log field", query: spanstore.TraceQueryParameters{ServiceName: "service", Tags: map[string]string{"event": "retry"}}, matches: true},
+		{caption: "other tag value", query: spanstore.TraceQueryParameters{ServiceName: "service", Tags: map[string]string{"host": "edge-2"}}, matches: false},
+		{caption: "other service", query: spanstore.TraceQueryParameters{ServiceName: "other", Tags: map[string]string{"host": "edge-1"}}, matches: false},
+		{caption: "duration", query: spanstore.TraceQueryParameters{DurationMin: time.Millisecond, DurationMax: 10 * time.Millisecond}, matches: true},
+		{caption: "longer duration", query: spanstore.TraceQueryParameters{DurationMin: 10 * time.Millisecond}, matches: false},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.caption, func(t *testing.T) {
+			plan := &queryPlan{residuals: indexSeeks(&testCase.query)}
+			assert.Equal(t, testCase.matches, plan.matches(trace, query))
+		})
+	}
+
+	// spans outside of the time range do not satisfy the residual seeks
+	plan := &queryPlan{residuals: indexSeeks(&spanstore.TraceQueryParameters{ServiceName: "service"})}
+	query.StartTimeMin = startT.Add(time.Second)
+	assert.False(t, plan.matches(trace, query))
+}
@@ -337,6 +337,155 @@ func TestMenuSeeks(t *testing.T) {
 	})
 }
 
+func TestTagSeeks(t *testing.T) {
+	runFactoryTest(t, func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		tid := time.Now()
+		for i := 0; i < 10; i++ {
+			s := model.Span{
+				TraceID:       model.TraceID{Low: uint64(i), High: 1},
+				SpanID:        model.SpanID(i),
+				OperationName: "operation",
+				Process: &model.Process{
+					ServiceName: fmt.Sprintf("service-%d", i%2),
+					Tags:        model.KeyValues{model.String("hostname", fmt.Sprintf("host-%d", i%3))},
+				},
+				Tags: model.KeyValues{
+					model.String("http.method", "GET"),
+					model.Int64("http.status_code", int64(200+i)),
+				},
+				Logs:      []model.Log{{Timestamp: tid, Fields: model.KeyValues{model.String("event", "retry")}}},
+				StartTime: tid.Add(time.Duration(i)),
+			}
+			assert.NoError(t, sw.WriteSpan(&s))
+		}
+		// a service whose name starts with another one
+		assert.NoError(t, sw.WriteSpan(&model.Span{
+			TraceID:   model.TraceID{Low: 100, High: 1},
+			Process:   &model.Process{ServiceName: "service-10"},
+			Tags:      model.KeyValues{model.String("db.type", "sql")},
+			StartTime: tid,
+		}))
+
+		tr := sr.(spanstore.TagReader)
+		keys, err := tr.GetTagKeys(context.Background(), "service-1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"event", "hostname", "http.method", "http.status_code"}, keys)
+
+		values, err := tr.GetTagValues(context.Background(), "service-1", "hostname", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"host-0", "host-1", "host-2"}, values)
+
+		values, err = tr.GetTagValues(context.Background(), "service-0", "http.status_code", "20")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"200", "202", "204", "206", "208"}, values)
+
+		values, err = tr.GetTagValues(context.Background(), "service-0", "http.status_code", "21")
+		assert.NoError(t, err)
+		assert.Empty(t, values)
+
+		// the index keys of http.status_code start with those of http.status
+		values, err = tr.GetTagValues(context.Background(), "service-0", "http.status", "")
+		assert.NoError(t, err)
+		assert.Empty(t, values)
+
+		// the index keys of service-10 start with those of service-1
+		values, err = tr.GetTagValues(context.Background(), "service-1", "0db.type", "")
+		assert.NoError(t, err)
+		assert.Empty(t, values)
+		values, err = tr.GetTagValues(context.Background(), "service-10", "db.type", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sql"}, values)
+	})
+}
+
+func TestTagFilterSeeks(t *testing.T) {
+	runFactoryTest(t, func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		startT := time.Now()
+		for i := 0; i < 20; i++ {
+			method := "GET"
+			if i%3 == 0 {
+				method = "POST"
+			}
+			s := model.Span{
+				TraceID:       model.TraceID{Low: uint64(i), High: 1},
+				SpanID:        model.SpanID(i),
+				OperationName: "operation",
+				Process:       &model.Process{ServiceName: "service-1"},
+				StartTime:     startT.Add(time.Duration(i) * time.Millisecond),
+				Tags: model.KeyValues{
+					model.String("http.method", method),
+					model.Int64("http.status_code", int64(200+300*(i%2))),
+				},
+			}
+			assert.NoError(t, sw.WriteSpan(&s))
+		}
+
+		params := &spanstore.TraceQueryParameters{
+			StartTimeMin: startT,
+			StartTimeMax: startT.Add(time.Second),
+			ServiceName:  "service-1",
+			NumTraces:    3,
+			TagFilters: []spanstore.TagFilter{
+				{Key: "http.status_code", Operator: spanstore.TagGreaterThanOrEqual, Value: "500"},
+			},
+		}
+		ids, err := sr.FindTraceIDs(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TraceID{{High: 1, Low: 19}, {High: 1, Low: 17}, {High: 1, Low: 15}}, ids)
+
+		params.NumTraces = 100
+		trs, err := sr.FindTraces(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Len(t, trs, 10)
+
+		params.TagFilters = []spanstore.TagFilter{
+			{Key: "http.method", Operator: spanstore.TagEquals, Value: "POST"},
+			{Key: "http.status_code", Operator: spanstore.TagNotEquals, Value: "500"},
+		}
+		ids, err = sr.FindTraceIDs(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TraceID{{High: 1, Low: 18}, {High: 1, Low: 12}, {High: 1, Low: 6}, {High: 1, Low: 0}}, ids)
+
+		params.TagFilters = []spanstore.TagFilter{{Key: "http.method", Operator: spanstore.TagRegex, Value: "P.*"}}
+		trs, err = sr.FindTraces(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Len(t, trs, 7)
+
+		// the filters are matched against the whole trace rather than a single span
+		child := model.Span{
+			TraceID:       model.TraceID{Low: 18, High: 1},
+			SpanID:        model.SpanID(100),
+			OperationName: "child",
+			Process:       &model.Process{ServiceName: "service-1"},
+			StartTime:     startT.Add(18 * time.Millisecond),
+			Tags:          model.KeyValues{model.String("db.type", "sql")},
+		}
+		assert.NoError(t, sw.WriteSpan(&child))
+		params.TagFilters = []spanstore.TagFilter{
+			{Key: "http.method", Operator: spanstore.TagEquals, Value: "POST"},
+			{Key: "db.type", Operator: spanstore.TagRegex, Value: "s.*"},
+		}
+		ids, err = sr.FindTraceIDs(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TraceID{{High: 1, Low: 18}}, ids)
+		params.TagFilters = []spanstore.TagFilter{
+			{Key: "http.method", Operator: spanstore.TagEquals, Value: "POST"},
+			{Key: "db.type", Operator: spanstore.TagNotEquals, Value: "sql"},
+		}
+		ids, err = sr.FindTraceIDs(context.Background(), params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.TraceID{{High: 1, Low: 15}, {High: 1, Low: 12}, {High: 1, Low: 9}, {High: 1, Low: 6}, {High: 1, Low: 3}, {High: 1, Low: 0}}, ids)
+
+		params.TagFilters = []spanstore.TagFilter{{Key: "http.status_code", Operator: spanstore.TagLessThan, Value: "5xx"}}
+		_, err = sr.FindTraces(context.Background(), params)
+		assert.EqualError(t, err, "tag filter 'http.status_code<5xx' requires a numeric value")
+
+		params.ServiceName = ""
+		_, err = sr.FindTraceIDs(context.Background(), params)
+		assert.Equal(t, bss.ErrServiceNameNotSet, err)
+	})
+}
+
 func TestPersist(t *testing.T) {
 	dir, err := ioutil.TempDir("", "badgerTest")
 	assert.NoError(t, err)
@@ -21,7 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
+	"sort"
 	"time"
 
 	"github.com/dgraph-io/badger"
@@ -59,9 +59,16 @@ var (
 const (
 	defaultNumTraces = 100
 	sizeOfTraceID    = 16
-	encodingTypeBits = 0x0F
+	// tagKeysTraceSampleSize is the number of most recent traces of a service inspected by GetTagKeys
+	tagKeysTraceSampleSize = 100
+	// tagValuesTracesPerValue is the number of traces GetTagValues keeps to verify each value found in the index
+	tagValuesTracesPerValue = 3
+	encodingTypeBits        = 0x0F
 )
 
+// tagValuesScanLimit is the maximum number of tag index keys scanned by GetTagValues, a var to allow overriding in unit tests
+var tagValuesScanLimit = 10000
+
 // TraceReader reads traces from the local badger store
 type TraceReader struct {
 	store *badger.DB
@@ -237,185 +244,240 @@ func (r *TraceReader) GetOperations(ctx context.Context, service string) ([]stri
 	return r.cache.GetOperations(service)
 }
 
-// setQueryDefaults alters the query with defaults if certain parameters are not set
-func setQueryDefaults(query *spanstore.TraceQueryParameters) {
-	if query.NumTraces == 0 {
-		query.NumTraces = defaultNumTraces
+// GetTagKeys returns the tag keys found in the most recent traces of the service. The tag index
+// concatenates the service name, the tag key and the tag value, so the keys cannot be read from it.
+func (r *TraceReader) GetTagKeys(ctx context.Context, service string) ([]string, error) {
+	traceIDs, err := r.recentTraceIDs(service, tagKeysTraceSampleSize)
+	if err != nil {
+		return nil, err
 	}
+	traces, err := r.getTraces(traceIDs)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]struct{})
+	addKeys := func(kvs []model.KeyValue) {
+		for _, kv := range kvs {
+			keys[kv.Key] = struct{}{}
+		}
+	}
+	for _, trace := range traces {
+		for _, span := range trace.Spans {
+			if span.Process.ServiceName != service {
+				continue
+			}
+			addKeys(span.Tags)
+			addKeys(span.Process.Tags)
+			for _, log := range span.Logs {
+				addKeys(log.Fields)
+			}
+		}
+	}
+	return sortedKeys(keys), nil
 }
 
-// serviceQueries parses the query to index seeks which are unique index seeks
-func serviceQueries(query *spanstore.TraceQueryParameters, indexSeeks [][]byte) [][]byte {
-	if query.ServiceName != "" {
-		indexSearchKey := make([]byte, 0, 64) // 64 is a magic guess
-		if query.OperationName != "" {
-			indexSearchKey = append(indexSearchKey, operationNameIndexKey)
-			indexSearchKey = append(indexSearchKey, []byte(query.ServiceName+query.OperationName)...)
-		} else {
-			indexSearchKey = append(indexSearchKey, serviceNameIndexKey)
-			indexSearchKey = append(indexSearchKey, []byte(query.ServiceName)...)
-		}
+// GetTagValues scans the tag index of the service for the values of the key that start with prefix.
+// The index concatenates the service name, the tag key and the tag value without delimiters, so the
+// keys of the tag http.status_code also start with those of http.status, and the keys of service svc2
+// with those of svc: every value found in the index is verified against one of its traces. At most
+// tagValuesScanLimit index keys are scanned, the remaining keys of a value are skipped once
+// tagValuesTracesPerValue of its traces are found, so that frequent values do not use up the limit.
+func (r *TraceReader) GetTagValues(ctx context.Context, service, key, prefix string) ([]string, error) {
+	// KEY: it<serviceName><tagsKey><tagsValue><startTime><traceId>
+	indexPrefix := make([]byte, 0, 1+len(service)+len(key)+len(prefix))
+	indexPrefix = append(indexPrefix, tagIndexKey)
+	indexPrefix = append(indexPrefix, service+key+prefix...)
+	valueStartIndex := 1 + len(service) + len(key)
+
+	candidates := make(map[string][]model.TraceID)
+	err := r.store.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
 
-		indexSeeks = append(indexSeeks, indexSearchKey)
-		if len(query.Tags) > 0 {
-			for k, v := range query.Tags {
-				tagSearch := []byte(query.ServiceName + k + v)
-				tagSearchKey := make([]byte, 0, len(tagSearch)+1)
-				tagSearchKey = append(tagSearchKey, tagIndexKey)
-				tagSearchKey = append(tagSearchKey, tagSearch...)
-				indexSeeks = append(indexSeeks, tagSearchKey)
+		scanned := 0
+		it.Seek(indexPrefix)
+		for it.ValidForPrefix(indexPrefix) && scanned < tagValuesScanLimit {
+			scanned++
+			key := it.Item().Key()
+			timestampStartIndex := len(key) - (sizeOfTraceID + 8) // timestamp is stored with 8 bytes
+			if timestampStartIndex < valueStartIndex {
+				it.Next()
+				continue
 			}
+			value := string(key[valueStartIndex:timestampStartIndex])
+			traceIDs := candidates[value]
+			if len(traceIDs) < tagValuesTracesPerValue {
+				traceIDs = append(traceIDs, model.TraceID{
+					High: binary.BigEndian.Uint64(key[len(key)-sizeOfTraceID : len(key)-8]),
+					Low:  binary.BigEndian.Uint64(key[len(key)-8:]),
+				})
+				candidates[value] = traceIDs
+			}
+			if len(traceIDs) == tagValuesTracesPerValue {
+				if next := nextTagValueKey(key, timestampStartIndex); next != nil {
+					it.Seek(next)
+					continue
+				}
+			}
+			it.Next()
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.verifyTagValues(service, key, candidates)
+}
+
+// nextTagValueKey returns the first index key after the keys of the value of key, or nil if there is none.
+// As the index has no delimiter after the value, the keys of longer values starting with it follow
+// its own keys, which all start with the same timestamp byte: the most significant byte of the
+// start time in microseconds stays 0 for millennia.
+func nextTagValueKey(key []byte, timestampStartIndex int) []byte {
+	if key[timestampStartIndex] == 0xFF {
+		return nil
 	}
-	return indexSeeks
+	next := make([]byte, timestampStartIndex+1)
+	copy(next, key)
+	next[timestampStartIndex]++
+	return next
 }
 
-// indexSeeksToTraceIDs does the index scanning against badger based on the parsed index queries
-func (r *TraceReader) indexSeeksToTraceIDs(query *spanstore.TraceQueryParameters, indexSeeks [][]byte, ids [][][]byte) ([][][]byte, error) {
-	for i, s := range indexSeeks {
-		indexResults, err := r.scanIndexKeys(s, query.StartTimeMin, query.StartTimeMax)
+// verifyTagValues returns the candidate values that a span of the service has for the tag key in
+// at least one of the traces found with them in the index.
+func (r *TraceReader) verifyTagValues(service, key string, candidates map[string][]model.TraceID) ([]string, error) {
+	values := make(map[string]struct{})
+	for value, traceIDs := range candidates {
+		traces, err := r.getTraces(traceIDs)
 		if err != nil {
 			return nil, err
 		}
-
-		// Same traceID can be returned multiple times, but always in sorted order so checking the previous key is enough
-		prevTraceID := []byte{}
-		ids = append(ids, make([][]byte, 0, len(indexResults)))
-		for _, k := range indexResults {
-			traceID := k[len(k)-sizeOfTraceID:]
-			if !bytes.Equal(prevTraceID, traceID) {
-				ids[i] = append(ids[i], traceID)
-				prevTraceID = traceID
+		for _, trace := range traces {
+			if traceHasTag(trace, service, key, value) {
+				values[value] = struct{}{}
+				break
 			}
 		}
 	}
-	return ids, nil
+	return sortedKeys(values), nil
 }
 
-// durationQueries checks non unique index of durations
-func (r *TraceReader) durationQueries(query *spanstore.TraceQueryParameters, ids [][][]byte) [][][]byte {
-	durMax := uint64(model.DurationAsMicroseconds(query.DurationMax))
-	durMin := uint64(model.DurationAsMicroseconds(query.DurationMin))
-
-	startKey := make([]byte, 0, 9)
-	endKey := make([]byte, 0, 9)
-
-	startKey = append(startKey, durationIndexKey) // [0] =
-	endKey = append(endKey, durationIndexKey)
-
-	endVal := make([]byte, 8)
-	if query.DurationMax == 0 {
-		// Set MAX to infinite, if Min is missing, 0 is a fine search result for us
-		durMax = math.MaxUint64
-	}
-	binary.BigEndian.PutUint64(endVal, durMax)
-
-	startVal := make([]byte, 8)
-	binary.BigEndian.PutUint64(startVal, durMin)
-
-	startKey = append(startKey, startVal...)
-	endKey = append(endKey, endVal...)
-
-	// This is not unique index result - same TraceID can be matched from multiple spans
-	indexResults, _ := r.scanRangeIndex(startKey, endKey, query.StartTimeMin, query.StartTimeMax)
-	hashFilter := make(map[model.TraceID]struct{}, len(indexResults))
-	filteredResults := make([]*model.TraceID, 0, len(indexResults)) // Max possible length
-	appendableResults := make([][]byte, 0, len(indexResults))       // Max possible length
-	var value struct{}
-	for _, k := range indexResults {
-		key := k[len(k)-sizeOfTraceID:]
-		id := &model.TraceID{
-			High: binary.BigEndian.Uint64(key[:8]),
-			Low:  binary.BigEndian.Uint64(key[8:]),
-		}
-		if _, exists := hashFilter[*id]; !exists {
-			filteredResults = append(filteredResults, id)
-			hashFilter[*id] = value
+func traceHasTag(trace *model.Trace, service, key, value string) bool {
+	for _, span := range trace.Spans {
+		if span.Process.ServiceName == service && hasTag(span, key, value) {
+			return true
 		}
 	}
+	return false
+}
 
-	model.SortTraceIDs(filteredResults)
+// recentTraceIDs returns the IDs of the most recent traces in the service index, newest first.
+func (r *TraceReader) recentTraceIDs(service string, limit int) ([]model.TraceID, error) {
+	// KEY: is<serviceName><startTime><traceId>
+	indexPrefix := make([]byte, 0, 1+len(service))
+	indexPrefix = append(indexPrefix, serviceNameIndexKey)
+	indexPrefix = append(indexPrefix, service...)
+	keyLength := len(indexPrefix) + 8 + sizeOfTraceID
 
-	// This is an ugly hack at this point - but has no impact on performance really
-	for _, tr := range filteredResults {
-		appendableResults = append(appendableResults, traceIDToComparableBytes(tr))
-	}
+	traceIDs := make([]model.TraceID, 0, limit)
+	seen := make(map[model.TraceID]struct{})
+	err := r.store.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
 
-	ids = append(ids, appendableResults)
-	return ids
+		// a reverse iterator needs to seek past the last key with the prefix
+		seekKey := append(append([]byte{}, indexPrefix...), 0xFF)
+		for it.Seek(seekKey); it.ValidForPrefix(indexPrefix) && len(traceIDs) < limit; it.Next() {
+			key := it.Item().Key()
+			if len(key) != keyLength {
+				// a service whose name starts with the requested one
+				continue
+			}
+			traceID := model.TraceID{
+				High: binary.BigEndian.Uint64(key[keyLength-sizeOfTraceID : keyLength-8]),
+				Low:  binary.BigEndian.Uint64(key[keyLength-8:]),
+			}
+			if _, found := seen[traceID]; !found {
+				seen[traceID] = struct{}{}
+				traceIDs = append(traceIDs, traceID)
+			}
+		}
+		return nil
+	})
+	return traceIDs, err
 }
 
-func mergeJoinIds(left, right [][]byte) [][]byte {
-	// len(left) or len(right) is the maximum, whichever is the smallest
-	allocateSize := len(left)
-	if len(right) < allocateSize {
-		allocateSize = len(right)
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
-	merged := make([][]byte, 0, allocateSize)
-
-	lMax := len(left) - 1
-	rMax := len(right) - 1
-	for r, l := 0, 0; r <= rMax && l <= lMax; {
-		switch bytes.Compare(left[l], right[r]) {
-		case 0:
-			// Left matches right - merge
-			merged = append(merged, left[l])
-			// Advance both
-			l++
-			r++
-		case 1:
-			// left > right, increase right one
-			r++
-		case -1:
-			// left < right, increase left one
-			l++
-		}
+// setQueryDefaults alters the query with defaults if certain parameters are not set
+func setQueryDefaults(query *spanstore.TraceQueryParameters) {
+	if query.NumTraces == 0 {
+		query.NumTraces = defaultNumTraces
 	}
-	return merged
 }
 
-// sortMergeIds does a sort-merge join operation to the list of TraceIDs to remove duplicates
-func sortMergeIds(query *spanstore.TraceQueryParameters, ids [][][]byte) []model.TraceID {
-	// Key only scan is a lot faster in the badger - use sort-merge join algorithm instead of hash join since we have the keys in sorted order already
-
-	var merged [][]byte
+func tagSearchKey(service, key, value string) []byte {
+	tagSearch := []byte(service + key + value)
+	tagSearchKey := make([]byte, 0, len(tagSearch)+1)
+	tagSearchKey = append(tagSearchKey, tagIndexKey)
+	tagSearchKey = append(tagSearchKey, tagSearch...)
+	return tagSearchKey
+}
 
-	if len(ids) > 1 {
-		merged = mergeJoinIds(ids[0], ids[1])
-		for i := 2; i < len(ids); i++ {
-			merged = mergeJoinIds(merged, ids[i])
+// tagFilterMatchers returns the matchers of the tag filters that cannot be answered by the tag index
+func tagFilterMatchers(query *spanstore.TraceQueryParameters) ([]*spanstore.TagMatcher, error) {
+	var filters []spanstore.TagFilter
+	for _, f := range query.TagFilters {
+		if f.Operator != spanstore.TagEquals {
+			filters = append(filters, f)
 		}
-	} else {
-		merged = ids[0]
 	}
+	return spanstore.NewTagMatchers(filters)
+}
 
-	// Get top query.NumTraces results (order in DESC)
-	if query.NumTraces < len(merged) {
-		merged = merged[len(merged)-query.NumTraces:]
+// matchesTagFilters checks the matchers against the tags of all the spans of the service in the trace,
+// like the equality filters answered by the index: every filter may be satisfied by a different span,
+// and a negated filter excludes the trace if any of these spans has the tag.
+func matchesTagFilters(trace *model.Trace, service string, matchers []*spanstore.TagMatcher) bool {
+	var tags model.KeyValues
+	found := false
+	for _, span := range trace.Spans {
+		if span.Process.ServiceName != service {
+			continue
+		}
+		found = true
+		tags = append(tags, span.Tags...)
+		tags = append(tags, span.Process.Tags...)
+		for _, log := range span.Logs {
+			tags = append(tags, log.Fields...)
+		}
 	}
-
-	// Results are in ASC (badger's default order), but Jaeger uses DESC, thus we need to reverse the array
-	for left, right := 0, len(merged)-1; left < right; left, right = left+1, right-1 {
-		merged[left], merged[right] = merged[right], merged[left]
+	if !found {
+		return false
 	}
-
-	// Create the structs from [][]byte to TraceID
-	keys := make([]model.TraceID, 0, len(merged))
-
-	for _, key := range merged {
-		keys = append(keys, model.TraceID{
-			High: binary.BigEndian.Uint64(key[:8]),
-			Low:  binary.BigEndian.Uint64(key[8:]),
-		})
+	for _, m := range matchers {
+		if !m.Matches(tags) {
+			return false
+		}
 	}
-
-	return keys
+	return true
 }
 
 // FindTraces retrieves traces that match the traceQuery
 func (r *TraceReader) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
-	keys, err := r.FindTraceIDs(ctx, query)
+	keys, traces, err := r.findTraceIDs(query)
 	if err != nil {
 		if err == ErrNotSupported && (!query.StartTimeMax.IsZero() && !query.StartTimeMin.IsZero()) {
 			return r.scanTimeRange(query.StartTimeMin, query.StartTimeMax)
@@ -423,42 +485,43 @@ func (r *TraceReader) FindTraces(ctx context.Context, query *spanstore.TraceQuer
 
 		return nil, err
 	}
+	if traces != nil {
+		// The traces were already read to check the query conditions
+		return traces, nil
+	}
 
 	return r.getTraces(keys)
 }
 
 // FindTraceIDs retrieves only the TraceIDs that match the traceQuery, but not the trace data
 func (r *TraceReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	keys, _, err := r.findTraceIDs(query)
+	return keys, err
+}
+
+func (r *TraceReader) findTraceIDs(query *spanstore.TraceQueryParameters) ([]model.TraceID, []*model.Trace, error) {
 	// Validate and set query defaults which were not defined
 	if err := validateQuery(query); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	setQueryDefaults(query)
 
-	// Find matches using indexes that are using service as part of the key
-	indexSeeks := make([][]byte, 0, 1)
-	indexSeeks = serviceQueries(query, indexSeeks)
-
-	ids := make([][][]byte, 0, len(indexSeeks)+1)
-	ids, err := r.indexSeeksToTraceIDs(query, indexSeeks, ids)
+	matchers, err := tagFilterMatchers(query)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Only secondary range index for now (StartTime filtering should be done using the PK)
-	if query.DurationMax != 0 || query.DurationMin != 0 {
-		ids = r.durationQueries(query, ids)
+	// Order the index seeks (both unique indexes as well as non-unique indexes) from the most selective one
+	plan, err := r.planQuery(query, matchers)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	// Transform index seeks (both unique indexes as well as non-unique indexes) to a list of TraceIDs without duplicates
-	if len(ids) > 0 {
-		keys := sortMergeIds(query, ids)
-		return keys, nil
+	if plan == nil {
+		// TODO We could support here all the other scans, such as time range only. These are not currently backed by an index, so a "full table scan" of traces is required.
+		return nil, nil, ErrNotSupported
 	}
-
-	// TODO We could support here all the other scans, such as time range only. These are not currently backed by an index, so a "full table scan" of traces is required.
-	return nil, ErrNotSupported
+	return r.findTraces(query, plan)
 }
 
 // validateQuery returns an error if certain restrictions are not met
@@ -466,7 +529,7 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p == nil {
 		return ErrMalformedRequestObject
 	}
-	if p.ServiceName == "" && len(p.Tags) > 0 {
+	if p.ServiceName == "" && (len(p.Tags) > 0 || len(p.TagFilters) > 0) {
 		return ErrServiceNameNotSet
 	}
 
@@ -486,108 +549,3 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	}
 	return nil
 }
-
-// scanIndexKeys scans the time range for index keys matching the given prefix.
-func (r *TraceReader) scanIndexKeys(indexKeyValue []byte, startTimeMin time.Time, startTimeMax time.Time) ([][]byte, error) {
-	indexResults := make([][]byte, 0)
-
-	startStampBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(startStampBytes, model.TimeAsEpochMicroseconds(startTimeMin))
-
-	err := r.store.View(func(txn *badger.Txn) error {
-		opts := badger.DefaultIteratorOptions
-		opts.PrefetchValues = false // Don't fetch values since we're only interested in the keys
-		it := txn.NewIterator(opts)
-		defer it.Close()
-
-		// Create starting point for sorted index scan
-		startIndex := make([]byte, 0, len(indexKeyValue)+len(startStampBytes))
-		startIndex = append(startIndex, indexKeyValue...)
-		startIndex = append(startIndex, startStampBytes...)
-
-		for it.Seek(startIndex); scanFunction(it, indexKeyValue, model.TimeAsEpochMicroseconds(startTimeMax)); it.Next() {
-			item := it.Item()
-
-			// ScanFunction is a prefix scanning (since we could have for example service1 & service12)
-			// Now we need to match only the exact key if we want to add it
-			timestampStartIndex := len(it.Item().Key()) - (sizeOfTraceID + 8) // timestamp is stored with 8 bytes
-			if bytes.Equal(indexKeyValue, it.Item().Key()[:timestampStartIndex]) {
-				key := []byte{}
-				key = append(key, item.Key()...) // badger reuses underlying slices so we have to copy the key
-				indexResults = append(indexResults, key)
-			}
-		}
-		return nil
-	})
-	return indexResults, err
-}
-
-// scanFunction compares the index name as well as the time range in the index key
-func scanFunction(it *badger.Iterator, indexPrefix []byte, timeIndexEnd uint64) bool {
-	if it.Item() != nil {
-		// We can't use the indexPrefix length, because we might have the same prefixValue for non-matching cases also
-		timestampStartIndex := len(it.Item().Key()) - (sizeOfTraceID + 8) // timestamp is stored with 8 bytes
-		timestamp := binary.BigEndian.Uint64(it.Item().Key()[timestampStartIndex : timestampStartIndex+8])
-
-		return bytes.HasPrefix(it.Item().Key()[:timestampStartIndex], indexPrefix) && timestamp <= timeIndexEnd
-	}
-	return false
-}
-
-// scanIndexKeys scans the time range for index keys matching the given prefix.
-func (r *TraceReader) scanRangeIndex(indexStartValue []byte, indexEndValue []byte, startTimeMin time.Time, startTimeMax time.Time) ([][]byte, error) {
-	indexResults := make([][]byte, 0)
-
-	startStampBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(startStampBytes, model.TimeAsEpochMicroseconds(startTimeMin))
-
-	err := r.store.View(func(txn *badger.Txn) error {
-		opts := badger.DefaultIteratorOptions
-		opts.PrefetchValues = false // Don't fetch values since we're only interested in the keys
-		it := txn.NewIterator(opts)
-		defer it.Close()
-
-		// Create starting point for sorted index scan
-		startIndex := make([]byte, 0, len(indexStartValue)+len(startStampBytes))
-		startIndex = append(startIndex, indexStartValue...)
-		startIndex = append(startIndex, startStampBytes...)
-
-		timeIndexEnd := model.TimeAsEpochMicroseconds(startTimeMax)
-
-		for it.Seek(startIndex); scanRangeFunction(it, indexEndValue); it.Next() {
-			item := it.Item()
-
-			// ScanFunction is a prefix scanning (since we could have for example service1 & service12)
-			// Now we need to match only the exact key if we want to add it
-			timestampStartIndex := len(it.Item().Key()) - (sizeOfTraceID + 8) // timestamp is stored with 8 bytes
-			timestamp := binary.BigEndian.Uint64(it.Item().Key()[timestampStartIndex : timestampStartIndex+8])
-			if timestamp <= timeIndexEnd {
-				key := []byte{}
-				key = item.KeyCopy(key)
-				key = append(key, item.Key()...) // badger reuses underlying slices so we have to copy the key
-				indexResults = append(indexResults, key)
-			}
-		}
-		return nil
-	})
-	return indexResults, err
-}
-
-// scanRangeFunction seeks until the index end has been reached
-func scanRangeFunction(it *badger.Iterator, indexEndValue []byte) bool {
-	if it.Item() != nil {
-		compareSlice := it.Item().Key()[:len(indexEndValue)]
-		return bytes.Compare(indexEndValue, compareSlice) >= 0
-	}
-	return false
-}
-
-// traceIDToComparableBytes transforms model.TraceID to BigEndian sorted []byte
-func traceIDToComparableBytes(traceID *model.TraceID) []byte {
-	buf := new(bytes.Buffer)
-
-	binary.Write(buf, binary.BigEndian, traceID.High)
-	binary.Write(buf, binary.BigEndian, traceID.Low)
-
-	return buf.Bytes()
-}
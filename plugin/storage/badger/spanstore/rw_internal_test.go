@@ -14,9 +14,7 @@
 package spanstore
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
 	"testing"
 	"time"
 
@@ -96,26 +94,6 @@ func TestDecodeErrorReturns(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestSortMergeIdsDuplicateDetection(t *testing.T) {
-	// Different IndexSeeks return the same results
-	ids := make([][][]byte, 2)
-	ids[0] = make([][]byte, 1)
-	ids[1] = make([][]byte, 1)
-	buf := new(bytes.Buffer)
-	binary.Write(buf, binary.BigEndian, uint64(0))
-	binary.Write(buf, binary.BigEndian, uint64(156697987635))
-	b := buf.Bytes()
-	ids[0][0] = b
-	ids[1][0] = b
-
-	query := &spanstore.TraceQueryParameters{
-		NumTraces: 64,
-	}
-
-	traces := sortMergeIds(query, ids)
-	assert.Equal(t, 1, len(traces))
-}
-
 func TestDuplicateTraceIDDetection(t *testing.T) {
 	runWithBadger(t, func(store *badger.DB, t *testing.T) {
 		testSpan := createDummySpan()
@@ -141,6 +119,37 @@ func TestDuplicateTraceIDDetection(t *testing.T) {
 	})
 }
 
+func TestTagValuesSkipFrequentValues(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		defer func(limit int) { tagValuesScanLimit = limit }(tagValuesScanLimit)
+		tagValuesScanLimit = 3 * tagValuesTracesPerValue
+
+		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), nil)
+		rw := NewTraceReader(store, cache)
+
+		start := time.Now()
+		write := func(traceID uint64, value string) {
+			assert.NoError(t, sw.WriteSpan(&model.Span{
+				TraceID:   model.TraceID{Low: traceID},
+				Process:   &model.Process{ServiceName: "service"},
+				Tags:      model.KeyValues{model.String("kind", value)},
+				StartTime: start.Add(time.Duration(traceID) * time.Millisecond),
+			}))
+		}
+		// the frequent values would use up the scan limit before the rare ones
+		for i := uint64(1); i <= 20; i++ {
+			write(i, "frequent")
+			write(100+i, "frequently")
+		}
+		write(200, "rare")
+
+		values, err := rw.GetTagValues(context.Background(), "service", "kind", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"frequent", "frequently", "rare"}, values)
+	})
+}
+
 func createDummySpan() model.Span {
 	tid := time.Now()
 
@@ -176,37 +185,3 @@ func createDummySpan() model.Span {
 
 	return testSpan
 }
-
-func TestMergeJoin(t *testing.T) {
-	assert := assert.New(t)
-
-	// Test equals
-
-	left := make([][]byte, 16)
-	right := make([][]byte, 16)
-
-	for i := 0; i < 16; i++ {
-		left[i] = make([]byte, 4)
-		binary.BigEndian.PutUint32(left[i], uint32(i))
-
-		right[i] = make([]byte, 4)
-		binary.BigEndian.PutUint32(right[i], uint32(i))
-	}
-
-	merged := mergeJoinIds(left, right)
-	assert.Equal(16, len(merged))
-
-	// Check order
-	assert.Equal(uint32(15), binary.BigEndian.Uint32(merged[15]))
-
-	// Test simple non-equality different size
-
-	merged = mergeJoinIds(left[1:2], right[13:])
-	assert.Empty(merged)
-
-	// Different size, some equalities
-
-	merged = mergeJoinIds(left[0:3], right[1:7])
-	assert.Equal(2, len(merged))
-	assert.Equal(uint32(2), binary.BigEndian.Uint32(merged[1]))
-}
@@ -17,10 +17,13 @@ package cassandra
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
@@ -33,6 +36,7 @@ import (
 
 var _ storage.Factory = new(Factory)
 var _ storage.ArchiveFactory = new(Factory)
+var _ storage.SamplingStoreFactory = new(Factory)
 
 type mockSessionBuilder struct {
 	session *mocks.Session
@@ -91,6 +95,12 @@ func TestCassandraFactory(t *testing.T) {
 	_, err = f.CreateArchiveSpanWriter()
 	assert.EqualError(t, err, "archive storage not configured")
 
+	_, err = f.CreateLock()
+	assert.NoError(t, err)
+
+	_, err = f.CreateSamplingStore()
+	assert.NoError(t, err)
+
 	f.archiveConfig = &mockSessionBuilder{}
 	assert.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
 
@@ -100,3 +110,27 @@ func TestCassandraFactory(t *testing.T) {
 	_, err = f.CreateArchiveSpanWriter()
 	assert.NoError(t, err)
 }
+
+func TestCassandraFactoryRetentionPolicy(t *testing.T) {
+	file, err := ioutil.TempFile("", "retention_policy")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`{"services": {"payments": "720h"}}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags([]string{"--cassandra.span-store-retention-policy=" + file.Name()})
+	f.InitFromViper(v)
+	f.primaryConfig = newMockSessionBuilder(&mocks.Session{}, nil)
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+
+	_, err = f.CreateSpanWriter()
+	assert.NoError(t, err)
+
+	f.Options.SpanStoreRetentionPolicy = file.Name() + ".missing"
+	_, err = f.CreateSpanWriter()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to open retention policy file")
+}
@@ -52,16 +52,18 @@ const (
 	suffixEnableDependenciesV2 = ".enable-dependencies-v2"
 
 	// common storage settings
-	suffixSpanStoreWriteCacheTTL = ".span-store-write-cache-ttl"
+	suffixSpanStoreWriteCacheTTL   = ".span-store-write-cache-ttl"
+	suffixSpanStoreRetentionPolicy = ".span-store-retention-policy"
 )
 
 // Options contains various type of Cassandra configs and provides the ability
 // to bind them to command line flag and apply overlays, so that some configurations
 // (e.g. archive) may be underspecified and infer the rest of its parameters from primary.
 type Options struct {
-	primary                *namespaceConfig
-	others                 map[string]*namespaceConfig
-	SpanStoreWriteCacheTTL time.Duration
+	primary                  *namespaceConfig
+	others                   map[string]*namespaceConfig
+	SpanStoreWriteCacheTTL   time.Duration
+	SpanStoreRetentionPolicy string
 }
 
 // the Servers field in config.Configuration is a list, which we cannot represent with flags.
@@ -116,6 +118,9 @@ func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
 	flagSet.Duration(opt.primary.namespace+suffixSpanStoreWriteCacheTTL,
 		opt.SpanStoreWriteCacheTTL,
 		"The duration to wait before rewriting an existing service or operation name")
+	flagSet.String(opt.primary.namespace+suffixSpanStoreRetentionPolicy,
+		opt.SpanStoreRetentionPolicy,
+		`The path to a JSON file with the TTLs of the spans per service and per tag, e.g. {"default_ttl": "48h", "services": {"payments": "720h"}, "tags": [{"key": "retention", "value": "long", "ttl": "720h"}]}`)
 }
 
 func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
@@ -222,6 +227,7 @@ func (opt *Options) InitFromViper(v *viper.Viper) {
 		cfg.initFromViper(v)
 	}
 	opt.SpanStoreWriteCacheTTL = v.GetDuration(opt.primary.namespace + suffixSpanStoreWriteCacheTTL)
+	opt.SpanStoreRetentionPolicy = v.GetString(opt.primary.namespace + suffixSpanStoreRetentionPolicy)
 }
 
 func (cfg *namespaceConfig) initFromViper(v *viper.Viper) {
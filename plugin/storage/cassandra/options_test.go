@@ -60,6 +60,7 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--cas.consistency=ONE",
 		"--cas.proto-version=3",
 		"--cas.socket-keep-alive=42s",
+		"--cas.span-store-retention-policy=/etc/jaeger/retention.json",
 		// enable aux with a couple overrides
 		"--cas-aux.enabled=true",
 		"--cas-aux.keyspace=jaeger-archive",
@@ -74,6 +75,7 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, []string{"1.1.1.1", "2.2.2.2"}, primary.Servers)
 	assert.Equal(t, "ONE", primary.Consistency)
 	assert.Equal(t, false, primary.EnableDependenciesV2)
+	assert.Equal(t, "/etc/jaeger/retention.json", opts.SpanStoreRetentionPolicy)
 
 	aux := opts.Get("cas-aux")
 	require.NotNil(t, aux)
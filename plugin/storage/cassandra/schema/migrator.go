@@ -0,0 +1,215 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/cassandra"
+	"github.com/jaegertracing/jaeger/plugin/storage/cassandra/dependencystore"
+)
+
+const (
+	tablesStmt            = "SELECT table_name FROM system_schema.tables WHERE keyspace_name = ?"
+	tableTTLStmt          = "SELECT default_time_to_live FROM system_schema.tables WHERE keyspace_name = ? AND table_name = ?"
+	typeFieldsStmt        = "SELECT field_names FROM system_schema.types WHERE keyspace_name = ? AND type_name = ?"
+	addDependencySource   = "ALTER TYPE %s.dependency ADD source text"
+	selectDependenciesV1  = "SELECT ts, dependencies FROM %s.dependencies"
+	insertDependenciesV2  = "INSERT INTO %s.dependencies_v2 (ts, ts_bucket, dependencies) VALUES (?, ?, ?)"
+	dropDependenciesV1    = "DROP TABLE IF EXISTS %s.dependencies"
+	copyDependenciesV1    = "-- copy all rows of %s.dependencies into %s.dependencies_v2"
+	dependenciesTableV1   = "dependencies"
+	dependenciesTableV2   = "dependencies_v2"
+	tracesTable           = "traces"
+	dependencyType        = "dependency"
+	dependencySourceField = "source"
+
+	// dependenciesBucket must match the bucket the dependency store writes dependencies_v2 with.
+	dependenciesBucket = 24 * time.Hour
+)
+
+// Migrator creates the Jaeger schema in a keyspace or migrates it to the latest version.
+// All the steps are idempotent, so an interrupted migration can simply be run again.
+type Migrator struct {
+	session cassandra.Session
+	params  Parameters
+	logger  *zap.Logger
+	options Options
+}
+
+// NewMigrator returns a Migrator that uses the given session, which must not be bound to the keyspace.
+func NewMigrator(session cassandra.Session, params Parameters, logger *zap.Logger, opts ...Option) *Migrator {
+	return &Migrator{
+		session: session,
+		params:  params,
+		logger:  logger,
+		options: Options{}.apply(opts...),
+	}
+}
+
+// CurrentVersion detects the version of the schema in the keyspace.
+func (m *Migrator) CurrentVersion() (Version, error) {
+	tables, err := m.tables()
+	if err != nil {
+		return NoSchema, err
+	}
+	return detectVersion(tables), nil
+}
+
+func detectVersion(tables map[string]bool) Version {
+	switch {
+	case tables[dependenciesTableV2]:
+		return V2
+	case tables[tracesTable]:
+		return V1
+	default:
+		return NoSchema
+	}
+}
+
+// Migrate brings the schema of the keyspace to LatestVersion.
+func (m *Migrator) Migrate() error {
+	if err := m.params.Validate(); err != nil {
+		return err
+	}
+	tables, err := m.tables()
+	if err != nil {
+		return err
+	}
+	version := detectVersion(tables)
+	m.logger.Info("Detected schema version",
+		zap.String("keyspace", m.params.Keyspace),
+		zap.Stringer("version", version))
+
+	params := m.params
+	if version == V1 {
+		// keep the dependencies TTL the keyspace was created with, as the migration scripts did
+		if params.DependenciesTTL, err = m.tableTTL(dependenciesTableV1); err != nil {
+			return err
+		}
+		if err := m.addDependencySource(); err != nil {
+			return err
+		}
+	}
+
+	statements, err := Render(LatestVersion, params)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if err := m.exec(statement); err != nil {
+			return err
+		}
+	}
+
+	if tables[dependenciesTableV1] {
+		if err := m.copyDependencies(); err != nil {
+			return err
+		}
+		if m.options.dropLegacyTables {
+			if err := m.exec(fmt.Sprintf(dropDependenciesV1, m.params.Keyspace)); err != nil {
+				return err
+			}
+		}
+	}
+	m.logger.Info("Schema is up to date",
+		zap.String("keyspace", m.params.Keyspace),
+		zap.Stringer("version", LatestVersion),
+		zap.Bool("dry-run", m.options.dryRun != nil))
+	return nil
+}
+
+func (m *Migrator) tables() (map[string]bool, error) {
+	tables := make(map[string]bool)
+	iter := m.session.Query(tablesStmt, m.params.Keyspace).Iter()
+	var table string
+	for iter.Scan(&table) {
+		tables[table] = true
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read the tables of keyspace %s", m.params.Keyspace)
+	}
+	return tables, nil
+}
+
+func (m *Migrator) tableTTL(table string) (time.Duration, error) {
+	var ttl int
+	iter := m.session.Query(tableTTLStmt, m.params.Keyspace, table).Iter()
+	iter.Scan(&ttl)
+	if err := iter.Close(); err != nil {
+		return 0, errors.Wrapf(err, "failed to read the TTL of table %s", table)
+	}
+	return time.Duration(ttl) * time.Second, nil
+}
+
+func (m *Migrator) addDependencySource() error {
+	var fields []string
+	iter := m.session.Query(typeFieldsStmt, m.params.Keyspace, dependencyType).Iter()
+	iter.Scan(&fields)
+	if err := iter.Close(); err != nil {
+		return errors.Wrapf(err, "failed to read the fields of type %s", dependencyType)
+	}
+	for _, field := range fields {
+		if field == dependencySourceField {
+			return nil
+		}
+	}
+	return m.exec(fmt.Sprintf(addDependencySource, m.params.Keyspace))
+}
+
+// copyDependencies copies the dependencies from the SASI indexed table of v001 into dependencies_v2.
+func (m *Migrator) copyDependencies() error {
+	keyspace := m.params.Keyspace
+	if m.options.dryRun != nil {
+		_, err := fmt.Fprintf(m.options.dryRun, copyDependenciesV1+"\n\n", keyspace, keyspace)
+		return err
+	}
+	var (
+		ts           time.Time
+		dependencies []dependencystore.Dependency
+		count        int
+	)
+	iter := m.session.Query(fmt.Sprintf(selectDependenciesV1, keyspace)).Iter()
+	for iter.Scan(&ts, &dependencies) {
+		query := m.session.Query(fmt.Sprintf(insertDependenciesV2, keyspace), ts, ts.Truncate(dependenciesBucket), dependencies)
+		if err := query.Exec(); err != nil {
+			iter.Close()
+			return errors.Wrapf(err, "failed to copy the dependencies of %v", ts)
+		}
+		count++
+	}
+	if err := iter.Close(); err != nil {
+		return errors.Wrap(err, "failed to read the dependencies")
+	}
+	m.logger.Info("Copied dependencies", zap.Int("rows", count))
+	return nil
+}
+
+// exec executes a statement that changes the keyspace, or prints it in dry-run mode.
+func (m *Migrator) exec(statement string) error {
+	if m.options.dryRun != nil {
+		_, err := fmt.Fprintf(m.options.dryRun, "%s;\n\n", statement)
+		return err
+	}
+	m.logger.Debug("Executing statement", zap.String("statement", statement))
+	if err := m.session.Query(statement).Exec(); err != nil {
+		return errors.Wrapf(err, "failed to execute %s", statement)
+	}
+	return nil
+}
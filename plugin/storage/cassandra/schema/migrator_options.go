@@ -0,0 +1,49 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"io"
+)
+
+// Option is a function that sets some option on the Migrator.
+type Option func(c *Options)
+
+// Options control the behavior of the Migrator.
+type Options struct {
+	dryRun           io.Writer
+	dropLegacyTables bool
+}
+
+// DryRun makes the Migrator print the statements to the writer instead of executing them.
+func DryRun(out io.Writer) Option {
+	return func(o *Options) {
+		o.dryRun = out
+	}
+}
+
+// DropLegacyTables makes the Migrator drop the tables that are no longer used by the latest version.
+func DropLegacyTables(drop bool) Option {
+	return func(o *Options) {
+		o.dropLegacyTables = drop
+	}
+}
+
+func (o Options) apply(opts ...Option) Options {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
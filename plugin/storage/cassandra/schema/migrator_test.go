@@ -0,0 +1,260 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/cassandra"
+	"github.com/jaegertracing/jaeger/pkg/cassandra/mocks"
+	"github.com/jaegertracing/jaeger/plugin/storage/cassandra/dependencystore"
+)
+
+// fakeKeyspace answers the queries of the Migrator and records the statements it executes.
+type fakeKeyspace struct {
+	tables           []string
+	dependencyFields []string
+	dependenciesTTL  int
+	dependencies     [][]interface{}
+	readErr          error
+	execErr          error
+	insertErr        error
+
+	executed []string
+	inserted [][]interface{}
+}
+
+func (k *fakeKeyspace) session() *mocks.Session {
+	session := &mocks.Session{}
+	session.On("Query", mock.AnythingOfType("string"), mock.Anything).Return(k.query)
+	return session
+}
+
+func (k *fakeKeyspace) query(stmt string, values ...interface{}) cassandra.Query {
+	query := &mocks.Query{}
+	switch stmt {
+	case tablesStmt:
+		var rows [][]interface{}
+		for _, table := range k.tables {
+			rows = append(rows, []interface{}{table})
+		}
+		query.On("Iter").Return(k.iterator(rows))
+	case tableTTLStmt:
+		query.On("Iter").Return(k.iterator([][]interface{}{{k.dependenciesTTL}}))
+	case typeFieldsStmt:
+		query.On("Iter").Return(k.iterator([][]interface{}{{k.dependencyFields}}))
+	case fmt.Sprintf(selectDependenciesV1, "jaeger_v1_test"):
+		query.On("Iter").Return(k.iterator(k.dependencies))
+	case fmt.Sprintf(insertDependenciesV2, "jaeger_v1_test"):
+		k.inserted = append(k.inserted, values)
+		query.On("Exec").Return(k.insertErr)
+	default:
+		k.executed = append(k.executed, stmt)
+		query.On("Exec").Return(k.execErr)
+	}
+	return query
+}
+
+func (k *fakeKeyspace) iterator(rows [][]interface{}) *mocks.Iterator {
+	iter := &mocks.Iterator{}
+	iter.On("Scan", mock.Anything).Return(func(dest ...interface{}) bool {
+		if len(rows) == 0 {
+			return false
+		}
+		for i, value := range rows[0] {
+			reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+		}
+		rows = rows[1:]
+		return true
+	})
+	iter.On("Close").Return(k.readErr)
+	return iter
+}
+
+func renderLatest(t *testing.T, params Parameters) []string {
+	statements, err := Render(LatestVersion, params)
+	require.NoError(t, err)
+	return statements
+}
+
+func TestMigratorCurrentVersion(t *testing.T) {
+	testCases := []struct {
+		tables  []string
+		version Version
+	}{
+		{tables: nil, version: NoSchema},
+		{tables: []string{"service_names"}, version: NoSchema},
+		{tables: []string{"traces", "dependencies"}, version: V1},
+		{tables: []string{"traces", "dependencies", "dependencies_v2"}, version: V2},
+		{tables: []string{"traces", "dependencies_v2"}, version: V2},
+	}
+	for _, testCase := range testCases {
+		keyspace := &fakeKeyspace{tables: testCase.tables}
+		version, err := NewMigrator(keyspace.session(), testParameters(), zap.NewNop()).CurrentVersion()
+		require.NoError(t, err)
+		assert.Equal(t, testCase.version, version, "%v", testCase.tables)
+	}
+
+	keyspace := &fakeKeyspace{readErr: errors.New("unavailable")}
+	_, err := NewMigrator(keyspace.session(), testParameters(), zap.NewNop()).CurrentVersion()
+	assert.EqualError(t, err, "failed to read the tables of keyspace jaeger_v1_test: unavailable")
+}
+
+func TestMigratorCreate(t *testing.T) {
+	keyspace := &fakeKeyspace{}
+	err := NewMigrator(keyspace.session(), testParameters(), zap.NewNop()).Migrate()
+	require.NoError(t, err)
+	assert.Equal(t, renderLatest(t, testParameters()), keyspace.executed)
+	assert.Empty(t, keyspace.inserted)
+}
+
+func TestMigratorUpToDate(t *testing.T) {
+	keyspace := &fakeKeyspace{tables: []string{"traces", "dependencies_v2"}}
+	err := NewMigrator(keyspace.session(), testParameters(), zap.NewNop(), DropLegacyTables(true)).Migrate()
+	require.NoError(t, err)
+	// the statements of the latest template are idempotent and re-create any missing table
+	assert.Equal(t, renderLatest(t, testParameters()), keyspace.executed)
+	assert.Empty(t, keyspace.inserted)
+}
+
+func TestMigratorFromV1(t *testing.T) {
+	ts := time.Date(2019, time.July, 24, 11, 15, 17, 0, time.UTC)
+	deps := []dependencystore.Dependency{{Parent: "a", Child: "b", CallCount: 42}}
+	testCases := []struct {
+		caption          string
+		dependencyFields []string
+		dropLegacyTables bool
+		alterType        bool
+	}{
+		{
+			caption:          "type without source",
+			dependencyFields: []string{"parent", "child", "call_count"},
+			alterType:        true,
+		},
+		{
+			caption:          "type with source",
+			dependencyFields: []string{"parent", "child", "call_count", "source"},
+		},
+		{
+			caption:          "drop legacy tables",
+			dependencyFields: []string{"parent", "child", "call_count", "source"},
+			dropLegacyTables: true,
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.caption, func(t *testing.T) {
+			keyspace := &fakeKeyspace{
+				tables:           []string{"traces", "dependencies"},
+				dependencyFields: testCase.dependencyFields,
+				dependenciesTTL:  86400,
+				dependencies:     [][]interface{}{{ts, deps}},
+			}
+			migrator := NewMigrator(keyspace.session(), testParameters(), zap.NewNop(), DropLegacyTables(testCase.dropLegacyTables))
+			require.NoError(t, migrator.Migrate())
+
+			params := testParameters()
+			params.DependenciesTTL = 24 * time.Hour
+			var expected []string
+			if testCase.alterType {
+				expected = append(expected, "ALTER TYPE jaeger_v1_test.dependency ADD source text")
+			}
+			expected = append(expected, renderLatest(t, params)...)
+			if testCase.dropLegacyTables {
+				expected = append(expected, "DROP TABLE IF EXISTS jaeger_v1_test.dependencies")
+			}
+			assert.Equal(t, expected, keyspace.executed)
+			assert.Contains(t, strings.Join(keyspace.executed, "\n"), "default_time_to_live = 86400")
+			assert.Equal(t, [][]interface{}{
+				{ts, time.Date(2019, time.July, 24, 0, 0, 0, 0, time.UTC), deps},
+			}, keyspace.inserted)
+		})
+	}
+}
+
+func TestMigratorDryRun(t *testing.T) {
+	keyspace := &fakeKeyspace{
+		tables:           []string{"traces", "dependencies"},
+		dependencyFields: []string{"parent", "child", "call_count"},
+	}
+	out := &bytes.Buffer{}
+	err := NewMigrator(keyspace.session(), testParameters(), zap.NewNop(), DryRun(out), DropLegacyTables(true)).Migrate()
+	require.NoError(t, err)
+	assert.Empty(t, keyspace.executed)
+	assert.Empty(t, keyspace.inserted)
+
+	cql := out.String()
+	assert.True(t, strings.HasPrefix(cql, "ALTER TYPE jaeger_v1_test.dependency ADD source text;\n\n"), cql)
+	assert.Contains(t, cql, renderLatest(t, testParameters())[0]+";\n\n")
+	assert.True(t, strings.HasSuffix(cql, "-- copy all rows of jaeger_v1_test.dependencies into jaeger_v1_test.dependencies_v2\n\n"+
+		"DROP TABLE IF EXISTS jaeger_v1_test.dependencies;\n\n"), cql)
+}
+
+func TestMigratorErrors(t *testing.T) {
+	testCases := []struct {
+		caption       string
+		keyspace      *fakeKeyspace
+		params        func(p *Parameters)
+		expectedError string
+	}{
+		{
+			caption:       "invalid parameters",
+			keyspace:      &fakeKeyspace{},
+			params:        func(p *Parameters) { p.Replication = "" },
+			expectedError: "replication strategy is not set",
+		},
+		{
+			caption:       "read tables",
+			keyspace:      &fakeKeyspace{readErr: errors.New("unavailable")},
+			expectedError: "failed to read the tables of keyspace jaeger_v1_test: unavailable",
+		},
+		{
+			caption:       "execute statement",
+			keyspace:      &fakeKeyspace{execErr: errors.New("timeout")},
+			expectedError: "failed to execute CREATE KEYSPACE IF NOT EXISTS jaeger_v1_test WITH replication = {'class': 'SimpleStrategy', 'replication_factor': '1'}: timeout",
+		},
+		{
+			caption: "copy dependencies",
+			keyspace: &fakeKeyspace{
+				tables:       []string{"dependencies_v2", "dependencies"},
+				dependencies: [][]interface{}{{time.Unix(0, 0).UTC(), []dependencystore.Dependency{}}},
+				insertErr:    errors.New("timeout"),
+			},
+			expectedError: "failed to copy the dependencies of 1970-01-01 00:00:00 +0000 UTC: timeout",
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.caption, func(t *testing.T) {
+			params := testParameters()
+			if testCase.params != nil {
+				testCase.params(&params)
+			}
+			err := NewMigrator(testCase.keyspace.session(), params, zap.NewNop()).Migrate()
+			assert.EqualError(t, err, testCase.expectedError)
+		})
+	}
+}
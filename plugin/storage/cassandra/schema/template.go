@@ -0,0 +1,166 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Version is the version of the Cassandra schema, i.e. the number N of the v00N.cql.tmpl template.
+type Version int
+
+const (
+	// NoSchema means that the keyspace does not exist or does not contain Jaeger tables.
+	NoSchema Version = iota
+
+	// V1 is the schema with the SASI indexed dependencies table.
+	V1
+
+	// V2 is the schema with the dependencies_v2 table bucketed by day.
+	V2
+
+	// LatestVersion is the version new keyspaces are created with.
+	LatestVersion = V2
+)
+
+var (
+	keyspaceRegex  = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	commentRegex   = regexp.MustCompile(`--.*`)
+	parameterRegex = regexp.MustCompile(`\$\{(\w+)\}`)
+)
+
+// String returns the name of the template of the version, e.g. v002.
+func (v Version) String() string {
+	if v == NoSchema {
+		return "none"
+	}
+	return fmt.Sprintf("v%03d", int(v))
+}
+
+// Parameters are the values substituted into the schema templates.
+type Parameters struct {
+	// Keyspace is the name of the keyspace holding the Jaeger tables.
+	Keyspace string
+
+	// Replication is the replication strategy of the keyspace,
+	// see SimpleReplication and NetworkTopologyReplication.
+	Replication string
+
+	// TraceTTL is the default time to live of the spans and their indexes.
+	TraceTTL time.Duration
+
+	// DependenciesTTL is the default time to live of the dependencies, 0 for no TTL.
+	DependenciesTTL time.Duration
+
+	// CompactionWindow is the time window of the tables using TimeWindowCompactionStrategy.
+	CompactionWindow time.Duration
+}
+
+// SimpleReplication returns the replication strategy for single datacenter (e.g. test) clusters.
+func SimpleReplication(replicationFactor int) string {
+	return fmt.Sprintf("{'class': 'SimpleStrategy', 'replication_factor': '%d'}", replicationFactor)
+}
+
+// NetworkTopologyReplication returns the replication strategy for production clusters.
+func NetworkTopologyReplication(datacenter string, replicationFactor int) string {
+	return fmt.Sprintf("{'class': 'NetworkTopologyStrategy', '%s': '%d'}", datacenter, replicationFactor)
+}
+
+// Validate returns an error if the parameters cannot be used to render a template.
+func (p Parameters) Validate() error {
+	if !keyspaceRegex.MatchString(p.Keyspace) {
+		return errors.Errorf("invalid keyspace '%s', please use letters, digits or underscores", p.Keyspace)
+	}
+	if p.Replication == "" {
+		return errors.New("replication strategy is not set")
+	}
+	if p.TraceTTL < time.Second {
+		return errors.Errorf("trace TTL must be at least one second, got %v", p.TraceTTL)
+	}
+	if p.DependenciesTTL < 0 {
+		return errors.Errorf("dependencies TTL must not be negative, got %v", p.DependenciesTTL)
+	}
+	if p.CompactionWindow < time.Minute || p.CompactionWindow%time.Minute != 0 {
+		return errors.Errorf("compaction window must be a positive number of minutes, got %v", p.CompactionWindow)
+	}
+	return nil
+}
+
+// compactionWindow returns the compaction window in the largest unit it is a multiple of.
+func (p Parameters) compactionWindow() (int64, string) {
+	window := p.CompactionWindow
+	switch {
+	case window%(24*time.Hour) == 0:
+		return int64(window / (24 * time.Hour)), "DAYS"
+	case window%time.Hour == 0:
+		return int64(window / time.Hour), "HOURS"
+	default:
+		return int64(window / time.Minute), "MINUTES"
+	}
+}
+
+func (p Parameters) values() map[string]string {
+	windowSize, windowUnit := p.compactionWindow()
+	return map[string]string{
+		"keyspace":               p.Keyspace,
+		"replication":            p.Replication,
+		"trace_ttl":              fmt.Sprint(int64(p.TraceTTL / time.Second)),
+		"dependencies_ttl":       fmt.Sprint(int64(p.DependenciesTTL / time.Second)),
+		"compaction_window_size": fmt.Sprint(windowSize),
+		"compaction_window_unit": windowUnit,
+	}
+}
+
+// Render returns the CQL statements of the template of the given version with the parameters substituted.
+func Render(version Version, params Parameters) ([]string, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	template, err := FSString(false, "/"+version.String()+".cql.tmpl")
+	if err != nil {
+		return nil, errors.Errorf("no template for schema version %s", version)
+	}
+	values := params.values()
+	var missing []string
+	template = parameterRegex.ReplaceAllStringFunc(commentRegex.ReplaceAllString(template, ""), func(name string) string {
+		name = parameterRegex.FindStringSubmatch(name)[1]
+		value, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return nil, errors.Errorf("template %s has unknown parameters %v", version, missing)
+	}
+	var statements []string
+	for _, statement := range strings.Split(template, ";") {
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			if line = strings.TrimRight(line, " \t"); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			statements = append(statements, strings.Join(lines, "\n"))
+		}
+	}
+	return statements, nil
+}
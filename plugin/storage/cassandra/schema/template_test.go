@@ -0,0 +1,146 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testParameters() Parameters {
+	return Parameters{
+		Keyspace:         "jaeger_v1_test",
+		Replication:      SimpleReplication(1),
+		TraceTTL:         48 * time.Hour,
+		DependenciesTTL:  0,
+		CompactionWindow: time.Hour,
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	assert.Equal(t, "none", NoSchema.String())
+	assert.Equal(t, "v001", V1.String())
+	assert.Equal(t, "v002", V2.String())
+}
+
+func TestReplication(t *testing.T) {
+	assert.Equal(t, "{'class': 'SimpleStrategy', 'replication_factor': '1'}", SimpleReplication(1))
+	assert.Equal(t, "{'class': 'NetworkTopologyStrategy', 'dc1': '3'}", NetworkTopologyReplication("dc1", 3))
+}
+
+func TestRender(t *testing.T) {
+	params := testParameters()
+	params.Replication = NetworkTopologyReplication("dc1", 2)
+	params.DependenciesTTL = 30 * 24 * time.Hour
+	params.CompactionWindow = 2 * time.Hour
+	statements, err := Render(V2, params)
+	require.NoError(t, err)
+	require.Len(t, statements, 14)
+	assert.Equal(t, "CREATE KEYSPACE IF NOT EXISTS jaeger_v1_test WITH replication = {'class': 'NetworkTopologyStrategy', 'dc1': '2'}", statements[0])
+
+	cql := strings.Join(statements, ";\n")
+	assert.NotContains(t, cql, "${")
+	assert.NotContains(t, cql, "--")
+	assert.Contains(t, cql, "CREATE TABLE IF NOT EXISTS jaeger_v1_test.traces (")
+	assert.Contains(t, cql, "default_time_to_live = 172800\n")
+	assert.Contains(t, cql, "default_time_to_live = 2592000")
+	assert.Contains(t, cql, "'compaction_window_size': '2',\n        'compaction_window_unit': 'HOURS',")
+	for _, statement := range statements {
+		assert.Equal(t, strings.TrimSpace(statement), statement)
+		assert.NotContains(t, statement, "\n\n")
+	}
+
+	statements, err = Render(V1, params)
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(statements, ";\n"), "CREATE TABLE IF NOT EXISTS jaeger_v1_test.dependencies (")
+}
+
+func TestRenderErrors(t *testing.T) {
+	testCases := []struct {
+		caption       string
+		version       Version
+		update        func(p *Parameters)
+		expectedError string
+	}{
+		{
+			caption:       "unknown version",
+			version:       Version(42),
+			update:        func(p *Parameters) {},
+			expectedError: "no template for schema version v042",
+		},
+		{
+			caption:       "invalid keyspace",
+			version:       V2,
+			update:        func(p *Parameters) { p.Keyspace = "jaeger-v1" },
+			expectedError: "invalid keyspace 'jaeger-v1', please use letters, digits or underscores",
+		},
+		{
+			caption:       "missing replication",
+			version:       V2,
+			update:        func(p *Parameters) { p.Replication = "" },
+			expectedError: "replication strategy is not set",
+		},
+		{
+			caption:       "trace TTL",
+			version:       V2,
+			update:        func(p *Parameters) { p.TraceTTL = 0 },
+			expectedError: "trace TTL must be at least one second, got 0s",
+		},
+		{
+			caption:       "dependencies TTL",
+			version:       V2,
+			update:        func(p *Parameters) { p.DependenciesTTL = -time.Hour },
+			expectedError: "dependencies TTL must not be negative, got -1h0m0s",
+		},
+		{
+			caption:       "compaction window",
+			version:       V2,
+			update:        func(p *Parameters) { p.CompactionWindow = 90 * time.Second },
+			expectedError: "compaction window must be a positive number of minutes, got 1m30s",
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.caption, func(t *testing.T) {
+			params := testParameters()
+			testCase.update(&params)
+			_, err := Render(testCase.version, params)
+			assert.EqualError(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestCompactionWindow(t *testing.T) {
+	testCases := []struct {
+		window time.Duration
+		size   int64
+		unit   string
+	}{
+		{window: 30 * time.Minute, size: 30, unit: "MINUTES"},
+		{window: 90 * time.Minute, size: 90, unit: "MINUTES"},
+		{window: time.Hour, size: 1, unit: "HOURS"},
+		{window: 36 * time.Hour, size: 36, unit: "HOURS"},
+		{window: 48 * time.Hour, size: 2, unit: "DAYS"},
+	}
+	for _, testCase := range testCases {
+		size, unit := Parameters{CompactionWindow: testCase.window}.compactionWindow()
+		assert.Equal(t, testCase.size, size, testCase.window.String())
+		assert.Equal(t, testCase.unit, unit, testCase.window.String())
+	}
+}
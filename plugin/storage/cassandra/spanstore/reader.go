@@ -251,6 +251,17 @@ func (s *SpanReader) FindTraces(ctx context.Context, traceQuery *spanstore.Trace
 
 // FindTraceIDs retrieve traceIDs that match the traceQuery
 func (s *SpanReader) FindTraceIDs(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	if traceQuery != nil && len(traceQuery.TagFilters) > 0 {
+		// the tag index can only be looked up by value
+		tags, err := traceQuery.EqualityTags()
+		if err != nil {
+			return nil, err
+		}
+		query := *traceQuery
+		query.Tags = tags
+		query.TagFilters = nil
+		traceQuery = &query
+	}
 	if err := validateQuery(traceQuery); err != nil {
 		return nil, err
 	}
@@ -163,6 +163,7 @@ func TestSpanReaderFindTraces(t *testing.T) {
 		caption                           string
 		numTraces                         int
 		queryTags                         bool
+		tagFilters                        []spanstore.TagFilter
 		queryOperation                    bool
 		queryDuration                     bool
 		mainQueryError                    error
@@ -183,6 +184,21 @@ func TestSpanReaderFindTraces(t *testing.T) {
 			expectedCount: 2,
 			queryTags:     true,
 		},
+		{
+			caption:        "equality tag filter query",
+			tagFilters:     []spanstore.TagFilter{{Key: "x", Operator: spanstore.TagEquals, Value: "y"}},
+			tagsQueryError: errors.New("tags query error"),
+			expectedError:  "tags query error",
+			expectedLogs: []string{
+				"Failed to exec query",
+				"tags query error",
+			},
+		},
+		{
+			caption:       "unsupported tag operator",
+			tagFilters:    []spanstore.TagFilter{{Key: "x", Operator: spanstore.TagNotEquals, Value: "y"}},
+			expectedError: "cannot filter tag 'x' with operator '!=': " + spanstore.ErrUnsupportedTagOperator.Error(),
+		},
 		{
 			caption:       "with limit",
 			numTraces:     1,
@@ -348,6 +364,7 @@ func TestSpanReaderFindTraces(t *testing.T) {
 					queryParams.Tags = make(map[string]string)
 					queryParams.Tags["x"] = "y"
 				}
+				queryParams.TagFilters = testCase.tagFilters
 				if testCase.queryOperation {
 					queryParams.OperationName = "operation-b"
 				}
@@ -0,0 +1,127 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// maxTTL is the largest TTL accepted by Cassandra (20 years)
+const maxTTL = 630720000 * time.Second
+
+// RetentionPolicy selects the TTL of the rows written for a span. Tag rules are checked in order
+// before the service TTLs, and spans matching neither keep DefaultTTL. A zero TTL leaves the rows
+// with the default TTL of the tables.
+type RetentionPolicy struct {
+	DefaultTTL  time.Duration
+	ServiceTTLs map[string]time.Duration
+	TagTTLs     []TagRetention
+}
+
+// TagRetention is the TTL of the spans that have a span or process tag with the given key and value.
+type TagRetention struct {
+	Key   string
+	Value string
+	TTL   time.Duration
+}
+
+// retentionPolicyFile is the JSON representation of a RetentionPolicy, e.g.
+// {"default_ttl": "48h", "services": {"payments": "720h"}, "tags": [{"key": "retention", "value": "long", "ttl": "720h"}]}
+type retentionPolicyFile struct {
+	DefaultTTL string            `json:"default_ttl"`
+	Services   map[string]string `json:"services"`
+	Tags       []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+		TTL   string `json:"ttl"`
+	} `json:"tags"`
+}
+
+// LoadRetentionPolicy reads a RetentionPolicy from a JSON file. TTLs are Go durations, e.g. "720h".
+func LoadRetentionPolicy(path string) (*RetentionPolicy, error) {
+	content, err := ioutil.ReadFile(path) /* nolint #nosec , this comes from an admin, not user */
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to open retention policy file")
+	}
+	var file retentionPolicyFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal retention policy")
+	}
+	policy := &RetentionPolicy{ServiceTTLs: make(map[string]time.Duration, len(file.Services))}
+	if file.DefaultTTL != "" {
+		if policy.DefaultTTL, err = parseTTL(file.DefaultTTL); err != nil {
+			return nil, errors.Wrap(err, "invalid default_ttl")
+		}
+	}
+	for service, ttl := range file.Services {
+		if policy.ServiceTTLs[service], err = parseTTL(ttl); err != nil {
+			return nil, errors.Wrapf(err, "invalid ttl for service '%s'", service)
+		}
+	}
+	for _, tag := range file.Tags {
+		if tag.Key == "" {
+			return nil, errors.New("tag retention without a key")
+		}
+		ttl, err := parseTTL(tag.TTL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid ttl for tag '%s=%s'", tag.Key, tag.Value)
+		}
+		policy.TagTTLs = append(policy.TagTTLs, TagRetention{Key: tag.Key, Value: tag.Value, TTL: ttl})
+	}
+	return policy, nil
+}
+
+func parseTTL(value string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if ttl < time.Second || ttl > maxTTL {
+		return 0, fmt.Errorf("ttl %v is not between 1s and %v", ttl, maxTTL)
+	}
+	return ttl, nil
+}
+
+// TTL returns the TTL of the rows written for the span, or zero for the default TTL of the tables.
+func (p *RetentionPolicy) TTL(span *model.Span) time.Duration {
+	if p == nil {
+		return 0
+	}
+	for _, rule := range p.TagTTLs {
+		if hasTag(span.Tags, rule.Key, rule.Value) || hasTag(span.Process.Tags, rule.Key, rule.Value) {
+			return rule.TTL
+		}
+	}
+	if ttl, ok := p.ServiceTTLs[span.Process.ServiceName]; ok {
+		return ttl
+	}
+	return p.DefaultTTL
+}
+
+func hasTag(tags model.KeyValues, key, value string) bool {
+	for _, kv := range tags {
+		if kv.Key == key && kv.AsString() == value {
+			return true
+		}
+	}
+	return false
+}
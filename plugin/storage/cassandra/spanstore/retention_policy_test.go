@@ -0,0 +1,137 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanstore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func writeRetentionPolicy(t *testing.T, content string) string {
+	file, err := ioutil.TempFile("", "retention_policy")
+	require.NoError(t, err)
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	return file.Name()
+}
+
+func TestLoadRetentionPolicy(t *testing.T) {
+	path := writeRetentionPolicy(t, `{
+		"default_ttl": "48h",
+		"services": {"payments": "720h", "healthcheck": "24h"},
+		"tags": [{"key": "retention", "value": "long", "ttl": "2160h"}]
+	}`)
+	defer os.Remove(path)
+
+	policy, err := LoadRetentionPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, &RetentionPolicy{
+		DefaultTTL:  48 * time.Hour,
+		ServiceTTLs: map[string]time.Duration{"payments": 720 * time.Hour, "healthcheck": 24 * time.Hour},
+		TagTTLs:     []TagRetention{{Key: "retention", Value: "long", TTL: 2160 * time.Hour}},
+	}, policy)
+}
+
+func TestLoadRetentionPolicyErrors(t *testing.T) {
+	_, err := LoadRetentionPolicy("/does/not/exist.json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to open retention policy file")
+
+	testCases := []struct {
+		content string
+		err     string
+	}{
+		{`{"services": ["payments"]}`, "Failed to unmarshal retention policy"},
+		{`{"default_ttl": "2 days"}`, "invalid default_ttl: time: unknown unit"},
+		{`{"services": {"payments": "500ms"}}`, "invalid ttl for service 'payments': ttl 500ms is not between 1s and 175200h0m0s"},
+		{`{"tags": [{"key": "retention", "value": "long", "ttl": "200000h"}]}`, "invalid ttl for tag 'retention=long': ttl 200000h0m0s is not between 1s and 175200h0m0s"},
+		{`{"tags": [{"value": "long", "ttl": "24h"}]}`, "tag retention without a key"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.content, func(t *testing.T) {
+			path := writeRetentionPolicy(t, tc.content)
+			defer os.Remove(path)
+			_, err := LoadRetentionPolicy(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestRetentionPolicyTTL(t *testing.T) {
+	policy := &RetentionPolicy{
+		DefaultTTL:  48 * time.Hour,
+		ServiceTTLs: map[string]time.Duration{"payments": 720 * time.Hour, "healthcheck": 24 * time.Hour},
+		TagTTLs: []TagRetention{
+			{Key: "retention", Value: "long", TTL: 2160 * time.Hour},
+			{Key: "debug", Value: "true", TTL: time.Hour},
+		},
+	}
+	testCases := []struct {
+		caption string
+		span    *model.Span
+		ttl     time.Duration
+	}{
+		{
+			caption: "default",
+			span:    &model.Span{Process: &model.Process{ServiceName: "frontend"}},
+			ttl:     48 * time.Hour,
+		},
+		{
+			caption: "service",
+			span:    &model.Span{Process: &model.Process{ServiceName: "healthcheck"}},
+			ttl:     24 * time.Hour,
+		},
+		{
+			caption: "span tag before service",
+			span: &model.Span{
+				Tags:    model.KeyValues{model.String("retention", "long")},
+				Process: &model.Process{ServiceName: "healthcheck"},
+			},
+			ttl: 2160 * time.Hour,
+		},
+		{
+			caption: "process tag",
+			span: &model.Span{
+				Process: &model.Process{ServiceName: "frontend", Tags: model.KeyValues{model.Bool("debug", true)}},
+			},
+			ttl: time.Hour,
+		},
+		{
+			caption: "other tag value",
+			span: &model.Span{
+				Tags:    model.KeyValues{model.String("retention", "short")},
+				Process: &model.Process{ServiceName: "payments"},
+			},
+			ttl: 720 * time.Hour,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caption, func(t *testing.T) {
+			assert.Equal(t, tc.ttl, policy.TTL(tc.span))
+		})
+	}
+
+	var noPolicy *RetentionPolicy
+	assert.Equal(t, time.Duration(0), noPolicy.TTL(&model.Span{Process: &model.Process{}}))
+}
@@ -58,6 +58,10 @@ const (
 		INTO duration_index(service_name, operation_name, bucket, duration, start_time, trace_id)
 		VALUES (?, ?, ?, ?, ?, ?)`
 
+	// usingTTL is appended to the inserts of the spans with a TTL from the retention policy
+	usingTTL = `
+		USING TTL ?`
+
 	maximumTagKeyOrValueSize = 256
 
 	// DefaultNumBuckets Number of buckets for bucketed keys
@@ -94,6 +98,7 @@ type SpanWriter struct {
 	tagFilter            dbmodel.TagFilter
 	storageMode          storageMode
 	indexFilter          dbmodel.IndexFilter
+	retentionPolicy      *RetentionPolicy
 }
 
 // NewSpanWriter returns a SpanWriter
@@ -124,6 +129,7 @@ func NewSpanWriter(
 		tagFilter:       opts.tagFilter,
 		storageMode:     opts.storageMode,
 		indexFilter:     opts.indexFilter,
+		retentionPolicy: opts.retentionPolicy,
 	}
 }
 
@@ -136,22 +142,22 @@ func (s *SpanWriter) Close() error {
 // WriteSpan saves the span into Cassandra
 func (s *SpanWriter) WriteSpan(span *model.Span) error {
 	ds := dbmodel.FromDomain(span)
+	ttl := int(s.retentionPolicy.TTL(span) / time.Second)
 	if s.storageMode&storeFlag == storeFlag {
-		if err := s.writeSpan(span, ds); err != nil {
+		if err := s.writeSpan(span, ds, ttl); err != nil {
 			return err
 		}
 	}
 	if s.storageMode&indexFlag == indexFlag {
-		if err := s.writeIndexes(span, ds); err != nil {
+		if err := s.writeIndexes(span, ds, ttl); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (s *SpanWriter) writeSpan(span *model.Span, ds *dbmodel.Span) error {
-	mainQuery := s.session.Query(
-		insertSpan,
+func (s *SpanWriter) writeSpan(span *model.Span, ds *dbmodel.Span, ttl int) error {
+	mainQuery := s.session.Query(ttlStatement(insertSpan, ttl), ttlValues(ttl,
 		ds.TraceID,
 		ds.SpanID,
 		ds.SpanHash,
@@ -164,49 +170,49 @@ func (s *SpanWriter) writeSpan(span *model.Span, ds *dbmodel.Span) error {
 		ds.Logs,
 		ds.Refs,
 		ds.Process,
-	)
+	)...)
 	if err := s.writerMetrics.traces.Exec(mainQuery, s.logger); err != nil {
 		return s.logError(ds, err, "Failed to insert span", s.logger)
 	}
 	return nil
 }
 
-func (s *SpanWriter) writeIndexes(span *model.Span, ds *dbmodel.Span) error {
+func (s *SpanWriter) writeIndexes(span *model.Span, ds *dbmodel.Span, ttl int) error {
 	if err := s.saveServiceNameAndOperationName(ds.ServiceName, ds.OperationName); err != nil {
 		// should this be a soft failure?
 		return s.logError(ds, err, "Failed to insert service name and operation name", s.logger)
 	}
 
-	if err := s.indexByTags(span, ds); err != nil {
+	if err := s.indexByTags(span, ds, ttl); err != nil {
 		return s.logError(ds, err, "Failed to index tags", s.logger)
 	}
 
 	if s.indexFilter(ds, dbmodel.ServiceIndex) {
-		if err := s.indexByService(ds); err != nil {
+		if err := s.indexByService(ds, ttl); err != nil {
 			return s.logError(ds, err, "Failed to index service name", s.logger)
 		}
 	}
 
 	if s.indexFilter(ds, dbmodel.OperationIndex) {
-		if err := s.indexByOperation(ds); err != nil {
+		if err := s.indexByOperation(ds, ttl); err != nil {
 			return s.logError(ds, err, "Failed to index operation name", s.logger)
 		}
 	}
 
 	if s.indexFilter(ds, dbmodel.DurationIndex) {
-		if err := s.indexByDuration(ds, span.StartTime); err != nil {
+		if err := s.indexByDuration(ds, span.StartTime, ttl); err != nil {
 			return s.logError(ds, err, "Failed to index duration", s.logger)
 		}
 	}
 	return nil
 }
 
-func (s *SpanWriter) indexByTags(span *model.Span, ds *dbmodel.Span) error {
+func (s *SpanWriter) indexByTags(span *model.Span, ds *dbmodel.Span, ttl int) error {
 	for _, v := range dbmodel.GetAllUniqueTags(span, s.tagFilter) {
 		// we should introduce retries or just ignore failures imo, retrying each individual tag insertion might be better
 		// we should consider bucketing.
 		if s.shouldIndexTag(v) {
-			insertTagQuery := s.session.Query(insertTag, ds.TraceID, ds.SpanID, v.ServiceName, ds.StartTime, v.TagKey, v.TagValue)
+			insertTagQuery := s.session.Query(ttlStatement(insertTag, ttl), ttlValues(ttl, ds.TraceID, ds.SpanID, v.ServiceName, ds.StartTime, v.TagKey, v.TagValue)...)
 			if err := s.writerMetrics.tagIndex.Exec(insertTagQuery, s.logger); err != nil {
 				withTagInfo := s.logger.
 					With(zap.String("tag_key", v.TagKey)).
@@ -221,12 +227,12 @@ func (s *SpanWriter) indexByTags(span *model.Span, ds *dbmodel.Span) error {
 	return nil
 }
 
-func (s *SpanWriter) indexByDuration(span *dbmodel.Span, startTime time.Time) error {
-	query := s.session.Query(durationIndex)
+func (s *SpanWriter) indexByDuration(span *dbmodel.Span, startTime time.Time, ttl int) error {
+	query := s.session.Query(ttlStatement(durationIndex, ttl))
 	timeBucket := startTime.Round(durationBucketSize)
 	var err error
 	indexByOperationName := func(operationName string) {
-		q1 := query.Bind(span.Process.ServiceName, operationName, timeBucket, span.Duration, span.StartTime, span.TraceID)
+		q1 := query.Bind(ttlValues(ttl, span.Process.ServiceName, operationName, timeBucket, span.Duration, span.StartTime, span.TraceID)...)
 		if err2 := s.writerMetrics.durationIndex.Exec(q1, s.logger); err2 != nil {
 			s.logError(span, err2, "Cannot index duration", s.logger)
 			err = err2
@@ -237,19 +243,35 @@ func (s *SpanWriter) indexByDuration(span *dbmodel.Span, startTime time.Time) er
 	return err
 }
 
-func (s *SpanWriter) indexByService(span *dbmodel.Span) error {
+func (s *SpanWriter) indexByService(span *dbmodel.Span, ttl int) error {
 	bucketNo := uint64(span.SpanHash) % defaultNumBuckets
-	query := s.session.Query(serviceNameIndex)
-	q := query.Bind(span.Process.ServiceName, bucketNo, span.StartTime, span.TraceID)
+	query := s.session.Query(ttlStatement(serviceNameIndex, ttl))
+	q := query.Bind(ttlValues(ttl, span.Process.ServiceName, bucketNo, span.StartTime, span.TraceID)...)
 	return s.writerMetrics.serviceNameIndex.Exec(q, s.logger)
 }
 
-func (s *SpanWriter) indexByOperation(span *dbmodel.Span) error {
-	query := s.session.Query(serviceOperationIndex)
-	q := query.Bind(span.Process.ServiceName, span.OperationName, span.StartTime, span.TraceID)
+func (s *SpanWriter) indexByOperation(span *dbmodel.Span, ttl int) error {
+	query := s.session.Query(ttlStatement(serviceOperationIndex, ttl))
+	q := query.Bind(ttlValues(ttl, span.Process.ServiceName, span.OperationName, span.StartTime, span.TraceID)...)
 	return s.writerMetrics.serviceOperationIndex.Exec(q, s.logger)
 }
 
+// ttlStatement adds a TTL to the insert statement unless ttl is zero, so the rows keep the default TTL of the table
+func ttlStatement(stmt string, ttl int) string {
+	if ttl == 0 {
+		return stmt
+	}
+	return stmt + usingTTL
+}
+
+// ttlValues appends the TTL in seconds to the values bound to a statement from ttlStatement
+func ttlValues(ttl int, values ...interface{}) []interface{} {
+	if ttl == 0 {
+		return values
+	}
+	return append(values, ttl)
+}
+
 // shouldIndexTag checks to see if the tag is json or not, if it's UTF8 valid and it's not too large
 func (s *SpanWriter) shouldIndexTag(tag dbmodel.TagInsertion) bool {
 	isJSON := func(s string) bool {
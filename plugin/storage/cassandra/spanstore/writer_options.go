@@ -24,9 +24,10 @@ type Option func(c *Options)
 
 // Options control behavior of the writer.
 type Options struct {
-	tagFilter   dbmodel.TagFilter
-	storageMode storageMode
-	indexFilter dbmodel.IndexFilter
+	tagFilter       dbmodel.TagFilter
+	storageMode     storageMode
+	indexFilter     dbmodel.IndexFilter
+	retentionPolicy *RetentionPolicy
 }
 
 // TagFilter can be provided to filter any tags that should not be indexed.
@@ -57,6 +58,13 @@ func IndexFilter(indexFilter dbmodel.IndexFilter) Option {
 	}
 }
 
+// Retention can be provided to write the spans with TTLs from a retention policy instead of the default TTL of the tables.
+func Retention(policy *RetentionPolicy) Option {
+	return func(o *Options) {
+		o.retentionPolicy = policy
+	}
+}
+
 func applyOptions(opts ...Option) Options {
 	o := Options{}
 	for _, opt := range opts {
@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-lib/metrics/metricstest"
 	"go.uber.org/zap"
 
@@ -212,6 +214,63 @@ func TestSpanWriter(t *testing.T) {
 	}
 }
 
+func TestSpanWriterRetentionPolicy(t *testing.T) {
+	policy := &RetentionPolicy{ServiceTTLs: map[string]time.Duration{"service-a": 24 * time.Hour}}
+	testCases := []struct {
+		service     string
+		expectedTTL int
+	}{
+		{service: "service-a", expectedTTL: 86400},
+		{service: "service-b"},
+	}
+	for _, tc := range testCases {
+		testCase := tc // capture loop var
+		t.Run(testCase.service, func(t *testing.T) {
+			withSpanWriter(0, func(w *spanWriterTest) {
+				var statements []string
+				var boundValues [][]interface{}
+				query := &mocks.Query{}
+				query.On("Bind", mock.Anything).Run(func(args mock.Arguments) {
+					boundValues = append(boundValues, args.Get(0).([]interface{}))
+				}).Return(query)
+				query.On("Exec").Return(nil)
+				w.session.On("Query", mock.AnythingOfType("string"), mock.Anything).Run(func(args mock.Arguments) {
+					statements = append(statements, args.String(0))
+					if values := args.Get(1).([]interface{}); len(values) > 0 {
+						boundValues = append(boundValues, values)
+					}
+				}).Return(query)
+				w.writer.serviceNamesWriter = func(serviceName string) error { return nil }
+				w.writer.operationNamesWriter = func(serviceName, operationName string) error { return nil }
+
+				err := w.writer.WriteSpan(&model.Span{
+					TraceID:       model.NewTraceID(0, 1),
+					OperationName: "operation-a",
+					Tags:          model.KeyValues{model.String("x", "y")},
+					Process:       &model.Process{ServiceName: testCase.service},
+				})
+				require.NoError(t, err)
+
+				// traces, tag_index, service_name_index, service_operation_index and duration_index
+				assert.Len(t, statements, 5)
+				for _, stmt := range statements {
+					assert.Equal(t, testCase.expectedTTL != 0, strings.Contains(stmt, "USING TTL ?"), stmt)
+				}
+				// duration_index is bound twice, by service and by operation
+				assert.Len(t, boundValues, 6)
+				for _, values := range boundValues {
+					last := values[len(values)-1]
+					if testCase.expectedTTL != 0 {
+						assert.Equal(t, testCase.expectedTTL, last)
+					} else {
+						assert.NotEqual(t, testCase.expectedTTL, last)
+					}
+				}
+			}, Retention(policy))
+		})
+	}
+}
+
 func TestSpanWriterSaveServiceNameAndOperationName(t *testing.T) {
 	expectedErr := errors.New("some error")
 	testCases := []struct {
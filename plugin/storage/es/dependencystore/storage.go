@@ -27,6 +27,7 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/es"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/dependencystore/dbmodel"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
 )
 
 const (
@@ -40,10 +41,11 @@ type DependencyStore struct {
 	client      es.Client
 	logger      *zap.Logger
 	indexPrefix string
+	dateLayout  indexdate.Layout
 }
 
 // NewDependencyStore returns a DependencyStore
-func NewDependencyStore(client es.Client, logger *zap.Logger, indexPrefix string) *DependencyStore {
+func NewDependencyStore(client es.Client, logger *zap.Logger, indexPrefix string, dateLayout indexdate.Layout) *DependencyStore {
 	var prefix string
 	if indexPrefix != "" {
 		prefix = indexPrefix + "-"
@@ -53,12 +55,13 @@ func NewDependencyStore(client es.Client, logger *zap.Logger, indexPrefix string
 		client:      client,
 		logger:      logger,
 		indexPrefix: prefix + dependencyIndex,
+		dateLayout:  dateLayout,
 	}
 }
 
 // WriteDependencies implements dependencystore.Writer#WriteDependencies.
 func (s *DependencyStore) WriteDependencies(ts time.Time, dependencies []model.DependencyLink) error {
-	indexName := indexWithDate(s.indexPrefix, ts)
+	indexName := s.dateLayout.IndexWithDate(s.indexPrefix, ts)
 	if err := s.createIndex(indexName); err != nil {
 		return err
 	}
@@ -83,7 +86,7 @@ func (s *DependencyStore) writeDependencies(indexName string, ts time.Time, depe
 
 // GetDependencies returns all interservice dependencies
 func (s *DependencyStore) GetDependencies(endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
-	indices := getIndices(s.indexPrefix, endTs, lookback)
+	indices := s.dateLayout.IndicesInRange(s.indexPrefix, endTs.Add(-lookback), endTs)
 	searchResult, err := s.client.Search(indices...).
 		Size(10000). // the default elasticsearch allowed limit
 		Query(buildTSQuery(endTs, lookback)).
@@ -109,19 +112,3 @@ func (s *DependencyStore) GetDependencies(endTs time.Time, lookback time.Duratio
 func buildTSQuery(endTs time.Time, lookback time.Duration) elastic.Query {
 	return elastic.NewRangeQuery("timestamp").Gte(endTs.Add(-lookback)).Lte(endTs)
 }
-
-func getIndices(prefix string, ts time.Time, lookback time.Duration) []string {
-	var indices []string
-	firstIndex := indexWithDate(prefix, ts.Add(-lookback))
-	currentIndex := indexWithDate(prefix, ts)
-	for currentIndex != firstIndex {
-		indices = append(indices, currentIndex)
-		ts = ts.Add(-24 * time.Hour)
-		currentIndex = indexWithDate(prefix, ts)
-	}
-	return append(indices, firstIndex)
-}
-
-func indexWithDate(indexNamePrefix string, date time.Time) string {
-	return indexNamePrefix + date.UTC().Format("2006-01-02")
-}
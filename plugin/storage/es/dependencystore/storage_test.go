@@ -25,11 +25,13 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/es/mocks"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 )
 
@@ -40,14 +42,14 @@ type depStorageTest struct {
 	storage   *DependencyStore
 }
 
-func withDepStorage(indexPrefix string, fn func(r *depStorageTest)) {
+func withDepStorage(indexPrefix string, dateLayout indexdate.Layout, fn func(r *depStorageTest)) {
 	client := &mocks.Client{}
 	logger, logBuffer := testutils.NewLogger()
 	r := &depStorageTest{
 		client:    client,
 		logger:    logger,
 		logBuffer: logBuffer,
-		storage:   NewDependencyStore(client, logger, indexPrefix),
+		storage:   NewDependencyStore(client, logger, indexPrefix, dateLayout),
 	}
 	fn(r)
 }
@@ -66,7 +68,7 @@ func TestNewSpanReaderIndexPrefix(t *testing.T) {
 	}
 	for _, testCase := range testCases {
 		client := &mocks.Client{}
-		r := NewDependencyStore(client, zap.NewNop(), testCase.prefix)
+		r := NewDependencyStore(client, zap.NewNop(), testCase.prefix, indexdate.Layout{})
 		assert.Equal(t, testCase.expected+dependencyIndex, r.indexPrefix)
 	}
 }
@@ -90,9 +92,9 @@ func TestWriteDependencies(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		withDepStorage("", func(r *depStorageTest) {
+		withDepStorage("", indexdate.Layout{}, func(r *depStorageTest) {
 			fixedTime := time.Date(1995, time.April, 21, 4, 21, 19, 95, time.UTC)
-			indexName := indexWithDate("", fixedTime)
+			indexName := indexdate.Layout{}.IndexWithDate("", fixedTime)
 
 			indexService := &mocks.IndicesCreateService{}
 			writeService := &mocks.IndexService{}
@@ -135,6 +137,8 @@ func TestGetDependencies(t *testing.T) {
 			]
 		}`
 	badDependencies := `badJson{hello}world`
+	weekly, err := indexdate.NewLayout(indexdate.Weekly, "")
+	require.NoError(t, err)
 
 	testCases := []struct {
 		searchResult   *elastic.SearchResult
@@ -142,6 +146,7 @@ func TestGetDependencies(t *testing.T) {
 		expectedError  string
 		expectedOutput []model.DependencyLink
 		indexPrefix    string
+		dateLayout     indexdate.Layout
 		indices        []interface{}
 	}{
 		{
@@ -171,9 +176,15 @@ func TestGetDependencies(t *testing.T) {
 			indexPrefix:   "foo",
 			indices:       []interface{}{"foo-jaeger-dependencies-1995-04-21", "foo-jaeger-dependencies-1995-04-20"},
 		},
+		{
+			searchError:   errors.New("search failure"),
+			expectedError: "Failed to search for dependencies: search failure",
+			dateLayout:    weekly,
+			indices:       []interface{}{"jaeger-dependencies-1995-04-17"},
+		},
 	}
 	for _, testCase := range testCases {
-		withDepStorage(testCase.indexPrefix, func(r *depStorageTest) {
+		withDepStorage(testCase.indexPrefix, testCase.dateLayout, func(r *depStorageTest) {
 			fixedTime := time.Date(1995, time.April, 21, 4, 21, 19, 95, time.UTC)
 
 			searchService := &mocks.SearchService{}
@@ -214,28 +225,28 @@ func TestGetIndices(t *testing.T) {
 		prefix   string
 	}{
 		{
-			expected: []string{indexWithDate("", fixedTime), indexWithDate("", fixedTime.Add(-24*time.Hour))},
+			expected: []string{indexdate.Layout{}.IndexWithDate("", fixedTime), indexdate.Layout{}.IndexWithDate("", fixedTime.Add(-24*time.Hour))},
 			lookback: 23 * time.Hour,
 			prefix:   "",
 		},
 		{
-			expected: []string{indexWithDate("", fixedTime), indexWithDate("", fixedTime.Add(-24*time.Hour))},
+			expected: []string{indexdate.Layout{}.IndexWithDate("", fixedTime), indexdate.Layout{}.IndexWithDate("", fixedTime.Add(-24*time.Hour))},
 			lookback: 13 * time.Hour,
 			prefix:   "",
 		},
 		{
-			expected: []string{indexWithDate("foo:", fixedTime)},
+			expected: []string{indexdate.Layout{}.IndexWithDate("foo:", fixedTime)},
 			lookback: 1 * time.Hour,
 			prefix:   "foo:",
 		},
 		{
-			expected: []string{indexWithDate("foo-", fixedTime)},
+			expected: []string{indexdate.Layout{}.IndexWithDate("foo-", fixedTime)},
 			lookback: 0,
 			prefix:   "foo-",
 		},
 	}
 	for _, testCase := range testCases {
-		assert.EqualValues(t, testCase.expected, getIndices(testCase.prefix, fixedTime, testCase.lookback))
+		assert.EqualValues(t, testCase.expected, indexdate.Layout{}.IndicesInRange(testCase.prefix, fixedTime.Add(-testCase.lookback), fixedTime))
 	}
 }
 
@@ -17,6 +17,7 @@ package es
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"os"
 	"path/filepath"
@@ -31,6 +32,8 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/es"
 	"github.com/jaegertracing/jaeger/pkg/es/config"
 	esDepStore "github.com/jaegertracing/jaeger/plugin/storage/es/dependencystore"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/lifecycle"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/mappings"
 	esSpanStore "github.com/jaegertracing/jaeger/plugin/storage/es/spanstore"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
@@ -44,7 +47,8 @@ const (
 
 // Factory implements storage.Factory for Elasticsearch backend.
 type Factory struct {
-	Options *Options
+	Options          *Options
+	LifecycleOptions lifecycle.Options
 
 	metricsFactory metrics.Factory
 	logger         *zap.Logger
@@ -53,6 +57,9 @@ type Factory struct {
 	primaryClient es.Client
 	archiveConfig config.ClientBuilder
 	archiveClient es.Client
+
+	dateLayout       indexdate.Layout
+	lifecycleManager *lifecycle.Manager
 }
 
 // NewFactory creates a new Factory.
@@ -67,17 +74,29 @@ func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
 	f.Options.AddFlags(flagSet)
 }
 
+// AddJobFlags adds the flags of the index maintenance job, which only the collector runs.
+func (f *Factory) AddJobFlags(flagSet *flag.FlagSet) {
+	lifecycle.AddJobFlags(flagSet)
+}
+
 // InitFromViper implements plugin.Configurable
 func (f *Factory) InitFromViper(v *viper.Viper) {
 	f.Options.InitFromViper(v)
 	f.primaryConfig = f.Options.GetPrimary()
 	f.archiveConfig = f.Options.Get(archiveNamespace)
+	f.LifecycleOptions.InitFromViper(v)
 }
 
 // Initialize implements storage.Factory
 func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
 	f.metricsFactory, f.logger = metricsFactory, logger
 
+	dateLayout, err := indexdate.NewLayout(f.primaryConfig.GetIndexFrequency(), f.primaryConfig.GetIndexDateLayout())
+	if err != nil {
+		return err
+	}
+	f.dateLayout = dateLayout
+
 	primaryClient, err := f.primaryConfig.NewClient(logger, metricsFactory)
 	if err != nil {
 		return errors.Wrap(err, "failed to create primary Elasticsearch client")
@@ -89,22 +108,63 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger)
 			return errors.Wrap(err, "failed to create archive Elasticsearch client")
 		}
 	}
+	if f.LifecycleOptions.Interval > 0 {
+		return f.startLifecycleJob()
+	}
+	return nil
+}
+
+// startLifecycleJob starts rolling over and cleaning up the primary indices in the background.
+// When read/write aliases are used the initial indices and aliases are created first.
+func (f *Factory) startLifecycleJob() error {
+	rawClient, err := f.primaryConfig.NewRawClient(f.logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Elasticsearch client for index maintenance")
+	}
+	spanMapping, serviceMapping := GetMappings(f.primaryConfig.GetNumShards(), f.primaryConfig.GetNumReplicas(), f.primaryClient.GetVersion())
+	options := f.LifecycleOptions
+	options.Archive = false
+	manager := lifecycle.NewManager(lifecycle.Params{
+		Client:         rawClient,
+		Logger:         f.logger,
+		IndexPrefix:    f.primaryConfig.GetIndexPrefix(),
+		DateLayout:     f.dateLayout,
+		SpanMapping:    spanMapping,
+		ServiceMapping: serviceMapping,
+		Rollover:       f.primaryConfig.GetUseReadWriteAliases(),
+		Options:        options,
+	})
+	if f.primaryConfig.GetUseReadWriteAliases() {
+		if err := manager.Init(context.Background()); err != nil {
+			return errors.Wrap(err, "failed to initialize Elasticsearch indices")
+		}
+	}
+	manager.Start(options.Interval)
+	f.lifecycleManager = manager
+	return nil
+}
+
+// Close implements io.Closer and stops the index maintenance job.
+func (f *Factory) Close() error {
+	if f.lifecycleManager != nil {
+		return f.lifecycleManager.Close()
+	}
 	return nil
 }
 
 // CreateSpanReader implements storage.Factory
 func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
-	return createSpanReader(f.metricsFactory, f.logger, f.primaryClient, f.primaryConfig, false)
+	return createSpanReader(f.metricsFactory, f.logger, f.primaryClient, f.primaryConfig, f.dateLayout, false)
 }
 
 // CreateSpanWriter implements storage.Factory
 func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
-	return createSpanWriter(f.metricsFactory, f.logger, f.primaryClient, f.primaryConfig, false)
+	return createSpanWriter(f.metricsFactory, f.logger, f.primaryClient, f.primaryConfig, f.dateLayout, false)
 }
 
 // CreateDependencyReader implements storage.Factory
 func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
-	return esDepStore.NewDependencyStore(f.primaryClient, f.logger, f.primaryConfig.GetIndexPrefix()), nil
+	return esDepStore.NewDependencyStore(f.primaryClient, f.logger, f.primaryConfig.GetIndexPrefix(), f.dateLayout), nil
 }
 
 func loadTagsFromFile(filePath string) ([]string, error) {
@@ -130,7 +190,7 @@ func (f *Factory) CreateArchiveSpanReader() (spanstore.Reader, error) {
 	if !f.archiveConfig.IsEnabled() {
 		return nil, nil
 	}
-	return createSpanReader(f.metricsFactory, f.logger, f.archiveClient, f.archiveConfig, true)
+	return createSpanReader(f.metricsFactory, f.logger, f.archiveClient, f.archiveConfig, f.dateLayout, true)
 }
 
 // CreateArchiveSpanWriter implements storage.ArchiveFactory
@@ -138,7 +198,7 @@ func (f *Factory) CreateArchiveSpanWriter() (spanstore.Writer, error) {
 	if !f.archiveConfig.IsEnabled() {
 		return nil, nil
 	}
-	return createSpanWriter(f.metricsFactory, f.logger, f.archiveClient, f.archiveConfig, true)
+	return createSpanWriter(f.metricsFactory, f.logger, f.archiveClient, f.archiveConfig, f.dateLayout, true)
 }
 
 func createSpanReader(
@@ -146,6 +206,7 @@ func createSpanReader(
 	logger *zap.Logger,
 	client es.Client,
 	cfg config.ClientBuilder,
+	dateLayout indexdate.Layout,
 	archive bool,
 ) (spanstore.Reader, error) {
 	return esSpanStore.NewSpanReader(esSpanStore.SpanReaderParams{
@@ -155,6 +216,7 @@ func createSpanReader(
 		MaxNumSpans:         cfg.GetMaxNumSpans(),
 		MaxSpanAge:          cfg.GetMaxSpanAge(),
 		IndexPrefix:         cfg.GetIndexPrefix(),
+		IndexDateLayout:     dateLayout,
 		TagDotReplacement:   cfg.GetTagDotReplacement(),
 		UseReadWriteAliases: cfg.GetUseReadWriteAliases(),
 		Archive:             archive,
@@ -166,6 +228,7 @@ func createSpanWriter(
 	logger *zap.Logger,
 	client es.Client,
 	cfg config.ClientBuilder,
+	dateLayout indexdate.Layout,
 	archive bool,
 ) (spanstore.Writer, error) {
 	var tags []string
@@ -183,11 +246,14 @@ func createSpanWriter(
 		Logger:              logger,
 		MetricsFactory:      mFactory,
 		IndexPrefix:         cfg.GetIndexPrefix(),
+		IndexDateLayout:     dateLayout,
 		AllTagsAsFields:     cfg.GetAllTagsAsFields(),
 		TagKeysAsFields:     tags,
 		TagDotReplacement:   cfg.GetTagDotReplacement(),
 		Archive:             archive,
 		UseReadWriteAliases: cfg.GetUseReadWriteAliases(),
+		ServiceCacheTTL:     cfg.GetServiceCacheTTL(),
+		ServiceCacheSize:    cfg.GetServiceCacheSize(),
 	})
 	if cfg.IsCreateIndexTemplates() {
 		err := writer.CreateTemplates(spanMapping, serviceMapping)
@@ -195,6 +261,12 @@ func createSpanWriter(
 			return nil, err
 		}
 	}
+	if cfg.GetServiceCachePrewarm() && !archive {
+		// a cold cache only costs extra writes, so do not fail the startup
+		if err := writer.PrewarmServiceCache(context.Background()); err != nil {
+			logger.Warn("Could not load service and operation names into the cache", zap.Error(err))
+		}
+	}
 	return writer, nil
 }
 
@@ -23,6 +23,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -34,6 +35,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/es"
 	escfg "github.com/jaegertracing/jaeger/pkg/es/config"
 	"github.com/jaegertracing/jaeger/pkg/es/mocks"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
 	"github.com/jaegertracing/jaeger/storage"
 )
 
@@ -53,6 +55,11 @@ func (m *mockClientBuilder) NewClient(logger *zap.Logger, metricsFactory metrics
 		tService.On("Do", context.Background()).Return(nil, m.createTemplateError)
 		c.On("CreateTemplate", mock.Anything).Return(tService)
 		c.On("GetVersion").Return(6)
+		sService := &mocks.SearchService{}
+		sService.On("Size", mock.Anything).Return(sService)
+		sService.On("IgnoreUnavailable", true).Return(sService)
+		sService.On("Do", mock.Anything).Return(nil, errors.New("index not found"))
+		c.On("Search", mock.Anything).Return(sService)
 		return c, nil
 	}
 	return nil, m.err
@@ -76,6 +83,11 @@ func TestElasticsearchFactory(t *testing.T) {
 	f.archiveConfig = &mockClientBuilder{}
 	assert.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
 
+	f.primaryConfig = &mockClientBuilder{Configuration: escfg.Configuration{IndexFrequency: "month"}}
+	assert.EqualError(t, f.Initialize(metrics.NullFactory, zap.NewNop()),
+		`unsupported index rollover frequency "month", must be one of hour, day, week`)
+	f.primaryConfig = &mockClientBuilder{}
+
 	_, err := f.CreateSpanReader()
 	assert.NoError(t, err)
 
@@ -92,6 +104,41 @@ func TestElasticsearchFactory(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestElasticsearchFactoryLifecycleJob(t *testing.T) {
+	server, requests := newIndexServer("6.8.0")
+	defer server.Close()
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags, f.AddJobFlags)
+	command.ParseFlags([]string{"--es.lifecycle.interval=1h"})
+	f.InitFromViper(v)
+	assert.Equal(t, time.Hour, f.LifecycleOptions.Interval)
+
+	f.primaryConfig = &mockClientBuilder{}
+	f.archiveConfig = &mockClientBuilder{}
+	assert.EqualError(t, f.Initialize(metrics.NullFactory, zap.NewNop()),
+		"failed to create Elasticsearch client for index maintenance: No servers specified")
+
+	f.primaryConfig = &mockClientBuilder{Configuration: escfg.Configuration{Servers: []string{server.URL}, UseReadWriteAliases: true}}
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	assert.Contains(t, requests(), "HEAD /jaeger-span-000001")
+	assert.Contains(t, requests(), "POST /_aliases")
+	assert.NoError(t, f.Close())
+}
+
+func TestElasticsearchFactoryPrewarmServiceCache(t *testing.T) {
+	f := NewFactory()
+	f.primaryConfig = &mockClientBuilder{Configuration: escfg.Configuration{ServiceCachePrewarm: true}}
+	f.archiveConfig = &mockClientBuilder{}
+	logger, logBuffer := testutils.NewLogger()
+	require.NoError(t, f.Initialize(metrics.NullFactory, logger))
+
+	// a failed pre-warm does not prevent the writer from being created
+	_, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	assert.Contains(t, logBuffer.String(), "Could not load service and operation names into the cache")
+}
+
 func TestElasticsearchTagsFileDoNotExist(t *testing.T) {
 	f := NewFactory()
 	mockConf := &mockClientBuilder{}
@@ -0,0 +1,105 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package es
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	escfg "github.com/jaegertracing/jaeger/pkg/es/config"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/lifecycle"
+)
+
+var indexActions = []string{"init", "rollover", "lookback", "cleanup"}
+
+// NewIndexCommand creates a command that manages the lifecycle of Jaeger indices in Elasticsearch.
+// It replaces esRollover.py and esCleaner.py and is configured with the same --es.* flags
+// as the storage.
+func NewIndexCommand() *cobra.Command {
+	v := viper.New()
+	options := NewOptions(primaryNamespace)
+	c := &cobra.Command{
+		Use:   "es-index ACTION",
+		Short: "Manages Elasticsearch indices",
+		Long: `Manages Jaeger indices in Elasticsearch. ACTION is one of:
+  init     - creates index templates, the first rollover indices and read/write aliases
+  rollover - rolls the write aliases over to new indices when a condition is met
+  lookback - removes old indices from the read aliases
+  cleanup  - deletes indices older than --es.lifecycle.retention-days`,
+		ValidArgs: indexActions,
+		Args:      cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.InitFromViper(v)
+			var lifecycleOptions lifecycle.Options
+			lifecycleOptions.InitFromViper(v)
+			logger, err := zap.NewProduction()
+			if err != nil {
+				return err
+			}
+			return runIndexAction(args[0], options.GetPrimary(), lifecycleOptions, logger)
+		},
+	}
+	config.AddFlags(v, c, options.AddFlags, lifecycle.AddFlags)
+	return c
+}
+
+func runIndexAction(action string, cfg *escfg.Configuration, options lifecycle.Options, logger *zap.Logger) error {
+	run, ok := map[string]func(m *lifecycle.Manager, ctx context.Context) error{
+		"init":     func(m *lifecycle.Manager, ctx context.Context) error { return m.Init(ctx) },
+		"rollover": func(m *lifecycle.Manager, ctx context.Context) error { return m.Rollover(ctx) },
+		"lookback": func(m *lifecycle.Manager, ctx context.Context) error { return m.Lookback(ctx, time.Now()) },
+		"cleanup":  func(m *lifecycle.Manager, ctx context.Context) error { return m.Cleanup(ctx, time.Now()) },
+	}[action]
+	if !ok {
+		return fmt.Errorf("unrecognized action %q, must be one of %v", action, indexActions)
+	}
+	dateLayout, err := indexdate.NewLayout(cfg.GetIndexFrequency(), cfg.GetIndexDateLayout())
+	if err != nil {
+		return err
+	}
+	client, err := cfg.NewRawClient(logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Elasticsearch client")
+	}
+	version, err := client.ElasticsearchVersion(cfg.Servers[0])
+	if err != nil {
+		return errors.Wrap(err, "failed to determine Elasticsearch version")
+	}
+	esVersion, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse Elasticsearch version %q", version)
+	}
+	spanMapping, serviceMapping := GetMappings(cfg.GetNumShards(), cfg.GetNumReplicas(), esVersion)
+	manager := lifecycle.NewManager(lifecycle.Params{
+		Client:         client,
+		Logger:         logger,
+		IndexPrefix:    cfg.GetIndexPrefix(),
+		SpanMapping:    spanMapping,
+		ServiceMapping: serviceMapping,
+		DateLayout:     dateLayout,
+		Options:        options,
+	})
+	return run(manager, context.Background())
+}
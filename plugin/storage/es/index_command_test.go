@@ -0,0 +1,83 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package es
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIndexServer returns a fake Elasticsearch that acknowledges every request and records them.
+func newIndexServer(version string) (*httptest.Server, func() []string) {
+	var mux sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mux.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/":
+			w.Write([]byte(`{"version":{"number":"` + version + `"}}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{}`))
+		default:
+			w.Write([]byte(`{"acknowledged":true}`))
+		}
+	}))
+	return server, func() []string {
+		mux.Lock()
+		defer mux.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestIndexCommand(t *testing.T) {
+	server, requests := newIndexServer("6.8.0")
+	defer server.Close()
+
+	c := NewIndexCommand()
+	c.SetArgs([]string{"init", "--es.server-urls=" + server.URL, "--es.index-prefix=foo"})
+	require.NoError(t, c.Execute())
+	assert.Contains(t, requests(), "PUT /_template/jaeger-span")
+	assert.Contains(t, requests(), "PUT /_template/jaeger-service")
+	assert.Contains(t, requests(), "HEAD /foo-jaeger-span-000001")
+	assert.Contains(t, requests(), "POST /_aliases")
+}
+
+func TestIndexCommandErrors(t *testing.T) {
+	server, _ := newIndexServer("x.y")
+	defer server.Close()
+
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{}, err: "accepts 1 arg(s), received 0"},
+		{args: []string{"foo"}, err: `unrecognized action "foo", must be one of [init rollover lookback cleanup]`},
+		{args: []string{"cleanup", "--es.server-urls=" + server.URL}, err: `failed to parse Elasticsearch version "x.y": strconv.Atoi: parsing "x": invalid syntax`},
+	}
+	for _, test := range tests {
+		c := NewIndexCommand()
+		c.SilenceErrors, c.SilenceUsage = true, true
+		c.SetArgs(test.args)
+		assert.EqualError(t, c.Execute(), test.err, "%v", test.args)
+	}
+}
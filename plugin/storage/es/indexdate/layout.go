@@ -0,0 +1,136 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexdate
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// Hourly creates a new index every hour.
+	Hourly = "hour"
+	// Daily creates a new index every day.
+	Daily = "day"
+	// Weekly creates a new index every week, starting on Monday.
+	Weekly = "week"
+
+	defaultLayout = "2006-01-02"
+	hourlyLayout  = "2006-01-02-15"
+)
+
+// Layout names time based indices. The zero value names daily indices with the 2006-01-02 layout.
+type Layout struct {
+	frequency string
+	layout    string
+}
+
+// NewLayout creates a Layout for the given frequency. An empty layout selects the default layout
+// of the frequency: 2006-01-02-15 for hourly indices and 2006-01-02 otherwise.
+func NewLayout(frequency, layout string) (Layout, error) {
+	switch frequency {
+	case "", Daily:
+		frequency = Daily
+	case Hourly, Weekly:
+	default:
+		return Layout{}, fmt.Errorf("unsupported index rollover frequency %q, must be one of %s, %s, %s", frequency, Hourly, Daily, Weekly)
+	}
+	if layout == "" && frequency == Hourly {
+		layout = hourlyLayout
+	}
+	l := Layout{frequency: frequency, layout: layout}
+	// the layout must tell consecutive indices apart and be parseable for the index cleanup
+	reference := l.truncate(time.Date(2019, time.December, 31, 23, 0, 0, 0, time.UTC))
+	for _, t := range []time.Time{reference, l.previous(reference)} {
+		parsed, err := l.ParseDate(l.format(t))
+		if err != nil || !parsed.Equal(t) {
+			return Layout{}, fmt.Errorf("index date layout %q cannot represent indices created every %s", l.getLayout(), frequency)
+		}
+	}
+	return l, nil
+}
+
+// IndexWithDate returns the name of the index with the given prefix that holds data from the given time.
+func (l Layout) IndexWithDate(prefix string, date time.Time) string {
+	return prefix + l.format(l.truncate(date))
+}
+
+// IndicesInRange returns the names of the indices with the given prefix that hold data between
+// start and end time, newest first. At least the index of the start time is returned.
+func (l Layout) IndicesInRange(prefix string, start, end time.Time) []string {
+	var indices []string
+	first := l.truncate(start)
+	for current := l.truncate(end); current.After(first); current = l.previous(current) {
+		indices = append(indices, prefix+l.format(current))
+	}
+	return append(indices, prefix+l.format(first))
+}
+
+// ParseDate parses the date part of an index name.
+func (l Layout) ParseDate(value string) (time.Time, error) {
+	return time.Parse(l.getLayout(), value)
+}
+
+// PeriodEnd returns the end of the period held by the index of the given time, i.e. the start
+// of the next index. Indices are older than a cutoff only if their period ends before it.
+func (l Layout) PeriodEnd(date time.Time) time.Time {
+	return l.next(l.truncate(date))
+}
+
+func (l Layout) getLayout() string {
+	if l.layout == "" {
+		return defaultLayout
+	}
+	return l.layout
+}
+
+func (l Layout) format(t time.Time) string {
+	return t.Format(l.getLayout())
+}
+
+func (l Layout) truncate(t time.Time) time.Time {
+	t = t.UTC()
+	switch l.frequency {
+	case Hourly:
+		return t.Truncate(time.Hour)
+	case Weekly:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func (l Layout) previous(t time.Time) time.Time {
+	switch l.frequency {
+	case Hourly:
+		return t.Add(-time.Hour)
+	case Weekly:
+		return t.AddDate(0, 0, -7)
+	default:
+		return t.AddDate(0, 0, -1)
+	}
+}
+
+func (l Layout) next(t time.Time) time.Time {
+	switch l.frequency {
+	case Hourly:
+		return t.Add(time.Hour)
+	case Weekly:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
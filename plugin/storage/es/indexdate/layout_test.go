@@ -0,0 +1,105 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexdate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLayoutErrors(t *testing.T) {
+	_, err := NewLayout("month", "")
+	assert.EqualError(t, err, `unsupported index rollover frequency "month", must be one of hour, day, week`)
+	_, err = NewLayout(Hourly, "2006-01-02")
+	assert.EqualError(t, err, `index date layout "2006-01-02" cannot represent indices created every hour`)
+	_, err = NewLayout(Daily, "2006-01")
+	assert.EqualError(t, err, `index date layout "2006-01" cannot represent indices created every day`)
+	_, err = NewLayout(Daily, "foo")
+	assert.Error(t, err)
+}
+
+func TestIndexWithDate(t *testing.T) {
+	date := time.Date(1995, time.April, 21, 4, 21, 19, 95, time.UTC)
+	testCases := []struct {
+		frequency string
+		layout    string
+		expected  string
+	}{
+		{frequency: "", expected: "jaeger-span-1995-04-21"},
+		{frequency: Daily, layout: "2006.01.02", expected: "jaeger-span-1995.04.21"},
+		{frequency: Hourly, expected: "jaeger-span-1995-04-21-04"},
+		{frequency: Hourly, layout: "2006010215", expected: "jaeger-span-1995042104"},
+		{frequency: Weekly, expected: "jaeger-span-1995-04-17"},
+	}
+	for _, testCase := range testCases {
+		l, err := NewLayout(testCase.frequency, testCase.layout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, l.IndexWithDate("jaeger-span-", date))
+	}
+	assert.Equal(t, "jaeger-span-1995-04-21", Layout{}.IndexWithDate("jaeger-span-", date))
+	// the date of the index is in UTC
+	assert.Equal(t, "jaeger-span-1995-04-20", Layout{}.IndexWithDate("jaeger-span-", time.Date(1995, time.April, 21, 1, 0, 0, 0, time.FixedZone("", 2*3600))))
+}
+
+func TestIndicesInRange(t *testing.T) {
+	end := time.Date(1995, time.April, 21, 4, 12, 19, 95, time.UTC)
+	testCases := []struct {
+		frequency string
+		start     time.Time
+		expected  []string
+	}{
+		{frequency: Daily, start: end.Add(-time.Millisecond), expected: []string{"foo-1995-04-21"}},
+		{frequency: Daily, start: end.Add(-13 * time.Hour), expected: []string{"foo-1995-04-21", "foo-1995-04-20"}},
+		{frequency: Daily, start: end.Add(-48 * time.Hour), expected: []string{"foo-1995-04-21", "foo-1995-04-20", "foo-1995-04-19"}},
+		{frequency: Daily, start: end.Add(time.Hour * 48), expected: []string{"foo-1995-04-23"}},
+		{frequency: Hourly, start: end.Add(-2 * time.Hour), expected: []string{"foo-1995-04-21-04", "foo-1995-04-21-03", "foo-1995-04-21-02"}},
+		{frequency: Weekly, start: end.Add(-5 * 24 * time.Hour), expected: []string{"foo-1995-04-17", "foo-1995-04-10"}},
+	}
+	for _, testCase := range testCases {
+		l, err := NewLayout(testCase.frequency, "")
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, l.IndicesInRange("foo-", testCase.start, end))
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	l, err := NewLayout(Hourly, "")
+	require.NoError(t, err)
+	date, err := l.ParseDate("1995-04-21-04")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(1995, time.April, 21, 4, 0, 0, 0, time.UTC), date)
+	_, err = l.ParseDate("000001")
+	assert.Error(t, err)
+}
+
+func TestPeriodEnd(t *testing.T) {
+	date := time.Date(1995, time.April, 21, 4, 21, 19, 95, time.UTC)
+	testCases := []struct {
+		frequency string
+		expected  time.Time
+	}{
+		{frequency: Hourly, expected: time.Date(1995, time.April, 21, 5, 0, 0, 0, time.UTC)},
+		{frequency: Daily, expected: time.Date(1995, time.April, 22, 0, 0, 0, 0, time.UTC)},
+		{frequency: Weekly, expected: time.Date(1995, time.April, 24, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, testCase := range testCases {
+		l, err := NewLayout(testCase.frequency, "")
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, l.PeriodEnd(date), testCase.frequency)
+	}
+}
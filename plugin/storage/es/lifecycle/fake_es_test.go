@@ -0,0 +1,202 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIndex struct {
+	aliases map[string]bool
+	created time.Time
+}
+
+// fakeES is a minimal in-memory Elasticsearch implementing the index APIs used by Manager.
+type fakeES struct {
+	sync.Mutex
+	server     *httptest.Server
+	indices    map[string]*fakeIndex
+	templates  map[string]string
+	conditions map[string]interface{}
+	rollover   bool
+	now        time.Time
+}
+
+func newFakeES(t *testing.T) (*fakeES, *elastic.Client) {
+	es := &fakeES{
+		indices:   make(map[string]*fakeIndex),
+		templates: make(map[string]string),
+		rollover:  true,
+		now:       time.Now(),
+	}
+	es.server = httptest.NewServer(http.HandlerFunc(es.handle))
+	client, err := elastic.NewClient(elastic.SetURL(es.server.URL), elastic.SetSniff(false), elastic.SetHealthcheck(false))
+	require.NoError(t, err)
+	return es, client
+}
+
+func (es *fakeES) addIndex(name string, created time.Time, aliases ...string) {
+	es.Lock()
+	defer es.Unlock()
+	index := &fakeIndex{aliases: make(map[string]bool), created: created}
+	for _, alias := range aliases {
+		index.aliases[alias] = true
+	}
+	es.indices[name] = index
+}
+
+func (es *fakeES) indexNames() []string {
+	es.Lock()
+	defer es.Unlock()
+	var names []string
+	for name := range es.indices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (es *fakeES) aliasIndices(alias string) []string {
+	es.Lock()
+	defer es.Unlock()
+	var names []string
+	for name, index := range es.indices {
+		if index.aliases[alias] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (es *fakeES) handle(w http.ResponseWriter, r *http.Request) {
+	es.Lock()
+	defer es.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/":
+		writeJSON(w, map[string]interface{}{"version": map[string]interface{}{"number": "6.8.0"}})
+	case parts[0] == "_template" && r.Method == http.MethodPut:
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		es.templates[parts[1]] = fmt.Sprint(body["index_patterns"])
+		writeJSON(w, map[string]interface{}{"acknowledged": true})
+	case parts[0] == "_aliases" && r.Method == http.MethodPost:
+		var body struct {
+			Actions []map[string]struct {
+				Index string `json:"index"`
+				Alias string `json:"alias"`
+			} `json:"actions"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, action := range body.Actions {
+			for kind, act := range action {
+				index, ok := es.indices[act.Index]
+				if !ok {
+					http.Error(w, `{"error":{"type":"index_not_found_exception"},"status":404}`, http.StatusNotFound)
+					return
+				}
+				if kind == "add" {
+					index.aliases[act.Alias] = true
+				} else {
+					delete(index.aliases, act.Alias)
+				}
+			}
+		}
+		writeJSON(w, map[string]interface{}{"acknowledged": true})
+	case len(parts) == 2 && parts[1] == "_rollover":
+		es.handleRollover(w, r, parts[0])
+	case r.Method == http.MethodHead:
+		if _, ok := es.indices[parts[0]]; ok {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodPut:
+		es.indices[parts[0]] = &fakeIndex{aliases: make(map[string]bool), created: es.now}
+		writeJSON(w, map[string]interface{}{"acknowledged": true, "index": parts[0]})
+	case r.Method == http.MethodGet:
+		result := make(map[string]interface{})
+		for name, index := range es.indices {
+			if ok, _ := path.Match(parts[0], name); !ok {
+				continue
+			}
+			aliases := make(map[string]interface{})
+			for alias := range index.aliases {
+				aliases[alias] = map[string]interface{}{}
+			}
+			result[name] = map[string]interface{}{
+				"aliases": aliases,
+				"settings": map[string]interface{}{"index": map[string]interface{}{
+					"creation_date": strconv.FormatInt(index.created.UnixNano()/int64(time.Millisecond), 10),
+				}},
+			}
+		}
+		writeJSON(w, result)
+	case r.Method == http.MethodDelete:
+		for _, name := range strings.Split(parts[0], ",") {
+			delete(es.indices, name)
+		}
+		writeJSON(w, map[string]interface{}{"acknowledged": true})
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.Path, http.StatusBadRequest)
+	}
+}
+
+func (es *fakeES) handleRollover(w http.ResponseWriter, r *http.Request, alias string) {
+	var body struct {
+		Conditions map[string]interface{} `json:"conditions"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+	es.conditions = body.Conditions
+	var oldIndex string
+	for name, index := range es.indices {
+		if index.aliases[alias] {
+			oldIndex = name
+		}
+	}
+	if oldIndex == "" {
+		http.Error(w, `{"error":{"type":"illegal_argument_exception"},"status":400}`, http.StatusBadRequest)
+		return
+	}
+	if !es.rollover {
+		writeJSON(w, map[string]interface{}{"old_index": oldIndex, "rolled_over": false})
+		return
+	}
+	pos := strings.LastIndex(oldIndex, "-")
+	n, _ := strconv.Atoi(oldIndex[pos+1:])
+	newIndex := fmt.Sprintf("%s-%06d", oldIndex[:pos], n+1)
+	delete(es.indices[oldIndex].aliases, alias)
+	es.indices[newIndex] = &fakeIndex{aliases: map[string]bool{alias: true}, created: es.now}
+	writeJSON(w, map[string]interface{}{"old_index": oldIndex, "new_index": newIndex, "rolled_over": true})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
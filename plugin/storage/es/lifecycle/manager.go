@@ -0,0 +1,394 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
+)
+
+const (
+	spanIndex         = "jaeger-span"
+	serviceIndex      = "jaeger-service"
+	archiveIndex      = "jaeger-span-archive"
+	readAliasSuffix   = "-read"
+	writeAliasSuffix  = "-write"
+	firstIndexSuffix  = "-000001"
+	indexPrefixSuffix = "-"
+)
+
+// Params contains the parameters of the index lifecycle Manager.
+type Params struct {
+	Client         *elastic.Client
+	Logger         *zap.Logger
+	IndexPrefix    string
+	SpanMapping    string
+	ServiceMapping string
+	DateLayout     indexdate.Layout
+	// Rollover enables the rollover and lookback steps of Maintain, it should match --es.use-aliases.
+	Rollover bool
+	Options
+}
+
+// Manager creates, rolls over and deletes Jaeger indices, replacing esRollover.py and esCleaner.py.
+type Manager struct {
+	client         *elastic.Client
+	logger         *zap.Logger
+	prefix         string
+	spanMapping    string
+	serviceMapping string
+	dateLayout     indexdate.Layout
+	rollover       bool
+	options        Options
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// indexSet is a group of rolled over indices sharing read and write aliases.
+type indexSet struct {
+	name     string
+	template string
+	mapping  string
+}
+
+type indexInfo struct {
+	name    string
+	aliases map[string]bool
+	created time.Time
+}
+
+// NewManager creates a new Manager.
+func NewManager(p Params) *Manager {
+	prefix := p.IndexPrefix
+	if prefix != "" {
+		prefix += indexPrefixSuffix
+	}
+	return &Manager{
+		client:         p.Client,
+		logger:         p.Logger,
+		prefix:         prefix,
+		spanMapping:    p.SpanMapping,
+		serviceMapping: p.ServiceMapping,
+		dateLayout:     p.DateLayout,
+		rollover:       p.Rollover,
+		options:        p.Options,
+		stop:           make(chan struct{}),
+	}
+}
+
+func (m *Manager) indexSets() []indexSet {
+	if m.options.Archive {
+		return []indexSet{{name: m.prefix + archiveIndex, template: spanIndex, mapping: m.spanMapping}}
+	}
+	return []indexSet{
+		{name: m.prefix + spanIndex, template: spanIndex, mapping: m.spanMapping},
+		{name: m.prefix + serviceIndex, template: serviceIndex, mapping: m.serviceMapping},
+	}
+}
+
+// Init creates the index templates, the first rollover indices and the read and write aliases.
+// Aliases which already point to an index are left untouched.
+func (m *Manager) Init(ctx context.Context) error {
+	for _, set := range m.indexSets() {
+		if _, err := m.client.IndexPutTemplate(set.template).BodyString(set.mapping).Do(ctx); err != nil {
+			return errors.Wrapf(err, "failed to create index template %s", set.template)
+		}
+		index := set.name + firstIndexSuffix
+		exists, err := m.client.IndexExists(index).Do(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check whether index %s exists", index)
+		}
+		if !exists {
+			m.logger.Info("Creating index", zap.String("index", index))
+			if _, err := m.client.CreateIndex(index).Do(ctx); err != nil {
+				return errors.Wrapf(err, "failed to create index %s", index)
+			}
+		}
+		indices, err := m.getIndices(ctx, set.name+"-*")
+		if err != nil {
+			return err
+		}
+		for _, alias := range []string{set.name + readAliasSuffix, set.name + writeAliasSuffix} {
+			if len(filterByAlias(indices, alias, false)) > 0 {
+				m.logger.Info("Alias is not empty, not adding indices to it", zap.String("alias", alias))
+				continue
+			}
+			m.logger.Info("Adding index to alias", zap.String("index", index), zap.String("alias", alias))
+			if _, err := m.client.Alias().Add(index, alias).Do(ctx); err != nil {
+				return errors.Wrapf(err, "failed to add index %s to alias %s", index, alias)
+			}
+		}
+	}
+	return nil
+}
+
+// Rollover rolls the write aliases over to new indices when any of the conditions is met
+// and adds the new indices to the read aliases. At least one condition must be set, Elasticsearch
+// rolls over unconditionally otherwise.
+func (m *Manager) Rollover(ctx context.Context) error {
+	if !m.hasRolloverConditions() {
+		return errors.New("at least one rollover condition must be set")
+	}
+	for _, set := range m.indexSets() {
+		writeAlias, readAlias := set.name+writeAliasSuffix, set.name+readAliasSuffix
+		service := m.client.RolloverIndex(writeAlias)
+		if m.options.MaxAge != "" {
+			service.AddMaxIndexAgeCondition(m.options.MaxAge)
+		}
+		if m.options.MaxSize != "" {
+			service.AddCondition("max_size", m.options.MaxSize)
+		}
+		if m.options.MaxDocs > 0 {
+			service.AddMaxIndexDocsCondition(m.options.MaxDocs)
+		}
+		res, err := service.Do(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to roll over alias %s", writeAlias)
+		}
+		if !res.RolledOver {
+			m.logger.Debug("Rollover conditions not met", zap.String("alias", writeAlias))
+			continue
+		}
+		m.logger.Info("Rolled over to new index", zap.String("alias", writeAlias), zap.String("index", res.NewIndex))
+		if _, err := m.client.Alias().Add(res.NewIndex, readAlias).Do(ctx); err != nil {
+			return errors.Wrapf(err, "failed to add index %s to alias %s", res.NewIndex, readAlias)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) hasRolloverConditions() bool {
+	return m.options.MaxAge != "" || m.options.MaxSize != "" || m.options.MaxDocs > 0
+}
+
+// Lookback removes indices created before now minus the lookback from the read aliases,
+// mimicking --es.max-span-age for rolled over indices.
+func (m *Manager) Lookback(ctx context.Context, now time.Time) error {
+	if m.options.Lookback <= 0 {
+		return errors.New("lookback must be positive")
+	}
+	cutoff := now.Add(-m.options.Lookback)
+	for _, set := range m.indexSets() {
+		writeAlias, readAlias := set.name+writeAliasSuffix, set.name+readAliasSuffix
+		indices, err := m.getIndices(ctx, set.name+"-*")
+		if err != nil {
+			return err
+		}
+		indices = filterByAlias(filterByAlias(indices, readAlias, false), writeAlias, true)
+		indices = filterCreatedBefore(indices, cutoff)
+		if len(indices) == 0 {
+			m.logger.Debug("No indices to remove from alias", zap.String("alias", readAlias))
+			continue
+		}
+		service := m.client.Alias()
+		for _, index := range indices {
+			m.logger.Info("Removing index from alias", zap.String("index", index.name), zap.String("alias", readAlias))
+			service.Remove(index.name, readAlias)
+		}
+		if _, err := service.Do(ctx); err != nil {
+			return errors.Wrapf(err, "failed to remove indices from alias %s", readAlias)
+		}
+	}
+	return nil
+}
+
+// Cleanup deletes indices older than the retention. Date based span, service and dependency indices
+// are aged by the end of the period in their name, so that the current weekly index is kept even
+// with a shorter retention, rolled over indices by their creation date. Indices behind
+// a write alias are never deleted.
+func (m *Manager) Cleanup(ctx context.Context, now time.Time) error {
+	if m.options.RetentionDays <= 0 {
+		return errors.New("retention days must be positive")
+	}
+	cutoff := now.Add(-time.Duration(m.options.RetentionDays) * 24 * time.Hour)
+	prefix := regexp.QuoteMeta(m.prefix)
+	indices, err := m.getIndices(ctx, m.prefix+"jaeger-*")
+	if err != nil {
+		return err
+	}
+
+	var toDelete []string
+	if m.options.Archive {
+		rolledOver := filterByName(indices, regexp.MustCompile("^"+prefix+archiveIndex+`-\d{6}$`))
+		rolledOver = filterByAlias(rolledOver, m.prefix+archiveIndex+writeAliasSuffix, true)
+		toDelete = names(filterCreatedBefore(rolledOver, cutoff))
+	} else {
+		rolledOverName := regexp.MustCompile("^" + prefix + `jaeger-(?:span|service)-\d{6}$`)
+		dated := regexp.MustCompile("^" + prefix + `jaeger-(?:span|service|dependencies)-(.+)$`)
+		for _, index := range indices {
+			match := dated.FindStringSubmatch(index.name)
+			if match == nil || rolledOverName.MatchString(index.name) {
+				continue
+			}
+			date, err := m.dateLayout.ParseDate(match[1])
+			if err == nil && !m.dateLayout.PeriodEnd(date).After(cutoff) {
+				toDelete = append(toDelete, index.name)
+			}
+		}
+		rolledOver := filterByName(indices, rolledOverName)
+		for _, set := range m.indexSets() {
+			rolledOver = filterByAlias(rolledOver, set.name+writeAliasSuffix, true)
+		}
+		toDelete = append(toDelete, names(filterCreatedBefore(rolledOver, cutoff))...)
+	}
+	if len(toDelete) == 0 {
+		m.logger.Debug("No indices to delete")
+		return nil
+	}
+	sort.Strings(toDelete)
+	m.logger.Info("Deleting indices", zap.Strings("indices", toDelete))
+	if _, err := m.client.DeleteIndex(toDelete...).Do(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete indices")
+	}
+	return nil
+}
+
+// Maintain performs the rollover, the lookback and the cleanup steps that are enabled.
+// All steps are attempted and the first error is returned.
+func (m *Manager) Maintain(ctx context.Context, now time.Time) error {
+	var steps []func() error
+	if m.rollover {
+		if m.hasRolloverConditions() {
+			steps = append(steps, func() error { return m.Rollover(ctx) })
+		}
+		if m.options.Lookback > 0 {
+			steps = append(steps, func() error { return m.Lookback(ctx, now) })
+		}
+	}
+	if m.options.RetentionDays > 0 {
+		steps = append(steps, func() error { return m.Cleanup(ctx, now) })
+	}
+	var firstErr error
+	for _, step := range steps {
+		if err := step(); err != nil {
+			m.logger.Error("Index maintenance failed", zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Start runs Maintain right away and then every interval in the background until Close is called.
+func (m *Manager) Start(interval time.Duration) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.Maintain(context.Background(), time.Now())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Maintain(context.Background(), time.Now())
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the background job started by Start.
+func (m *Manager) Close() error {
+	close(m.stop)
+	m.wg.Wait()
+	return nil
+}
+
+func (m *Manager) getIndices(ctx context.Context, pattern string) ([]indexInfo, error) {
+	res, err := m.client.IndexGet(pattern).Do(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get indices %s", pattern)
+	}
+	indices := make([]indexInfo, 0, len(res))
+	for name, index := range res {
+		info := indexInfo{name: name, aliases: make(map[string]bool, len(index.Aliases))}
+		for alias := range index.Aliases {
+			info.aliases[alias] = true
+		}
+		created, err := creationDate(index.Settings)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read creation date of index %s", name)
+		}
+		info.created = created
+		indices = append(indices, info)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i].name < indices[j].name })
+	return indices, nil
+}
+
+func creationDate(settings map[string]interface{}) (time.Time, error) {
+	index, _ := settings["index"].(map[string]interface{})
+	value, ok := index["creation_date"].(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected settings %v", settings)
+	}
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, millis*int64(time.Millisecond)), nil
+}
+
+func filterByAlias(indices []indexInfo, alias string, exclude bool) []indexInfo {
+	var result []indexInfo
+	for _, index := range indices {
+		if index.aliases[alias] != exclude {
+			result = append(result, index)
+		}
+	}
+	return result
+}
+
+func filterByName(indices []indexInfo, re *regexp.Regexp) []indexInfo {
+	var result []indexInfo
+	for _, index := range indices {
+		if re.MatchString(index.name) {
+			result = append(result, index)
+		}
+	}
+	return result
+}
+
+func filterCreatedBefore(indices []indexInfo, cutoff time.Time) []indexInfo {
+	var result []indexInfo
+	for _, index := range indices {
+		if index.created.Before(cutoff) {
+			result = append(result, index)
+		}
+	}
+	return result
+}
+
+func names(indices []indexInfo) []string {
+	result := make([]string, 0, len(indices))
+	for _, index := range indices {
+		result = append(result, index.name)
+	}
+	return result
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
+)
+
+const (
+	spanMapping    = `{"index_patterns": "*jaeger-span-*"}`
+	serviceMapping = `{"index_patterns": "*jaeger-service-*"}`
+)
+
+func newTestManager(t *testing.T, prefix string, options Options) (*fakeES, *Manager) {
+	es, client := newFakeES(t)
+	return es, NewManager(Params{
+		Client:         client,
+		Logger:         zap.NewNop(),
+		IndexPrefix:    prefix,
+		SpanMapping:    spanMapping,
+		ServiceMapping: serviceMapping,
+		Rollover:       true,
+		Options:        options,
+	})
+}
+
+func TestInit(t *testing.T) {
+	es, m := newTestManager(t, "foo", Options{})
+	defer es.server.Close()
+
+	require.NoError(t, m.Init(context.Background()))
+	assert.Equal(t, map[string]string{"jaeger-span": "*jaeger-span-*", "jaeger-service": "*jaeger-service-*"}, es.templates)
+	assert.Equal(t, []string{"foo-jaeger-service-000001", "foo-jaeger-span-000001"}, es.indexNames())
+	for _, alias := range []string{"foo-jaeger-span-read", "foo-jaeger-span-write"} {
+		assert.Equal(t, []string{"foo-jaeger-span-000001"}, es.aliasIndices(alias))
+	}
+	for _, alias := range []string{"foo-jaeger-service-read", "foo-jaeger-service-write"} {
+		assert.Equal(t, []string{"foo-jaeger-service-000001"}, es.aliasIndices(alias))
+	}
+
+	// aliases already pointing to other indices are left untouched
+	es.addIndex("foo-jaeger-span-000002", time.Now(), "foo-jaeger-span-read")
+	es.Lock()
+	delete(es.indices["foo-jaeger-span-000001"].aliases, "foo-jaeger-span-read")
+	es.Unlock()
+	require.NoError(t, m.Init(context.Background()))
+	assert.Equal(t, []string{"foo-jaeger-span-000002"}, es.aliasIndices("foo-jaeger-span-read"))
+	assert.Equal(t, []string{"foo-jaeger-span-000001"}, es.aliasIndices("foo-jaeger-span-write"))
+}
+
+func TestInitArchive(t *testing.T) {
+	es, m := newTestManager(t, "", Options{Archive: true})
+	defer es.server.Close()
+
+	require.NoError(t, m.Init(context.Background()))
+	assert.Equal(t, map[string]string{"jaeger-span": "*jaeger-span-*"}, es.templates)
+	assert.Equal(t, []string{"jaeger-span-archive-000001"}, es.indexNames())
+	assert.Equal(t, []string{"jaeger-span-archive-000001"}, es.aliasIndices("jaeger-span-archive-read"))
+	assert.Equal(t, []string{"jaeger-span-archive-000001"}, es.aliasIndices("jaeger-span-archive-write"))
+}
+
+func TestRollover(t *testing.T) {
+	es, m := newTestManager(t, "", Options{MaxAge: "1d", MaxSize: "10gb", MaxDocs: 100})
+	defer es.server.Close()
+	require.NoError(t, m.Init(context.Background()))
+
+	require.NoError(t, m.Rollover(context.Background()))
+	assert.Equal(t, map[string]interface{}{"max_age": "1d", "max_size": "10gb", "max_docs": float64(100)}, es.conditions)
+	assert.Equal(t, []string{"jaeger-span-000002"}, es.aliasIndices("jaeger-span-write"))
+	assert.Equal(t, []string{"jaeger-span-000001", "jaeger-span-000002"}, es.aliasIndices("jaeger-span-read"))
+	assert.Equal(t, []string{"jaeger-service-000002"}, es.aliasIndices("jaeger-service-write"))
+	assert.Equal(t, []string{"jaeger-service-000001", "jaeger-service-000002"}, es.aliasIndices("jaeger-service-read"))
+
+	es.rollover = false
+	m.options = Options{MaxDocs: 5}
+	require.NoError(t, m.Rollover(context.Background()))
+	assert.Equal(t, map[string]interface{}{"max_docs": float64(5)}, es.conditions)
+	assert.Equal(t, []string{"jaeger-span-000002"}, es.aliasIndices("jaeger-span-write"))
+	assert.Len(t, es.indexNames(), 4)
+}
+
+func TestRolloverWithoutAlias(t *testing.T) {
+	es, m := newTestManager(t, "", Options{MaxAge: "1d"})
+	defer es.server.Close()
+
+	err := m.Rollover(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to roll over alias jaeger-span-write")
+}
+
+func TestRolloverWithoutConditions(t *testing.T) {
+	es, m := newTestManager(t, "", Options{Lookback: time.Hour})
+	defer es.server.Close()
+	require.NoError(t, m.Init(context.Background()))
+
+	assert.EqualError(t, m.Rollover(context.Background()), "at least one rollover condition must be set")
+	// the rollover step is skipped by Maintain
+	require.NoError(t, m.Maintain(context.Background(), time.Now()))
+	assert.Equal(t, []string{"jaeger-service-000001", "jaeger-span-000001"}, es.indexNames())
+}
+
+func TestLookback(t *testing.T) {
+	now := time.Now()
+	es, m := newTestManager(t, "", Options{Lookback: 48 * time.Hour})
+	defer es.server.Close()
+	es.addIndex("jaeger-span-000001", now.Add(-72*time.Hour), "jaeger-span-read")
+	es.addIndex("jaeger-span-000002", now.Add(-24*time.Hour), "jaeger-span-read")
+	es.addIndex("jaeger-span-000003", now.Add(-96*time.Hour), "jaeger-span-read", "jaeger-span-write")
+	es.addIndex("jaeger-service-000001", now.Add(-24*time.Hour), "jaeger-service-read", "jaeger-service-write")
+
+	require.NoError(t, m.Lookback(context.Background(), now))
+	assert.Equal(t, []string{"jaeger-span-000002", "jaeger-span-000003"}, es.aliasIndices("jaeger-span-read"))
+	assert.Equal(t, []string{"jaeger-service-000001"}, es.aliasIndices("jaeger-service-read"))
+	assert.Len(t, es.indexNames(), 4)
+
+	m.options.Lookback = 0
+	assert.EqualError(t, m.Lookback(context.Background(), now), "lookback must be positive")
+}
+
+func TestCleanup(t *testing.T) {
+	now := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+	es, m := newTestManager(t, "foo", Options{RetentionDays: 3})
+	defer es.server.Close()
+	old, recent := now.Add(-5*24*time.Hour), now.Add(-time.Hour)
+	for _, index := range []string{
+		"foo-jaeger-span-2019-06-01", "foo-jaeger-service-2019-06-01", "foo-jaeger-dependencies-2019-06-01",
+		"foo-jaeger-span-2019-06-09", "foo-jaeger-service-2019-06-09", "foo-jaeger-dependencies-2019-06-09",
+		"foo-jaeger-span-archive", "jaeger-span-2019-06-01", "foo-jaeger-span-archive-000001",
+	} {
+		es.addIndex(index, recent)
+	}
+	es.addIndex("foo-jaeger-span-000001", old, "foo-jaeger-span-read")
+	es.addIndex("foo-jaeger-span-000002", old, "foo-jaeger-span-read", "foo-jaeger-span-write")
+	es.addIndex("foo-jaeger-service-000001", old, "foo-jaeger-service-read", "foo-jaeger-service-write")
+	es.addIndex("foo-jaeger-service-000002", recent)
+
+	require.NoError(t, m.Cleanup(context.Background(), now))
+	assert.Equal(t, []string{
+		"foo-jaeger-dependencies-2019-06-09",
+		"foo-jaeger-service-000001",
+		"foo-jaeger-service-000002",
+		"foo-jaeger-service-2019-06-09",
+		"foo-jaeger-span-000002",
+		"foo-jaeger-span-2019-06-09",
+		"foo-jaeger-span-archive",
+		"foo-jaeger-span-archive-000001",
+		"jaeger-span-2019-06-01",
+	}, es.indexNames())
+
+	// nothing left to delete
+	require.NoError(t, m.Cleanup(context.Background(), now))
+
+	m.options.RetentionDays = 0
+	assert.EqualError(t, m.Cleanup(context.Background(), now), "retention days must be positive")
+}
+
+func TestCleanupHourly(t *testing.T) {
+	now := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+	es, m := newTestManager(t, "", Options{RetentionDays: 1})
+	defer es.server.Close()
+	layout, err := indexdate.NewLayout(indexdate.Hourly, "")
+	require.NoError(t, err)
+	m.dateLayout = layout
+	for _, index := range []string{"jaeger-span-2019-06-09-11", "jaeger-span-2019-06-09-12", "jaeger-dependencies-2019-06-09-13"} {
+		es.addIndex(index, now)
+	}
+	es.addIndex("jaeger-span-000001", now, "jaeger-span-write")
+
+	require.NoError(t, m.Cleanup(context.Background(), now))
+	assert.Equal(t, []string{"jaeger-dependencies-2019-06-09-13", "jaeger-span-000001", "jaeger-span-2019-06-09-12"}, es.indexNames())
+}
+
+func TestCleanupWeekly(t *testing.T) {
+	// Wednesday
+	now := time.Date(2019, 6, 12, 12, 0, 0, 0, time.UTC)
+	es, m := newTestManager(t, "", Options{RetentionDays: 3})
+	defer es.server.Close()
+	layout, err := indexdate.NewLayout(indexdate.Weekly, "")
+	require.NoError(t, err)
+	m.dateLayout = layout
+	for _, index := range []string{"jaeger-span-2019-05-27", "jaeger-span-2019-06-03", "jaeger-span-2019-06-10"} {
+		es.addIndex(index, now)
+	}
+
+	require.NoError(t, m.Cleanup(context.Background(), now))
+	// the week of June 3rd ends after the cutoff of June 9th noon, the current week is kept
+	assert.Equal(t, []string{"jaeger-span-2019-06-03", "jaeger-span-2019-06-10"}, es.indexNames())
+}
+
+func TestCleanupArchive(t *testing.T) {
+	now := time.Now()
+	es, m := newTestManager(t, "", Options{Archive: true, RetentionDays: 1})
+	defer es.server.Close()
+	old := now.Add(-48 * time.Hour)
+	es.addIndex("jaeger-span-archive", old)
+	es.addIndex("jaeger-span-2000-01-01", old)
+	es.addIndex("jaeger-span-archive-000001", old, "jaeger-span-archive-read")
+	es.addIndex("jaeger-span-archive-000002", old, "jaeger-span-archive-read", "jaeger-span-archive-write")
+
+	require.NoError(t, m.Cleanup(context.Background(), now))
+	assert.Equal(t, []string{"jaeger-span-2000-01-01", "jaeger-span-archive", "jaeger-span-archive-000002"}, es.indexNames())
+}
+
+func TestMaintain(t *testing.T) {
+	now := time.Now()
+	es, m := newTestManager(t, "", Options{MaxAge: "1d", Lookback: time.Hour, RetentionDays: 1})
+	defer es.server.Close()
+	es.now = now
+	es.addIndex("jaeger-span-000001", now.Add(-2*time.Hour), "jaeger-span-read", "jaeger-span-write")
+	es.addIndex("jaeger-service-000001", now.Add(-2*time.Hour), "jaeger-service-read", "jaeger-service-write")
+	es.addIndex("jaeger-dependencies-2000-01-01", now)
+
+	require.NoError(t, m.Maintain(context.Background(), now))
+	assert.Equal(t, []string{"jaeger-service-000001", "jaeger-service-000002", "jaeger-span-000001", "jaeger-span-000002"}, es.indexNames())
+	assert.Equal(t, []string{"jaeger-span-000002"}, es.aliasIndices("jaeger-span-read"))
+	assert.Equal(t, []string{"jaeger-service-000002"}, es.aliasIndices("jaeger-service-read"))
+
+	// only the cleanup runs when aliases are not used
+	m.rollover = false
+	es.addIndex("jaeger-span-2000-01-01", now)
+	require.NoError(t, m.Maintain(context.Background(), now))
+	assert.Len(t, es.indexNames(), 4)
+
+	es.server.Close()
+	err := m.Maintain(context.Background(), now)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get indices jaeger-*")
+}
+
+func TestStartClose(t *testing.T) {
+	es, m := newTestManager(t, "", Options{RetentionDays: 1})
+	defer es.server.Close()
+	es.addIndex("jaeger-span-2000-01-01", time.Now())
+
+	// the first maintenance doesn't wait for the interval
+	m.Start(time.Hour)
+	for i := 0; i < 1000 && len(es.indexNames()) > 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Empty(t, es.indexNames())
+	assert.NoError(t, m.Close())
+}
+
+func TestErrors(t *testing.T) {
+	es, m := newTestManager(t, "", Options{Lookback: time.Hour, RetentionDays: 1})
+	es.server.Close()
+
+	err := m.Init(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create index template jaeger-span")
+	err = m.Lookback(context.Background(), time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get indices jaeger-span-*")
+}
+
+func TestCreationDate(t *testing.T) {
+	created, err := creationDate(map[string]interface{}{"index": map[string]interface{}{"creation_date": "1560000000000"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1560000000), created.Unix())
+
+	_, err = creationDate(map[string]interface{}{})
+	assert.EqualError(t, err, "unexpected settings map[]")
+	_, err = creationDate(map[string]interface{}{"index": map[string]interface{}{"creation_date": "x"}})
+	assert.Error(t, err)
+}
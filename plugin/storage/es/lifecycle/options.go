@@ -0,0 +1,101 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"flag"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	lifecyclePrefix = "es.lifecycle"
+	archive         = lifecyclePrefix + ".archive"
+	maxAge          = lifecyclePrefix + ".rollover.max-age"
+	maxSize         = lifecyclePrefix + ".rollover.max-size"
+	maxDocs         = lifecyclePrefix + ".rollover.max-docs"
+	lookback        = lifecyclePrefix + ".lookback"
+	retentionDays   = lifecyclePrefix + ".retention-days"
+	interval        = lifecyclePrefix + ".interval"
+
+	defaultMaxAge = "7d"
+)
+
+// Options describes how Elasticsearch indices are rolled over and cleaned up.
+type Options struct {
+	// Archive selects the archive indices instead of the span and service indices.
+	Archive bool
+	// MaxAge, MaxSize and MaxDocs are the rollover conditions, empty or zero values are not sent.
+	// Indices are only rolled over if at least one of them is set.
+	MaxAge  string
+	MaxSize string
+	MaxDocs int64
+	// Lookback is the age after which rolled over indices are removed from the read alias.
+	Lookback time.Duration
+	// RetentionDays is the number of days after which indices are deleted.
+	RetentionDays int
+	// Interval is how often the background job performs index maintenance, zero disables the job.
+	// The job takes no lock, it must be enabled on a single collector only.
+	Interval time.Duration
+}
+
+// AddFlags adds flags for index maintenance.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(
+		archive,
+		false,
+		"Whether to manage the archive indices instead of the span and service indices")
+	flagSet.String(
+		maxAge,
+		defaultMaxAge,
+		"Roll over to a new write index once the current one is older than this Elasticsearch time unit, e.g. 12h or 7d; empty disables the condition")
+	flagSet.String(
+		maxSize,
+		"",
+		"Roll over to a new write index once the current one is larger than this Elasticsearch byte size, e.g. 50gb; empty disables the condition")
+	flagSet.Int64(
+		maxDocs,
+		0,
+		"Roll over to a new write index once the current one holds more documents; 0 disables the condition")
+	flagSet.Duration(
+		lookback,
+		0,
+		"Remove rolled over indices older than this from the read alias; 0 disables the lookback")
+	flagSet.Int(
+		retentionDays,
+		0,
+		"Delete span, service and dependency indices older than this number of days; 0 disables the cleanup")
+}
+
+// AddJobFlags adds flags for index maintenance performed in the background.
+func AddJobFlags(flagSet *flag.FlagSet) {
+	AddFlags(flagSet)
+	flagSet.Duration(
+		interval,
+		0,
+		"How often to roll over and clean up indices in the background; 0 disables the background job. The job does not coordinate with other collectors, enable it on a single collector only")
+}
+
+// InitFromViper initializes Options with properties from viper.
+func (o *Options) InitFromViper(v *viper.Viper) {
+	o.Archive = v.GetBool(archive)
+	o.MaxAge = v.GetString(maxAge)
+	o.MaxSize = v.GetString(maxSize)
+	o.MaxDocs = v.GetInt64(maxDocs)
+	o.Lookback = v.GetDuration(lookback)
+	o.RetentionDays = v.GetInt(retentionDays)
+	o.Interval = v.GetDuration(interval)
+}
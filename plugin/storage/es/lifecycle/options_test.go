@@ -0,0 +1,56 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsDefaults(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	var opts Options
+	opts.InitFromViper(v)
+	assert.Equal(t, Options{MaxAge: "7d"}, opts)
+}
+
+func TestOptionsWithFlags(t *testing.T) {
+	v, command := config.Viperize(AddJobFlags)
+	command.ParseFlags([]string{
+		"--es.lifecycle.archive=true",
+		"--es.lifecycle.rollover.max-age=1d",
+		"--es.lifecycle.rollover.max-size=5gb",
+		"--es.lifecycle.rollover.max-docs=1000",
+		"--es.lifecycle.lookback=48h",
+		"--es.lifecycle.retention-days=7",
+		"--es.lifecycle.interval=1h",
+	})
+	var opts Options
+	opts.InitFromViper(v)
+	assert.Equal(t, Options{
+		Archive:       true,
+		MaxAge:        "1d",
+		MaxSize:       "5gb",
+		MaxDocs:       1000,
+		Lookback:      48 * time.Hour,
+		RetentionDays: 7,
+		Interval:      time.Hour,
+	}, opts)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/jaegertracing/jaeger/pkg/es/config"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -40,6 +41,12 @@ const (
 	suffixBulkWorkers         = ".bulk.workers"
 	suffixBulkActions         = ".bulk.actions"
 	suffixBulkFlushInterval   = ".bulk.flush-interval"
+	suffixBulkMaxRetries      = ".bulk.max-retries"
+	suffixBulkRetryBackoff    = ".bulk.retry-backoff"
+	suffixBulkRetryMaxBackoff = ".bulk.retry-max-backoff"
+	suffixDeadLetterFile      = ".bulk.dead-letter.file"
+	suffixDeadLetterTopic     = ".bulk.dead-letter.kafka.topic"
+	suffixDeadLetterBrokers   = ".bulk.dead-letter.kafka.brokers"
 	suffixTimeout             = ".timeout"
 	suffixTLS                 = ".tls"
 	suffixCert                = ".tls.cert"
@@ -47,6 +54,11 @@ const (
 	suffixCA                  = ".tls.ca"
 	suffixSkipHostVerify      = ".tls.skip-host-verify"
 	suffixIndexPrefix         = ".index-prefix"
+	suffixIndexDateLayout     = ".index-date-layout"
+	suffixServiceCacheTTL     = ".service-cache.ttl"
+	suffixServiceCacheSize    = ".service-cache.size"
+	suffixServiceCachePrewarm = ".service-cache.prewarm"
+	suffixIndexFrequency      = ".index-rollover-frequency"
 	suffixTagsAsFields        = ".tags-as-fields"
 	suffixTagsAsFieldsAll     = suffixTagsAsFields + ".all"
 	suffixTagsFile            = suffixTagsAsFields + ".config-file"
@@ -93,7 +105,13 @@ func NewOptions(primaryNamespace string, otherNamespaces ...string) *Options {
 				BulkWorkers:          1,
 				BulkActions:          1000,
 				BulkFlushInterval:    time.Millisecond * 200,
+				BulkMaxRetries:       3,
+				BulkRetryBackoff:     100 * time.Millisecond,
+				BulkRetryMaxBackoff:  10 * time.Second,
 				TagDotReplacement:    "@",
+				IndexFrequency:       indexdate.Daily,
+				ServiceCacheTTL:      12 * time.Hour,
+				ServiceCacheSize:     100000,
 				Enabled:              true,
 				CreateIndexTemplates: true,
 			},
@@ -175,6 +193,30 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
 		nsConfig.namespace+suffixBulkFlushInterval,
 		nsConfig.BulkFlushInterval,
 		"A time.Duration after which bulk requests are committed, regardless of other thresholds. Set to zero to disable. By default, this is disabled.")
+	flagSet.Int(
+		nsConfig.namespace+suffixBulkMaxRetries,
+		nsConfig.BulkMaxRetries,
+		"The number of times documents rejected with a transient status such as 429 are retried before they are sent to the dead-letter sink.")
+	flagSet.Duration(
+		nsConfig.namespace+suffixBulkRetryBackoff,
+		nsConfig.BulkRetryBackoff,
+		"The initial delay before retrying rejected documents, doubled on every retry.")
+	flagSet.Duration(
+		nsConfig.namespace+suffixBulkRetryMaxBackoff,
+		nsConfig.BulkRetryMaxBackoff,
+		"The maximum delay before retrying rejected documents.")
+	flagSet.String(
+		nsConfig.namespace+suffixDeadLetterFile,
+		nsConfig.DeadLetterFile,
+		"Optional path to a file where permanently rejected documents are appended as JSON lines.")
+	flagSet.String(
+		nsConfig.namespace+suffixDeadLetterTopic,
+		nsConfig.DeadLetterKafkaTopic,
+		"Optional Kafka topic where permanently rejected documents are published. Cannot be combined with "+nsConfig.namespace+suffixDeadLetterFile+".")
+	flagSet.String(
+		nsConfig.namespace+suffixDeadLetterBrokers,
+		"127.0.0.1:9092",
+		"The comma-separated list of Kafka brokers for "+nsConfig.namespace+suffixDeadLetterTopic+".")
 	flagSet.Bool(
 		nsConfig.namespace+suffixTLS,
 		nsConfig.TLS.Enabled,
@@ -199,6 +241,28 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
 		nsConfig.namespace+suffixIndexPrefix,
 		nsConfig.IndexPrefix,
 		"Optional prefix of Jaeger indices. For example \"production\" creates \"production-jaeger-*\".")
+	flagSet.String(
+		nsConfig.namespace+suffixIndexFrequency,
+		nsConfig.IndexFrequency,
+		"How often a new span, service and dependency index is created, one of "+indexdate.Hourly+", "+indexdate.Daily+" or "+indexdate.Weekly+". "+
+			"Weekly indices start on Monday. It is not used with "+nsConfig.namespace+suffixReadAlias+".")
+	flagSet.String(
+		nsConfig.namespace+suffixIndexDateLayout,
+		nsConfig.IndexDateLayout,
+		"Optional Go time layout of the date in index names, e.g. 2006.01.02. Defaults to 2006-01-02-15 for hourly and 2006-01-02 for daily and weekly indices.")
+	flagSet.Duration(
+		nsConfig.namespace+suffixServiceCacheTTL,
+		nsConfig.ServiceCacheTTL,
+		"How long a service and operation name pair is cached by the span writer before it is written to the service index again.")
+	flagSet.Int(
+		nsConfig.namespace+suffixServiceCacheSize,
+		nsConfig.ServiceCacheSize,
+		"The maximum number of service and operation name pairs cached by the span writer.")
+	flagSet.Bool(
+		nsConfig.namespace+suffixServiceCachePrewarm,
+		nsConfig.ServiceCachePrewarm,
+		"Load the service and operation name pairs from the current service index into the cache on startup, "+
+			"so that they are not written again after a restart.")
 	flagSet.Bool(
 		nsConfig.namespace+suffixTagsAsFieldsAll,
 		nsConfig.AllTagsAsFields,
@@ -251,6 +315,12 @@ func initFromViper(cfg *namespaceConfig, v *viper.Viper) {
 	cfg.BulkWorkers = v.GetInt(cfg.namespace + suffixBulkWorkers)
 	cfg.BulkActions = v.GetInt(cfg.namespace + suffixBulkActions)
 	cfg.BulkFlushInterval = v.GetDuration(cfg.namespace + suffixBulkFlushInterval)
+	cfg.BulkMaxRetries = v.GetInt(cfg.namespace + suffixBulkMaxRetries)
+	cfg.BulkRetryBackoff = v.GetDuration(cfg.namespace + suffixBulkRetryBackoff)
+	cfg.BulkRetryMaxBackoff = v.GetDuration(cfg.namespace + suffixBulkRetryMaxBackoff)
+	cfg.DeadLetterFile = v.GetString(cfg.namespace + suffixDeadLetterFile)
+	cfg.DeadLetterKafkaTopic = v.GetString(cfg.namespace + suffixDeadLetterTopic)
+	cfg.DeadLetterKafkaBrokers = strings.Split(stripWhiteSpace(v.GetString(cfg.namespace+suffixDeadLetterBrokers)), ",")
 	cfg.Timeout = v.GetDuration(cfg.namespace + suffixTimeout)
 	cfg.TLS.Enabled = v.GetBool(cfg.namespace + suffixTLS)
 	cfg.TLS.SkipHostVerify = v.GetBool(cfg.namespace + suffixSkipHostVerify)
@@ -258,6 +328,11 @@ func initFromViper(cfg *namespaceConfig, v *viper.Viper) {
 	cfg.TLS.KeyPath = v.GetString(cfg.namespace + suffixKey)
 	cfg.TLS.CaPath = v.GetString(cfg.namespace + suffixCA)
 	cfg.IndexPrefix = v.GetString(cfg.namespace + suffixIndexPrefix)
+	cfg.IndexDateLayout = v.GetString(cfg.namespace + suffixIndexDateLayout)
+	cfg.IndexFrequency = v.GetString(cfg.namespace + suffixIndexFrequency)
+	cfg.ServiceCacheTTL = v.GetDuration(cfg.namespace + suffixServiceCacheTTL)
+	cfg.ServiceCacheSize = v.GetInt(cfg.namespace + suffixServiceCacheSize)
+	cfg.ServiceCachePrewarm = v.GetBool(cfg.namespace + suffixServiceCachePrewarm)
 	cfg.AllTagsAsFields = v.GetBool(cfg.namespace + suffixTagsAsFieldsAll)
 	cfg.TagsFilePath = v.GetString(cfg.namespace + suffixTagsFile)
 	cfg.TagDotReplacement = v.GetString(cfg.namespace + suffixTagDeDotChar)
@@ -34,6 +34,16 @@ func TestOptions(t *testing.T) {
 	assert.Equal(t, int64(1), primary.NumReplicas)
 	assert.Equal(t, 72*time.Hour, primary.MaxSpanAge)
 	assert.False(t, primary.Sniffer)
+	assert.Equal(t, "day", primary.IndexFrequency)
+	assert.Empty(t, primary.IndexDateLayout)
+	assert.Equal(t, 3, primary.BulkMaxRetries)
+	assert.Equal(t, 100*time.Millisecond, primary.BulkRetryBackoff)
+	assert.Equal(t, 10*time.Second, primary.BulkRetryMaxBackoff)
+	assert.Empty(t, primary.DeadLetterFile)
+	assert.Empty(t, primary.DeadLetterKafkaTopic)
+	assert.Equal(t, 12*time.Hour, primary.ServiceCacheTTL)
+	assert.Equal(t, 100000, primary.ServiceCacheSize)
+	assert.False(t, primary.ServiceCachePrewarm)
 
 	aux := opts.Get("archive")
 	assert.Equal(t, primary.Username, aux.Username)
@@ -59,6 +69,16 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--es.aux.num-replicas=10",
 		"--es.tls=true",
 		"--es.tls.skip-host-verify=true",
+		"--es.index-rollover-frequency=hour",
+		"--es.index-date-layout=2006.01.02.15",
+		"--es.bulk.max-retries=5",
+		"--es.bulk.retry-backoff=1s",
+		"--es.bulk.dead-letter.kafka.topic=rejected-spans",
+		"--es.bulk.dead-letter.kafka.brokers=1.1.1.1:9092, 2.2.2.2:9092",
+		"--es.aux.bulk.dead-letter.file=/tmp/rejected.json",
+		"--es.service-cache.ttl=1h",
+		"--es.service-cache.size=500",
+		"--es.service-cache.prewarm=true",
 	})
 	opts.InitFromViper(v)
 
@@ -70,6 +90,15 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.True(t, primary.Sniffer)
 	assert.Equal(t, true, primary.TLS.Enabled)
 	assert.Equal(t, true, primary.TLS.SkipHostVerify)
+	assert.Equal(t, "hour", primary.IndexFrequency)
+	assert.Equal(t, "2006.01.02.15", primary.IndexDateLayout)
+	assert.Equal(t, 5, primary.BulkMaxRetries)
+	assert.Equal(t, time.Second, primary.BulkRetryBackoff)
+	assert.Equal(t, "rejected-spans", primary.DeadLetterKafkaTopic)
+	assert.Equal(t, []string{"1.1.1.1:9092", "2.2.2.2:9092"}, primary.DeadLetterKafkaBrokers)
+	assert.Equal(t, time.Hour, primary.ServiceCacheTTL)
+	assert.Equal(t, 500, primary.ServiceCacheSize)
+	assert.True(t, primary.ServiceCachePrewarm)
 
 	aux := opts.Get("es.aux")
 	assert.Equal(t, []string{"3.3.3.3", "4.4.4.4"}, aux.Servers)
@@ -79,5 +108,9 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, int64(10), aux.NumReplicas)
 	assert.Equal(t, 24*time.Hour, aux.MaxSpanAge)
 	assert.True(t, aux.Sniffer)
+	assert.Equal(t, "hour", aux.IndexFrequency)
+	assert.Equal(t, 5, aux.BulkMaxRetries)
+	assert.Equal(t, "/tmp/rejected.json", aux.DeadLetterFile)
+	assert.Empty(t, aux.DeadLetterKafkaTopic)
 
 }
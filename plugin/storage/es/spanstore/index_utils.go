@@ -14,16 +14,6 @@
 
 package spanstore
 
-import (
-	"time"
-)
-
-// returns index name with date
-func indexWithDate(indexPrefix string, date time.Time) string {
-	spanDate := date.UTC().Format("2006-01-02")
-	return indexPrefix + spanDate
-}
-
 // returns archive index name
 func archiveIndex(indexPrefix, archiveSuffix string) string {
 	return indexPrefix + archiveSuffix
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/olivere/elastic"
@@ -32,6 +33,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/es"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/spanstore/dbmodel"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
@@ -60,6 +62,12 @@ const (
 
 	defaultDocCount  = 10000 // the default elasticsearch allowed limit
 	defaultNumTraces = 100
+
+	// numericTagScript compares the values of params.field which parse as numbers, %s is the operator
+	numericTagScript = "if (!doc.containsKey(params.field)) { return false; } " +
+		"for (def v : doc[params.field]) { " +
+		"try { if (Double.parseDouble(v) %s params.value) { return true; } } catch (NumberFormatException e) {} " +
+		"} return false;"
 )
 
 var (
@@ -93,8 +101,8 @@ type SpanReader struct {
 	ctx    context.Context
 	client es.Client
 	logger *zap.Logger
-	// The age of the oldest service/operation we will look for. Because indices in ElasticSearch are by day,
-	// this will be rounded down to UTC 00:00 of that day.
+	// The age of the oldest service/operation we will look for. Because indices in ElasticSearch are by
+	// hour, day or week, this will be rounded down to the start of that period in UTC.
 	maxSpanAge              time.Duration
 	serviceOperationStorage *ServiceOperationStorage
 	spanIndexPrefix         string
@@ -112,6 +120,7 @@ type SpanReaderParams struct {
 	MaxNumSpans         int
 	MetricsFactory      metrics.Factory
 	IndexPrefix         string
+	IndexDateLayout     indexdate.Layout
 	TagDotReplacement   string
 	Archive             bool
 	UseReadWriteAliases bool
@@ -125,11 +134,11 @@ func NewSpanReader(p SpanReaderParams) *SpanReader {
 		client:                  p.Client,
 		logger:                  p.Logger,
 		maxSpanAge:              p.MaxSpanAge,
-		serviceOperationStorage: NewServiceOperationStorage(p.Client, p.Logger, 0), // the decorator takes care of metrics
+		serviceOperationStorage: NewServiceOperationStorage(p.Client, p.Logger, 0, 0), // the decorator takes care of metrics
 		spanIndexPrefix:         indexNames(p.IndexPrefix, spanIndex),
 		serviceIndexPrefix:      indexNames(p.IndexPrefix, serviceIndex),
 		spanConverter:           dbmodel.NewToDomain(p.TagDotReplacement),
-		timeRangeIndices:        getTimeRangeIndexFn(p.Archive, p.UseReadWriteAliases),
+		timeRangeIndices:        getTimeRangeIndexFn(p.Archive, p.UseReadWriteAliases, p.IndexDateLayout),
 		sourceFn:                getSourceFn(p.Archive, p.MaxNumSpans),
 	}
 }
@@ -138,7 +147,7 @@ type timeRangeIndexFn func(indexName string, startTime time.Time, endTime time.T
 
 type sourceFn func(query elastic.Query, nextTime uint64) *elastic.SearchSource
 
-func getTimeRangeIndexFn(archive, useReadWriteAliases bool) timeRangeIndexFn {
+func getTimeRangeIndexFn(archive, useReadWriteAliases bool, dateLayout indexdate.Layout) timeRangeIndexFn {
 	if archive {
 		var archivePrefix string
 		if useReadWriteAliases {
@@ -155,7 +164,7 @@ func getTimeRangeIndexFn(archive, useReadWriteAliases bool) timeRangeIndexFn {
 			return []string{indices + "read"}
 		}
 	}
-	return timeRangeIndices
+	return dateLayout.IndicesInRange
 }
 
 func getSourceFn(archive bool, maxNumSpans int) sourceFn {
@@ -172,20 +181,6 @@ func getSourceFn(archive bool, maxNumSpans int) sourceFn {
 	}
 }
 
-// timeRangeIndices returns the array of indices that we need to query, based on query params
-func timeRangeIndices(indexName string, startTime time.Time, endTime time.Time) []string {
-	var indices []string
-	firstIndex := indexWithDate(indexName, startTime)
-	currentIndex := indexWithDate(indexName, endTime)
-	for currentIndex != firstIndex {
-		indices = append(indices, currentIndex)
-		endTime = endTime.Add(-24 * time.Hour)
-		currentIndex = indexWithDate(indexName, endTime)
-	}
-	indices = append(indices, firstIndex)
-	return indices
-}
-
 func indexNames(prefix, index string) string {
 	if prefix != "" {
 		return prefix + indexPrefixSeparator + index
@@ -398,7 +393,7 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p == nil {
 		return ErrMalformedRequestObject
 	}
-	if p.ServiceName == "" && len(p.Tags) > 0 {
+	if p.ServiceName == "" && (len(p.Tags) > 0 || len(p.TagFilters) > 0) {
 		return ErrServiceNameNotSet
 	}
 	if p.StartTimeMin.IsZero() || p.StartTimeMax.IsZero() {
@@ -410,6 +405,10 @@ func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p.DurationMin != 0 && p.DurationMax != 0 && p.DurationMin > p.DurationMax {
 		return ErrDurationMinGreaterThanMax
 	}
+	// the filters are evaluated by Elasticsearch, matchers only validate their values
+	if _, err := spanstore.NewTagMatchers(p.TagFilters); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -538,6 +537,14 @@ func (s *SpanReader) buildFindTraceIDsQuery(traceQuery *spanstore.TraceQueryPara
 		tagQuery := s.buildTagQuery(k, v)
 		boolQuery.Must(tagQuery)
 	}
+
+	for _, f := range traceQuery.TagFilters {
+		if f.Operator == spanstore.TagNotEquals {
+			boolQuery.MustNot(s.buildTagQuery(f.Key, f.Value))
+		} else {
+			boolQuery.Must(s.buildTagFilterQuery(f))
+		}
+	}
 	return boolQuery
 }
 
@@ -594,6 +601,43 @@ func (s *SpanReader) buildObjectQuery(field string, k string, v string) elastic.
 	return elastic.NewBoolQuery().Must(keyQuery)
 }
 
+func (s *SpanReader) buildTagFilterQuery(f spanstore.TagFilter) elastic.Query {
+	queries := make([]elastic.Query, 0, len(objectTagFieldList)+len(nestedTagFieldList))
+	kd := s.spanConverter.ReplaceDot(f.Key)
+	for _, field := range objectTagFieldList {
+		queries = append(queries, buildTagValueQuery(fmt.Sprintf("%s.%s", field, kd), f))
+	}
+	for _, field := range nestedTagFieldList {
+		keyField := fmt.Sprintf("%s.%s", field, tagKeyField)
+		tagBoolQuery := elastic.NewBoolQuery().Must(elastic.NewMatchQuery(keyField, f.Key))
+		if f.Operator != spanstore.TagExists {
+			valueField := fmt.Sprintf("%s.%s", field, tagValueField)
+			tagBoolQuery.Must(buildTagValueQuery(valueField, f))
+		}
+		queries = append(queries, elastic.NewNestedQuery(field, tagBoolQuery))
+	}
+	return elastic.NewBoolQuery().Should(queries...)
+}
+
+// buildTagValueQuery matches the values of the field against a filter with any operator but TagNotEquals
+func buildTagValueQuery(field string, f spanstore.TagFilter) elastic.Query {
+	switch {
+	case f.Operator == spanstore.TagExists:
+		return elastic.NewExistsQuery(field)
+	case f.Operator == spanstore.TagRegex:
+		return elastic.NewRegexpQuery(field, f.Value)
+	case f.Operator.IsNumeric():
+		// tag values are indexed as keywords, so they are compared as numbers by a script
+		value, _ := strconv.ParseFloat(f.Value, 64)
+		script := elastic.NewScript(fmt.Sprintf(numericTagScript, f.Operator)).
+			Lang("painless").
+			Params(map[string]interface{}{"field": field, "value": value})
+		return elastic.NewScriptQuery(script)
+	default:
+		return elastic.NewMatchQuery(field, f.Value)
+	}
+}
+
 func logErrorToSpan(span opentracing.Span, err error) {
 	ottag.Error.Set(span, true)
 	span.LogFields(otlog.Error(err))
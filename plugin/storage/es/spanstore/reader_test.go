@@ -36,6 +36,7 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/es/mocks"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/spanstore/dbmodel"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
@@ -145,10 +146,16 @@ func TestSpanReaderIndices(t *testing.T) {
 	metricsFactory := metricstest.NewFactory(0)
 	date := time.Now()
 	dateFormat := date.UTC().Format("2006-01-02")
+	weekly, err := indexdate.NewLayout(indexdate.Weekly, "2006.01.02")
+	require.NoError(t, err)
+	weekDate := date.UTC().AddDate(0, 0, -(int(date.UTC().Weekday())+6)%7)
 	testCases := []struct {
 		index  string
 		params SpanReaderParams
 	}{
+		{params: SpanReaderParams{Client: client, Logger: logger, MetricsFactory: metricsFactory,
+			IndexPrefix: "foo", IndexDateLayout: weekly},
+			index: "foo-" + spanIndex + weekDate.Format("2006.01.02")},
 		{params: SpanReaderParams{Client: client, Logger: logger, MetricsFactory: metricsFactory,
 			IndexPrefix: "", Archive: false},
 			index: spanIndex + dateFormat},
@@ -438,24 +445,24 @@ func TestSpanReaderFindIndices(t *testing.T) {
 			startTime: today.Add(-time.Millisecond),
 			endTime:   today,
 			expected: []string{
-				indexWithDate(spanIndex, today),
+				indexdate.Layout{}.IndexWithDate(spanIndex, today),
 			},
 		},
 		{
 			startTime: today.Add(-13 * time.Hour),
 			endTime:   today,
 			expected: []string{
-				indexWithDate(spanIndex, today),
-				indexWithDate(spanIndex, yesterday),
+				indexdate.Layout{}.IndexWithDate(spanIndex, today),
+				indexdate.Layout{}.IndexWithDate(spanIndex, yesterday),
 			},
 		},
 		{
 			startTime: today.Add(-48 * time.Hour),
 			endTime:   today,
 			expected: []string{
-				indexWithDate(spanIndex, today),
-				indexWithDate(spanIndex, yesterday),
-				indexWithDate(spanIndex, twoDaysAgo),
+				indexdate.Layout{}.IndexWithDate(spanIndex, today),
+				indexdate.Layout{}.IndexWithDate(spanIndex, yesterday),
+				indexdate.Layout{}.IndexWithDate(spanIndex, twoDaysAgo),
 			},
 		},
 	}
@@ -469,7 +476,7 @@ func TestSpanReaderFindIndices(t *testing.T) {
 
 func TestSpanReader_indexWithDate(t *testing.T) {
 	withSpanReader(func(r *spanReaderTest) {
-		actual := indexWithDate(spanIndex, time.Date(1995, time.April, 21, 4, 21, 19, 95, time.UTC))
+		actual := indexdate.Layout{}.IndexWithDate(spanIndex, time.Date(1995, time.April, 21, 4, 21, 19, 95, time.UTC))
 		assert.Equal(t, "jaeger-span-1995-04-21", actual)
 	})
 }
@@ -866,6 +873,16 @@ func TestTraceQueryParameterValidation(t *testing.T) {
 	tqp.DurationMax = time.Minute
 	err = validateQuery(tqp)
 	assert.EqualError(t, err, ErrDurationMinGreaterThanMax.Error())
+
+	tqp.DurationMin = 0
+	tqp.TagFilters = []spanstore.TagFilter{{Key: "http.status_code", Operator: spanstore.TagGreaterThan, Value: "5xx"}}
+	err = validateQuery(tqp)
+	assert.EqualError(t, err, "tag filter 'http.status_code>5xx' requires a numeric value")
+
+	tqp.Tags = nil
+	tqp.ServiceName = ""
+	err = validateQuery(tqp)
+	assert.EqualError(t, err, ErrServiceNameNotSet.Error())
 }
 
 func TestSpanReader_buildTraceIDAggregation(t *testing.T) {
@@ -923,6 +940,35 @@ func TestSpanReader_buildFindTraceIDsQuery(t *testing.T) {
 	})
 }
 
+func TestSpanReader_buildFindTraceIDsQueryWithTagFilters(t *testing.T) {
+	withSpanReader(func(r *spanReaderTest) {
+		regexFilter := spanstore.TagFilter{Key: "http.url", Operator: spanstore.TagRegex, Value: ".*/api/.*"}
+		traceQuery := &spanstore.TraceQueryParameters{
+			StartTimeMin: time.Time{},
+			StartTimeMax: time.Time{}.Add(time.Second),
+			ServiceName:  "s",
+			TagFilters: []spanstore.TagFilter{
+				{Key: "error", Operator: spanstore.TagNotEquals, Value: "true"},
+				regexFilter,
+			},
+		}
+
+		actualQuery := r.reader.buildFindTraceIDsQuery(traceQuery)
+		actual, err := actualQuery.Source()
+		require.NoError(t, err)
+		expectedQuery := elastic.NewBoolQuery().
+			Must(
+				r.reader.buildStartTimeQuery(time.Time{}, time.Time{}.Add(time.Second)),
+				r.reader.buildServiceNameQuery("s"),
+				r.reader.buildTagFilterQuery(regexFilter),
+			).
+			MustNot(r.reader.buildTagQuery("error", "true"))
+		expected, err := expectedQuery.Source()
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+}
+
 func TestSpanReader_buildDurationQuery(t *testing.T) {
 	expectedStr :=
 		`{ "range":
@@ -1018,6 +1064,54 @@ func TestSpanReader_buildTagQuery(t *testing.T) {
 	})
 }
 
+func TestSpanReader_buildTagFilterQuery(t *testing.T) {
+	inStr, err := ioutil.ReadFile("fixtures/query_02.json")
+	require.NoError(t, err)
+	withSpanReader(func(r *spanReaderTest) {
+		tagQuery := r.reader.buildTagFilterQuery(spanstore.TagFilter{Key: "bat.foo", Operator: spanstore.TagGreaterThanOrEqual, Value: "500"})
+		actual, err := tagQuery.Source()
+		require.NoError(t, err)
+
+		expected := make(map[string]interface{})
+		json.Unmarshal(inStr, &expected)
+
+		actualJSON, err := json.Marshal(actual)
+		require.NoError(t, err)
+		var actualMap map[string]interface{}
+		require.NoError(t, json.Unmarshal(actualJSON, &actualMap))
+		assert.EqualValues(t, expected, actualMap)
+	})
+}
+
+func TestSpanReader_buildTagValueQuery(t *testing.T) {
+	testCases := []struct {
+		filter   spanstore.TagFilter
+		expected string
+	}{
+		{
+			filter:   spanstore.TagFilter{Key: "k", Operator: spanstore.TagEquals, Value: "v"},
+			expected: `{"match":{"f":{"query":"v"}}}`,
+		},
+		{
+			filter:   spanstore.TagFilter{Key: "k", Operator: spanstore.TagExists},
+			expected: `{"exists":{"field":"f"}}`,
+		},
+		{
+			filter:   spanstore.TagFilter{Key: "k", Operator: spanstore.TagRegex, Value: "v.*"},
+			expected: `{"regexp":{"f":{"value":"v.*"}}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filter.String(), func(t *testing.T) {
+			actual, err := buildTagValueQuery("f", tc.filter).Source()
+			require.NoError(t, err)
+			actualJSON, err := json.Marshal(actual)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(actualJSON))
+		})
+	}
+}
+
 func TestSpanReader_GetEmptyIndex(t *testing.T) {
 	withSpanReader(func(r *spanReaderTest) {
 		mockSearchService(r).
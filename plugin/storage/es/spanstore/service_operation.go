@@ -17,6 +17,7 @@ package spanstore
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"hash/fnv"
 	"time"
@@ -35,6 +36,9 @@ const (
 
 	operationsAggregation = "distinct_operations"
 	servicesAggregation   = "distinct_services"
+
+	// maxPrewarmDocs is the default index.max_result_window, a search cannot return more documents.
+	maxPrewarmDocs = 10000
 )
 
 // ServiceOperationStorage stores service to operation pairs.
@@ -42,6 +46,7 @@ type ServiceOperationStorage struct {
 	client       es.Client
 	logger       *zap.Logger
 	serviceCache cache.Cache
+	cacheSize    int
 }
 
 // NewServiceOperationStorage returns a new ServiceOperationStorage.
@@ -49,16 +54,18 @@ func NewServiceOperationStorage(
 	client es.Client,
 	logger *zap.Logger,
 	cacheTTL time.Duration,
+	cacheSize int,
 ) *ServiceOperationStorage {
 	return &ServiceOperationStorage{
 		client: client,
 		logger: logger,
 		serviceCache: cache.NewLRUWithOptions(
-			100000,
+			cacheSize,
 			&cache.Options{
 				TTL: cacheTTL,
 			},
 		),
+		cacheSize: cacheSize,
 	}
 }
 
@@ -77,6 +84,38 @@ func (s *ServiceOperationStorage) Write(indexName string, jsonSpan *dbmodel.Span
 	}
 }
 
+// Prewarm loads the service to operation pairs already stored in the given index into the cache,
+// so that they are not written again after a restart. It returns the number of cached pairs.
+func (s *ServiceOperationStorage) Prewarm(ctx context.Context, indexName string) (int, error) {
+	size := s.cacheSize
+	if size > maxPrewarmDocs {
+		size = maxPrewarmDocs
+	}
+	searchResult, err := s.client.Search(indexName).
+		Size(size).
+		IgnoreUnavailable(true).
+		Do(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "Search service failed")
+	}
+	if searchResult.Hits == nil {
+		return 0, nil
+	}
+	var cached int
+	for _, hit := range searchResult.Hits.Hits {
+		if hit.Source == nil {
+			continue
+		}
+		var service dbmodel.Service
+		if err := json.Unmarshal(*hit.Source, &service); err != nil {
+			return cached, errors.Wrap(err, "Unmarshalling service document failed")
+		}
+		writeCache(hashCode(service), s.serviceCache)
+		cached++
+	}
+	return cached, nil
+}
+
 func (s *ServiceOperationStorage) getServices(context context.Context, indices []string) ([]string, error) {
 	serviceAggregation := getServicesAggregation()
 
@@ -17,12 +17,18 @@ package spanstore
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/olivere/elastic"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-lib/metrics"
+	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/pkg/es/mocks"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/spanstore/dbmodel"
@@ -126,3 +132,71 @@ func TestSpanReader_GetOperationsEmptyIndex(t *testing.T) {
 		assert.Empty(t, services)
 	})
 }
+
+func TestPrewarmServiceCache(t *testing.T) {
+	withSpanWriter(func(w *spanWriterTest) {
+		source := json.RawMessage(`{"serviceName":"service","operationName":"operation"}`)
+		searchService := &mocks.SearchService{}
+		searchService.On("Size", maxPrewarmDocs).Return(searchService)
+		searchService.On("IgnoreUnavailable", true).Return(searchService)
+		searchService.On("Do", mock.Anything).Return(&elastic.SearchResult{
+			Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{{Source: &source}}},
+		}, nil)
+		w.client.On("Search", mock.MatchedBy(func(index string) bool {
+			return strings.HasPrefix(index, "jaeger-service-")
+		})).Return(searchService)
+
+		require.NoError(t, w.writer.PrewarmServiceCache(context.Background()))
+		assert.Contains(t, w.logBuffer.String(), `"count":1`)
+
+		// the pair is cached, so the index service must not be called
+		jsonSpan := &dbmodel.Span{
+			OperationName: "operation",
+			Process:       dbmodel.Process{ServiceName: "service"},
+		}
+		w.writer.writeService("jaeger-service-1995-04-21", jsonSpan)
+		w.client.AssertNotCalled(t, "Index")
+	})
+}
+
+func TestPrewarmServiceCacheErrors(t *testing.T) {
+	invalid := json.RawMessage(`{"serviceName":`)
+	testCases := []struct {
+		name          string
+		searchResult  *elastic.SearchResult
+		searchError   error
+		expectedError string
+	}{
+		{
+			name:          "search error",
+			searchError:   errors.New("index not found"),
+			expectedError: "Search service failed: index not found",
+		},
+		{
+			name:          "invalid document",
+			searchResult:  &elastic.SearchResult{Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{{Source: &invalid}}}},
+			expectedError: "Unmarshalling service document failed: unexpected end of JSON input",
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			client := &mocks.Client{}
+			searchService := &mocks.SearchService{}
+			searchService.On("Size", 10).Return(searchService)
+			searchService.On("IgnoreUnavailable", true).Return(searchService)
+			searchService.On("Do", mock.Anything).Return(test.searchResult, test.searchError)
+			client.On("Search", "jaeger-service-1995-04-21").Return(searchService)
+
+			storage := NewServiceOperationStorage(client, zap.NewNop(), time.Hour, 10)
+			_, err := storage.Prewarm(context.Background(), "jaeger-service-1995-04-21")
+			assert.EqualError(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestPrewarmServiceCacheArchive(t *testing.T) {
+	client := &mocks.Client{}
+	writer := NewSpanWriter(SpanWriterParams{Client: client, Logger: zap.NewNop(), MetricsFactory: metrics.NullFactory, Archive: true})
+	require.NoError(t, writer.PrewarmServiceCache(context.Background()))
+	client.AssertNotCalled(t, "Search", mock.Anything)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/olivere/elastic"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/jaegertracing/jaeger/pkg/es"
+)
+
+const (
+	tagKeysAggregation   = "tag_keys"
+	tagValuesAggregation = "tag_values"
+	tagFilterAggregation = "tag_filter"
+)
+
+// nestedSpanTagFieldList are the nested fields holding span and process tags, log fields are not indexed for autocompletion.
+var nestedSpanTagFieldList = []string{nestedTagsField, nestedProcessTagsField}
+
+// GetTagKeys returns the keys of the span and process tags of the service stored as nested objects.
+// Tags stored as object fields (see --es.tags-as-fields) are not aggregatable by key and are not returned.
+func (s *SpanReader) GetTagKeys(ctx context.Context, service string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "GetTagKeys")
+	defer span.Finish()
+	searchService := s.tagSearchService(service)
+	for _, field := range nestedSpanTagFieldList {
+		searchService = searchService.Aggregation(field, elastic.NewNestedAggregation().
+			Path(field).
+			SubAggregation(tagKeysAggregation, elastic.NewTermsAggregation().
+				Field(field+"."+tagKeyField).
+				Size(defaultDocCount)))
+	}
+	searchResult, err := searchService.Do(ctx)
+	if err != nil {
+		logErrorToSpan(span, err)
+		return nil, errors.Wrap(err, "Search service failed")
+	}
+	keys := map[string]struct{}{}
+	for _, field := range nestedSpanTagFieldList {
+		if err := collectBuckets(keys, searchResult.Aggregations, field, tagKeysAggregation); err != nil {
+			return nil, err
+		}
+	}
+	return sortedStrings(keys), nil
+}
+
+// GetTagValues returns the values of the tag of the service that start with prefix,
+// whether the tag is stored as a nested object or as an object field.
+func (s *SpanReader) GetTagValues(ctx context.Context, service, key, prefix string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "GetTagValues")
+	defer span.Finish()
+	searchService := s.tagSearchService(service)
+	for _, field := range nestedSpanTagFieldList {
+		valueField := field + "." + tagValueField
+		filter := elastic.NewBoolQuery().Must(elastic.NewTermQuery(field+"."+tagKeyField, key))
+		if prefix != "" {
+			filter = filter.Must(elastic.NewPrefixQuery(valueField, prefix))
+		}
+		searchService = searchService.Aggregation(field, elastic.NewNestedAggregation().
+			Path(field).
+			SubAggregation(tagFilterAggregation, elastic.NewFilterAggregation().
+				Filter(filter).
+				SubAggregation(tagValuesAggregation, elastic.NewTermsAggregation().
+					Field(valueField).
+					Size(defaultDocCount))))
+	}
+	keyField := s.spanConverter.ReplaceDot(key)
+	for _, field := range objectTagFieldList {
+		valueField := field + "." + keyField
+		var filter elastic.Query = elastic.NewExistsQuery(valueField)
+		if prefix != "" {
+			filter = elastic.NewPrefixQuery(valueField, prefix)
+		}
+		searchService = searchService.Aggregation(field, elastic.NewFilterAggregation().
+			Filter(filter).
+			SubAggregation(tagValuesAggregation, elastic.NewTermsAggregation().
+				Field(valueField).
+				Size(defaultDocCount)))
+	}
+	searchResult, err := searchService.Do(ctx)
+	if err != nil {
+		logErrorToSpan(span, err)
+		return nil, errors.Wrap(err, "Search service failed")
+	}
+	values := map[string]struct{}{}
+	for _, field := range nestedSpanTagFieldList {
+		if err := collectBuckets(values, searchResult.Aggregations, field, tagFilterAggregation, tagValuesAggregation); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range objectTagFieldList {
+		if err := collectBuckets(values, searchResult.Aggregations, field, tagValuesAggregation); err != nil {
+			return nil, err
+		}
+	}
+	return sortedStrings(values), nil
+}
+
+func (s *SpanReader) tagSearchService(service string) es.SearchService {
+	currentTime := time.Now()
+	jaegerIndices := s.timeRangeIndices(s.spanIndexPrefix, currentTime.Add(-s.maxSpanAge), currentTime)
+	return s.client.Search(jaegerIndices...).
+		Size(0). // set to 0 because we don't want actual documents.
+		IgnoreUnavailable(true).
+		Query(elastic.NewTermQuery(serviceNameField, service))
+}
+
+// collectBuckets adds the keys of the terms aggregation found under the path of single bucket aggregations.
+func collectBuckets(set map[string]struct{}, aggregations elastic.Aggregations, path ...string) error {
+	if aggregations == nil {
+		return nil
+	}
+	last := len(path) - 1
+	// nested and filter aggregations share the single bucket format
+	for _, name := range path[:last] {
+		bucket, found := aggregations.Filter(name)
+		if !found {
+			return nil
+		}
+		aggregations = bucket.Aggregations
+	}
+	terms, found := aggregations.Terms(path[last])
+	if !found {
+		return nil
+	}
+	strs, err := bucketToStringArray(terms.Buckets)
+	if err != nil {
+		return err
+	}
+	for _, str := range strs {
+		set[str] = struct{}{}
+	}
+	return nil
+}
+
+func sortedStrings(set map[string]struct{}) []string {
+	strs := make([]string, 0, len(set))
+	for str := range set {
+		strs = append(strs, str)
+	}
+	sort.Strings(strs)
+	return strs
+}
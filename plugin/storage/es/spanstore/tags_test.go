@@ -0,0 +1,127 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/olivere/elastic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/es/mocks"
+)
+
+func mockTagSearchService(t *testing.T, r *spanReaderTest, aggregations string, err error) *mocks.SearchService {
+	searchService := &mocks.SearchService{}
+	searchService.On("Size", 0).Return(searchService)
+	searchService.On("IgnoreUnavailable", true).Return(searchService)
+	searchService.On("Query", mock.AnythingOfType("*elastic.TermQuery")).Return(searchService)
+	searchService.On("Aggregation", mock.AnythingOfType("string"), mock.Anything).Return(searchService)
+	var result *elastic.SearchResult
+	if err == nil {
+		result = &elastic.SearchResult{}
+		if aggregations != "" {
+			require.NoError(t, json.Unmarshal([]byte(aggregations), &result.Aggregations))
+		}
+	}
+	searchService.On("Do", mock.Anything).Return(result, err)
+	r.client.On("Search", mock.AnythingOfType("string")).Return(searchService)
+	return searchService
+}
+
+func TestSpanReader_GetTagKeys(t *testing.T) {
+	testCases := []struct {
+		name          string
+		aggregations  string
+		searchError   error
+		expectedKeys  []string
+		expectedError string
+	}{
+		{
+			name: "keys of span and process tags",
+			aggregations: `{
+				"tags": {"doc_count": 3, "tag_keys": {"buckets": [{"key": "http.method", "doc_count": 2}, {"key": "error", "doc_count": 1}]}},
+				"process.tags": {"doc_count": 2, "tag_keys": {"buckets": [{"key": "hostname", "doc_count": 2}, {"key": "error", "doc_count": 1}]}}
+			}`,
+			expectedKeys: []string{"error", "hostname", "http.method"},
+		},
+		{
+			name:         "no aggregations",
+			expectedKeys: []string{},
+		},
+		{
+			name:          "non-string key",
+			aggregations:  `{"tags": {"doc_count": 1, "tag_keys": {"buckets": [{"key": 1, "doc_count": 1}]}}}`,
+			expectedError: "Non-string key found in aggregation",
+		},
+		{
+			name:          "search error",
+			searchError:   errors.New("index not found"),
+			expectedError: "Search service failed: index not found",
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			withSpanReader(func(r *spanReaderTest) {
+				searchService := mockTagSearchService(t, r, test.aggregations, test.searchError)
+				keys, err := r.reader.GetTagKeys(context.Background(), "frontend")
+				if test.expectedError != "" {
+					assert.EqualError(t, err, test.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedKeys, keys)
+				searchService.AssertCalled(t, "Aggregation", nestedTagsField, mock.AnythingOfType("*elastic.NestedAggregation"))
+				searchService.AssertCalled(t, "Aggregation", nestedProcessTagsField, mock.AnythingOfType("*elastic.NestedAggregation"))
+			})
+		})
+	}
+}
+
+func TestSpanReader_GetTagValues(t *testing.T) {
+	withSpanReader(func(r *spanReaderTest) {
+		searchService := mockTagSearchService(t, r, `{
+			"tags": {"doc_count": 3, "tag_filter": {"doc_count": 2, "tag_values": {"buckets": [{"key": "GET", "doc_count": 2}]}}},
+			"process.tags": {"doc_count": 0, "tag_filter": {"doc_count": 0, "tag_values": {"buckets": []}}},
+			"tag": {"doc_count": 1, "tag_values": {"buckets": [{"key": "GET", "doc_count": 1}, {"key": "GETX", "doc_count": 1}]}}
+		}`, nil)
+		values, err := r.reader.GetTagValues(context.Background(), "frontend", "http.method", "GET")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GET", "GETX"}, values)
+
+		// dots in object field names are replaced
+		searchService.AssertCalled(t, "Aggregation", objectTagsField, mock.MatchedBy(func(aggregation *elastic.FilterAggregation) bool {
+			source, err := aggregation.Source()
+			require.NoError(t, err)
+			b, err := json.Marshal(source)
+			require.NoError(t, err)
+			return strings.Contains(string(b), `"tag.http@method":"GET"`)
+		}))
+	})
+}
+
+func TestSpanReader_GetTagValuesError(t *testing.T) {
+	withSpanReader(func(r *spanReaderTest) {
+		mockTagSearchService(t, r, "", errors.New("index not found"))
+		_, err := r.reader.GetTagValues(context.Background(), "frontend", "http.method", "")
+		assert.EqualError(t, err, "Search service failed: index not found")
+	})
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/cache"
 	"github.com/jaegertracing/jaeger/pkg/es"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/spanstore/dbmodel"
 	storageMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 )
@@ -32,6 +33,9 @@ import (
 const (
 	spanType    = "span"
 	serviceType = "service"
+
+	defaultServiceCacheTTL  = 12 * time.Hour
+	defaultServiceCacheSize = 100000
 )
 
 type spanWriterMetrics struct {
@@ -46,7 +50,7 @@ type SpanWriter struct {
 	client           es.Client
 	logger           *zap.Logger
 	writerMetrics    spanWriterMetrics // TODO: build functions to wrap around each Do fn
-	indexCache       cache.Cache
+	serviceStorage   *ServiceOperationStorage
 	serviceWriter    serviceWriter
 	spanConverter    dbmodel.FromDomain
 	spanServiceIndex spanAndServiceIndexFn
@@ -58,19 +62,27 @@ type SpanWriterParams struct {
 	Logger              *zap.Logger
 	MetricsFactory      metrics.Factory
 	IndexPrefix         string
+	IndexDateLayout     indexdate.Layout
 	AllTagsAsFields     bool
 	TagKeysAsFields     []string
 	TagDotReplacement   string
 	Archive             bool
 	UseReadWriteAliases bool
+	ServiceCacheTTL     time.Duration
+	ServiceCacheSize    int
 }
 
 // NewSpanWriter creates a new SpanWriter for use
 func NewSpanWriter(p SpanWriterParams) *SpanWriter {
 	ctx := context.Background()
 
-	// TODO: Configurable TTL
-	serviceOperationStorage := NewServiceOperationStorage(p.Client, p.Logger, time.Hour*12)
+	if p.ServiceCacheTTL == 0 {
+		p.ServiceCacheTTL = defaultServiceCacheTTL
+	}
+	if p.ServiceCacheSize == 0 {
+		p.ServiceCacheSize = defaultServiceCacheSize
+	}
+	serviceOperationStorage := NewServiceOperationStorage(p.Client, p.Logger, p.ServiceCacheTTL, p.ServiceCacheSize)
 	return &SpanWriter{
 		ctx:    ctx,
 		client: p.Client,
@@ -78,15 +90,10 @@ func NewSpanWriter(p SpanWriterParams) *SpanWriter {
 		writerMetrics: spanWriterMetrics{
 			indexCreate: storageMetrics.NewWriteMetrics(p.MetricsFactory, "index_create"),
 		},
-		serviceWriter: serviceOperationStorage.Write,
-		indexCache: cache.NewLRUWithOptions(
-			5,
-			&cache.Options{
-				TTL: 48 * time.Hour,
-			},
-		),
+		serviceStorage:   serviceOperationStorage,
+		serviceWriter:    serviceOperationStorage.Write,
 		spanConverter:    dbmodel.NewFromDomain(p.AllTagsAsFields, p.TagKeysAsFields, p.TagDotReplacement),
-		spanServiceIndex: getSpanAndServiceIndexFn(p.Archive, p.UseReadWriteAliases, p.IndexPrefix),
+		spanServiceIndex: getSpanAndServiceIndexFn(p.Archive, p.UseReadWriteAliases, p.IndexPrefix, p.IndexDateLayout),
 	}
 }
 
@@ -103,10 +110,25 @@ func (s *SpanWriter) CreateTemplates(spanTemplate, serviceTemplate string) error
 	return nil
 }
 
+// PrewarmServiceCache loads the service to operation pairs from the current service index,
+// so that a restarted collector does not write them again.
+func (s *SpanWriter) PrewarmServiceCache(ctx context.Context) error {
+	_, serviceIndexName := s.spanServiceIndex(time.Now())
+	if serviceIndexName == "" {
+		return nil
+	}
+	cached, err := s.serviceStorage.Prewarm(ctx, serviceIndexName)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Loaded service and operation names into the cache", zap.String("index", serviceIndexName), zap.Int("count", cached))
+	return nil
+}
+
 // spanAndServiceIndexFn returns names of span and service indices
 type spanAndServiceIndexFn func(spanTime time.Time) (string, string)
 
-func getSpanAndServiceIndexFn(archive, useReadWriteAliases bool, prefix string) spanAndServiceIndexFn {
+func getSpanAndServiceIndexFn(archive, useReadWriteAliases bool, prefix string, dateLayout indexdate.Layout) spanAndServiceIndexFn {
 	if prefix != "" {
 		prefix += indexPrefixSeparator
 	}
@@ -127,7 +149,7 @@ func getSpanAndServiceIndexFn(archive, useReadWriteAliases bool, prefix string)
 		}
 	}
 	return func(date time.Time) (string, string) {
-		return indexWithDate(spanIndexPrefix, date), indexWithDate(serviceIndexPrefix, date)
+		return dateLayout.IndexWithDate(spanIndexPrefix, date), dateLayout.IndexWithDate(serviceIndexPrefix, date)
 	}
 }
 
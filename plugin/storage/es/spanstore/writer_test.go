@@ -31,6 +31,7 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/es/mocks"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/spanstore/dbmodel"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
@@ -63,10 +64,19 @@ func TestSpanWriterIndices(t *testing.T) {
 	metricsFactory := metricstest.NewFactory(0)
 	date := time.Now()
 	dateFormat := date.UTC().Format("2006-01-02")
+	hourly, err := indexdate.NewLayout(indexdate.Hourly, "")
+	require.NoError(t, err)
+	hourFormat := date.UTC().Format("2006-01-02-15")
 	testCases := []struct {
 		indices []string
 		params  SpanWriterParams
 	}{
+		{params: SpanWriterParams{Client: client, Logger: logger, MetricsFactory: metricsFactory,
+			IndexPrefix: "foo", IndexDateLayout: hourly},
+			indices: []string{"foo-" + spanIndex + hourFormat, "foo-" + serviceIndex + hourFormat}},
+		{params: SpanWriterParams{Client: client, Logger: logger, MetricsFactory: metricsFactory,
+			IndexDateLayout: hourly, UseReadWriteAliases: true},
+			indices: []string{spanIndex + "write", serviceIndex + "write"}},
 		{params: SpanWriterParams{Client: client, Logger: logger, MetricsFactory: metricsFactory,
 			IndexPrefix: "", Archive: false},
 			indices: []string{spanIndex + dateFormat, serviceIndex + dateFormat}},
@@ -254,8 +264,8 @@ func TestSpanIndexName(t *testing.T) {
 	span := &model.Span{
 		StartTime: date,
 	}
-	spanIndexName := indexWithDate(spanIndex, span.StartTime)
-	serviceIndexName := indexWithDate(serviceIndex, span.StartTime)
+	spanIndexName := indexdate.Layout{}.IndexWithDate(spanIndex, span.StartTime)
+	serviceIndexName := indexdate.Layout{}.IndexWithDate(serviceIndex, span.StartTime)
 	assert.Equal(t, "jaeger-span-1995-04-21", spanIndexName)
 	assert.Equal(t, "jaeger-service-1995-04-21", serviceIndexName)
 }
@@ -18,11 +18,14 @@ package storage
 import (
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 
 	"github.com/spf13/viper"
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/pkg/multierror"
 	"github.com/jaegertracing/jaeger/plugin"
 	"github.com/jaegertracing/jaeger/plugin/storage/badger"
 	"github.com/jaegertracing/jaeger/plugin/storage/cassandra"
@@ -161,6 +164,35 @@ func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return factory.CreateDependencyReader()
 }
 
+// CreateSamplingStoreFactory returns the first configured span writer backend that supports
+// storage.SamplingStoreFactory, or nil if none of them do.
+func (f *Factory) CreateSamplingStoreFactory() (storage.SamplingStoreFactory, error) {
+	for _, storageType := range f.SpanWriterTypes {
+		factory, ok := f.factories[storageType]
+		if !ok {
+			return nil, fmt.Errorf("no %s backend registered for span store", storageType)
+		}
+		if ssFactory, ok := factory.(storage.SamplingStoreFactory); ok {
+			return ssFactory, nil
+		}
+	}
+	return nil, nil
+}
+
+// AdminHandlers returns the admin HTTP handlers of all configured backends that implement
+// storage.AdminFactory, keyed by route.
+func (f *Factory) AdminHandlers() map[string]http.Handler {
+	handlers := make(map[string]http.Handler)
+	for _, factory := range f.factories {
+		if adminFactory, ok := factory.(storage.AdminFactory); ok {
+			for route, handler := range adminFactory.AdminHandlers() {
+				handlers[route] = handler
+			}
+		}
+	}
+	return handlers
+}
+
 // AddFlags implements plugin.Configurable
 func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
 	for _, factory := range f.factories {
@@ -171,6 +203,35 @@ func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
 	addDownsamplingFlags(flagSet)
 }
 
+// jobConfigurable is implemented by the factories that can run maintenance jobs in the background
+type jobConfigurable interface {
+	// AddJobFlags adds the flags of the background jobs
+	AddJobFlags(flagSet *flag.FlagSet)
+}
+
+// AddJobFlags adds the flags of the background jobs of the configured backends. Only the binaries meant
+// to run these jobs, i.e. the collector, should register them.
+func (f *Factory) AddJobFlags(flagSet *flag.FlagSet) {
+	for _, factory := range f.factories {
+		if conf, ok := factory.(jobConfigurable); ok {
+			conf.AddJobFlags(flagSet)
+		}
+	}
+}
+
+// Close implements io.Closer and closes the backends which implement it.
+func (f *Factory) Close() error {
+	var errs []error
+	for _, factory := range f.factories {
+		if closer, ok := factory.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return multierror.Wrap(errs)
+}
+
 // addDownsamplingFlags add flags for Downsampling params
 func addDownsamplingFlags(flagSet *flag.FlagSet) {
 	flagSet.Float64(
@@ -18,6 +18,7 @@ package storage
 import (
 	"errors"
 	"flag"
+	"net/http"
 	"reflect"
 	"strings"
 	"testing"
@@ -38,6 +39,7 @@ import (
 
 var _ storage.Factory = new(Factory)
 var _ storage.ArchiveFactory = new(Factory)
+var _ storage.AdminFactory = new(Factory)
 
 func defaultCfg() FactoryConfig {
 	return FactoryConfig{
@@ -274,6 +276,59 @@ func TestCreateError(t *testing.T) {
 		assert.Nil(t, w)
 		assert.EqualError(t, err, expectedErr)
 	}
+
+	{
+		ss, err := f.CreateSamplingStoreFactory()
+		assert.Nil(t, ss)
+		assert.EqualError(t, err, expectedErr)
+	}
+}
+
+func TestCreateSamplingStoreFactory(t *testing.T) {
+	f, err := NewFactory(defaultCfg())
+	require.NoError(t, err)
+
+	// the real cassandra factory supports sampling storage
+	ss, err := f.CreateSamplingStoreFactory()
+	assert.NoError(t, err)
+	assert.Equal(t, f.factories[cassandraStorageType], ss)
+
+	// a backend without sampling storage support
+	f.factories[cassandraStorageType] = new(mocks.Factory)
+	ss, err = f.CreateSamplingStoreFactory()
+	assert.NoError(t, err)
+	assert.Nil(t, ss)
+
+	mock := &struct {
+		mocks.Factory
+		mocks.SamplingStoreFactory
+	}{}
+	f.factories[cassandraStorageType] = mock
+	ss, err = f.CreateSamplingStoreFactory()
+	assert.NoError(t, err)
+	assert.Equal(t, mock, ss)
+}
+
+type adminFactory struct {
+	mocks.Factory
+	handlers map[string]http.Handler
+}
+
+// AdminHandlers implements storage.AdminFactory
+func (f *adminFactory) AdminHandlers() map[string]http.Handler {
+	return f.handlers
+}
+
+func TestAdminHandlers(t *testing.T) {
+	f, err := NewFactory(defaultCfg())
+	require.NoError(t, err)
+	assert.Empty(t, f.AdminHandlers())
+
+	handler := http.NotFoundHandler()
+	f.factories[cassandraStorageType] = &adminFactory{handlers: map[string]http.Handler{"/foo": handler}}
+	handlers := f.AdminHandlers()
+	assert.Len(t, handlers, 1)
+	assert.NotNil(t, handlers["/foo"])
 }
 
 type configurable struct {
@@ -314,6 +369,49 @@ func TestConfigurable(t *testing.T) {
 	assert.Equal(t, v, mock.viper)
 }
 
+type jobFactory struct {
+	mocks.Factory
+	flagSet  *flag.FlagSet
+	closeErr error
+	closed   bool
+}
+
+// AddJobFlags implements jobConfigurable
+func (f *jobFactory) AddJobFlags(flagSet *flag.FlagSet) {
+	f.flagSet = flagSet
+}
+
+// Close implements io.Closer
+func (f *jobFactory) Close() error {
+	f.closed = true
+	return f.closeErr
+}
+
+func TestAddJobFlags(t *testing.T) {
+	f, err := NewFactory(defaultCfg())
+	require.NoError(t, err)
+	mock := new(jobFactory)
+	f.factories[cassandraStorageType] = mock
+
+	fs := new(flag.FlagSet)
+	f.AddFlags(fs)
+	assert.Nil(t, mock.flagSet, "job flags are only added on demand")
+	f.AddJobFlags(fs)
+	assert.Equal(t, fs, mock.flagSet)
+}
+
+func TestClose(t *testing.T) {
+	f, err := NewFactory(defaultCfg())
+	require.NoError(t, err)
+	f.factories[cassandraStorageType] = new(mocks.Factory)
+	assert.NoError(t, f.Close(), "factories without Close are skipped")
+
+	mock := &jobFactory{closeErr: errors.New("close error")}
+	f.factories[cassandraStorageType] = mock
+	assert.EqualError(t, f.Close(), "close error")
+	assert.True(t, mock.closed)
+}
+
 func TestParsingDownsamplingRatio(t *testing.T) {
 	f := Factory{}
 	v, command := config.Viperize(addDownsamplingFlags)
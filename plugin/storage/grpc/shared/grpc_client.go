@@ -96,11 +96,16 @@ func (c *grpcClient) GetOperations(ctx context.Context, service string) ([]strin
 
 // FindTraces retrieves traces that match the traceQuery
 func (c *grpcClient) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	// the plugin protocol only supports equality tag filters
+	tags, err := query.EqualityTags()
+	if err != nil {
+		return nil, err
+	}
 	stream, err := c.readerClient.FindTraces(context.Background(), &storage_v1.FindTracesRequest{
 		Query: &storage_v1.TraceQueryParameters{
 			ServiceName:   query.ServiceName,
 			OperationName: query.OperationName,
-			Tags:          query.Tags,
+			Tags:          tags,
 			StartTimeMin:  query.StartTimeMin,
 			StartTimeMax:  query.StartTimeMax,
 			DurationMin:   query.DurationMin,
@@ -134,11 +139,16 @@ func (c *grpcClient) FindTraces(ctx context.Context, query *spanstore.TraceQuery
 
 // FindTraceIDs retrieves traceIDs that match the traceQuery
 func (c *grpcClient) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	// the plugin protocol only supports equality tag filters
+	tags, err := query.EqualityTags()
+	if err != nil {
+		return nil, err
+	}
 	resp, err := c.readerClient.FindTraceIDs(context.Background(), &storage_v1.FindTraceIDsRequest{
 		Query: &storage_v1.TraceQueryParameters{
 			ServiceName:   query.ServiceName,
 			OperationName: query.OperationName,
-			Tags:          query.Tags,
+			Tags:          tags,
 			StartTimeMin:  query.StartTimeMin,
 			StartTimeMax:  query.StartTimeMax,
 			DurationMin:   query.DurationMin,
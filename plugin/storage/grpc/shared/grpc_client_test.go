@@ -184,6 +184,33 @@ func TestGRPCClientFindTraces(t *testing.T) {
 	})
 }
 
+func TestGRPCClientFindTraces_TagFilters(t *testing.T) {
+	withGRPCClient(func(r *grpcClientTest) {
+		traceClient := new(grpcMocks.SpanReaderPlugin_FindTracesClient)
+		traceClient.On("Recv").Return(nil, io.EOF)
+		r.spanReader.On("FindTraces", mock.Anything, &storage_v1.FindTracesRequest{
+			Query: &storage_v1.TraceQueryParameters{Tags: map[string]string{"http.method": "GET"}},
+		}).Return(traceClient, nil)
+
+		s, err := r.client.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+			TagFilters: []spanstore.TagFilter{{Key: "http.method", Operator: spanstore.TagEquals, Value: "GET"}},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, s)
+
+		query := &spanstore.TraceQueryParameters{
+			TagFilters: []spanstore.TagFilter{{Key: "http.status_code", Operator: spanstore.TagGreaterThan, Value: "499"}},
+		}
+		expectedErr := "cannot filter tag 'http.status_code' with operator '>': " + spanstore.ErrUnsupportedTagOperator.Error()
+		s, err = r.client.FindTraces(context.Background(), query)
+		assert.EqualError(t, err, expectedErr)
+		assert.Nil(t, s)
+		ids, err := r.client.FindTraceIDs(context.Background(), query)
+		assert.EqualError(t, err, expectedErr)
+		assert.Nil(t, ids)
+	})
+}
+
 func TestGRPCClientFindTraces_Error(t *testing.T) {
 	withGRPCClient(func(r *grpcClientTest) {
 		r.spanReader.On("FindTraces", mock.Anything, &storage_v1.FindTracesRequest{
@@ -35,6 +35,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 	"github.com/jaegertracing/jaeger/plugin/storage/es"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/dependencystore"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/indexdate"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/spanstore"
 )
 
@@ -85,7 +86,7 @@ func (s *ESStorageIntegration) initializeES(allTagsAsFields, archive bool) error
 	}
 	s.bulkProcessor, _ = s.client.BulkProcessor().Do(context.Background())
 	client := eswrapper.WrapESClient(s.client, s.bulkProcessor, esVersion)
-	dependencyStore := dependencystore.NewDependencyStore(client, s.logger, indexPrefix)
+	dependencyStore := dependencystore.NewDependencyStore(client, s.logger, indexPrefix, indexdate.Layout{})
 	s.DependencyReader = dependencyStore
 	s.DependencyWriter = dependencyStore
 	s.initSpanstore(allTagsAsFields, archive)
@@ -22,16 +22,24 @@ import (
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/pkg/distributedlock"
+	"github.com/jaegertracing/jaeger/plugin/pkg/distributedlock/local"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
+// samplingMaxBuckets is the number of throughput and probabilities insertions retained by
+// the sampling store, which must cover the aggregation buckets of the adaptive sampling processor.
+const samplingMaxBuckets = 30
+
 // Factory implements storage.Factory and creates storage components backed by memory store.
 type Factory struct {
 	options        Options
 	metricsFactory metrics.Factory
 	logger         *zap.Logger
 	store          *Store
+	samplingStore  *SamplingStore
 }
 
 // NewFactory creates a new Factory.
@@ -53,6 +61,7 @@ func (f *Factory) InitFromViper(v *viper.Viper) {
 func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
 	f.metricsFactory, f.logger = metricsFactory, logger
 	f.store = WithConfiguration(f.options.Configuration)
+	f.samplingStore = NewSamplingStore(samplingMaxBuckets)
 	logger.Info("Memory storage initialized", zap.Any("configuration", f.store.config))
 	return nil
 }
@@ -71,3 +80,13 @@ func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
 func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	return f.store, nil
 }
+
+// CreateLock implements storage.SamplingStoreFactory
+func (f *Factory) CreateLock() (distributedlock.Lock, error) {
+	return local.NewLock(), nil
+}
+
+// CreateSamplingStore implements storage.SamplingStoreFactory
+func (f *Factory) CreateSamplingStore() (samplingstore.Store, error) {
+	return f.samplingStore, nil
+}
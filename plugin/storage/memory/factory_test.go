@@ -26,6 +26,7 @@ import (
 )
 
 var _ storage.Factory = new(Factory)
+var _ storage.SamplingStoreFactory = new(Factory)
 
 func TestMemoryStorageFactory(t *testing.T) {
 	f := NewFactory()
@@ -40,6 +41,12 @@ func TestMemoryStorageFactory(t *testing.T) {
 	depReader, err := f.CreateDependencyReader()
 	assert.NoError(t, err)
 	assert.Equal(t, f.store, depReader)
+	lock, err := f.CreateLock()
+	assert.NoError(t, err)
+	assert.NotNil(t, lock)
+	samplingStore, err := f.CreateSamplingStore()
+	assert.NoError(t, err)
+	assert.Equal(t, f.samplingStore, samplingStore)
 }
 
 func TestWithConfiguration(t *testing.T) {
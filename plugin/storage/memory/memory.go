@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -184,13 +185,75 @@ func (m *Store) GetOperations(ctx context.Context, service string) ([]string, er
 	return []string{}, nil
 }
 
+// GetTagKeys returns the keys of the span, process and log tags of the given service
+func (m *Store) GetTagKeys(ctx context.Context, service string) ([]string, error) {
+	m.RLock()
+	defer m.RUnlock()
+	keys := map[string]struct{}{}
+	m.forEachTag(service, func(kv model.KeyValue) {
+		keys[kv.Key] = struct{}{}
+	})
+	return sortedKeys(keys), nil
+}
+
+// GetTagValues returns the values of the tag of the given service that start with prefix
+func (m *Store) GetTagValues(ctx context.Context, service, key, prefix string) ([]string, error) {
+	m.RLock()
+	defer m.RUnlock()
+	values := map[string]struct{}{}
+	m.forEachTag(service, func(kv model.KeyValue) {
+		if kv.Key != key {
+			return
+		}
+		if value := kv.AsString(); strings.HasPrefix(value, prefix) {
+			values[value] = struct{}{}
+		}
+	})
+	return sortedKeys(values), nil
+}
+
+func (m *Store) forEachTag(service string, fn func(kv model.KeyValue)) {
+	for _, trace := range m.traces {
+		for _, span := range trace.Spans {
+			if span.Process.ServiceName != service {
+				continue
+			}
+			// not using flattenTags, which may append to the span's tags while other readers hold the lock
+			for _, kv := range span.Tags {
+				fn(kv)
+			}
+			for _, kv := range span.Process.Tags {
+				fn(kv)
+			}
+			for _, log := range span.Logs {
+				for _, kv := range log.Fields {
+					fn(kv)
+				}
+			}
+		}
+	}
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	retMe := make([]string, 0, len(set))
+	for k := range set {
+		retMe = append(retMe, k)
+	}
+	sort.Strings(retMe)
+	return retMe
+}
+
 // FindTraces returns all traces in the query parameters are satisfied by a trace's span
 func (m *Store) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	matchers, err := spanstore.NewTagMatchers(query.TagFilters)
+	if err != nil {
+		return nil, err
+	}
 	m.RLock()
 	defer m.RUnlock()
 	var retMe []*model.Trace
 	for _, trace := range m.traces {
-		if m.validTrace(trace, query) {
+		if m.validTrace(trace, query, matchers) {
 			retMe = append(retMe, trace)
 		}
 	}
@@ -212,9 +275,9 @@ func (m *Store) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryPar
 	return nil, errors.New("not implemented")
 }
 
-func (m *Store) validTrace(trace *model.Trace, query *spanstore.TraceQueryParameters) bool {
+func (m *Store) validTrace(trace *model.Trace, query *spanstore.TraceQueryParameters, matchers []*spanstore.TagMatcher) bool {
 	for _, span := range trace.Spans {
-		if m.validSpan(span, query) {
+		if m.validSpan(span, query, matchers) {
 			return true
 		}
 	}
@@ -230,7 +293,7 @@ func findKeyValueMatch(kvs model.KeyValues, key, value string) (model.KeyValue,
 	return model.KeyValue{}, false
 }
 
-func (m *Store) validSpan(span *model.Span, query *spanstore.TraceQueryParameters) bool {
+func (m *Store) validSpan(span *model.Span, query *spanstore.TraceQueryParameters, matchers []*spanstore.TagMatcher) bool {
 	if query.ServiceName != span.Process.ServiceName {
 		return false
 	}
@@ -256,6 +319,11 @@ func (m *Store) validSpan(span *model.Span, query *spanstore.TraceQueryParameter
 			return false
 		}
 	}
+	for _, matcher := range matchers {
+		if !matcher.Matches(spanKVs) {
+			return false
+		}
+	}
 	return true
 }
 
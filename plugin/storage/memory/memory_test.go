@@ -241,6 +241,47 @@ func TestStoreGetOperationsNotFound(t *testing.T) {
 	})
 }
 
+func TestStoreGetTagKeys(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		keys, err := store.GetTagKeys(context.Background(), testingSpan.Process.ServiceName)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"logKey", "tagKey"}, keys)
+
+		keys, err = store.GetTagKeys(context.Background(), "unknown")
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+}
+
+func TestStoreGetTagValues(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		store.WriteSpan(&model.Span{
+			TraceID: model.NewTraceID(1, 3),
+			SpanID:  model.NewSpanID(1),
+			Process: &model.Process{
+				ServiceName: "serviceName",
+				Tags:        model.KeyValues{model.Int64("tagKey", 42)},
+			},
+			Tags: model.KeyValues{model.String("tagKey", "otherValue")},
+		})
+		testCases := []struct {
+			key      string
+			prefix   string
+			expected []string
+		}{
+			{key: "tagKey", expected: []string{"42", "otherValue", "tagValue"}},
+			{key: "tagKey", prefix: "tag", expected: []string{"tagValue"}},
+			{key: "logKey", expected: []string{"logValue"}},
+			{key: "unknown", expected: []string{}},
+		}
+		for _, test := range testCases {
+			values, err := store.GetTagValues(context.Background(), "serviceName", test.key, test.prefix)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, values)
+		}
+	})
+}
+
 func TestStoreGetEmptyTraceSet(t *testing.T) {
 	withPopulatedMemoryStore(func(store *Store) {
 		traces, err := store.FindTraces(context.Background(), &spanstore.TraceQueryParameters{})
@@ -360,6 +401,40 @@ func TestStoreGetTrace(t *testing.T) {
 				},
 			}, false,
 		},
+		{
+			&spanstore.TraceQueryParameters{
+				ServiceName: testingSpan.Process.ServiceName,
+				TagFilters: []spanstore.TagFilter{
+					{Key: testingSpan.Tags[0].Key, Operator: spanstore.TagNotEquals, Value: "otherValue"},
+					{Key: testingSpan.Logs[0].Fields[0].Key, Operator: spanstore.TagExists},
+					{Key: testingSpan.Tags[0].Key, Operator: spanstore.TagRegex, Value: "tag.*"},
+				},
+			}, true,
+		},
+		{
+			&spanstore.TraceQueryParameters{
+				ServiceName: testingSpan.Process.ServiceName,
+				TagFilters: []spanstore.TagFilter{
+					{Key: testingSpan.Tags[0].Key, Operator: spanstore.TagNotEquals, Value: testingSpan.Tags[0].VStr},
+				},
+			}, false,
+		},
+		{
+			&spanstore.TraceQueryParameters{
+				ServiceName: testingSpan.Process.ServiceName,
+				TagFilters: []spanstore.TagFilter{
+					{Key: "missingKey", Operator: spanstore.TagExists},
+				},
+			}, false,
+		},
+		{
+			&spanstore.TraceQueryParameters{
+				ServiceName: testingSpan.Process.ServiceName,
+				TagFilters: []spanstore.TagFilter{
+					{Key: testingSpan.Tags[0].Key, Operator: spanstore.TagGreaterThan, Value: "0"},
+				},
+			}, false,
+		},
 	}
 	for _, testS := range testStruct {
 		withPopulatedMemoryStore(func(store *Store) {
@@ -377,6 +452,17 @@ func TestStoreGetTrace(t *testing.T) {
 	}
 }
 
+func TestStoreFindTracesInvalidTagFilter(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		traces, err := store.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName: testingSpan.Process.ServiceName,
+			TagFilters:  []spanstore.TagFilter{{Key: "http.status_code", Operator: spanstore.TagLessThan, Value: "abc"}},
+		})
+		assert.Nil(t, traces)
+		assert.EqualError(t, err, "tag filter 'http.status_code<abc' requires a numeric value")
+	})
+}
+
 func TestStore_FindTraceIDs(t *testing.T) {
 	withMemoryStore(func(store *Store) {
 		traceIDs, err := store.FindTraceIDs(context.Background(), nil)
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+)
+
+type storedThroughput struct {
+	throughput []*model.Throughput
+	time       time.Time
+}
+
+type storedProbabilitiesAndQPS struct {
+	hostname      string
+	probabilities model.ServiceOperationProbabilities
+	qps           model.ServiceOperationQPS
+	time          time.Time
+}
+
+// SamplingStore is an in-memory store of throughput and probabilities for adaptive sampling.
+// Only the latest maxBuckets insertions of each kind are retained, the newest first.
+type SamplingStore struct {
+	sync.RWMutex
+	maxBuckets          int
+	throughputs         []*storedThroughput
+	probabilitiesAndQPS []*storedProbabilitiesAndQPS
+}
+
+// NewSamplingStore creates an in-memory sampling store that retains up to maxBuckets insertions.
+func NewSamplingStore(maxBuckets int) *SamplingStore {
+	return &SamplingStore{maxBuckets: maxBuckets}
+}
+
+// InsertThroughput implements samplingstore.Store#InsertThroughput.
+func (s *SamplingStore) InsertThroughput(throughput []*model.Throughput) error {
+	s.Lock()
+	defer s.Unlock()
+	s.throughputs = append([]*storedThroughput{{throughput: throughput, time: time.Now()}}, s.throughputs...)
+	if len(s.throughputs) > s.maxBuckets {
+		s.throughputs = s.throughputs[:s.maxBuckets]
+	}
+	return nil
+}
+
+// GetThroughput implements samplingstore.Store#GetThroughput.
+func (s *SamplingStore) GetThroughput(start, end time.Time) ([]*model.Throughput, error) {
+	s.RLock()
+	defer s.RUnlock()
+	var ret []*model.Throughput
+	for _, t := range s.throughputs {
+		if t.time.After(start) && !t.time.After(end) {
+			ret = append(ret, t.throughput...)
+		}
+	}
+	return ret, nil
+}
+
+// InsertProbabilitiesAndQPS implements samplingstore.Store#InsertProbabilitiesAndQPS.
+func (s *SamplingStore) InsertProbabilitiesAndQPS(
+	hostname string,
+	probabilities model.ServiceOperationProbabilities,
+	qps model.ServiceOperationQPS,
+) error {
+	s.Lock()
+	defer s.Unlock()
+	entry := &storedProbabilitiesAndQPS{
+		hostname:      hostname,
+		probabilities: probabilities,
+		qps:           qps,
+		time:          time.Now(),
+	}
+	s.probabilitiesAndQPS = append([]*storedProbabilitiesAndQPS{entry}, s.probabilitiesAndQPS...)
+	if len(s.probabilitiesAndQPS) > s.maxBuckets {
+		s.probabilitiesAndQPS = s.probabilitiesAndQPS[:s.maxBuckets]
+	}
+	return nil
+}
+
+// GetLatestProbabilities implements samplingstore.Store#GetLatestProbabilities.
+func (s *SamplingStore) GetLatestProbabilities() (model.ServiceOperationProbabilities, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if len(s.probabilitiesAndQPS) == 0 {
+		return model.ServiceOperationProbabilities{}, nil
+	}
+	return s.probabilitiesAndQPS[0].probabilities, nil
+}
+
+// GetProbabilitiesAndQPS implements samplingstore.Store#GetProbabilitiesAndQPS.
+func (s *SamplingStore) GetProbabilitiesAndQPS(start, end time.Time) (map[string][]model.ServiceOperationData, error) {
+	s.RLock()
+	defer s.RUnlock()
+	ret := make(map[string][]model.ServiceOperationData)
+	for _, p := range s.probabilitiesAndQPS {
+		if p.time.After(start) && !p.time.After(end) {
+			ret[p.hostname] = append(ret[p.hostname], toServiceOperationData(p.probabilities, p.qps))
+		}
+	}
+	return ret, nil
+}
+
+func toServiceOperationData(probabilities model.ServiceOperationProbabilities, qps model.ServiceOperationQPS) model.ServiceOperationData {
+	data := make(model.ServiceOperationData)
+	for svc, opProbabilities := range probabilities {
+		data[svc] = make(map[string]*model.ProbabilityAndQPS)
+		for op, probability := range opProbabilities {
+			data[svc][op] = &model.ProbabilityAndQPS{
+				Probability: probability,
+				QPS:         qps[svc][op],
+			}
+		}
+	}
+	return data
+}
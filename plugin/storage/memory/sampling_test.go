@@ -0,0 +1,85 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+)
+
+var _ samplingstore.Store = new(SamplingStore)
+
+func TestSamplingStoreThroughput(t *testing.T) {
+	store := NewSamplingStore(2)
+	start := time.Now().Add(-time.Minute)
+
+	throughput, err := store.GetThroughput(start, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, throughput)
+
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(t, store.InsertThroughput([]*model.Throughput{{Service: "svc", Operation: "op", Count: i}}))
+	}
+	throughput, err = store.GetThroughput(start, time.Now())
+	require.NoError(t, err)
+	require.Len(t, throughput, 2, "oldest bucket is evicted")
+	assert.EqualValues(t, 3, throughput[0].Count)
+	assert.EqualValues(t, 2, throughput[1].Count)
+
+	throughput, err = store.GetThroughput(time.Now(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, throughput)
+}
+
+func TestSamplingStoreProbabilitiesAndQPS(t *testing.T) {
+	store := NewSamplingStore(10)
+	start := time.Now().Add(-time.Minute)
+
+	probabilities, err := store.GetLatestProbabilities()
+	require.NoError(t, err)
+	assert.Empty(t, probabilities)
+
+	require.NoError(t, store.InsertProbabilitiesAndQPS(
+		"host1",
+		model.ServiceOperationProbabilities{"svc": {"op": 0.1}},
+		model.ServiceOperationQPS{"svc": {"op": 5}},
+	))
+	require.NoError(t, store.InsertProbabilitiesAndQPS(
+		"host2",
+		model.ServiceOperationProbabilities{"svc": {"op": 0.2}},
+		model.ServiceOperationQPS{"svc": {"op": 6}},
+	))
+
+	probabilities, err = store.GetLatestProbabilities()
+	require.NoError(t, err)
+	assert.Equal(t, model.ServiceOperationProbabilities{"svc": {"op": 0.2}}, probabilities)
+
+	data, err := store.GetProbabilitiesAndQPS(start, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]model.ServiceOperationData{
+		"host1": {{"svc": {"op": {Probability: 0.1, QPS: 5}}}},
+		"host2": {{"svc": {"op": {Probability: 0.2, QPS: 6}}}},
+	}, data)
+
+	data, err = store.GetProbabilitiesAndQPS(time.Now(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
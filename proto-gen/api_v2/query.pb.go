@@ -34,6 +34,49 @@ var _ = time.Kitchen
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 
+type TagFilter_Operator int32
+
+const (
+	TagFilter_EQUALS                TagFilter_Operator = 0
+	TagFilter_NOT_EQUALS            TagFilter_Operator = 1
+	TagFilter_EXISTS                TagFilter_Operator = 2
+	TagFilter_GREATER_THAN          TagFilter_Operator = 3
+	TagFilter_GREATER_THAN_OR_EQUAL TagFilter_Operator = 4
+	TagFilter_LESS_THAN             TagFilter_Operator = 5
+	TagFilter_LESS_THAN_OR_EQUAL    TagFilter_Operator = 6
+	TagFilter_REGEX                 TagFilter_Operator = 7
+)
+
+var TagFilter_Operator_name = map[int32]string{
+	0: "EQUALS",
+	1: "NOT_EQUALS",
+	2: "EXISTS",
+	3: "GREATER_THAN",
+	4: "GREATER_THAN_OR_EQUAL",
+	5: "LESS_THAN",
+	6: "LESS_THAN_OR_EQUAL",
+	7: "REGEX",
+}
+
+var TagFilter_Operator_value = map[string]int32{
+	"EQUALS":                0,
+	"NOT_EQUALS":            1,
+	"EXISTS":                2,
+	"GREATER_THAN":          3,
+	"GREATER_THAN_OR_EQUAL": 4,
+	"LESS_THAN":             5,
+	"LESS_THAN_OR_EQUAL":    6,
+	"REGEX":                 7,
+}
+
+func (x TagFilter_Operator) String() string {
+	return proto.EnumName(TagFilter_Operator_name, int32(x))
+}
+
+func (TagFilter_Operator) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{4, 0}
+}
+
 type GetTraceRequest struct {
 	TraceID              github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_id"`
 	XXX_NoUnkeyedLiteral struct{}                                      `json:"-"`
@@ -200,6 +243,69 @@ func (m *ArchiveTraceResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_ArchiveTraceResponse proto.InternalMessageInfo
 
+type TagFilter struct {
+	Key                  string             `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Operator             TagFilter_Operator `protobuf:"varint,2,opt,name=operator,proto3,enum=jaeger.api_v2.TagFilter_Operator" json:"operator,omitempty"`
+	Value                string             `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *TagFilter) Reset()         { *m = TagFilter{} }
+func (m *TagFilter) String() string { return proto.CompactTextString(m) }
+func (*TagFilter) ProtoMessage()    {}
+func (*TagFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{4}
+}
+func (m *TagFilter) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TagFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TagFilter.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TagFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TagFilter.Merge(m, src)
+}
+func (m *TagFilter) XXX_Size() int {
+	return m.Size()
+}
+func (m *TagFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_TagFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TagFilter proto.InternalMessageInfo
+
+func (m *TagFilter) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *TagFilter) GetOperator() TagFilter_Operator {
+	if m != nil {
+		return m.Operator
+	}
+	return TagFilter_EQUALS
+}
+
+func (m *TagFilter) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
 type TraceQueryParameters struct {
 	ServiceName          string            `protobuf:"bytes,1,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
 	OperationName        string            `protobuf:"bytes,2,opt,name=operation_name,json=operationName,proto3" json:"operation_name,omitempty"`
@@ -209,6 +315,7 @@ type TraceQueryParameters struct {
 	DurationMin          time.Duration     `protobuf:"bytes,6,opt,name=duration_min,json=durationMin,proto3,stdduration" json:"duration_min"`
 	DurationMax          time.Duration     `protobuf:"bytes,7,opt,name=duration_max,json=durationMax,proto3,stdduration" json:"duration_max"`
 	SearchDepth          int32             `protobuf:"varint,8,opt,name=search_depth,json=searchDepth,proto3" json:"search_depth,omitempty"`
+	TagFilters           []*TagFilter      `protobuf:"bytes,9,rep,name=tag_filters,json=tagFilters,proto3" json:"tag_filters,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -218,7 +325,7 @@ func (m *TraceQueryParameters) Reset()         { *m = TraceQueryParameters{} }
 func (m *TraceQueryParameters) String() string { return proto.CompactTextString(m) }
 func (*TraceQueryParameters) ProtoMessage()    {}
 func (*TraceQueryParameters) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{4}
+	return fileDescriptor_26651706f9f8a4f0, []int{5}
 }
 func (m *TraceQueryParameters) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -303,6 +410,13 @@ func (m *TraceQueryParameters) GetSearchDepth() int32 {
 	return 0
 }
 
+func (m *TraceQueryParameters) GetTagFilters() []*TagFilter {
+	if m != nil {
+		return m.TagFilters
+	}
+	return nil
+}
+
 type FindTracesRequest struct {
 	Query                *TraceQueryParameters `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
@@ -314,7 +428,7 @@ func (m *FindTracesRequest) Reset()         { *m = FindTracesRequest{} }
 func (m *FindTracesRequest) String() string { return proto.CompactTextString(m) }
 func (*FindTracesRequest) ProtoMessage()    {}
 func (*FindTracesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{5}
+	return fileDescriptor_26651706f9f8a4f0, []int{6}
 }
 func (m *FindTracesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -360,7 +474,7 @@ func (m *GetServicesRequest) Reset()         { *m = GetServicesRequest{} }
 func (m *GetServicesRequest) String() string { return proto.CompactTextString(m) }
 func (*GetServicesRequest) ProtoMessage()    {}
 func (*GetServicesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{6}
+	return fileDescriptor_26651706f9f8a4f0, []int{7}
 }
 func (m *GetServicesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -400,7 +514,7 @@ func (m *GetServicesResponse) Reset()         { *m = GetServicesResponse{} }
 func (m *GetServicesResponse) String() string { return proto.CompactTextString(m) }
 func (*GetServicesResponse) ProtoMessage()    {}
 func (*GetServicesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{7}
+	return fileDescriptor_26651706f9f8a4f0, []int{8}
 }
 func (m *GetServicesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -447,7 +561,7 @@ func (m *GetOperationsRequest) Reset()         { *m = GetOperationsRequest{} }
 func (m *GetOperationsRequest) String() string { return proto.CompactTextString(m) }
 func (*GetOperationsRequest) ProtoMessage()    {}
 func (*GetOperationsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{8}
+	return fileDescriptor_26651706f9f8a4f0, []int{9}
 }
 func (m *GetOperationsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -494,7 +608,7 @@ func (m *GetOperationsResponse) Reset()         { *m = GetOperationsResponse{} }
 func (m *GetOperationsResponse) String() string { return proto.CompactTextString(m) }
 func (*GetOperationsResponse) ProtoMessage()    {}
 func (*GetOperationsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{9}
+	return fileDescriptor_26651706f9f8a4f0, []int{10}
 }
 func (m *GetOperationsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -542,7 +656,7 @@ func (m *GetDependenciesRequest) Reset()         { *m = GetDependenciesRequest{}
 func (m *GetDependenciesRequest) String() string { return proto.CompactTextString(m) }
 func (*GetDependenciesRequest) ProtoMessage()    {}
 func (*GetDependenciesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{10}
+	return fileDescriptor_26651706f9f8a4f0, []int{11}
 }
 func (m *GetDependenciesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -596,7 +710,7 @@ func (m *GetDependenciesResponse) Reset()         { *m = GetDependenciesResponse
 func (m *GetDependenciesResponse) String() string { return proto.CompactTextString(m) }
 func (*GetDependenciesResponse) ProtoMessage()    {}
 func (*GetDependenciesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_26651706f9f8a4f0, []int{11}
+	return fileDescriptor_26651706f9f8a4f0, []int{12}
 }
 func (m *GetDependenciesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -632,7 +746,213 @@ func (m *GetDependenciesResponse) GetDependencies() []model.DependencyLink {
 	return nil
 }
 
+type GetTagKeysRequest struct {
+	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTagKeysRequest) Reset()         { *m = GetTagKeysRequest{} }
+func (m *GetTagKeysRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTagKeysRequest) ProtoMessage()    {}
+func (*GetTagKeysRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{13}
+}
+func (m *GetTagKeysRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetTagKeysRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetTagKeysRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetTagKeysRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTagKeysRequest.Merge(m, src)
+}
+func (m *GetTagKeysRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetTagKeysRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTagKeysRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTagKeysRequest proto.InternalMessageInfo
+
+func (m *GetTagKeysRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+type GetTagKeysResponse struct {
+	Keys                 []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTagKeysResponse) Reset()         { *m = GetTagKeysResponse{} }
+func (m *GetTagKeysResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTagKeysResponse) ProtoMessage()    {}
+func (*GetTagKeysResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{14}
+}
+func (m *GetTagKeysResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetTagKeysResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetTagKeysResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetTagKeysResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTagKeysResponse.Merge(m, src)
+}
+func (m *GetTagKeysResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetTagKeysResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTagKeysResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTagKeysResponse proto.InternalMessageInfo
+
+func (m *GetTagKeysResponse) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type GetTagValuesRequest struct {
+	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Prefix               string   `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTagValuesRequest) Reset()         { *m = GetTagValuesRequest{} }
+func (m *GetTagValuesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTagValuesRequest) ProtoMessage()    {}
+func (*GetTagValuesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{15}
+}
+func (m *GetTagValuesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetTagValuesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetTagValuesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetTagValuesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTagValuesRequest.Merge(m, src)
+}
+func (m *GetTagValuesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetTagValuesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTagValuesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTagValuesRequest proto.InternalMessageInfo
+
+func (m *GetTagValuesRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *GetTagValuesRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *GetTagValuesRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+type GetTagValuesResponse struct {
+	Values               []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTagValuesResponse) Reset()         { *m = GetTagValuesResponse{} }
+func (m *GetTagValuesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTagValuesResponse) ProtoMessage()    {}
+func (*GetTagValuesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26651706f9f8a4f0, []int{16}
+}
+func (m *GetTagValuesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetTagValuesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetTagValuesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetTagValuesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTagValuesResponse.Merge(m, src)
+}
+func (m *GetTagValuesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetTagValuesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTagValuesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTagValuesResponse proto.InternalMessageInfo
+
+func (m *GetTagValuesResponse) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
 func init() {
+	proto.RegisterEnum("jaeger.api_v2.TagFilter_Operator", TagFilter_Operator_name, TagFilter_Operator_value)
+	golang_proto.RegisterEnum("jaeger.api_v2.TagFilter_Operator", TagFilter_Operator_name, TagFilter_Operator_value)
 	proto.RegisterType((*GetTraceRequest)(nil), "jaeger.api_v2.GetTraceRequest")
 	golang_proto.RegisterType((*GetTraceRequest)(nil), "jaeger.api_v2.GetTraceRequest")
 	proto.RegisterType((*SpansResponseChunk)(nil), "jaeger.api_v2.SpansResponseChunk")
@@ -641,6 +961,8 @@ func init() {
 	golang_proto.RegisterType((*ArchiveTraceRequest)(nil), "jaeger.api_v2.ArchiveTraceRequest")
 	proto.RegisterType((*ArchiveTraceResponse)(nil), "jaeger.api_v2.ArchiveTraceResponse")
 	golang_proto.RegisterType((*ArchiveTraceResponse)(nil), "jaeger.api_v2.ArchiveTraceResponse")
+	proto.RegisterType((*TagFilter)(nil), "jaeger.api_v2.TagFilter")
+	golang_proto.RegisterType((*TagFilter)(nil), "jaeger.api_v2.TagFilter")
 	proto.RegisterType((*TraceQueryParameters)(nil), "jaeger.api_v2.TraceQueryParameters")
 	golang_proto.RegisterType((*TraceQueryParameters)(nil), "jaeger.api_v2.TraceQueryParameters")
 	proto.RegisterMapType((map[string]string)(nil), "jaeger.api_v2.TraceQueryParameters.TagsEntry")
@@ -659,74 +981,99 @@ func init() {
 	golang_proto.RegisterType((*GetDependenciesRequest)(nil), "jaeger.api_v2.GetDependenciesRequest")
 	proto.RegisterType((*GetDependenciesResponse)(nil), "jaeger.api_v2.GetDependenciesResponse")
 	golang_proto.RegisterType((*GetDependenciesResponse)(nil), "jaeger.api_v2.GetDependenciesResponse")
+	proto.RegisterType((*GetTagKeysRequest)(nil), "jaeger.api_v2.GetTagKeysRequest")
+	golang_proto.RegisterType((*GetTagKeysRequest)(nil), "jaeger.api_v2.GetTagKeysRequest")
+	proto.RegisterType((*GetTagKeysResponse)(nil), "jaeger.api_v2.GetTagKeysResponse")
+	golang_proto.RegisterType((*GetTagKeysResponse)(nil), "jaeger.api_v2.GetTagKeysResponse")
+	proto.RegisterType((*GetTagValuesRequest)(nil), "jaeger.api_v2.GetTagValuesRequest")
+	golang_proto.RegisterType((*GetTagValuesRequest)(nil), "jaeger.api_v2.GetTagValuesRequest")
+	proto.RegisterType((*GetTagValuesResponse)(nil), "jaeger.api_v2.GetTagValuesResponse")
+	golang_proto.RegisterType((*GetTagValuesResponse)(nil), "jaeger.api_v2.GetTagValuesResponse")
 }
 
 func init() { proto.RegisterFile("api_v2/query.proto", fileDescriptor_26651706f9f8a4f0) }
 func init() { golang_proto.RegisterFile("api_v2/query.proto", fileDescriptor_26651706f9f8a4f0) }
 
 var fileDescriptor_26651706f9f8a4f0 = []byte{
-	// 965 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x55, 0x4f, 0x73, 0xdb, 0x44,
-	0x14, 0x47, 0x4e, 0x1c, 0xdb, 0x4f, 0x76, 0x4b, 0xd7, 0x4e, 0x6b, 0x04, 0xd8, 0x8e, 0x42, 0x21,
-	0xd3, 0x21, 0xda, 0xd4, 0x0c, 0x53, 0xc8, 0x05, 0xe2, 0xa6, 0xf5, 0xa4, 0x03, 0xa5, 0xa8, 0x39,
-	0xc1, 0xc1, 0xb3, 0xb1, 0x16, 0x59, 0x24, 0x5e, 0xa9, 0xd2, 0xda, 0x4d, 0x86, 0xe1, 0xc2, 0x27,
-	0x60, 0xe0, 0xc2, 0x89, 0x2b, 0x27, 0xbe, 0x03, 0xc7, 0x1e, 0x99, 0xe1, 0xc6, 0x21, 0x30, 0x81,
-	0x0f, 0xc2, 0xec, 0x1f, 0x39, 0xb6, 0xec, 0x49, 0x43, 0x0f, 0x3d, 0x69, 0xf7, 0xed, 0x7b, 0xbf,
-	0xf7, 0xef, 0xf7, 0x9e, 0x00, 0x91, 0x28, 0xe8, 0x8d, 0xdb, 0xf8, 0xc9, 0x88, 0xc6, 0x27, 0x4e,
-	0x14, 0x87, 0x3c, 0x44, 0x95, 0xaf, 0x09, 0xf5, 0x69, 0xec, 0xa8, 0x27, 0xcb, 0x1c, 0x86, 0x1e,
-	0x3d, 0x52, 0x6f, 0x56, 0xcd, 0x0f, 0xfd, 0x50, 0x1e, 0xb1, 0x38, 0x69, 0xe9, 0x1b, 0x7e, 0x18,
-	0xfa, 0x47, 0x14, 0x93, 0x28, 0xc0, 0x84, 0xb1, 0x90, 0x13, 0x1e, 0x84, 0x2c, 0xd1, 0xaf, 0x4d,
-	0xfd, 0x2a, 0x6f, 0x07, 0xa3, 0xaf, 0x30, 0x0f, 0x86, 0x34, 0xe1, 0x64, 0x18, 0x69, 0x85, 0x46,
-	0x56, 0xc1, 0x1b, 0xc5, 0x12, 0x41, 0xbf, 0xbf, 0x2b, 0x3f, 0xfd, 0x4d, 0x9f, 0xb2, 0xcd, 0xe4,
-	0x29, 0xf1, 0x7d, 0x1a, 0xe3, 0x30, 0x92, 0x2e, 0xe6, 0xdd, 0xd9, 0x0c, 0xae, 0x76, 0x29, 0xdf,
-	0x8f, 0x49, 0x9f, 0xba, 0xf4, 0xc9, 0x88, 0x26, 0x1c, 0x7d, 0x09, 0x45, 0x2e, 0xee, 0xbd, 0xc0,
-	0xab, 0x1b, 0x2d, 0x63, 0xa3, 0xdc, 0xf9, 0xf8, 0xd9, 0x69, 0xf3, 0x95, 0x3f, 0x4f, 0x9b, 0x9b,
-	0x7e, 0xc0, 0x07, 0xa3, 0x03, 0xa7, 0x1f, 0x0e, 0xb1, 0x4a, 0x5b, 0x28, 0x06, 0xcc, 0xd7, 0x37,
-	0xac, 0x92, 0x97, 0x68, 0x7b, 0xbb, 0x67, 0xa7, 0xcd, 0x82, 0x3e, 0xba, 0x05, 0x89, 0xb8, 0xe7,
-	0xd9, 0xf7, 0x00, 0x3d, 0x8e, 0x08, 0x4b, 0x5c, 0x9a, 0x44, 0x21, 0x4b, 0xe8, 0xdd, 0xc1, 0x88,
-	0x1d, 0x22, 0x0c, 0xf9, 0x44, 0x48, 0xeb, 0x46, 0x6b, 0x69, 0xc3, 0x6c, 0x57, 0x9d, 0x99, 0xa2,
-	0x3a, 0xc2, 0xa2, 0xb3, 0x2c, 0x82, 0x70, 0x95, 0x9e, 0x1d, 0x43, 0x75, 0x27, 0xee, 0x0f, 0x82,
-	0x31, 0x7d, 0x79, 0xa1, 0x5f, 0x87, 0xda, 0xac, 0x4f, 0x95, 0x81, 0xfd, 0xcb, 0x32, 0xd4, 0xa4,
-	0xe4, 0x73, 0x41, 0x8b, 0x47, 0x24, 0x26, 0x43, 0xca, 0x69, 0x9c, 0xa0, 0x35, 0x28, 0x27, 0x34,
-	0x1e, 0x07, 0x7d, 0xda, 0x63, 0x64, 0x48, 0x65, 0x44, 0x25, 0xd7, 0xd4, 0xb2, 0x87, 0x64, 0x48,
-	0xd1, 0x4d, 0xb8, 0x12, 0x46, 0x54, 0xf5, 0x4f, 0x29, 0xe5, 0xa4, 0x52, 0x65, 0x22, 0x95, 0x6a,
-	0x3b, 0xb0, 0xcc, 0x89, 0x9f, 0xd4, 0x97, 0x64, 0x79, 0x36, 0x33, 0xe5, 0x59, 0xe4, 0xdc, 0xd9,
-	0x27, 0x7e, 0x72, 0x8f, 0xf1, 0xf8, 0xc4, 0x95, 0xa6, 0xe8, 0x01, 0x5c, 0x49, 0x38, 0x89, 0x79,
-	0x4f, 0xf0, 0xa9, 0x37, 0x0c, 0x58, 0x7d, 0xb9, 0x65, 0x6c, 0x98, 0x6d, 0xcb, 0x51, 0x7c, 0x72,
-	0x52, 0x3e, 0x39, 0xfb, 0x29, 0xe1, 0x3a, 0x45, 0x51, 0xbc, 0xef, 0xff, 0x6a, 0x1a, 0x6e, 0x59,
-	0xda, 0x8a, 0x97, 0x4f, 0x03, 0x96, 0xc5, 0x22, 0xc7, 0xf5, 0xfc, 0x8b, 0x61, 0x91, 0x63, 0x74,
-	0x1f, 0xca, 0x29, 0x81, 0x65, 0x54, 0x2b, 0x12, 0xe9, 0xb5, 0x39, 0xa4, 0x5d, 0xad, 0xa4, 0x80,
-	0x7e, 0x12, 0x40, 0x66, 0x6a, 0x28, 0x62, 0x9a, 0xc1, 0x21, 0xc7, 0xf5, 0xc2, 0x8b, 0xe0, 0x90,
-	0x63, 0xd5, 0x34, 0x12, 0xf7, 0x07, 0x3d, 0x8f, 0x46, 0x7c, 0x50, 0x2f, 0xb6, 0x8c, 0x8d, 0xbc,
-	0x68, 0x9a, 0x90, 0xed, 0x0a, 0x91, 0x75, 0x07, 0x4a, 0x93, 0xea, 0xa2, 0x57, 0x61, 0xe9, 0x90,
-	0x9e, 0xe8, 0xde, 0x8a, 0x23, 0xaa, 0x41, 0x7e, 0x4c, 0x8e, 0x46, 0x69, 0x2b, 0xd5, 0x65, 0x3b,
-	0xf7, 0x81, 0x61, 0x3f, 0x84, 0x6b, 0xf7, 0x03, 0xe6, 0xc9, 0x7e, 0x25, 0x29, 0x67, 0x3f, 0x84,
-	0xbc, 0xdc, 0x27, 0x12, 0xc2, 0x6c, 0xaf, 0x5f, 0xa2, 0xb9, 0xae, 0xb2, 0xb0, 0x6b, 0x80, 0xba,
-	0x94, 0x3f, 0x56, 0x7c, 0x4a, 0x01, 0xed, 0xdb, 0x50, 0x9d, 0x91, 0x2a, 0x9a, 0x22, 0x0b, 0x8a,
-	0x9a, 0x79, 0x6a, 0xcc, 0x4a, 0xee, 0xe4, 0x6e, 0x6f, 0x41, 0xad, 0x4b, 0xf9, 0x67, 0x29, 0xe7,
-	0x26, 0xb1, 0xd5, 0xa1, 0xa0, 0x75, 0x74, 0x82, 0xe9, 0xd5, 0xbe, 0x03, 0xab, 0x19, 0x0b, 0xed,
-	0xa6, 0x01, 0x30, 0xe1, 0x6e, 0xea, 0x68, 0x4a, 0x62, 0xff, 0x6c, 0xc0, 0xf5, 0x2e, 0xe5, 0xbb,
-	0x34, 0xa2, 0xcc, 0xa3, 0xac, 0x1f, 0x9c, 0x57, 0xe2, 0x2e, 0xc0, 0x39, 0xad, 0x74, 0x39, 0x2e,
-	0x47, 0xa9, 0xd2, 0x84, 0x52, 0xe8, 0x23, 0x28, 0x52, 0xe6, 0x29, 0x88, 0xdc, 0xff, 0x80, 0x28,
-	0x50, 0xe6, 0x09, 0xb9, 0x7d, 0x00, 0x37, 0xe6, 0xe2, 0xd3, 0xb9, 0x75, 0xa1, 0xec, 0x4d, 0xc9,
-	0xf5, 0xb6, 0x7a, 0x33, 0xd3, 0xb1, 0x89, 0xe9, 0xc9, 0x27, 0x01, 0x3b, 0xd4, 0x7b, 0x6b, 0xc6,
-	0xb0, 0xfd, 0x6b, 0x1e, 0xca, 0xb2, 0xa7, 0xba, 0x4b, 0xe8, 0x10, 0x8a, 0xe9, 0x1a, 0x46, 0x8d,
-	0x0c, 0x5e, 0x66, 0x3f, 0x5b, 0x6b, 0x0b, 0xb6, 0xe3, 0xec, 0x3e, 0xb5, 0xad, 0xef, 0xfe, 0xf8,
-	0xf7, 0xc7, 0x5c, 0x0d, 0x21, 0x2c, 0x97, 0x57, 0x82, 0xbf, 0x49, 0xd7, 0xe2, 0xb7, 0x5b, 0x06,
-	0xe2, 0x50, 0x9e, 0x5e, 0x64, 0xc8, 0xce, 0x00, 0x2e, 0xd8, 0xac, 0xd6, 0xfa, 0x85, 0x3a, 0x7a,
-	0x13, 0xbe, 0x2e, 0xdd, 0xae, 0xda, 0x55, 0x4c, 0xd4, 0xf3, 0x94, 0x5f, 0xe4, 0x03, 0x9c, 0x93,
-	0x1f, 0xb5, 0x32, 0x78, 0x73, 0x73, 0x71, 0x99, 0x34, 0x91, 0xf4, 0x57, 0xb6, 0x0b, 0x58, 0x8d,
-	0xe7, 0xb6, 0x71, 0x6b, 0xcb, 0x40, 0x3e, 0x98, 0x53, 0xfc, 0x47, 0x6b, 0xf3, 0xe5, 0xcc, 0x4c,
-	0x8c, 0x65, 0x5f, 0xa4, 0xa2, 0x73, 0xbb, 0x26, 0x7d, 0x99, 0xa8, 0x84, 0xd3, 0xa9, 0x41, 0x21,
-	0x54, 0x66, 0x66, 0x00, 0xad, 0xcf, 0xe3, 0xcc, 0xcd, 0x94, 0xf5, 0xd6, 0xc5, 0x4a, 0xda, 0x5d,
-	0x55, 0xba, 0xab, 0x20, 0x13, 0x9f, 0xcf, 0x0e, 0x7a, 0x2a, 0x7f, 0xd6, 0xd3, 0xd4, 0x44, 0x37,
-	0xe7, 0xd1, 0x16, 0x8c, 0x96, 0xf5, 0xf6, 0xf3, 0xd4, 0xb4, 0xdb, 0x55, 0xe9, 0xf6, 0x2a, 0xaa,
-	0xe0, 0x69, 0xbe, 0x76, 0xc6, 0x3f, 0xec, 0x74, 0x50, 0xbe, 0xbd, 0x74, 0xdb, 0xd9, 0xba, 0x95,
-	0x33, 0x72, 0xf1, 0xfb, 0x00, 0x0f, 0x24, 0x5e, 0x6b, 0xe7, 0xd1, 0x1e, 0x7a, 0x67, 0xc0, 0x79,
-	0x94, 0x6c, 0x63, 0xfc, 0x9c, 0x7f, 0xec, 0xb3, 0xb3, 0x86, 0xf1, 0xfb, 0x59, 0xc3, 0xf8, 0xfb,
-	0xac, 0x61, 0xfc, 0xf6, 0x4f, 0xc3, 0x80, 0x1b, 0x41, 0xe8, 0xcc, 0x28, 0xea, 0xf0, 0xbe, 0x58,
-	0x51, 0xdf, 0x83, 0x15, 0x39, 0xb2, 0xef, 0xfd, 0x17, 0x00, 0x00, 0xff, 0xff, 0xee, 0x7a, 0xc9,
-	0xa7, 0x79, 0x09, 0x00, 0x00,
+	// 1246 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0x4d, 0x73, 0x1b, 0x45,
+	0x13, 0xce, 0xca, 0xd6, 0x57, 0x4b, 0x72, 0x94, 0xb6, 0xec, 0x6c, 0xf6, 0x7d, 0x91, 0xe5, 0x35,
+	0x01, 0x57, 0x0a, 0xef, 0x3a, 0xa2, 0xa8, 0x90, 0x54, 0x51, 0x20, 0xc7, 0x8a, 0x70, 0x08, 0x76,
+	0xb2, 0x12, 0x54, 0x80, 0x83, 0x18, 0x4b, 0x93, 0xf5, 0x62, 0x6b, 0x77, 0xb3, 0x3b, 0x72, 0xec,
+	0xa2, 0xb8, 0x70, 0xe0, 0xcc, 0xc7, 0x85, 0x13, 0xbf, 0x85, 0x63, 0x8e, 0x54, 0x71, 0xe3, 0x10,
+	0x28, 0xc3, 0xef, 0xa0, 0xa8, 0x9d, 0x99, 0xd5, 0xa7, 0x71, 0x9c, 0x1c, 0x38, 0x69, 0xa7, 0xe7,
+	0xe9, 0xa7, 0x67, 0x7a, 0xfa, 0xe9, 0x16, 0x20, 0xf1, 0x9d, 0xf6, 0x61, 0xd5, 0x7c, 0xdc, 0xa7,
+	0xc1, 0xb1, 0xe1, 0x07, 0x1e, 0xf3, 0xb0, 0xf0, 0x05, 0xa1, 0x36, 0x0d, 0x0c, 0xb1, 0xa5, 0xe5,
+	0x7a, 0x5e, 0x97, 0x1e, 0x88, 0x3d, 0xad, 0x64, 0x7b, 0xb6, 0xc7, 0x3f, 0xcd, 0xe8, 0x4b, 0x5a,
+	0xff, 0x6f, 0x7b, 0x9e, 0x7d, 0x40, 0x4d, 0xe2, 0x3b, 0x26, 0x71, 0x5d, 0x8f, 0x11, 0xe6, 0x78,
+	0x6e, 0x28, 0x77, 0x97, 0xe4, 0x2e, 0x5f, 0xed, 0xf6, 0x1f, 0x99, 0xcc, 0xe9, 0xd1, 0x90, 0x91,
+	0x9e, 0x2f, 0x01, 0xe5, 0x49, 0x40, 0xb7, 0x1f, 0x70, 0x06, 0xb9, 0xff, 0x06, 0xff, 0xe9, 0xac,
+	0xd9, 0xd4, 0x5d, 0x0b, 0x9f, 0x10, 0xdb, 0xa6, 0x81, 0xe9, 0xf9, 0x3c, 0xc4, 0x74, 0x38, 0xdd,
+	0x85, 0x8b, 0x0d, 0xca, 0x5a, 0x01, 0xe9, 0x50, 0x8b, 0x3e, 0xee, 0xd3, 0x90, 0xe1, 0x67, 0x90,
+	0x61, 0xd1, 0xba, 0xed, 0x74, 0x55, 0xa5, 0xa2, 0xac, 0xe6, 0x37, 0xde, 0x7b, 0xfa, 0x6c, 0xe9,
+	0xc2, 0x6f, 0xcf, 0x96, 0xd6, 0x6c, 0x87, 0xed, 0xf5, 0x77, 0x8d, 0x8e, 0xd7, 0x33, 0xc5, 0xb5,
+	0x23, 0xa0, 0xe3, 0xda, 0x72, 0x65, 0x8a, 0xcb, 0x73, 0xb6, 0xad, 0xcd, 0x93, 0x67, 0x4b, 0x69,
+	0xf9, 0x69, 0xa5, 0x39, 0xe3, 0x56, 0x57, 0xaf, 0x03, 0x36, 0x7d, 0xe2, 0x86, 0x16, 0x0d, 0x7d,
+	0xcf, 0x0d, 0xe9, 0xed, 0xbd, 0xbe, 0xbb, 0x8f, 0x26, 0x24, 0xc3, 0xc8, 0xaa, 0x2a, 0x95, 0x99,
+	0xd5, 0x5c, 0x75, 0xde, 0x18, 0x4b, 0xaa, 0x11, 0x79, 0x6c, 0xcc, 0x46, 0x87, 0xb0, 0x04, 0x4e,
+	0x0f, 0x60, 0xbe, 0x16, 0x74, 0xf6, 0x9c, 0x43, 0xfa, 0xdf, 0x1d, 0x7d, 0x11, 0x4a, 0xe3, 0x31,
+	0xc5, 0x0d, 0xf4, 0x6f, 0x12, 0x90, 0x6d, 0x11, 0xfb, 0x8e, 0x73, 0xc0, 0x68, 0x80, 0x45, 0x98,
+	0xd9, 0xa7, 0xc7, 0x3c, 0x7a, 0xd6, 0x8a, 0x3e, 0xf1, 0x1d, 0xc8, 0x78, 0x3e, 0x0d, 0x08, 0xf3,
+	0x02, 0x35, 0x51, 0x51, 0x56, 0xe7, 0xaa, 0xcb, 0x13, 0xf7, 0x1b, 0x78, 0x1b, 0x3b, 0x12, 0x68,
+	0x0d, 0x5c, 0xb0, 0x04, 0xc9, 0x43, 0x72, 0xd0, 0xa7, 0xea, 0x0c, 0xa7, 0x14, 0x0b, 0xfd, 0x3b,
+	0x05, 0x32, 0x31, 0x18, 0x01, 0x52, 0xf5, 0x07, 0x1f, 0xd5, 0xee, 0x35, 0x8b, 0x17, 0x70, 0x0e,
+	0x60, 0x7b, 0xa7, 0xd5, 0x96, 0x6b, 0x85, 0xef, 0x3d, 0xdc, 0x6a, 0xb6, 0x9a, 0xc5, 0x04, 0x16,
+	0x21, 0xdf, 0xb0, 0xea, 0xb5, 0x56, 0xdd, 0x6a, 0xb7, 0xde, 0xaf, 0x6d, 0x17, 0x67, 0xf0, 0x0a,
+	0x2c, 0x8c, 0x5a, 0xda, 0x3b, 0x96, 0xf0, 0x2c, 0xce, 0x62, 0x01, 0xb2, 0xf7, 0xea, 0xcd, 0xa6,
+	0x40, 0x26, 0x71, 0x11, 0x70, 0xb0, 0x1c, 0xc2, 0x52, 0x98, 0x85, 0xa4, 0x55, 0x6f, 0xd4, 0x1f,
+	0x16, 0xd3, 0xfa, 0xc9, 0x2c, 0x94, 0x78, 0x6a, 0x1e, 0x44, 0xfa, 0xb8, 0x4f, 0x02, 0xd2, 0xa3,
+	0x8c, 0x06, 0x21, 0x2e, 0x43, 0x3e, 0xa4, 0xc1, 0xa1, 0xd3, 0xa1, 0x6d, 0x97, 0xf4, 0xa8, 0x4c,
+	0x4e, 0x4e, 0xda, 0xb6, 0x49, 0x8f, 0xe2, 0x55, 0x98, 0x13, 0x37, 0x76, 0x3c, 0x57, 0x80, 0x12,
+	0x1c, 0x54, 0x18, 0x58, 0x39, 0xac, 0x06, 0xb3, 0x8c, 0xd8, 0xa1, 0x3a, 0xc3, 0xeb, 0x64, 0x6d,
+	0x32, 0x8f, 0xa7, 0x04, 0x8f, 0x92, 0x1b, 0xd6, 0x5d, 0x16, 0x1c, 0x5b, 0xdc, 0x15, 0xef, 0xc2,
+	0x5c, 0xc8, 0x48, 0xc0, 0xda, 0x91, 0xb0, 0xda, 0x3d, 0xc7, 0x55, 0x67, 0x2b, 0xca, 0x6a, 0xae,
+	0xaa, 0x19, 0x42, 0x58, 0x46, 0x2c, 0x2c, 0xa3, 0x15, 0x2b, 0x6f, 0x23, 0x13, 0x55, 0xd1, 0xb7,
+	0xbf, 0x2f, 0x29, 0x56, 0x9e, 0xfb, 0x46, 0x3b, 0x1f, 0x3a, 0xee, 0x24, 0x17, 0x39, 0x52, 0x93,
+	0x2f, 0xc7, 0x45, 0x8e, 0xf0, 0x0e, 0xe4, 0x63, 0x25, 0xf3, 0x53, 0xa5, 0x38, 0xd3, 0x95, 0x29,
+	0xa6, 0x4d, 0x09, 0x12, 0x44, 0x3f, 0x46, 0x44, 0xb9, 0xd8, 0x31, 0x3a, 0xd3, 0x18, 0x0f, 0x39,
+	0x52, 0xd3, 0x2f, 0xc3, 0x43, 0x8e, 0xc4, 0xa3, 0x91, 0xa0, 0xb3, 0xd7, 0xee, 0x52, 0x9f, 0xed,
+	0xa9, 0x99, 0x8a, 0xb2, 0x9a, 0xb4, 0x72, 0xc2, 0xb6, 0x19, 0x99, 0xf0, 0x26, 0xe4, 0x18, 0xb1,
+	0xdb, 0x8f, 0x78, 0xed, 0x86, 0x6a, 0x96, 0x3f, 0x8a, 0xfa, 0x6f, 0xc5, 0x6d, 0x01, 0x8b, 0x3f,
+	0x43, 0xed, 0x06, 0x64, 0x07, 0x0f, 0x73, 0x8a, 0x66, 0x06, 0x45, 0x9f, 0x18, 0x29, 0xfa, 0x5b,
+	0x89, 0xb7, 0x15, 0x7d, 0x1b, 0x2e, 0xdd, 0x71, 0xdc, 0x2e, 0x7f, 0xea, 0x30, 0xd6, 0xfd, 0x4d,
+	0x48, 0xf2, 0x9e, 0xcc, 0x29, 0x72, 0xd5, 0x95, 0x73, 0xd4, 0x85, 0x25, 0x3c, 0xf4, 0x12, 0x60,
+	0x83, 0xb2, 0xa6, 0x28, 0xc5, 0x98, 0x50, 0xbf, 0x0e, 0xf3, 0x63, 0x56, 0x21, 0x75, 0xd4, 0x20,
+	0x23, 0x8b, 0x56, 0xb4, 0xaa, 0xac, 0x35, 0x58, 0xeb, 0xeb, 0x50, 0x6a, 0x50, 0xb6, 0x13, 0x97,
+	0xeb, 0xe0, 0x6c, 0x2a, 0xa4, 0x25, 0x46, 0x5e, 0x30, 0x5e, 0xea, 0x37, 0x60, 0x61, 0xc2, 0x43,
+	0x86, 0x29, 0x03, 0x0c, 0xca, 0x3e, 0x0e, 0x34, 0x62, 0xd1, 0x7f, 0x52, 0x60, 0xb1, 0x41, 0xd9,
+	0x26, 0xf5, 0xa9, 0xdb, 0xa5, 0x6e, 0xc7, 0x19, 0x66, 0xe2, 0x36, 0xc0, 0xb0, 0x22, 0x55, 0xe5,
+	0x05, 0xaa, 0x31, 0x3b, 0xa8, 0x46, 0x7c, 0x17, 0x32, 0xd4, 0xed, 0x0a, 0x8a, 0xc4, 0x0b, 0x50,
+	0xa4, 0xa9, 0xdb, 0x8d, 0xec, 0xfa, 0x2e, 0x5c, 0x9e, 0x3a, 0x9f, 0xbc, 0x5b, 0x03, 0xf2, 0xdd,
+	0x11, 0xbb, 0xec, 0xf8, 0xaf, 0x4c, 0xbc, 0xd8, 0xc0, 0xf5, 0xf8, 0x9e, 0xe3, 0xee, 0xcb, 0xde,
+	0x3f, 0xe6, 0xa8, 0xaf, 0xc1, 0xa5, 0x68, 0x72, 0x11, 0xfb, 0x03, 0x7a, 0x7c, 0x8e, 0x64, 0xaf,
+	0x02, 0x8e, 0xc2, 0xe5, 0x69, 0x10, 0x66, 0xf7, 0xe9, 0x71, 0x9c, 0x63, 0xfe, 0xad, 0x7f, 0xc2,
+	0xdf, 0xbe, 0x45, 0xec, 0x8f, 0xa3, 0xa2, 0x7b, 0x3e, 0x75, 0x5c, 0xbe, 0x89, 0x61, 0xf9, 0x2e,
+	0x42, 0xca, 0x0f, 0xe8, 0x23, 0xe7, 0x48, 0x36, 0x6d, 0xb9, 0xd2, 0x0d, 0x28, 0x8d, 0x53, 0xcb,
+	0x63, 0x2c, 0x42, 0x8a, 0x57, 0x78, 0x7c, 0x10, 0xb9, 0xaa, 0xfe, 0x9d, 0x82, 0x3c, 0xaf, 0x5b,
+	0x59, 0x89, 0xb8, 0x0f, 0x99, 0x78, 0x5c, 0x63, 0x79, 0x22, 0x67, 0x13, 0x73, 0x5c, 0x5b, 0x3e,
+	0x65, 0x8a, 0x8e, 0xcf, 0x5d, 0x5d, 0xfb, 0xfa, 0xd7, 0xbf, 0x7e, 0x48, 0x94, 0x10, 0x4d, 0x3e,
+	0xe4, 0x42, 0xf3, 0xcb, 0x78, 0x7c, 0x7e, 0xb5, 0xae, 0x20, 0x83, 0xfc, 0xe8, 0xc0, 0x43, 0x7d,
+	0x82, 0xf0, 0x94, 0x09, 0xac, 0xad, 0x9c, 0x89, 0x91, 0x13, 0xf3, 0x7f, 0x3c, 0xec, 0x82, 0x3e,
+	0x6f, 0x12, 0xb1, 0x3d, 0x12, 0x17, 0x6d, 0x80, 0xa1, 0xc0, 0xb1, 0x32, 0xc1, 0x37, 0xa5, 0xfd,
+	0xf3, 0x5c, 0x13, 0x79, 0xbc, 0xbc, 0x9e, 0x36, 0x45, 0xf7, 0xba, 0xa5, 0x5c, 0x5b, 0x57, 0xd0,
+	0x86, 0xdc, 0x88, 0xc6, 0x71, 0x79, 0x3a, 0x9d, 0x13, 0x5d, 0x41, 0xd3, 0xcf, 0x82, 0xc8, 0xbb,
+	0x5d, 0xe2, 0xb1, 0x72, 0x98, 0x35, 0xe3, 0xce, 0x80, 0x1e, 0x14, 0xc6, 0x74, 0x8e, 0x2b, 0xd3,
+	0x3c, 0x53, 0x7d, 0x43, 0x7b, 0xf5, 0x6c, 0x90, 0x0c, 0x37, 0xcf, 0xc3, 0x15, 0x30, 0x67, 0x0e,
+	0xfb, 0x03, 0x3e, 0xe1, 0x7f, 0xea, 0x46, 0xe5, 0x87, 0x57, 0xa7, 0xd9, 0x4e, 0x69, 0x1f, 0xda,
+	0x6b, 0xcf, 0x83, 0xc9, 0xb0, 0x0b, 0x3c, 0xec, 0x45, 0x2c, 0x98, 0xa3, 0x9a, 0xc4, 0xcf, 0x01,
+	0x86, 0x22, 0x9b, 0x7a, 0xbb, 0x29, 0xb9, 0x6a, 0xcb, 0x67, 0x20, 0x64, 0xa4, 0x02, 0x8f, 0x94,
+	0xc6, 0xa4, 0xc9, 0xa7, 0x77, 0x0f, 0xf2, 0xa3, 0x0a, 0x42, 0xfd, 0x54, 0x86, 0x31, 0xe5, 0x6a,
+	0x2b, 0x67, 0x62, 0x64, 0x9c, 0x12, 0x8f, 0x33, 0x87, 0x79, 0x1e, 0xc7, 0x14, 0x02, 0xdc, 0x38,
+	0xfc, 0xbe, 0xb6, 0x81, 0xc9, 0xea, 0xcc, 0x75, 0x63, 0xfd, 0x5a, 0x42, 0x49, 0x04, 0x6f, 0x01,
+	0xdc, 0xe5, 0x5c, 0x95, 0xda, 0xfd, 0x2d, 0x7c, 0x7d, 0x8f, 0x31, 0x3f, 0xbc, 0x65, 0x9a, 0xcf,
+	0xf9, 0x73, 0xf9, 0xf4, 0xa4, 0xac, 0xfc, 0x72, 0x52, 0x56, 0xfe, 0x38, 0x29, 0x2b, 0x3f, 0xff,
+	0x59, 0x56, 0xe0, 0xb2, 0xe3, 0x19, 0x63, 0x40, 0x79, 0xb4, 0x4f, 0x53, 0xe2, 0x77, 0x37, 0xc5,
+	0xfb, 0xec, 0x9b, 0xff, 0x0c, 0x00, 0xb8, 0xaa, 0xd5, 0xb4, 0x72, 0x0c, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -747,6 +1094,8 @@ type QueryServiceClient interface {
 	GetServices(ctx context.Context, in *GetServicesRequest, opts ...grpc.CallOption) (*GetServicesResponse, error)
 	GetOperations(ctx context.Context, in *GetOperationsRequest, opts ...grpc.CallOption) (*GetOperationsResponse, error)
 	GetDependencies(ctx context.Context, in *GetDependenciesRequest, opts ...grpc.CallOption) (*GetDependenciesResponse, error)
+	GetTagKeys(ctx context.Context, in *GetTagKeysRequest, opts ...grpc.CallOption) (*GetTagKeysResponse, error)
+	GetTagValues(ctx context.Context, in *GetTagValuesRequest, opts ...grpc.CallOption) (*GetTagValuesResponse, error)
 }
 
 type queryServiceClient struct {
@@ -857,6 +1206,24 @@ func (c *queryServiceClient) GetDependencies(ctx context.Context, in *GetDepende
 	return out, nil
 }
 
+func (c *queryServiceClient) GetTagKeys(ctx context.Context, in *GetTagKeysRequest, opts ...grpc.CallOption) (*GetTagKeysResponse, error) {
+	out := new(GetTagKeysResponse)
+	err := c.cc.Invoke(ctx, "/jaeger.api_v2.QueryService/GetTagKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) GetTagValues(ctx context.Context, in *GetTagValuesRequest, opts ...grpc.CallOption) (*GetTagValuesResponse, error) {
+	out := new(GetTagValuesResponse)
+	err := c.cc.Invoke(ctx, "/jaeger.api_v2.QueryService/GetTagValues", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // QueryServiceServer is the server API for QueryService service.
 type QueryServiceServer interface {
 	GetTrace(*GetTraceRequest, QueryService_GetTraceServer) error
@@ -865,6 +1232,8 @@ type QueryServiceServer interface {
 	GetServices(context.Context, *GetServicesRequest) (*GetServicesResponse, error)
 	GetOperations(context.Context, *GetOperationsRequest) (*GetOperationsResponse, error)
 	GetDependencies(context.Context, *GetDependenciesRequest) (*GetDependenciesResponse, error)
+	GetTagKeys(context.Context, *GetTagKeysRequest) (*GetTagKeysResponse, error)
+	GetTagValues(context.Context, *GetTagValuesRequest) (*GetTagValuesResponse, error)
 }
 
 func RegisterQueryServiceServer(s *grpc.Server, srv QueryServiceServer) {
@@ -985,6 +1354,42 @@ func _QueryService_GetDependencies_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _QueryService_GetTagKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTagKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).GetTagKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jaeger.api_v2.QueryService/GetTagKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).GetTagKeys(ctx, req.(*GetTagKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_GetTagValues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTagValuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).GetTagValues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jaeger.api_v2.QueryService/GetTagValues",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).GetTagValues(ctx, req.(*GetTagValuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _QueryService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "jaeger.api_v2.QueryService",
 	HandlerType: (*QueryServiceServer)(nil),
@@ -1005,6 +1410,14 @@ var _QueryService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetDependencies",
 			Handler:    _QueryService_GetDependencies_Handler,
 		},
+		{
+			MethodName: "GetTagKeys",
+			Handler:    _QueryService_GetTagKeys_Handler,
+		},
+		{
+			MethodName: "GetTagValues",
+			Handler:    _QueryService_GetTagValues_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1133,7 +1546,7 @@ func (m *ArchiveTraceResponse) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *TraceQueryParameters) Marshal() (dAtA []byte, err error) {
+func (m *TagFilter) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1143,32 +1556,70 @@ func (m *TraceQueryParameters) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *TraceQueryParameters) MarshalTo(dAtA []byte) (int, error) {
+func (m *TagFilter) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.ServiceName) > 0 {
+	if len(m.Key) > 0 {
 		dAtA[i] = 0xa
 		i++
-		i = encodeVarintQuery(dAtA, i, uint64(len(m.ServiceName)))
-		i += copy(dAtA[i:], m.ServiceName)
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
 	}
-	if len(m.OperationName) > 0 {
-		dAtA[i] = 0x12
+	if m.Operator != 0 {
+		dAtA[i] = 0x10
 		i++
-		i = encodeVarintQuery(dAtA, i, uint64(len(m.OperationName)))
-		i += copy(dAtA[i:], m.OperationName)
+		i = encodeVarintQuery(dAtA, i, uint64(m.Operator))
 	}
-	if len(m.Tags) > 0 {
-		for k, _ := range m.Tags {
-			dAtA[i] = 0x1a
-			i++
-			v := m.Tags[k]
-			mapSize := 1 + len(k) + sovQuery(uint64(len(k))) + 1 + len(v) + sovQuery(uint64(len(v)))
-			i = encodeVarintQuery(dAtA, i, uint64(mapSize))
-			dAtA[i] = 0xa
-			i++
+	if len(m.Value) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Value)))
+		i += copy(dAtA[i:], m.Value)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *TraceQueryParameters) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TraceQueryParameters) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ServiceName) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.ServiceName)))
+		i += copy(dAtA[i:], m.ServiceName)
+	}
+	if len(m.OperationName) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.OperationName)))
+		i += copy(dAtA[i:], m.OperationName)
+	}
+	if len(m.Tags) > 0 {
+		for k, _ := range m.Tags {
+			dAtA[i] = 0x1a
+			i++
+			v := m.Tags[k]
+			mapSize := 1 + len(k) + sovQuery(uint64(len(k))) + 1 + len(v) + sovQuery(uint64(len(v)))
+			i = encodeVarintQuery(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0xa
+			i++
 			i = encodeVarintQuery(dAtA, i, uint64(len(k)))
 			i += copy(dAtA[i:], k)
 			dAtA[i] = 0x12
@@ -1214,6 +1665,18 @@ func (m *TraceQueryParameters) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintQuery(dAtA, i, uint64(m.SearchDepth))
 	}
+	if len(m.TagFilters) > 0 {
+		for _, msg := range m.TagFilters {
+			dAtA[i] = 0x4a
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1441,6 +1904,144 @@ func (m *GetDependenciesResponse) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *GetTagKeysRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetTagKeysRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Service) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Service)))
+		i += copy(dAtA[i:], m.Service)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetTagKeysResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetTagKeysResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetTagValuesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetTagValuesRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Service) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Service)))
+		i += copy(dAtA[i:], m.Service)
+	}
+	if len(m.Key) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Prefix) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Prefix)))
+		i += copy(dAtA[i:], m.Prefix)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *GetTagValuesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetTagValuesResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, s := range m.Values {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
 func encodeVarintQuery(dAtA []byte, offset int, v uint64) int {
 	for v >= 1<<7 {
 		dAtA[offset] = uint8(v&0x7f | 0x80)
@@ -1508,6 +2109,29 @@ func (m *ArchiveTraceResponse) Size() (n int) {
 	return n
 }
 
+func (m *TagFilter) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.Operator != 0 {
+		n += 1 + sovQuery(uint64(m.Operator))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func (m *TraceQueryParameters) Size() (n int) {
 	if m == nil {
 		return 0
@@ -1541,6 +2165,12 @@ func (m *TraceQueryParameters) Size() (n int) {
 	if m.SearchDepth != 0 {
 		n += 1 + sovQuery(uint64(m.SearchDepth))
 	}
+	if len(m.TagFilters) > 0 {
+		for _, e := range m.TagFilters {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1661,28 +2291,104 @@ func (m *GetDependenciesResponse) Size() (n int) {
 	return n
 }
 
-func sovQuery(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
+func (m *GetTagKeysRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Service)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetTagKeysResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			l = len(s)
+			n += 1 + l + sovQuery(uint64(l))
 		}
 	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
 	return n
 }
-func sozQuery(x uint64) (n int) {
-	return sovQuery(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+
+func (m *GetTagValuesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Service)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Prefix)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
 }
-func (m *GetTraceRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowQuery
+
+func (m *GetTagValuesResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, s := range m.Values {
+			l = len(s)
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovQuery(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozQuery(x uint64) (n int) {
+	return sovQuery(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *GetTraceRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
 			}
 			if iNdEx >= l {
 				return io.ErrUnexpectedEOF
@@ -1990,6 +2696,143 @@ func (m *ArchiveTraceResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *TagFilter) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TagFilter: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TagFilter: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operator", wireType)
+			}
+			m.Operator = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Operator |= TagFilter_Operator(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -2305,15 +3148,385 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.DurationMin, dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.DurationMin, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DurationMax", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.DurationMax, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SearchDepth", wireType)
+			}
+			m.SearchDepth = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SearchDepth |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TagFilters", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TagFilters = append(m.TagFilters, &TagFilter{})
+			if err := m.TagFilters[len(m.TagFilters)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FindTracesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FindTracesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Query == nil {
+				m.Query = &TraceQueryParameters{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetServicesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetServicesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetServicesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetServicesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetServicesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetServicesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Services", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Services = append(m.Services, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetOperationsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
 			}
-			iNdEx = postIndex
-		case 7:
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetOperationsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetOperationsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DurationMax", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Service", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2323,44 +3536,24 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthQuery
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.DurationMax, dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Service = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 8:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SearchDepth", wireType)
-			}
-			m.SearchDepth = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.SearchDepth |= int32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -2386,7 +3579,7 @@ func (m *TraceQueryParameters) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
+func (m *GetOperationsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2409,17 +3602,17 @@ func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FindTracesRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetOperationsResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FindTracesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetOperationsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Operations", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2429,27 +3622,23 @@ func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthQuery
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Query == nil {
-				m.Query = &TraceQueryParameters{}
-			}
-			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Operations = append(m.Operations, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2476,7 +3665,7 @@ func (m *FindTracesRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetServicesRequest) Unmarshal(dAtA []byte) error {
+func (m *GetDependenciesRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2499,12 +3688,78 @@ func (m *GetServicesRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetServicesRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetDependenciesRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetServicesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetDependenciesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.StartTime, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EndTime", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.EndTime, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -2530,7 +3785,7 @@ func (m *GetServicesRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetServicesResponse) Unmarshal(dAtA []byte) error {
+func (m *GetDependenciesResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2553,17 +3808,17 @@ func (m *GetServicesResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetServicesResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetDependenciesResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetServicesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetDependenciesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Services", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Dependencies", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2573,23 +3828,25 @@ func (m *GetServicesResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthQuery
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Services = append(m.Services, string(dAtA[iNdEx:postIndex]))
+			m.Dependencies = append(m.Dependencies, model.DependencyLink{})
+			if err := m.Dependencies[len(m.Dependencies)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2616,7 +3873,7 @@ func (m *GetServicesResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetOperationsRequest) Unmarshal(dAtA []byte) error {
+func (m *GetTagKeysRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2639,10 +3896,10 @@ func (m *GetOperationsRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetOperationsRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetTagKeysRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetOperationsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetTagKeysRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -2702,7 +3959,7 @@ func (m *GetOperationsRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetOperationsResponse) Unmarshal(dAtA []byte) error {
+func (m *GetTagKeysResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2725,15 +3982,15 @@ func (m *GetOperationsResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetOperationsResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetTagKeysResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetOperationsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetTagKeysResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Operations", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2761,7 +4018,7 @@ func (m *GetOperationsResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Operations = append(m.Operations, string(dAtA[iNdEx:postIndex]))
+			m.Keys = append(m.Keys, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2788,7 +4045,7 @@ func (m *GetOperationsResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetDependenciesRequest) Unmarshal(dAtA []byte) error {
+func (m *GetTagValuesRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2811,17 +4068,17 @@ func (m *GetDependenciesRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetDependenciesRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetTagValuesRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetDependenciesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetTagValuesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Service", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2831,30 +4088,29 @@ func (m *GetDependenciesRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthQuery
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.StartTime, dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Service = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field EndTime", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2864,24 +4120,55 @@ func (m *GetDependenciesRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthQuery
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.EndTime, dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prefix", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
 			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Prefix = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2908,7 +4195,7 @@ func (m *GetDependenciesRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetDependenciesResponse) Unmarshal(dAtA []byte) error {
+func (m *GetTagValuesResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2931,17 +4218,17 @@ func (m *GetDependenciesResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetDependenciesResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: GetTagValuesResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetDependenciesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: GetTagValuesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Dependencies", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2951,25 +4238,23 @@ func (m *GetDependenciesResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthQuery
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Dependencies = append(m.Dependencies, model.DependencyLink{})
-			if err := m.Dependencies[len(m.Dependencies)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Values = append(m.Values, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -17,11 +17,14 @@ package storage
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/uber/jaeger-lib/metrics"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/pkg/distributedlock"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -62,3 +65,20 @@ type ArchiveFactory interface {
 	// CreateArchiveSpanWriter creates a spanstore.Writer.
 	CreateArchiveSpanWriter() (spanstore.Writer, error)
 }
+
+// SamplingStoreFactory is an additional interface that can be implemented by a factory to support
+// adaptive sampling, which requires persisting throughput and probabilities, and a leader lock.
+type SamplingStoreFactory interface {
+	// CreateLock creates a distributedlock.Lock.
+	CreateLock() (distributedlock.Lock, error)
+
+	// CreateSamplingStore creates a samplingstore.Store.
+	CreateSamplingStore() (samplingstore.Store, error)
+}
+
+// AdminFactory is an additional interface that can be implemented by a factory to expose
+// maintenance operations of the backend, such as backups, on the admin HTTP server.
+type AdminFactory interface {
+	// AdminHandlers returns the HTTP handlers of the maintenance operations, keyed by route.
+	AdminHandlers() map[string]http.Handler
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocks
+
+import distributedlock "github.com/jaegertracing/jaeger/pkg/distributedlock"
+import mock "github.com/stretchr/testify/mock"
+import samplingstore "github.com/jaegertracing/jaeger/storage/samplingstore"
+
+// SamplingStoreFactory is an autogenerated mock type for the SamplingStoreFactory type
+type SamplingStoreFactory struct {
+	mock.Mock
+}
+
+// CreateLock provides a mock function with given fields:
+func (_m *SamplingStoreFactory) CreateLock() (distributedlock.Lock, error) {
+	ret := _m.Called()
+
+	var r0 distributedlock.Lock
+	if rf, ok := ret.Get(0).(func() distributedlock.Lock); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(distributedlock.Lock)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSamplingStore provides a mock function with given fields:
+func (_m *SamplingStoreFactory) CreateSamplingStore() (samplingstore.Store, error) {
+	ret := _m.Called()
+
+	var r0 samplingstore.Store
+	if rf, ok := ret.Get(0).(func() samplingstore.Store); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(samplingstore.Store)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -31,6 +31,9 @@ type Writer interface {
 var (
 	// ErrTraceNotFound is returned by Reader's GetTrace if no data is found for given trace ID.
 	ErrTraceNotFound = errors.New("trace not found")
+
+	// ErrTagsNotSupported is returned when the span storage cannot list tag keys and values.
+	ErrTagsNotSupported = errors.New("listing tag keys and values is not supported by the span storage")
 )
 
 // Reader finds and loads traces and other data from storage.
@@ -42,11 +45,21 @@ type Reader interface {
 	FindTraceIDs(ctx context.Context, query *TraceQueryParameters) ([]model.TraceID, error)
 }
 
+// TagReader is implemented by span readers that can list the tags of a service, e.g. for autocompletion.
+type TagReader interface {
+	// GetTagKeys returns the keys of the tags found in the spans of the service.
+	GetTagKeys(ctx context.Context, service string) ([]string, error)
+	// GetTagValues returns the values of the tag with the given key that start with prefix.
+	GetTagValues(ctx context.Context, service, key, prefix string) ([]string, error)
+}
+
 // TraceQueryParameters contains parameters of a trace query.
+// Tags are matched by equality, while TagFilters support the other TagOperators.
 type TraceQueryParameters struct {
 	ServiceName   string
 	OperationName string
 	Tags          map[string]string
+	TagFilters    []TagFilter
 	StartTimeMin  time.Time
 	StartTimeMax  time.Time
 	DurationMin   time.Duration
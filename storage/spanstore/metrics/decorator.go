@@ -33,6 +33,8 @@ type ReadMetricsDecorator struct {
 	getTraceMetrics      *queryMetrics
 	getServicesMetrics   *queryMetrics
 	getOperationsMetrics *queryMetrics
+	getTagKeysMetrics    *queryMetrics
+	getTagValuesMetrics  *queryMetrics
 }
 
 type queryMetrics struct {
@@ -63,6 +65,8 @@ func NewReadMetricsDecorator(spanReader spanstore.Reader, metricsFactory metrics
 		getTraceMetrics:      buildQueryMetrics("get_trace", metricsFactory),
 		getServicesMetrics:   buildQueryMetrics("get_services", metricsFactory),
 		getOperationsMetrics: buildQueryMetrics("get_operations", metricsFactory),
+		getTagKeysMetrics:    buildQueryMetrics("get_tag_keys", metricsFactory),
+		getTagValuesMetrics:  buildQueryMetrics("get_tag_values", metricsFactory),
 	}
 }
 
@@ -112,3 +116,27 @@ func (m *ReadMetricsDecorator) GetOperations(ctx context.Context, service string
 	m.getOperationsMetrics.emit(err, time.Since(start), len(retMe))
 	return retMe, err
 }
+
+// GetTagKeys implements spanstore.TagReader#GetTagKeys
+func (m *ReadMetricsDecorator) GetTagKeys(ctx context.Context, service string) ([]string, error) {
+	tagReader, ok := m.spanReader.(spanstore.TagReader)
+	if !ok {
+		return nil, spanstore.ErrTagsNotSupported
+	}
+	start := time.Now()
+	retMe, err := tagReader.GetTagKeys(ctx, service)
+	m.getTagKeysMetrics.emit(err, time.Since(start), len(retMe))
+	return retMe, err
+}
+
+// GetTagValues implements spanstore.TagReader#GetTagValues
+func (m *ReadMetricsDecorator) GetTagValues(ctx context.Context, service, key, prefix string) ([]string, error) {
+	tagReader, ok := m.spanReader.(spanstore.TagReader)
+	if !ok {
+		return nil, spanstore.ErrTagsNotSupported
+	}
+	start := time.Now()
+	retMe, err := tagReader.GetTagValues(ctx, service, key, prefix)
+	m.getTagValuesMetrics.emit(err, time.Since(start), len(retMe))
+	return retMe, err
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uber/jaeger-lib/metrics/metricstest"
 
 	"github.com/jaegertracing/jaeger/model"
@@ -127,3 +128,31 @@ func TestFailingUnderlyingCalls(t *testing.T) {
 
 	checkExpectedExistingAndNonExistentCounters(t, counters, expecteds, gauges, existingKeys, nonExistentKeys)
 }
+
+func TestTagReaderCalls(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+
+	tagReader := struct {
+		mocks.Reader
+		mocks.TagReader
+	}{}
+	mrs := NewReadMetricsDecorator(&tagReader, mf)
+	tagReader.TagReader.On("GetTagKeys", context.Background(), "something").Return([]string{"http.method"}, nil)
+	keys, err := mrs.GetTagKeys(context.Background(), "something")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http.method"}, keys)
+	tagReader.TagReader.On("GetTagValues", context.Background(), "something", "http.method", "G").Return(nil, errors.New("Failure"))
+	_, err = mrs.GetTagValues(context.Background(), "something", "http.method", "G")
+	assert.EqualError(t, err, "Failure")
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=get_tag_keys|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=get_tag_values|result=err"])
+
+	// readers that cannot list tags are reported as such
+	mrs = NewReadMetricsDecorator(&mocks.Reader{}, mf)
+	_, err = mrs.GetTagKeys(context.Background(), "something")
+	assert.Equal(t, spanstore.ErrTagsNotSupported, err)
+	_, err = mrs.GetTagValues(context.Background(), "something", "http.method", "")
+	assert.Equal(t, spanstore.ErrTagsNotSupported, err)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocks
+
+import context "context"
+import mock "github.com/stretchr/testify/mock"
+
+// TagReader is an autogenerated mock type for the TagReader type
+type TagReader struct {
+	mock.Mock
+}
+
+// GetTagKeys provides a mock function with given fields: ctx, service
+func (_m *TagReader) GetTagKeys(ctx context.Context, service string) ([]string, error) {
+	ret := _m.Called(ctx, service)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, service)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, service)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTagValues provides a mock function with given fields: ctx, service, key, prefix
+func (_m *TagReader) GetTagValues(ctx context.Context, service string, key string, prefix string) ([]string, error) {
+	ret := _m.Called(ctx, service, key, prefix)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) []string); ok {
+		r0 = rf(ctx, service, key, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, service, key, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanstore
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// ErrUnsupportedTagOperator is returned by span readers that cannot evaluate the operator of a tag filter.
+var ErrUnsupportedTagOperator = errors.New("tag filter operator is not supported by the span storage")
+
+// TagOperator is the comparison a TagFilter applies to the values of a tag.
+type TagOperator int
+
+const (
+	// TagEquals matches tags whose value is equal to the filter value.
+	TagEquals TagOperator = iota
+	// TagNotEquals matches spans that do not have the tag with the filter value.
+	TagNotEquals
+	// TagExists matches tags with the filter key, whatever their value.
+	TagExists
+	// TagGreaterThan matches tags whose numeric value is greater than the filter value.
+	TagGreaterThan
+	// TagGreaterThanOrEqual matches tags whose numeric value is greater than or equal to the filter value.
+	TagGreaterThanOrEqual
+	// TagLessThan matches tags whose numeric value is less than the filter value.
+	TagLessThan
+	// TagLessThanOrEqual matches tags whose numeric value is less than or equal to the filter value.
+	TagLessThanOrEqual
+	// TagRegex matches tags whose whole value matches the regular expression of the filter value.
+	TagRegex
+)
+
+var tagOperatorSymbols = []string{"=", "!=", "exists", ">", ">=", "<", "<=", "=~"}
+
+// ParseTagOperator returns the operator with the given symbol, e.g. ">=".
+func ParseTagOperator(symbol string) (TagOperator, error) {
+	for i, s := range tagOperatorSymbols {
+		if s == symbol {
+			return TagOperator(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown tag operator '%s'", symbol)
+}
+
+// String returns the symbol of the operator.
+func (o TagOperator) String() string {
+	if o < 0 || int(o) >= len(tagOperatorSymbols) {
+		return fmt.Sprintf("TagOperator(%d)", int(o))
+	}
+	return tagOperatorSymbols[o]
+}
+
+// IsNumeric returns true for the operators that compare the values as numbers.
+func (o TagOperator) IsNumeric() bool {
+	return o >= TagGreaterThan && o <= TagLessThanOrEqual
+}
+
+// TagFilter restricts a trace query to the spans whose tags satisfy the operator.
+// The span tags, the process tags and the log fields of a span are all considered.
+type TagFilter struct {
+	Key      string
+	Operator TagOperator
+	// Value is ignored by TagExists, must be a number for the numeric operators
+	// and a regular expression for TagRegex.
+	Value string
+}
+
+func (f TagFilter) String() string {
+	if f.Operator == TagExists {
+		return fmt.Sprintf("%s exists", f.Key)
+	}
+	return fmt.Sprintf("%s%s%s", f.Key, f.Operator, f.Value)
+}
+
+// EqualityTags returns the tags of the query merged with its TagEquals filters, for span readers that can
+// only look tags up by value. Any other operator fails with an error caused by ErrUnsupportedTagOperator.
+func (p *TraceQueryParameters) EqualityTags() (map[string]string, error) {
+	if len(p.TagFilters) == 0 {
+		return p.Tags, nil
+	}
+	tags := make(map[string]string, len(p.Tags)+len(p.TagFilters))
+	for k, v := range p.Tags {
+		tags[k] = v
+	}
+	for _, f := range p.TagFilters {
+		if f.Operator != TagEquals {
+			return nil, errors.Wrapf(ErrUnsupportedTagOperator, "cannot filter tag '%s' with operator '%s'", f.Key, f.Operator)
+		}
+		if v, ok := tags[f.Key]; ok && v != f.Value {
+			return nil, errors.Wrapf(ErrUnsupportedTagOperator, "cannot filter tag '%s' by several values", f.Key)
+		}
+		tags[f.Key] = f.Value
+	}
+	return tags, nil
+}
+
+// TagMatcher evaluates a TagFilter against the tags of a span.
+type TagMatcher struct {
+	filter TagFilter
+	number float64
+	regex  *regexp.Regexp
+}
+
+// NewTagMatcher validates the filter and prepares it for matching.
+func NewTagMatcher(filter TagFilter) (*TagMatcher, error) {
+	m := &TagMatcher{filter: filter}
+	switch {
+	case filter.Operator.IsNumeric():
+		number, err := strconv.ParseFloat(filter.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("tag filter '%s' requires a numeric value", filter)
+		}
+		m.number = number
+	case filter.Operator == TagRegex:
+		regex, err := regexp.Compile("^(?:" + filter.Value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("tag filter '%s' has an invalid regular expression: %v", filter, err)
+		}
+		m.regex = regex
+	case filter.Operator < TagEquals || filter.Operator > TagRegex:
+		return nil, fmt.Errorf("tag filter on '%s' has an unknown operator %s", filter.Key, filter.Operator)
+	}
+	return m, nil
+}
+
+// NewTagMatchers returns a matcher for each of the filters.
+func NewTagMatchers(filters []TagFilter) ([]*TagMatcher, error) {
+	matchers := make([]*TagMatcher, len(filters))
+	for i, f := range filters {
+		m, err := NewTagMatcher(f)
+		if err != nil {
+			return nil, err
+		}
+		matchers[i] = m
+	}
+	return matchers, nil
+}
+
+// Matches returns true if the tags satisfy the filter.
+func (m *TagMatcher) Matches(tags model.KeyValues) bool {
+	if m.filter.Operator == TagNotEquals {
+		// (NB): there can be multiple tags with the same key
+		for _, kv := range tags {
+			if kv.Key == m.filter.Key && kv.AsString() == m.filter.Value {
+				return false
+			}
+		}
+		return true
+	}
+	for _, kv := range tags {
+		if kv.Key == m.filter.Key && m.matchesValue(kv) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *TagMatcher) matchesValue(kv model.KeyValue) bool {
+	switch m.filter.Operator {
+	case TagEquals:
+		return kv.AsString() == m.filter.Value
+	case TagExists:
+		return true
+	case TagRegex:
+		return m.regex.MatchString(kv.AsString())
+	}
+	var value float64
+	switch kv.VType {
+	case model.Int64Type:
+		value = float64(kv.Int64())
+	case model.Float64Type:
+		value = kv.Float64()
+	case model.StringType:
+		parsed, err := strconv.ParseFloat(kv.VStr, 64)
+		if err != nil {
+			return false
+		}
+		value = parsed
+	default:
+		return false
+	}
+	switch m.filter.Operator {
+	case TagGreaterThan:
+		return value > m.number
+	case TagGreaterThanOrEqual:
+		return value >= m.number
+	case TagLessThan:
+		return value < m.number
+	default:
+		return value <= m.number
+	}
+}
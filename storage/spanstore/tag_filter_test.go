@@ -0,0 +1,119 @@
+// Copyright (c) 2019 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanstore
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestParseTagOperator(t *testing.T) {
+	for _, op := range []TagOperator{TagEquals, TagNotEquals, TagExists, TagGreaterThan, TagGreaterThanOrEqual, TagLessThan, TagLessThanOrEqual, TagRegex} {
+		parsed, err := ParseTagOperator(op.String())
+		require.NoError(t, err)
+		assert.Equal(t, op, parsed)
+	}
+	_, err := ParseTagOperator("~")
+	assert.EqualError(t, err, "unknown tag operator '~'")
+	assert.Equal(t, "TagOperator(42)", TagOperator(42).String())
+}
+
+func TestTagFilterString(t *testing.T) {
+	assert.Equal(t, "error exists", TagFilter{Key: "error", Operator: TagExists, Value: "ignored"}.String())
+	assert.Equal(t, "http.status_code>=500", TagFilter{Key: "http.status_code", Operator: TagGreaterThanOrEqual, Value: "500"}.String())
+}
+
+func TestNewTagMatcherErrors(t *testing.T) {
+	_, err := NewTagMatcher(TagFilter{Key: "k", Operator: TagGreaterThan, Value: "abc"})
+	assert.EqualError(t, err, "tag filter 'k>abc' requires a numeric value")
+
+	_, err = NewTagMatcher(TagFilter{Key: "k", Operator: TagRegex, Value: "("})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tag filter 'k=~(' has an invalid regular expression")
+
+	_, err = NewTagMatchers([]TagFilter{{Key: "k", Operator: TagOperator(42)}})
+	assert.EqualError(t, err, "tag filter on 'k' has an unknown operator TagOperator(42)")
+}
+
+func TestTagMatcherMatches(t *testing.T) {
+	tags := model.KeyValues{
+		model.String("http.method", "GET"),
+		model.Int64("http.status_code", 503),
+		model.Float64("ratio", 0.5),
+		model.String("retries", "3"),
+		model.Bool("error", true),
+		model.String("peer", "frontend"),
+		model.String("peer", "backend"),
+	}
+	testCases := []struct {
+		filter  TagFilter
+		matches bool
+	}{
+		{TagFilter{Key: "http.method", Operator: TagEquals, Value: "GET"}, true},
+		{TagFilter{Key: "http.method", Operator: TagEquals, Value: "POST"}, false},
+		{TagFilter{Key: "http.method", Operator: TagNotEquals, Value: "POST"}, true},
+		{TagFilter{Key: "http.method", Operator: TagNotEquals, Value: "GET"}, false},
+		{TagFilter{Key: "missing", Operator: TagNotEquals, Value: "GET"}, true},
+		{TagFilter{Key: "peer", Operator: TagNotEquals, Value: "backend"}, false},
+		{TagFilter{Key: "error", Operator: TagExists}, true},
+		{TagFilter{Key: "missing", Operator: TagExists}, false},
+		{TagFilter{Key: "http.status_code", Operator: TagGreaterThan, Value: "500"}, true},
+		{TagFilter{Key: "http.status_code", Operator: TagGreaterThan, Value: "503"}, false},
+		{TagFilter{Key: "http.status_code", Operator: TagGreaterThanOrEqual, Value: "503"}, true},
+		{TagFilter{Key: "http.status_code", Operator: TagLessThan, Value: "503"}, false},
+		{TagFilter{Key: "http.status_code", Operator: TagLessThanOrEqual, Value: "503"}, true},
+		{TagFilter{Key: "ratio", Operator: TagLessThan, Value: "0.75"}, true},
+		{TagFilter{Key: "retries", Operator: TagGreaterThanOrEqual, Value: "3"}, true},
+		{TagFilter{Key: "http.method", Operator: TagGreaterThan, Value: "0"}, false},
+		{TagFilter{Key: "error", Operator: TagGreaterThan, Value: "0"}, false},
+		{TagFilter{Key: "peer", Operator: TagRegex, Value: "back.*"}, true},
+		{TagFilter{Key: "peer", Operator: TagRegex, Value: "end"}, false},
+		{TagFilter{Key: "http.status_code", Operator: TagRegex, Value: "5\\d\\d"}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filter.String(), func(t *testing.T) {
+			m, err := NewTagMatcher(tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.matches, m.Matches(tags))
+		})
+	}
+}
+
+func TestEqualityTags(t *testing.T) {
+	query := &TraceQueryParameters{Tags: map[string]string{"a": "1"}}
+	tags, err := query.EqualityTags()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1"}, tags)
+
+	query.TagFilters = []TagFilter{{Key: "a", Operator: TagEquals, Value: "1"}, {Key: "b", Operator: TagEquals, Value: "2"}}
+	tags, err = query.EqualityTags()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, tags)
+	assert.Equal(t, map[string]string{"a": "1"}, query.Tags)
+
+	query.TagFilters = []TagFilter{{Key: "a", Operator: TagEquals, Value: "2"}}
+	_, err = query.EqualityTags()
+	assert.EqualError(t, err, "cannot filter tag 'a' by several values: "+ErrUnsupportedTagOperator.Error())
+
+	query.TagFilters = []TagFilter{{Key: "b", Operator: TagRegex, Value: ".*"}}
+	_, err = query.EqualityTags()
+	assert.Equal(t, ErrUnsupportedTagOperator, errors.Cause(err))
+	assert.EqualError(t, err, "cannot filter tag 'b' with operator '=~': "+ErrUnsupportedTagOperator.Error())
+}